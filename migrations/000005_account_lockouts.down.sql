@@ -0,0 +1,4 @@
+-- Rollback account lockouts
+DROP INDEX IF EXISTS idx_login_attempts_ip_failed;
+DROP INDEX IF EXISTS idx_login_attempts_username_failed;
+DROP TABLE IF EXISTS account_lockouts;
@@ -0,0 +1,29 @@
+-- Account lockout migration for ATLAS Core API
+-- Version: 000005
+-- Description: Per-username and per-IP lockout state for IAM brute-force protection
+
+-- ========================================
+-- Account Lockouts
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS account_lockouts (
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('username', 'ip')),
+    subject VARCHAR(255) NOT NULL,
+    locked_until TIMESTAMP,
+    lockout_count INTEGER NOT NULL DEFAULT 0,
+    reset_at TIMESTAMP,
+    reason VARCHAR(255),
+    unlocked_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    unlocked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (scope, subject)
+);
+
+CREATE INDEX idx_account_lockouts_active ON account_lockouts(locked_until) WHERE locked_until IS NOT NULL;
+
+-- Failed-attempt counting filters on success within a window
+CREATE INDEX idx_login_attempts_username_failed ON login_attempts(username, created_at DESC) WHERE success = false;
+CREATE INDEX idx_login_attempts_ip_failed ON login_attempts(ip_address, created_at DESC) WHERE success = false;
+
+COMMENT ON TABLE account_lockouts IS 'Temporary lockouts applied after repeated failed logins per username or IP';
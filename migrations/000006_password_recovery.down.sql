@@ -0,0 +1,4 @@
+-- Rollback password recovery
+ALTER TABLE users DROP COLUMN IF EXISTS password_changed_at;
+DROP TABLE IF EXISTS password_history;
+DROP TABLE IF EXISTS user_tokens;
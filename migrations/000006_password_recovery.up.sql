@@ -0,0 +1,39 @@
+-- Password recovery migration for ATLAS Core API
+-- Version: 000006
+-- Description: Single-use reset/verification tokens and password history for IAM
+
+-- ========================================
+-- User Tokens
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(30) NOT NULL CHECK (purpose IN ('password_reset', 'email_verification')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    requested_ip VARCHAR(45),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_user_tokens_user_purpose ON user_tokens(user_id, purpose) WHERE used_at IS NULL;
+CREATE INDEX idx_user_tokens_expires_at ON user_tokens(expires_at);
+
+-- ========================================
+-- Password History
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS password_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_history_user ON password_history(user_id, created_at DESC);
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+COMMENT ON TABLE user_tokens IS 'Hashed single-use tokens for password reset and email verification';
+COMMENT ON TABLE password_history IS 'Previous password hashes used to prevent reuse';
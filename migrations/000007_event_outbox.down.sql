@@ -0,0 +1,2 @@
+-- Rollback event outbox
+DROP TABLE IF EXISTS event_outbox;
@@ -0,0 +1,28 @@
+-- Event outbox migration for ATLAS Core API
+-- Version: 000007
+-- Description: Transactional outbox for IAM domain events relayed to Kafka
+
+-- ========================================
+-- Event Outbox
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS event_outbox (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    aggregate_id VARCHAR(255) NOT NULL,
+    topic VARCHAR(255) NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    claimed_until TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    published_at TIMESTAMP
+);
+
+-- Relay scans pending rows in insertion order and checks per-aggregate predecessors
+CREATE INDEX idx_event_outbox_pending ON event_outbox(id) WHERE published_at IS NULL;
+CREATE INDEX idx_event_outbox_aggregate_pending ON event_outbox(aggregate_id, id) WHERE published_at IS NULL;
+CREATE INDEX idx_event_outbox_published_at ON event_outbox(published_at) WHERE published_at IS NOT NULL;
+
+COMMENT ON TABLE event_outbox IS 'Domain events written in the same transaction as state changes, relayed to Kafka in per-aggregate order';
@@ -0,0 +1,2 @@
+-- Rollback user versioning
+ALTER TABLE users DROP COLUMN IF EXISTS version;
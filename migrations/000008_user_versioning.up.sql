@@ -0,0 +1,7 @@
+-- User versioning migration for ATLAS Core API
+-- Version: 000008
+-- Description: Optimistic concurrency version for the IAM user aggregate
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS version INTEGER NOT NULL DEFAULT 1;
+
+COMMENT ON COLUMN users.version IS 'Incremented on every aggregate write; updates must match the version they read';
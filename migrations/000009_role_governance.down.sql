@@ -0,0 +1,8 @@
+-- Rollback role governance
+DROP TABLE IF EXISTS role_grant_requests;
+DROP TABLE IF EXISTS role_exclusions;
+DROP TABLE IF EXISTS role_delegations;
+ALTER TABLE roles DROP COLUMN IF EXISTS requires_approval;
+DROP INDEX IF EXISTS idx_user_roles_expires_at;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS assigned_by;
+ALTER TABLE user_roles DROP COLUMN IF EXISTS expires_at;
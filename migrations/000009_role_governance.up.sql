@@ -0,0 +1,72 @@
+-- Role governance migration for ATLAS Core API
+-- Version: 000009
+-- Description: Delegated role administration, separation of duties,
+--              time-bound role assignments and approval of privileged grants
+
+-- ========================================
+-- Time-bound Assignments
+-- ========================================
+
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+ALTER TABLE user_roles ADD COLUMN IF NOT EXISTS assigned_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_user_roles_expires_at ON user_roles(expires_at) WHERE expires_at IS NOT NULL;
+
+-- Privileged roles are only granted through an approved request
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS requires_approval BOOLEAN NOT NULL DEFAULT false;
+UPDATE roles SET requires_approval = true WHERE name = 'admin';
+
+-- ========================================
+-- Delegated Administration
+-- ========================================
+
+-- Holders of grantor_role may assign and revoke assignable_role
+CREATE TABLE IF NOT EXISTS role_delegations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grantor_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    assignable_role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (grantor_role_id, assignable_role_id)
+);
+
+-- ========================================
+-- Separation of Duties
+-- ========================================
+
+-- Pairs of roles no user may hold at the same time; stored with role_a < role_b
+CREATE TABLE IF NOT EXISTS role_exclusions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    role_a_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    role_b_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CHECK (role_a_id < role_b_id),
+    UNIQUE (role_a_id, role_b_id)
+);
+
+-- ========================================
+-- Role Grant Requests
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS role_grant_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    requested_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    reason TEXT,
+    grant_expires_at TIMESTAMP,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT,
+    decided_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_role_grant_requests_status ON role_grant_requests(status, created_at);
+CREATE UNIQUE INDEX idx_role_grant_requests_pending ON role_grant_requests(user_id, role_id) WHERE status = 'pending';
+
+COMMENT ON TABLE role_delegations IS 'Which roles may assign which other roles, below the global admin';
+COMMENT ON TABLE role_exclusions IS 'Separation-of-duties constraints between mutually exclusive roles';
+COMMENT ON TABLE role_grant_requests IS 'Approval workflow for roles flagged requires_approval';
@@ -0,0 +1,4 @@
+-- Rollback privileged access elevation
+DROP TABLE IF EXISTS role_elevations;
+DROP TABLE IF EXISTS break_glass_accounts;
+DROP TABLE IF EXISTS role_elevation_policies;
@@ -0,0 +1,61 @@
+-- Privileged access elevation migration for ATLAS Core API
+-- Version: 000010
+-- Description: Just-in-time role elevation with approval, automatic expiry
+--              and break-glass accounts
+
+-- ========================================
+-- Elevation Policies
+-- ========================================
+
+-- Only roles with a policy can be requested as a temporary elevation
+CREATE TABLE IF NOT EXISTS role_elevation_policies (
+    role_id UUID PRIMARY KEY REFERENCES roles(id) ON DELETE CASCADE,
+    max_duration_minutes INTEGER NOT NULL CHECK (max_duration_minutes > 0),
+    updated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO role_elevation_policies (role_id, max_duration_minutes)
+SELECT id, 240 FROM roles WHERE name = 'admin'
+ON CONFLICT (role_id) DO NOTHING;
+
+-- Accounts allowed to elevate without approval in an emergency
+CREATE TABLE IF NOT EXISTS break_glass_accounts (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    reason TEXT,
+    designated_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ========================================
+-- Elevations
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS role_elevations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    incident_ref VARCHAR(255),
+    duration_seconds INTEGER NOT NULL CHECK (duration_seconds > 0),
+    break_glass BOOLEAN NOT NULL DEFAULT false,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'active', 'rejected', 'expired', 'ended')),
+    decided_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    decision_note TEXT,
+    decided_at TIMESTAMP,
+    activated_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    ended_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    ended_at TIMESTAMP,
+    requested_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_role_elevations_requested ON role_elevations(requested_at DESC);
+CREATE INDEX idx_role_elevations_user ON role_elevations(user_id, requested_at DESC);
+CREATE INDEX idx_role_elevations_active ON role_elevations(expires_at) WHERE status = 'active';
+CREATE UNIQUE INDEX idx_role_elevations_open ON role_elevations(user_id, role_id) WHERE status IN ('pending', 'active');
+
+COMMENT ON TABLE role_elevation_policies IS 'Roles eligible for just-in-time elevation and their maximum duration';
+COMMENT ON TABLE break_glass_accounts IS 'Accounts that may self-elevate without approval in an emergency';
+COMMENT ON TABLE role_elevations IS 'Temporary privileged role elevations, kept for compliance reporting';
@@ -0,0 +1,3 @@
+-- Rollback settings history and seeded defaults
+DELETE FROM system_config WHERE namespace IN ('user_defaults', 'platform', 'retention') AND updated_by IS NULL;
+DROP TABLE IF EXISTS settings_history;
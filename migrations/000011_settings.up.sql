@@ -0,0 +1,52 @@
+-- Settings migration for ATLAS Core API
+-- Version: 000011
+-- Description: Change history for user settings, system configuration and
+--              notification preferences, and seeded defaults
+
+-- ========================================
+-- Settings History
+-- ========================================
+
+CREATE TABLE IF NOT EXISTS settings_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    scope VARCHAR(20) NOT NULL CHECK (scope IN ('user', 'system', 'notification')),
+    -- Owner of a user setting or notification preference; NULL for system settings
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    -- Category, system namespace or notification type
+    category VARCHAR(100) NOT NULL,
+    key VARCHAR(255) NOT NULL,
+    old_value JSONB,
+    new_value JSONB,
+    redacted BOOLEAN NOT NULL DEFAULT false,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_settings_history_user ON settings_history(user_id, changed_at DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_settings_history_system ON settings_history(category, changed_at DESC) WHERE scope = 'system';
+
+COMMENT ON TABLE settings_history IS 'Append-only history of settings and notification preference changes';
+
+-- ========================================
+-- Defaults
+-- ========================================
+
+-- Organisation-wide defaults for user settings; users override them per key
+INSERT INTO system_config (namespace, key, value, description) VALUES
+    ('user_defaults', 'general.language', '"en"', 'Default interface language'),
+    ('user_defaults', 'general.timezone', '"UTC"', 'Default time zone'),
+    ('user_defaults', 'general.date_format', '"YYYY-MM-DD"', 'Default date format'),
+    ('user_defaults', 'appearance.theme', '"system"', 'Default colour theme'),
+    ('user_defaults', 'appearance.density', '"comfortable"', 'Default table density'),
+    ('user_defaults', 'dashboard.default_view', '"overview"', 'Default landing page'),
+    ('user_defaults', 'dashboard.refresh_interval_seconds', '60', 'Default dashboard refresh interval'),
+    ('user_defaults', 'dashboard.pinned_widgets', '[]', 'Default pinned widgets'),
+    ('user_defaults', 'notifications.email_digest', '"daily"', 'Default email digest frequency'),
+    ('user_defaults', 'notifications.sound', 'true', 'Default urgent notification sound'),
+    ('user_defaults', 'security.session_timeout_minutes', '60', 'Default idle sign-out'),
+    ('user_defaults', 'security.login_alerts', 'true', 'Default new-IP sign-in alerts'),
+    ('platform', 'maintenance_mode', 'false', 'Show the maintenance banner and block non-admin writes in clients'),
+    ('platform', 'banner_message', '""', 'Message shown to every user at the top of the client'),
+    ('platform', 'support_email', '""', 'Contact address shown in the client'),
+    ('retention', 'notification_days', '90', 'Days read notifications are kept')
+ON CONFLICT (namespace, key) DO NOTHING;
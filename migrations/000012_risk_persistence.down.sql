@@ -0,0 +1,43 @@
+-- Rollback risk persistence
+
+-- Assessments of entities without a UUID cannot be converted back, and
+-- deleting them cascades to the alerts raised from them. Copy both, with
+-- every column, before the columns are dropped; once 000012 is re-applied
+-- the rows can be restored from the backup tables.
+CREATE TABLE IF NOT EXISTS risk_assessments_000012_backup AS
+    SELECT * FROM risk_assessments WITH NO DATA;
+INSERT INTO risk_assessments_000012_backup
+    SELECT * FROM risk_assessments
+    WHERE entity_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+
+CREATE TABLE IF NOT EXISTS risk_alerts_000012_backup AS
+    SELECT * FROM risk_alerts WITH NO DATA;
+INSERT INTO risk_alerts_000012_backup
+    SELECT a.* FROM risk_alerts a
+    JOIN risk_assessments_000012_backup b ON b.id = a.assessment_id;
+
+DROP INDEX IF EXISTS idx_alerts_entity;
+ALTER TABLE risk_alerts
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS last_triggered_at,
+    DROP COLUMN IF EXISTS is_triggered,
+    DROP COLUMN IF EXISTS is_active,
+    DROP COLUMN IF EXISTS condition,
+    DROP COLUMN IF EXISTS threshold,
+    DROP COLUMN IF EXISTS dimension,
+    DROP COLUMN IF EXISTS entity_id;
+ALTER TABLE risk_alerts ALTER COLUMN alert_type DROP DEFAULT;
+ALTER TABLE risk_alerts ALTER COLUMN severity DROP DEFAULT;
+
+DROP INDEX IF EXISTS idx_risk_dimensions;
+DROP INDEX IF EXISTS idx_risk_entity_assessed;
+ALTER TABLE risk_assessments
+    DROP COLUMN IF EXISTS valid_until,
+    DROP COLUMN IF EXISTS assessed_at,
+    DROP COLUMN IF EXISTS factors,
+    DROP COLUMN IF EXISTS dimensions,
+    DROP COLUMN IF EXISTS confidence;
+
+-- Drop the rows copied above so entity_id can narrow back to UUID
+DELETE FROM risk_assessments WHERE entity_id !~* '^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$';
+ALTER TABLE risk_assessments ALTER COLUMN entity_id TYPE UUID USING entity_id::uuid;
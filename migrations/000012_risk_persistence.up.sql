@@ -0,0 +1,47 @@
+-- Risk persistence migration for ATLAS Core API
+-- Version: 000012
+-- Description: Store full risk assessments (dimensions and factors as JSONB)
+--              and threshold alert rules for the risk-assessment service
+
+-- ========================================
+-- Risk Assessments
+-- ========================================
+
+-- Assessed entities come from many sources and are not always UUIDs
+ALTER TABLE risk_assessments ALTER COLUMN entity_id TYPE VARCHAR(255) USING entity_id::text;
+
+ALTER TABLE risk_assessments
+    ADD COLUMN IF NOT EXISTS confidence DECIMAL(5,4) CHECK (confidence >= 0 AND confidence <= 1),
+    ADD COLUMN IF NOT EXISTS dimensions JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS factors JSONB NOT NULL DEFAULT '[]',
+    ADD COLUMN IF NOT EXISTS assessed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS valid_until TIMESTAMP;
+
+-- Rows written before this migration keep their creation time
+UPDATE risk_assessments SET assessed_at = created_at WHERE created_at IS NOT NULL;
+
+-- History and trend queries scan one entity in time order
+CREATE INDEX IF NOT EXISTS idx_risk_entity_assessed ON risk_assessments(entity_id, assessed_at DESC);
+-- Trend queries filtered to one dimension use the ? operator
+CREATE INDEX IF NOT EXISTS idx_risk_dimensions ON risk_assessments USING GIN (dimensions);
+
+-- ========================================
+-- Risk Alerts
+-- ========================================
+
+-- Threshold rules share the table with raised alerts; a rule has no
+-- assessment until it fires
+ALTER TABLE risk_alerts ALTER COLUMN severity SET DEFAULT 'medium';
+ALTER TABLE risk_alerts ALTER COLUMN alert_type SET DEFAULT 'threshold';
+
+ALTER TABLE risk_alerts
+    ADD COLUMN IF NOT EXISTS entity_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS dimension VARCHAR(50),
+    ADD COLUMN IF NOT EXISTS threshold DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS condition VARCHAR(20) CHECK (condition IN ('above', 'below', 'equals')),
+    ADD COLUMN IF NOT EXISTS is_active BOOLEAN NOT NULL DEFAULT true,
+    ADD COLUMN IF NOT EXISTS is_triggered BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS last_triggered_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_entity ON risk_alerts(entity_id) WHERE is_active;
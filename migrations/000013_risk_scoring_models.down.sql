@@ -0,0 +1,6 @@
+-- Rollback risk scoring models
+DROP INDEX IF EXISTS idx_risk_scoring_model;
+ALTER TABLE risk_assessments
+    DROP COLUMN IF EXISTS scoring_model_version,
+    DROP COLUMN IF EXISTS scoring_model_id;
+DROP TABLE IF EXISTS risk_scoring_models;
@@ -0,0 +1,37 @@
+-- Risk scoring models migration for ATLAS Core API
+-- Version: 000013
+-- Description: Versioned scoring model configurations per entity type and
+--              the model version behind each assessment
+
+-- ========================================
+-- Scoring Models
+-- ========================================
+
+-- entity_type '*' is the default model for types without their own
+CREATE TABLE IF NOT EXISTS risk_scoring_models (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    entity_type VARCHAR(100) NOT NULL,
+    version INTEGER NOT NULL CHECK (version > 0),
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft' CHECK (status IN ('draft', 'active', 'retired')),
+    dimensions JSONB NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    activated_at TIMESTAMP,
+    UNIQUE (entity_type, version)
+);
+
+-- At most one active version per entity type
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scoring_models_active
+    ON risk_scoring_models(entity_type) WHERE status = 'active';
+
+-- ========================================
+-- Assessment Provenance
+-- ========================================
+
+ALTER TABLE risk_assessments
+    ADD COLUMN IF NOT EXISTS scoring_model_id UUID REFERENCES risk_scoring_models(id),
+    ADD COLUMN IF NOT EXISTS scoring_model_version INTEGER;
+
+CREATE INDEX IF NOT EXISTS idx_risk_scoring_model ON risk_assessments(scoring_model_id);
@@ -0,0 +1,5 @@
+-- Rollback risk confidence
+DROP INDEX IF EXISTS idx_risk_low_confidence;
+ALTER TABLE risk_assessments
+    DROP COLUMN IF EXISTS warnings,
+    DROP COLUMN IF EXISTS low_confidence;
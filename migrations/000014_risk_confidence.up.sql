@@ -0,0 +1,10 @@
+-- Risk confidence migration for ATLAS Core API
+-- Version: 000014
+-- Description: Flag assessments whose providers failed or whose data
+--              confidence is low
+
+ALTER TABLE risk_assessments
+    ADD COLUMN IF NOT EXISTS low_confidence BOOLEAN NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS warnings JSONB NOT NULL DEFAULT '[]';
+
+CREATE INDEX IF NOT EXISTS idx_risk_low_confidence ON risk_assessments(assessed_at DESC) WHERE low_confidence;
@@ -0,0 +1,3 @@
+-- Rollback risk assessment jobs
+DROP TABLE IF EXISTS risk_assessment_job_items;
+DROP TABLE IF EXISTS risk_assessment_jobs;
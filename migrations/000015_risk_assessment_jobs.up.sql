@@ -0,0 +1,38 @@
+-- Risk assessment jobs migration for ATLAS Core API
+-- Version: 000015
+-- Description: Batch assessment jobs and the per-entity outcome of each,
+--              kept so unfinished jobs resume after a restart
+
+CREATE TABLE IF NOT EXISTS risk_assessment_jobs (
+    id UUID PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    dimensions JSONB NOT NULL DEFAULT '[]',
+    include_factors BOOLEAN NOT NULL DEFAULT false,
+    total INTEGER NOT NULL,
+    succeeded INTEGER NOT NULL DEFAULT 0,
+    failed INTEGER NOT NULL DEFAULT 0,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT chk_job_status CHECK (status IN ('queued', 'running', 'completed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_unfinished ON risk_assessment_jobs(created_at) WHERE status <> 'completed';
+
+CREATE TABLE IF NOT EXISTS risk_assessment_job_items (
+    job_id UUID NOT NULL REFERENCES risk_assessment_jobs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    entity_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(100) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending',
+    assessment_id UUID,
+    overall_score DECIMAL(5,2),
+    degraded BOOLEAN NOT NULL DEFAULT false,
+    error TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (job_id, position),
+    CONSTRAINT chk_job_item_status CHECK (status IN ('pending', 'succeeded', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_job_items_status ON risk_assessment_job_items(job_id, status, position);
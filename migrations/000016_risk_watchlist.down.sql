@@ -0,0 +1,2 @@
+-- Rollback risk watchlist
+DROP TABLE IF EXISTS risk_watchlist;
@@ -0,0 +1,26 @@
+-- Risk watchlist migration for ATLAS Core API
+-- Version: 000016
+-- Description: Entities reassessed on a schedule, with the scores of their
+--              last run kept for change detection
+
+CREATE TABLE IF NOT EXISTS risk_watchlist (
+    id UUID PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(100) NOT NULL,
+    dimensions JSONB NOT NULL DEFAULT '[]',
+    schedule VARCHAR(100) NOT NULL,
+    change_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.05,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    next_run_at TIMESTAMP NOT NULL,
+    last_run_at TIMESTAMP,
+    last_assessment_id UUID,
+    last_score DOUBLE PRECISION,
+    last_dimension_scores JSONB,
+    last_error TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT uq_watchlist_entity UNIQUE (entity_id, entity_type)
+);
+
+CREATE INDEX IF NOT EXISTS idx_watchlist_due ON risk_watchlist(next_run_at) WHERE is_active;
@@ -0,0 +1,10 @@
+-- Rollback risk alert lifecycle
+ALTER TABLE risk_alerts
+    DROP COLUMN IF EXISTS resolution,
+    DROP COLUMN IF EXISTS resolver,
+    DROP COLUMN IF EXISTS acknowledged_at,
+    DROP COLUMN IF EXISTS acknowledged_by,
+    DROP COLUMN IF EXISTS triggered_score,
+    DROP COLUMN IF EXISTS cooldown_seconds,
+    DROP COLUMN IF EXISTS hysteresis,
+    DROP COLUMN IF EXISTS state;
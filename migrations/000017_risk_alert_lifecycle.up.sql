@@ -0,0 +1,20 @@
+-- Risk alert lifecycle migration for ATLAS Core API
+-- Version: 000017
+-- Description: Alert states (armed, firing, acknowledged, resolved) with
+--              hysteresis and cooldown for threshold alert rules
+
+-- resolved_by references users; rules are also resolved by the system when
+-- the score clears, so the resolver is kept by name
+ALTER TABLE risk_alerts
+    ADD COLUMN IF NOT EXISTS state VARCHAR(20) NOT NULL DEFAULT 'armed'
+        CHECK (state IN ('armed', 'firing', 'acknowledged', 'resolved')),
+    ADD COLUMN IF NOT EXISTS hysteresis DECIMAL(5,2) NOT NULL DEFAULT 0.05,
+    ADD COLUMN IF NOT EXISTS cooldown_seconds INTEGER NOT NULL DEFAULT 900,
+    ADD COLUMN IF NOT EXISTS triggered_score DECIMAL(5,2),
+    ADD COLUMN IF NOT EXISTS acknowledged_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS acknowledged_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS resolver VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS resolution TEXT;
+
+-- Rules that had fired stay firing until resolved
+UPDATE risk_alerts SET state = 'firing' WHERE alert_type = 'threshold' AND is_triggered;
@@ -0,0 +1,15 @@
+-- Rollback risk alert rules
+ALTER TABLE risk_assessments DROP COLUMN IF EXISTS tags;
+
+DROP INDEX IF EXISTS idx_alerts_rule;
+
+-- Group rules, their instances and expression rules cannot be kept
+DELETE FROM risk_alerts
+WHERE alert_type = 'threshold'
+  AND (rule_id IS NOT NULL OR entity_id IS NULL OR expression IS NOT NULL);
+
+ALTER TABLE risk_alerts
+    DROP COLUMN IF EXISTS rule_id,
+    DROP COLUMN IF EXISTS tag,
+    DROP COLUMN IF EXISTS entity_type,
+    DROP COLUMN IF EXISTS expression;
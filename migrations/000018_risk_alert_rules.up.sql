@@ -0,0 +1,18 @@
+-- Risk alert rules migration for ATLAS Core API
+-- Version: 000018
+-- Description: Rule expressions, entity-group scoping and per-entity alert
+--              instances for threshold alert rules; entity tags on assessments
+
+-- A rule scoped to an entity type or tag raises one alert per matching
+-- entity, linked back through rule_id
+ALTER TABLE risk_alerts
+    ADD COLUMN IF NOT EXISTS expression TEXT,
+    ADD COLUMN IF NOT EXISTS entity_type VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS tag VARCHAR(100),
+    ADD COLUMN IF NOT EXISTS rule_id UUID REFERENCES risk_alerts(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS idx_alerts_rule ON risk_alerts(rule_id) WHERE rule_id IS NOT NULL;
+
+-- Tags group entities for alert rules
+ALTER TABLE risk_assessments
+    ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
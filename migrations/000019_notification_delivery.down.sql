@@ -0,0 +1,14 @@
+-- Rollback notification delivery
+ALTER TABLE risk_alerts DROP COLUMN IF EXISTS created_by;
+
+DROP INDEX IF EXISTS idx_notifications_dedup;
+DROP INDEX IF EXISTS idx_notifications_due;
+
+ALTER TABLE notifications
+    DROP COLUMN IF EXISTS dedup_key,
+    DROP COLUMN IF EXISTS last_error,
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS attempts,
+    DROP COLUMN IF EXISTS target,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,23 @@
+-- Notification delivery migration for ATLAS Core API
+-- Version: 000019
+-- Description: Delivery state for notifications sent over email, webhook
+--              and Slack, with retries and deduplication; risk alert owners
+
+-- In-app notifications are delivered when written; other channels stay
+-- pending until delivered or out of attempts
+ALTER TABLE notifications
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'delivered'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    ADD COLUMN IF NOT EXISTS target VARCHAR(1000),
+    ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS last_error TEXT,
+    ADD COLUMN IF NOT EXISTS dedup_key VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_due ON notifications(next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_notifications_dedup
+    ON notifications(user_id, channel, dedup_key, created_at DESC) WHERE dedup_key IS NOT NULL;
+
+-- The owner of an alert rule is notified when it fires or resolves
+ALTER TABLE risk_alerts ADD COLUMN IF NOT EXISTS created_by VARCHAR(255);
@@ -0,0 +1,16 @@
+-- Rollback webhook delivery
+DROP INDEX IF EXISTS idx_webhook_deliveries_due;
+DROP INDEX IF EXISTS idx_webhook_deliveries_event_once;
+
+ALTER TABLE webhook_deliveries
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS redelivery_of,
+    DROP COLUMN IF EXISTS delivered_at,
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS next_attempt_at,
+    DROP COLUMN IF EXISTS status,
+    DROP COLUMN IF EXISTS event_id;
+
+ALTER TABLE webhook_subscriptions
+    DROP COLUMN IF EXISTS disabled_reason,
+    DROP COLUMN IF EXISTS disabled_at;
@@ -0,0 +1,31 @@
+-- Webhook delivery migration for ATLAS Core API
+-- Version: 000020
+-- Description: Queued, signed webhook deliveries with retries, manual
+--              redelivery and automatic disabling of failing subscriptions
+
+-- A subscription is disabled after too many consecutive failed deliveries
+ALTER TABLE webhook_subscriptions
+    ADD COLUMN IF NOT EXISTS disabled_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS disabled_reason TEXT;
+
+-- Each delivery row is one event sent to one subscription; attempt_number
+-- counts the attempts made so far and the response columns describe the last
+ALTER TABLE webhook_deliveries
+    ADD COLUMN IF NOT EXISTS event_id VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS status VARCHAR(20) NOT NULL DEFAULT 'pending'
+        CHECK (status IN ('pending', 'delivered', 'failed')),
+    ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS delivered_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE webhook_deliveries SET status = CASE WHEN success THEN 'delivered' ELSE 'failed' END;
+
+-- An event read twice from Kafka is queued once per subscription
+CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_event_once
+    ON webhook_deliveries(subscription_id, event_id)
+    WHERE redelivery_of IS NULL AND event_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+    ON webhook_deliveries(next_attempt_at)
+    WHERE status = 'pending';
@@ -0,0 +1,4 @@
+-- Rollback risk backtesting
+DROP INDEX IF EXISTS idx_risk_assessed;
+DROP TABLE IF EXISTS risk_backtests;
+DROP TABLE IF EXISTS risk_outcomes;
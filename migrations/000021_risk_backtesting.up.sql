@@ -0,0 +1,44 @@
+-- Risk backtesting migration for ATLAS Core API
+-- Version: 000021
+-- Description: Real outcomes recorded against entities and backtests that
+--              replay stored assessments against them
+
+CREATE TABLE IF NOT EXISTS risk_outcomes (
+    id UUID PRIMARY KEY,
+    entity_id VARCHAR(255) NOT NULL,
+    entity_type VARCHAR(100),
+    outcome_type VARCHAR(50) NOT NULL,
+    occurred_at TIMESTAMP NOT NULL,
+    description TEXT,
+    source VARCHAR(255),
+    recorded_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_outcome_type CHECK (outcome_type IN ('default', 'sanction', 'breach', 'supply_disruption')),
+    CONSTRAINT uq_outcome UNIQUE (entity_id, outcome_type, occurred_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_outcomes_occurred ON risk_outcomes(occurred_at DESC);
+
+CREATE TABLE IF NOT EXISTS risk_backtests (
+    id UUID PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    window_from TIMESTAMP NOT NULL,
+    window_to TIMESTAMP NOT NULL,
+    horizon_days INTEGER NOT NULL,
+    outcome_types JSONB NOT NULL DEFAULT '[]',
+    bins INTEGER NOT NULL,
+    assessments INTEGER NOT NULL DEFAULT 0,
+    censored INTEGER NOT NULL DEFAULT 0,
+    results JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    CONSTRAINT chk_backtest_status CHECK (status IN ('queued', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_backtests_unfinished ON risk_backtests(created_at) WHERE status IN ('queued', 'running');
+
+-- Backtests page through assessments by time
+CREATE INDEX IF NOT EXISTS idx_risk_assessed ON risk_assessments(assessed_at, id);
@@ -0,0 +1,4 @@
+-- Rollback risk assessment job item claims
+ALTER TABLE risk_assessment_job_items
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS claimed_by;
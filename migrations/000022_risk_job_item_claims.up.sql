@@ -0,0 +1,9 @@
+-- Risk assessment job item claims migration for ATLAS Core API
+-- Version: 000022
+-- Description: Lease pending job items to one replica at a time, so every
+--              replica can resume unfinished jobs without assessing the
+--              same entity twice
+
+ALTER TABLE risk_assessment_job_items
+    ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
@@ -0,0 +1,4 @@
+-- Rollback risk backtest claims
+ALTER TABLE risk_backtests
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS claimed_by;
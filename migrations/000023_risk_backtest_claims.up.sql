@@ -0,0 +1,9 @@
+-- Risk backtest claims migration for ATLAS Core API
+-- Version: 000023
+-- Description: Lease unfinished backtests to one replica at a time, so every
+--              replica can restart interrupted backtests without running
+--              the same one twice
+
+ALTER TABLE risk_backtests
+    ADD COLUMN IF NOT EXISTS claimed_by VARCHAR(255),
+    ADD COLUMN IF NOT EXISTS claimed_until TIMESTAMP;
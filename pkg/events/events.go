@@ -24,10 +24,15 @@ const (
 	TopicUserDeactivated  = "atlas.user.deactivated"
 	TopicPasswordChanged  = "atlas.user.password_changed"
 
+	// IAM Elevation Topics
+	TopicElevationRequested  = "atlas.user.elevation_requested"
+	TopicBreakGlassActivated = "atlas.user.break_glass_activated"
+
 	// Risk Assessment Topics
 	TopicRiskAssessed      = "atlas.risk.assessed"
 	TopicAlertTriggered    = "atlas.alert.triggered"
 	TopicAlertResolved     = "atlas.alert.resolved"
+	TopicAlertAcknowledged = "atlas.alert.acknowledged"
 	TopicThresholdBreached = "atlas.risk.threshold_breached"
 
 	// Simulation Topics
@@ -0,0 +1,141 @@
+// Package safehttp builds HTTP clients for calls to user-supplied URLs,
+// such as webhook and Slack notification targets. A safe client dials only
+// public addresses, checked after DNS resolution so a hostname that
+// resolves or rebinds to an internal address is refused. It does not
+// follow redirects and ignores proxy settings, either of which would let a
+// target reach an address the dialer never checked.
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrBlockedAddress is returned for a target that resolves to an address
+// outbound calls may not reach
+var ErrBlockedAddress = errors.New("target address is not allowed")
+
+// ValidateURL checks that raw is an absolute URL outbound calls may be
+// made to. It must use https unless allowInsecure is set, and its host may
+// not be localhost or a literal loopback, private, link-local or
+// unique-local address. Hostnames are checked again when each call is
+// dialed, since DNS can change after the URL is saved.
+func ValidateURL(raw string, allowInsecure bool) error {
+	target, err := url.Parse(raw)
+	if err != nil || target.Host == "" || target.Hostname() == "" {
+		return fmt.Errorf("url must be an absolute https URL")
+	}
+	switch target.Scheme {
+	case "https":
+	case "http":
+		if !allowInsecure {
+			return fmt.Errorf("url must use https")
+		}
+	default:
+		return fmt.Errorf("url must be an absolute https URL")
+	}
+	if target.User != nil {
+		return fmt.Errorf("url must not contain credentials")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(target.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("url host: %w", ErrBlockedAddress)
+	}
+	if ip := net.ParseIP(host); ip != nil && blockedIP(ip) {
+		return fmt.Errorf("url host: %w", ErrBlockedAddress)
+	}
+	return nil
+}
+
+// Config controls what a client may reach
+type Config struct {
+	// AllowPrivateNetworks lets calls reach loopback, private and
+	// link-local addresses. Only tests should set it.
+	AllowPrivateNetworks bool
+	// Timeout bounds each request; zero means no client-wide limit
+	Timeout time.Duration
+}
+
+// NewClient returns a client that never follows redirects or uses a proxy
+// and, unless the config allows it, refuses internal addresses when it
+// dials
+func NewClient(config Config) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// Proxies would dial on our behalf and bypass the address check
+	transport.Proxy = nil
+	if !config.AllowPrivateNetworks {
+		transport.DialContext = newSafeDialer().DialContext
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   config.Timeout,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// blockedIP reports whether ip is loopback, private, link-local,
+// unique-local or otherwise not a public unicast address
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified()
+}
+
+// safeDialer resolves the host itself and only connects to public
+// addresses, so a hostname that resolves, or later rebinds, to an
+// internal address cannot be reached
+type safeDialer struct {
+	dialer   *net.Dialer
+	resolver *net.Resolver
+}
+
+func newSafeDialer() *safeDialer {
+	return &safeDialer{
+		dialer:   &net.Dialer{Timeout: 10 * time.Second, KeepAlive: 30 * time.Second},
+		resolver: net.DefaultResolver,
+	}
+}
+
+func (d *safeDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := d.resolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("%s: no addresses found", host)
+	}
+	// Refuse the host outright if any address is internal, rather than
+	// picking a public one and leaving the choice to the resolver
+	for _, addr := range addrs {
+		if blockedIP(addr.IP) {
+			return nil, fmt.Errorf("%s resolves to %s: %w", host, addr.IP, ErrBlockedAddress)
+		}
+	}
+
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := d.dialer.DialContext(ctx, network, net.JoinHostPort(addr.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,35 @@
+package servicetoken
+
+import (
+	"crypto/ed25519"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Require rejects requests without a valid token minted by the API gateway
+// in trustDomain for service. The user context in the token replaces any
+// X-User-ID / X-Username headers, which callers could otherwise forge.
+// Keys lists the gateway's current and, during rotation, previous public key.
+func Require(trustDomain, service string, keys ...ed25519.PublicKey) gin.HandlerFunc {
+	verifier := NewVerifier(Identity(trustDomain, service), []string{Identity(trustDomain, Gateway)}, keys...)
+
+	return func(c *gin.Context) {
+		claims, err := verifier.Verify(c.GetHeader(Header))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error":   "unauthorized",
+				"message": "A valid service token is required",
+			})
+			return
+		}
+
+		c.Request.Header.Set("X-User-ID", claims.Subject)
+		c.Request.Header.Set("X-Username", claims.Username)
+		c.Set("user_id", claims.Subject)
+		c.Set("username", claims.Username)
+		c.Set("roles", claims.Roles)
+		c.Set("caller", claims.Issuer)
+		c.Next()
+	}
+}
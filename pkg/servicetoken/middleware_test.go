@@ -0,0 +1,79 @@
+package servicetoken
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequire(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	current, currentPublic := newKey(t)
+	previous, previousPublic := newKey(t)
+	router := gin.New()
+	router.Use(Require("atlas.internal", "risk-assessment", currentPublic, previousPublic))
+	router.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"header": c.GetHeader("X-User-ID"),
+			"user":   c.GetString("user_id"),
+			"roles":  c.GetStringSlice("roles"),
+		})
+	})
+
+	serve := func(token, forgedUser string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+		if token != "" {
+			req.Header.Set(Header, token)
+		}
+		if forgedUser != "" {
+			req.Header.Set("X-User-ID", forgedUser)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("token user replaces forged headers", func(t *testing.T) {
+		token, err := NewSigner("atlas.internal", Gateway, current, time.Minute).
+			Sign("risk-assessment", Claims{Subject: "u-1", Roles: []string{"analyst"}})
+		require.NoError(t, err)
+
+		w := serve(token, "admin")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"header":"u-1","user":"u-1","roles":["analyst"]}`, w.Body.String())
+	})
+
+	t.Run("accepts previous key during rotation", func(t *testing.T) {
+		token, _ := NewSigner("atlas.internal", Gateway, previous, time.Minute).
+			Sign("risk-assessment", Claims{Subject: "u-1"})
+		assert.Equal(t, http.StatusOK, serve(token, "").Code)
+	})
+
+	t.Run("rejects missing token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve("", "admin").Code)
+	})
+
+	t.Run("rejects other trust domain", func(t *testing.T) {
+		token, _ := NewSigner("partner.example", Gateway, current, time.Minute).
+			Sign("risk-assessment", Claims{Subject: "u-1"})
+		assert.Equal(t, http.StatusUnauthorized, serve(token, "").Code)
+	})
+
+	t.Run("rejects token for another service", func(t *testing.T) {
+		token, _ := NewSigner("atlas.internal", Gateway, current, time.Minute).
+			Sign("ingestion-service", Claims{Subject: "u-1"})
+		assert.Equal(t, http.StatusUnauthorized, serve(token, "").Code)
+	})
+
+	t.Run("rejects expired token", func(t *testing.T) {
+		expired := NewSigner("atlas.internal", Gateway, current, time.Minute)
+		expired.now = func() time.Time { return time.Now().Add(-time.Hour) }
+		token, _ := expired.Sign("risk-assessment", Claims{Subject: "u-1"})
+		assert.Equal(t, http.StatusUnauthorized, serve(token, "").Code)
+	})
+}
@@ -0,0 +1,252 @@
+// Package servicetoken mints and verifies the short-lived tokens the gateway
+// attaches to proxied requests. A token is an EdDSA (Ed25519) JWT whose
+// issuer and audience are SPIFFE-style workload identities. Only the gateway
+// holds the private key; services hold the public key, so they can verify
+// tokens but not mint them, and a compromised service cannot impersonate the
+// gateway to the others. A token carries the authenticated user, replacing
+// the X-User-ID headers that any caller on the network could forge.
+package servicetoken
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Header carries the token on proxied requests
+const Header = "X-Service-Token"
+
+// DefaultPrivateKey and DefaultPublicKey are the development key pair the
+// gateway and services fall back to when SERVICE_TOKEN_PRIVATE_KEY and
+// SERVICE_TOKEN_PUBLIC_KEY are unset. Anyone who has read the source can
+// sign with it, so it is only good for local development.
+const (
+	DefaultPrivateKey = "BQyqHbL07uSzLL5wCeNu/mMOVWXVshTb2iXxZezPTGk="
+	DefaultPublicKey  = "E4OWgFT8JAetxkkugIINUJCKJJNwtGTvXX7u+T3gumM="
+)
+
+// algorithm is the JWS algorithm name for Ed25519 signatures
+const algorithm = "EdDSA"
+
+// Gateway is the workload name of the API gateway, the only issuer
+// downstream services trust
+const Gateway = "api-gateway"
+
+var (
+	ErrMalformed       = errors.New("service token is malformed")
+	ErrUnknownKey      = errors.New("service token signed with an unknown key")
+	ErrBadSignature    = errors.New("service token signature is invalid")
+	ErrExpired         = errors.New("service token has expired")
+	ErrWrongAudience   = errors.New("service token is for another service")
+	ErrUntrustedIssuer = errors.New("service token issuer is not trusted")
+	ErrInsecureKey     = errors.New("service token key is empty or the development default")
+	ErrInvalidKey      = errors.New("service token key is not a base64 Ed25519 key")
+)
+
+// leeway absorbs clock skew between the gateway and services
+const leeway = 30 * time.Second
+
+// Identity returns the SPIFFE ID of a workload, e.g.
+// spiffe://atlas.internal/service/risk-assessment
+func Identity(trustDomain, service string) string {
+	return "spiffe://" + trustDomain + "/service/" + service
+}
+
+// CheckKey returns ErrInsecureKey when an encoded key is empty or one of
+// the development defaults. The gateway and services call it at startup in
+// production.
+func CheckKey(key string) error {
+	if key == "" || key == DefaultPrivateKey || key == DefaultPublicKey {
+		return ErrInsecureKey
+	}
+	return nil
+}
+
+// ParsePrivateKey decodes a base64 Ed25519 seed (32 bytes) or full private
+// key (64 bytes)
+func ParsePrivateKey(encoded string) (ed25519.PrivateKey, error) {
+	b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, ErrInvalidKey
+	}
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(b), nil
+	}
+	return nil, ErrInvalidKey
+}
+
+// ParsePublicKeys decodes base64 Ed25519 public keys, skipping empty ones
+// so an unset previous key can be passed through
+func ParsePublicKeys(encoded ...string) ([]ed25519.PublicKey, error) {
+	keys := make([]ed25519.PublicKey, 0, len(encoded))
+	for _, e := range encoded {
+		if e == "" {
+			continue
+		}
+		b, err := base64.StdEncoding.DecodeString(strings.TrimSpace(e))
+		if err != nil || len(b) != ed25519.PublicKeySize {
+			return nil, ErrInvalidKey
+		}
+		keys = append(keys, ed25519.PublicKey(b))
+	}
+	return keys, nil
+}
+
+// Claims is the user and request context a token carries
+type Claims struct {
+	Issuer    string   `json:"iss"`
+	Audience  string   `json:"aud"`
+	Subject   string   `json:"sub,omitempty"`
+	Username  string   `json:"username,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	RequestID string   `json:"rid,omitempty"`
+	IssuedAt  int64    `json:"iat"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+type header struct {
+	Algorithm string `json:"alg"`
+	Type      string `json:"typ"`
+	KeyID     string `json:"kid"`
+}
+
+// KeyID identifies a public key, so services can accept the previous key
+// during rotation
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:4])
+}
+
+// Signer mints tokens on behalf of one workload
+type Signer struct {
+	identity    string
+	trustDomain string
+	key         ed25519.PrivateKey
+	keyID       string
+	ttl         time.Duration
+	now         func() time.Time
+}
+
+// NewSigner creates a signer for service in trustDomain
+func NewSigner(trustDomain, service string, key ed25519.PrivateKey, ttl time.Duration) *Signer {
+	return &Signer{
+		identity:    Identity(trustDomain, service),
+		trustDomain: trustDomain,
+		key:         key,
+		keyID:       KeyID(key.Public().(ed25519.PublicKey)),
+		ttl:         ttl,
+		now:         time.Now,
+	}
+}
+
+// Sign mints a token for the audience service. Issuer, audience and
+// lifetime in claims are set by the signer.
+func (s *Signer) Sign(audience string, claims Claims) (string, error) {
+	now := s.now()
+	claims.Issuer = s.identity
+	claims.Audience = Identity(s.trustDomain, audience)
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(s.ttl).Unix()
+
+	h, err := json.Marshal(header{Algorithm: algorithm, Type: "JWT", KeyID: s.keyID})
+	if err != nil {
+		return "", err
+	}
+	c, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := encode(h) + "." + encode(c)
+	return unsigned + "." + encode(ed25519.Sign(s.key, []byte(unsigned))), nil
+}
+
+// Verifier checks tokens addressed to one workload
+type Verifier struct {
+	audience string
+	issuers  map[string]bool
+	keys     map[string]ed25519.PublicKey
+	now      func() time.Time
+}
+
+// NewVerifier accepts tokens for audience from the trusted issuers, signed
+// by the private half of any of keys
+func NewVerifier(audience string, issuers []string, keys ...ed25519.PublicKey) *Verifier {
+	v := &Verifier{
+		audience: audience,
+		issuers:  make(map[string]bool, len(issuers)),
+		keys:     make(map[string]ed25519.PublicKey, len(keys)),
+		now:      time.Now,
+	}
+	for _, issuer := range issuers {
+		v.issuers[issuer] = true
+	}
+	for _, key := range keys {
+		v.keys[KeyID(key)] = key
+	}
+	return v
+}
+
+// Verify checks the signature, lifetime, audience and issuer of a token
+func (v *Verifier) Verify(token string) (*Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrMalformed
+	}
+
+	var h header
+	if err := decodeJSON(parts[0], &h); err != nil || h.Algorithm != algorithm {
+		return nil, ErrMalformed
+	}
+	key, ok := v.keys[h.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	if !ed25519.Verify(key, []byte(parts[0]+"."+parts[1]), signature) {
+		return nil, ErrBadSignature
+	}
+
+	var claims Claims
+	if err := decodeJSON(parts[1], &claims); err != nil {
+		return nil, ErrMalformed
+	}
+	now := v.now()
+	if now.After(time.Unix(claims.ExpiresAt, 0).Add(leeway)) {
+		return nil, ErrExpired
+	}
+	if time.Unix(claims.IssuedAt, 0).After(now.Add(leeway)) {
+		return nil, fmt.Errorf("%w: issued in the future", ErrMalformed)
+	}
+	if claims.Audience != v.audience {
+		return nil, ErrWrongAudience
+	}
+	if !v.issuers[claims.Issuer] {
+		return nil, ErrUntrustedIssuer
+	}
+	return &claims, nil
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeJSON(segment string, v interface{}) error {
+	b, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
@@ -0,0 +1,153 @@
+package servicetoken
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newKey(t *testing.T) (ed25519.PrivateKey, ed25519.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	return private, public
+}
+
+func TestSignAndVerify(t *testing.T) {
+	gateway := Identity("atlas.internal", "api-gateway")
+	audience := Identity("atlas.internal", "risk-assessment")
+	current, currentPublic := newKey(t)
+	previous, previousPublic := newKey(t)
+	signer := NewSigner("atlas.internal", "api-gateway", current, time.Minute)
+	verifier := NewVerifier(audience, []string{gateway}, currentPublic)
+
+	t.Run("round trip carries user context", func(t *testing.T) {
+		token, err := signer.Sign("risk-assessment", Claims{Subject: "u-1", Username: "alice", Roles: []string{"analyst"}, RequestID: "r-1"})
+		require.NoError(t, err)
+
+		claims, err := verifier.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, "u-1", claims.Subject)
+		assert.Equal(t, "alice", claims.Username)
+		assert.Equal(t, []string{"analyst"}, claims.Roles)
+		assert.Equal(t, gateway, claims.Issuer)
+	})
+
+	t.Run("rejects token for another service", func(t *testing.T) {
+		token, _ := signer.Sign("ingestion-service", Claims{Subject: "u-1"})
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrWrongAudience)
+	})
+
+	t.Run("rejects untrusted issuer", func(t *testing.T) {
+		other := NewSigner("atlas.internal", "ingestion-service", current, time.Minute)
+		token, _ := other.Sign("risk-assessment", Claims{Subject: "u-1"})
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrUntrustedIssuer)
+	})
+
+	t.Run("rejects gateway from another trust domain", func(t *testing.T) {
+		foreign := NewSigner("partner.example", "api-gateway", current, time.Minute)
+		token, _ := foreign.Sign("risk-assessment", Claims{Subject: "u-1"})
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrWrongAudience)
+	})
+
+	t.Run("rejects a service posing as the gateway with its own key", func(t *testing.T) {
+		serviceKey, _ := newKey(t)
+		impostor := NewSigner("atlas.internal", "api-gateway", serviceKey, time.Minute)
+		token, _ := impostor.Sign("risk-assessment", Claims{Subject: "admin"})
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+
+		// Copying the gateway's key ID does not help without its private key
+		impostor.keyID = KeyID(currentPublic)
+		token, _ = impostor.Sign("risk-assessment", Claims{Subject: "admin"})
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrBadSignature)
+	})
+
+	t.Run("rejects expired token", func(t *testing.T) {
+		expired := NewSigner("atlas.internal", "api-gateway", current, time.Minute)
+		expired.now = func() time.Time { return time.Now().Add(-2 * time.Minute) }
+		token, _ := expired.Sign("risk-assessment", Claims{Subject: "u-1"})
+		_, err := verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("allows clock skew within leeway", func(t *testing.T) {
+		skewed := NewSigner("atlas.internal", "api-gateway", current, time.Minute)
+		skewed.now = func() time.Time { return time.Now().Add(-time.Minute - leeway/2) }
+		token, _ := skewed.Sign("risk-assessment", Claims{Subject: "u-1"})
+		_, err := verifier.Verify(token)
+		assert.NoError(t, err)
+	})
+
+	t.Run("rejects tampered claims", func(t *testing.T) {
+		token, _ := signer.Sign("risk-assessment", Claims{Subject: "u-1"})
+		forged, _ := signer.Sign("risk-assessment", Claims{Subject: "admin"})
+		a, b := strings.Split(token, "."), strings.Split(forged, ".")
+		_, err := verifier.Verify(a[0] + "." + b[1] + "." + a[2])
+		assert.ErrorIs(t, err, ErrBadSignature)
+	})
+
+	t.Run("rejects other algorithms", func(t *testing.T) {
+		token, _ := signer.Sign("risk-assessment", Claims{Subject: "u-1"})
+		parts := strings.Split(token, ".")
+		h, _ := json.Marshal(header{Algorithm: "HS256", Type: "JWT", KeyID: KeyID(currentPublic)})
+		parts[0] = base64.RawURLEncoding.EncodeToString(h)
+		_, err := verifier.Verify(strings.Join(parts, "."))
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+
+	t.Run("accepts previous key during rotation", func(t *testing.T) {
+		old := NewSigner("atlas.internal", "api-gateway", previous, time.Minute)
+		token, _ := old.Sign("risk-assessment", Claims{Subject: "u-1"})
+
+		_, err := NewVerifier(audience, []string{gateway}, currentPublic, previousPublic).Verify(token)
+		assert.NoError(t, err)
+		_, err = verifier.Verify(token)
+		assert.ErrorIs(t, err, ErrUnknownKey)
+	})
+
+	t.Run("rejects garbage", func(t *testing.T) {
+		_, err := verifier.Verify("not-a-token")
+		assert.ErrorIs(t, err, ErrMalformed)
+	})
+}
+
+func TestParseKeys(t *testing.T) {
+	private, err := ParsePrivateKey(DefaultPrivateKey)
+	require.NoError(t, err)
+	public, err := ParsePublicKeys(DefaultPublicKey, "")
+	require.NoError(t, err)
+	require.Len(t, public, 1, "empty keys are skipped")
+	assert.Equal(t, private.Public(), public[0], "the development keys are a pair")
+
+	full := base64.StdEncoding.EncodeToString(private)
+	fromFull, err := ParsePrivateKey(full)
+	require.NoError(t, err)
+	assert.Equal(t, private, fromFull)
+
+	_, err = ParsePrivateKey("not base64!")
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ParsePrivateKey(base64.StdEncoding.EncodeToString([]byte("short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = ParsePublicKeys(DefaultPrivateKey[:10])
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestCheckKey(t *testing.T) {
+	assert.ErrorIs(t, CheckKey(""), ErrInsecureKey)
+	assert.ErrorIs(t, CheckKey(DefaultPrivateKey), ErrInsecureKey)
+	assert.ErrorIs(t, CheckKey(DefaultPublicKey), ErrInsecureKey)
+
+	_, public := newKey(t)
+	assert.NoError(t, CheckKey(base64.StdEncoding.EncodeToString(public)))
+}
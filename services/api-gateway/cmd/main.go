@@ -17,6 +17,7 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 
+	"atlas-core-api/pkg/servicetoken"
 	"atlas-core-api/services/api-gateway/internal/api/handlers"
 	"atlas-core-api/services/api-gateway/internal/api/middleware"
 	"atlas-core-api/services/api-gateway/internal/api/router"
@@ -52,12 +53,21 @@ func main() {
 	if cfg.Auth.JWTSecret == "change-me-in-production" && cfg.Environment == "production" {
 		logger.Fatal("JWT_SECRET must be changed in production")
 	}
+	if cfg.Environment == "production" {
+		if err := servicetoken.CheckKey(cfg.ServiceAuth.PrivateKey); err != nil {
+			logger.Fatal("SERVICE_TOKEN_PRIVATE_KEY must be set in production", zap.Error(err))
+		}
+	}
+	signingKey, err := servicetoken.ParsePrivateKey(cfg.ServiceAuth.PrivateKey)
+	if err != nil {
+		logger.Fatal("Invalid SERVICE_TOKEN_PRIVATE_KEY", zap.Error(err))
+	}
+	signer := servicetoken.NewSigner(cfg.ServiceAuth.TrustDomain, servicetoken.Gateway, signingKey, cfg.ServiceAuth.TokenTTL)
 
 	metricsInstance := metrics.NewMetrics(logger)
 
 	// Initialize cache
 	var cacheInstance cache.Cache
-	var err error
 	if cfg.Cache.Type == "redis" {
 		cacheInstance, err = cache.NewRedisCache(cfg.Cache.RedisURL, logger)
 	} else {
@@ -154,6 +164,9 @@ func main() {
 		public.POST("/auth/login", authHandler.Login)
 		public.POST("/auth/refresh", authHandler.RefreshToken)
 		public.GET("/auth/validate", authHandler.ValidateToken)
+		public.POST("/auth/forgot-password", authHandler.ForgotPassword)
+		public.POST("/auth/reset-password", authHandler.ResetPassword)
+		public.POST("/auth/verify-email", authHandler.VerifyEmail)
 	}
 
 	// Protected endpoints
@@ -165,12 +178,13 @@ func main() {
 			middleware.SensitiveEndpoint(logger),
 			authHandler.ChangePassword,
 		)
+		protected.POST("/auth/resend-verification", authHandler.ResendVerification)
 
 		// Idempotency for mutating operations
 		protected.Use(middleware.IdempotencyKey(cacheInstance, logger))
 
 		// All downstream service proxy routes
-		router.SetupRoutes(protected, cfg, logger)
+		router.SetupRoutes(protected, cfg, signer, logger)
 	}
 
 	// =============================================
@@ -123,6 +123,9 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 	iamReq.Header.Set("Content-Type", "application/json")
 	iamReq.Header.Set("X-Request-ID", c.GetString("request_id"))
+	// IAM tracks attempts per client IP, so pass the original caller through
+	iamReq.Header.Set("X-Forwarded-For", c.ClientIP())
+	iamReq.Header.Set("User-Agent", c.Request.UserAgent())
 
 	iamTimeout := h.config.Services.IAMService.Timeout
 	if iamTimeout == 0 {
@@ -151,6 +154,24 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 	// If IAM rejected the credentials, increment brute-force counter and relay error
 	if iamResp.StatusCode != http.StatusOK {
+		// Expired password: the credentials were valid, so pass IAM's one-time
+		// reset token through for the client to set a new password
+		if iamResp.StatusCode == http.StatusForbidden {
+			var expired struct {
+				Error      string `json:"error"`
+				ResetToken string `json:"reset_token"`
+			}
+			if json.Unmarshal(iamBody, &expired) == nil && expired.Error == "password_expired" {
+				apiErr := types.NewAPIErrorWithDetails(types.ErrForbidden, "Password has expired and must be changed", map[string]interface{}{
+					"reason":      "password_expired",
+					"reset_token": expired.ResetToken,
+				})
+				apiErr.TraceID = c.GetString("request_id")
+				c.JSON(http.StatusForbidden, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+				return
+			}
+		}
+
 		if h.cache != nil {
 			attemptsKey := loginAttemptsPrefix + c.ClientIP()
 			h.cache.IncrementCounter(c.Request.Context(), attemptsKey, 1)
@@ -161,6 +182,18 @@ func (h *AuthHandler) Login(c *gin.Context) {
 			zap.String("username", req.Username),
 			zap.Int("iam_status", iamResp.StatusCode),
 		)
+
+		// IAM lockout: relay the 429 and its Retry-After instead of masking it as 401
+		if iamResp.StatusCode == http.StatusTooManyRequests {
+			if retryAfter := iamResp.Header.Get("Retry-After"); retryAfter != "" {
+				c.Header("Retry-After", retryAfter)
+			}
+			apiErr := types.NewAPIError(types.ErrTooManyRequests, "Too many failed login attempts. Please try again later.")
+			apiErr.TraceID = c.GetString("request_id")
+			c.JSON(http.StatusTooManyRequests, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+			return
+		}
+
 		apiErr := types.NewAPIError(types.ErrUnauthorized, "Invalid username or password")
 		apiErr.TraceID = c.GetString("request_id")
 		c.JSON(http.StatusUnauthorized, types.NewErrorResponse(apiErr, c.Request.URL.Path))
@@ -425,18 +458,15 @@ func (h *AuthHandler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	// TODO: Forward to IAM service for actual password change
-
-	h.logger.Info("Password changed",
+	h.logger.Info("Password change requested",
 		zap.Any("user_id", userID),
 		zap.String("ip", c.ClientIP()),
 	)
 
-	c.JSON(http.StatusOK, dto.SuccessResponse{
-		Code:    http.StatusOK,
-		Message: "Password changed successfully",
-		TraceID: c.GetString("request_id"),
-	})
+	h.relayIAM(c, "/api/v1/auth/change-password", map[string]string{
+		"old_password": req.OldPassword,
+		"new_password": req.NewPassword,
+	}, "Password changed successfully")
 }
 
 // ValidateToken validates a JWT token
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"atlas-core-api/services/api-gateway/internal/domain/types"
+	"atlas-core-api/services/api-gateway/internal/presentation/dto"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// iamErrorResponse is the error body returned by the IAM service
+type iamErrorResponse struct {
+	Error      string   `json:"error"`
+	Message    string   `json:"message"`
+	Violations []string `json:"violations,omitempty"`
+}
+
+// ForgotPassword forwards a reset-link request to the IAM service
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req dto.ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apiErr := types.NewAPIError(types.ErrValidationFailed, "Invalid request format")
+		apiErr.TraceID = c.GetString("request_id")
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+		return
+	}
+
+	h.relayIAM(c, "/api/v1/auth/forgot-password", map[string]string{"email": req.Email},
+		"If an account exists for that email, a reset link has been sent")
+}
+
+// ResetPassword forwards a token-based password reset to the IAM service
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apiErr := types.NewAPIError(types.ErrValidationFailed, "Invalid request format")
+		apiErr.TraceID = c.GetString("request_id")
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+		return
+	}
+
+	h.relayIAM(c, "/api/v1/auth/reset-password", map[string]string{
+		"token":        req.Token,
+		"new_password": req.NewPassword,
+	}, "Password reset successfully")
+}
+
+// VerifyEmail forwards an email verification token to the IAM service
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req struct {
+		Token string `json:"token" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		apiErr := types.NewAPIError(types.ErrValidationFailed, "Invalid request format")
+		apiErr.TraceID = c.GetString("request_id")
+		c.JSON(http.StatusBadRequest, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+		return
+	}
+
+	h.relayIAM(c, "/api/v1/auth/verify-email", map[string]string{"token": req.Token}, "Email verified successfully")
+}
+
+// ResendVerification asks the IAM service to mail a fresh verification link
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	h.relayIAM(c, "/api/v1/auth/resend-verification", map[string]string{}, "Verification email sent")
+}
+
+// relayIAM posts payload to the IAM service and maps its reply onto the
+// gateway's response envelope. The caller's Authorization header is passed
+// through so IAM can identify the user on authenticated endpoints.
+func (h *AuthHandler) relayIAM(c *gin.Context, path string, payload interface{}, successMessage string) {
+	iamBaseURL, exists := h.config.Services.Registry["iam-service"]
+	if !exists {
+		iamBaseURL = h.config.Services.IAMService.URL
+	}
+
+	body, _ := json.Marshal(payload)
+	iamReq, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, iamBaseURL+path, bytes.NewReader(body))
+	if err != nil {
+		h.logger.Error("Failed to create IAM request", zap.String("path", path), zap.Error(err))
+		apiErr := types.NewAPIError(types.ErrInternalServerError, "Internal server error")
+		apiErr.TraceID = c.GetString("request_id")
+		c.JSON(http.StatusInternalServerError, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+		return
+	}
+	iamReq.Header.Set("Content-Type", "application/json")
+	iamReq.Header.Set("X-Request-ID", c.GetString("request_id"))
+	iamReq.Header.Set("X-Forwarded-For", c.ClientIP())
+	iamReq.Header.Set("User-Agent", c.Request.UserAgent())
+	if auth := c.GetHeader("Authorization"); auth != "" {
+		iamReq.Header.Set("Authorization", auth)
+	}
+
+	iamTimeout := h.config.Services.IAMService.Timeout
+	if iamTimeout == 0 {
+		iamTimeout = 10 * time.Second
+	}
+	httpClient := &http.Client{Timeout: iamTimeout}
+
+	iamResp, err := httpClient.Do(iamReq)
+	if err != nil {
+		h.logger.Warn("IAM service unreachable", zap.String("path", path), zap.Error(err))
+		apiErr := types.NewAPIError(types.ErrServiceUnavailable, "Authentication service unavailable")
+		apiErr.TraceID = c.GetString("request_id")
+		c.JSON(http.StatusServiceUnavailable, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+		return
+	}
+	defer iamResp.Body.Close()
+
+	if iamResp.StatusCode >= 200 && iamResp.StatusCode < 300 {
+		c.JSON(iamResp.StatusCode, dto.SuccessResponse{
+			Code:    iamResp.StatusCode,
+			Message: successMessage,
+			TraceID: c.GetString("request_id"),
+		})
+		return
+	}
+
+	var iamErr iamErrorResponse
+	respBody, _ := io.ReadAll(iamResp.Body)
+	_ = json.Unmarshal(respBody, &iamErr)
+	if iamErr.Message == "" {
+		iamErr.Message = http.StatusText(iamResp.StatusCode)
+	}
+
+	code := types.ErrOperationFailed
+	switch iamResp.StatusCode {
+	case http.StatusBadRequest:
+		code = types.ErrValidationFailed
+	case http.StatusUnauthorized:
+		code = types.ErrUnauthorized
+	case http.StatusNotFound:
+		code = types.ErrNotFound
+	case http.StatusConflict:
+		code = types.ErrConflict
+	case http.StatusUnprocessableEntity:
+		code = types.ErrUnprocessableEntity
+	case http.StatusTooManyRequests:
+		code = types.ErrTooManyRequests
+	}
+
+	h.logger.Warn("IAM request rejected",
+		zap.String("path", path),
+		zap.Int("iam_status", iamResp.StatusCode),
+		zap.String("iam_error", iamErr.Error),
+	)
+	apiErr := types.NewAPIError(code, iamErr.Message)
+	if len(iamErr.Violations) > 0 {
+		apiErr.Details["violations"] = iamErr.Violations
+	}
+	apiErr.TraceID = c.GetString("request_id")
+	c.JSON(iamResp.StatusCode, types.NewErrorResponse(apiErr, c.Request.URL.Path))
+}
@@ -9,13 +9,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"atlas-core-api/pkg/servicetoken"
 	"atlas-core-api/services/api-gateway/internal/infrastructure/circuitbreaker"
 	"atlas-core-api/services/api-gateway/internal/infrastructure/config"
 )
 
-// SetupRoutes configures all API gateway proxy routes using config-driven service registry
-func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, logger *zap.Logger) {
-	proxy := newServiceProxy(cfg, logger)
+// SetupRoutes configures all API gateway proxy routes using config-driven
+// service registry. Proxied requests carry a service token minted by signer.
+func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, signer *servicetoken.Signer, logger *zap.Logger) {
+	proxy := newServiceProxy(cfg, signer, logger)
 
 	// Strategic Entity Management
 	entities := r.Group("/entities")
@@ -31,10 +33,48 @@ func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, logger *zap.Logger) {
 	risks := r.Group("/risks")
 	{
 		risks.POST("/assess", proxy.forward("risk-assessment", "/api/v1/risks/assess"))
+		risks.POST("/simulate", proxy.forward("risk-assessment", "/api/v1/risks/simulate"))
 		risks.GET("/:id", proxy.forward("risk-assessment", "/api/v1/risks/:id"))
 		risks.GET("/trends", proxy.forward("risk-assessment", "/api/v1/risks/trends"))
-		risks.POST("/alerts", proxy.forward("risk-assessment", "/api/v1/risks/alerts"))
 		risks.GET("/profiles", proxy.forward("risk-assessment", "/api/v1/risks/profiles"))
+		risks.GET("/entities/:entity_id", proxy.forward("risk-assessment", "/api/v1/risks/entities/:entity_id"))
+		risks.GET("/providers/health", proxy.forward("risk-assessment", "/api/v1/risks/providers/health"))
+
+		// Batch assessment jobs
+		risks.POST("/jobs", proxy.forward("risk-assessment", "/api/v1/risks/jobs"))
+		risks.GET("/jobs/:id", proxy.forward("risk-assessment", "/api/v1/risks/jobs/:id"))
+		risks.GET("/jobs/:id/results", proxy.forward("risk-assessment", "/api/v1/risks/jobs/:id/results"))
+
+		// Threshold alerts and their lifecycle
+		risks.POST("/alerts", proxy.forward("risk-assessment", "/api/v1/risks/alerts"))
+		risks.GET("/alerts", proxy.forward("risk-assessment", "/api/v1/risks/alerts"))
+		risks.POST("/alerts/test", proxy.forward("risk-assessment", "/api/v1/risks/alerts/test"))
+		risks.GET("/alerts/:id", proxy.forward("risk-assessment", "/api/v1/risks/alerts/:id"))
+		risks.DELETE("/alerts/:id", proxy.forward("risk-assessment", "/api/v1/risks/alerts/:id"))
+		risks.POST("/alerts/:id/acknowledge", proxy.forward("risk-assessment", "/api/v1/risks/alerts/:id/acknowledge"))
+		risks.POST("/alerts/:id/resolve", proxy.forward("risk-assessment", "/api/v1/risks/alerts/:id/resolve"))
+
+		// Scheduled reassessment watchlist
+		risks.POST("/watchlist", proxy.forward("risk-assessment", "/api/v1/risks/watchlist"))
+		risks.GET("/watchlist", proxy.forward("risk-assessment", "/api/v1/risks/watchlist"))
+		risks.GET("/watchlist/:id", proxy.forward("risk-assessment", "/api/v1/risks/watchlist/:id"))
+		risks.DELETE("/watchlist/:id", proxy.forward("risk-assessment", "/api/v1/risks/watchlist/:id"))
+
+		// Observed outcomes and backtests of stored scores against them
+		risks.POST("/outcomes", proxy.forward("risk-assessment", "/api/v1/risks/outcomes"))
+		risks.GET("/outcomes", proxy.forward("risk-assessment", "/api/v1/risks/outcomes"))
+		risks.GET("/outcomes/:id", proxy.forward("risk-assessment", "/api/v1/risks/outcomes/:id"))
+		risks.DELETE("/outcomes/:id", proxy.forward("risk-assessment", "/api/v1/risks/outcomes/:id"))
+		risks.POST("/backtests", proxy.forward("risk-assessment", "/api/v1/risks/backtests"))
+		risks.GET("/backtests", proxy.forward("risk-assessment", "/api/v1/risks/backtests"))
+		risks.GET("/backtests/:id", proxy.forward("risk-assessment", "/api/v1/risks/backtests/:id"))
+		risks.GET("/backtests/:id/report", proxy.forward("risk-assessment", "/api/v1/risks/backtests/:id/report"))
+
+		// Scoring model versions
+		risks.GET("/models", proxy.forward("risk-assessment", "/api/v1/risks/models"))
+		risks.POST("/models", proxy.forward("risk-assessment", "/api/v1/risks/models"))
+		risks.GET("/models/:id", proxy.forward("risk-assessment", "/api/v1/risks/models/:id"))
+		risks.POST("/models/:id/activate", proxy.forward("risk-assessment", "/api/v1/risks/models/:id/activate"))
 	}
 
 	// Risk Profiles (Executive summaries)
@@ -45,6 +85,14 @@ func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, logger *zap.Logger) {
 		riskProfiles.POST("", proxy.forward("risk-assessment", "/api/v1/risk-profiles"))
 	}
 
+	// In-app alert notifications
+	notifications := r.Group("/notifications")
+	{
+		notifications.GET("", proxy.forward("risk-assessment", "/api/v1/notifications"))
+		notifications.POST("/:id/read", proxy.forward("risk-assessment", "/api/v1/notifications/:id/read"))
+		notifications.POST("/:id/dismiss", proxy.forward("risk-assessment", "/api/v1/notifications/:id/dismiss"))
+	}
+
 	// Scenario Simulation
 	scenarios := r.Group("/scenarios")
 	{
@@ -399,9 +447,29 @@ func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, logger *zap.Logger) {
 	// Settings
 	settings := r.Group("/settings")
 	{
+		settings.GET("/schema", proxy.forward("iam-service", "/api/v1/settings/schema"))
 		settings.GET("/user", proxy.forward("iam-service", "/api/v1/settings/user"))
 		settings.PUT("/user", proxy.forward("iam-service", "/api/v1/settings/user"))
+		settings.GET("/user/history", proxy.forward("iam-service", "/api/v1/settings/user/history"))
+		settings.DELETE("/user/:category/:key", proxy.forward("iam-service", "/api/v1/settings/user/:category/:key"))
+		settings.GET("/notifications", proxy.forward("iam-service", "/api/v1/settings/notifications"))
+		settings.PUT("/notifications", proxy.forward("iam-service", "/api/v1/settings/notifications"))
 		settings.GET("/system", proxy.forward("iam-service", "/api/v1/settings/system"))
+		settings.GET("/system/history", proxy.forward("iam-service", "/api/v1/settings/system/history"))
+		settings.PUT("/system/:namespace/:key", proxy.forward("iam-service", "/api/v1/settings/system/:namespace/:key"))
+	}
+
+	// Webhook subscriptions to platform events
+	webhooks := r.Group("/webhooks")
+	{
+		webhooks.GET("/events", proxy.forward("iam-service", "/api/v1/webhooks/events"))
+		webhooks.GET("", proxy.forward("iam-service", "/api/v1/webhooks"))
+		webhooks.POST("", proxy.forward("iam-service", "/api/v1/webhooks"))
+		webhooks.GET("/:webhookId", proxy.forward("iam-service", "/api/v1/webhooks/:webhookId"))
+		webhooks.PUT("/:webhookId", proxy.forward("iam-service", "/api/v1/webhooks/:webhookId"))
+		webhooks.DELETE("/:webhookId", proxy.forward("iam-service", "/api/v1/webhooks/:webhookId"))
+		webhooks.GET("/:webhookId/deliveries", proxy.forward("iam-service", "/api/v1/webhooks/:webhookId/deliveries"))
+		webhooks.POST("/:webhookId/deliveries/:deliveryId/redeliver", proxy.forward("iam-service", "/api/v1/webhooks/:webhookId/deliveries/:deliveryId/redeliver"))
 	}
 
 	// Platform Overview (alias for overview endpoints, used by frontend SDK)
@@ -416,6 +484,8 @@ func SetupRoutes(r *gin.RouterGroup, cfg *config.Config, logger *zap.Logger) {
 	users := r.Group("/users")
 	{
 		users.GET("/me", proxy.forward("iam-service", "/api/v1/users/me"))
+		users.POST("/import", proxy.forward("iam-service", "/api/v1/users/import"))
+		users.GET("/export", proxy.forward("iam-service", "/api/v1/users/export"))
 	}
 
 	logger.Info("All proxy routes configured", zap.Int("registered_services", len(cfg.Services.Registry)))
@@ -426,9 +496,19 @@ type serviceProxy struct {
 	cfg    *config.Config
 	logger *zap.Logger
 	client *http.Client
+	signer *servicetoken.Signer
+}
+
+// identityHeaders are set by the gateway from the authenticated context and
+// never passed through from clients
+var identityHeaders = map[string]bool{
+	"X-User-Id":       true,
+	"X-Username":      true,
+	"X-User-Roles":    true,
+	"X-Service-Token": true,
 }
 
-func newServiceProxy(cfg *config.Config, logger *zap.Logger) *serviceProxy {
+func newServiceProxy(cfg *config.Config, signer *servicetoken.Signer, logger *zap.Logger) *serviceProxy {
 	return &serviceProxy{
 		cfg:    cfg,
 		logger: logger,
@@ -440,6 +520,7 @@ func newServiceProxy(cfg *config.Config, logger *zap.Logger) *serviceProxy {
 				IdleConnTimeout:     90 * time.Second,
 			},
 		},
+		signer: signer,
 	}
 }
 
@@ -481,7 +562,7 @@ func (p *serviceProxy) forward(serviceName, backendPath string) gin.HandlerFunc
 			"Trailers": true, "Upgrade": true,
 		}
 		for key, values := range c.Request.Header {
-			if key == "Host" || hopByHop[key] {
+			if key == "Host" || hopByHop[key] || identityHeaders[key] {
 				continue
 			}
 			for _, value := range values {
@@ -500,6 +581,20 @@ func (p *serviceProxy) forward(serviceName, backendPath string) gin.HandlerFunc
 			req.Header.Set("X-Username", username)
 		}
 
+		// Downstream services only trust the user context in a signed token
+		token, err := p.signer.Sign(serviceName, servicetoken.Claims{
+			Subject:   c.GetString("user_id"),
+			Username:  c.GetString("username"),
+			Roles:     c.GetStringSlice("roles"),
+			RequestID: c.GetString("request_id"),
+		})
+		if err != nil {
+			p.logger.Error("Failed to sign service token", zap.String("service", serviceName), zap.Error(err))
+			c.JSON(http.StatusInternalServerError, gin.H{"code": 500, "message": "Failed to create request"})
+			return
+		}
+		req.Header.Set(servicetoken.Header, token)
+
 		// Execute with circuit breaker resilience
 		resp, err := circuitbreaker.DoHTTPRequest(serviceName, req)
 		if err != nil {
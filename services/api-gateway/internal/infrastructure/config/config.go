@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"atlas-core-api/pkg/servicetoken"
 )
 
 // Config represents the complete application configuration
@@ -32,6 +34,9 @@ type Config struct {
 	// Security
 	Security SecurityConfig
 
+	// Service-to-service authentication
+	ServiceAuth ServiceAuthConfig
+
 	// Rate Limiting
 	RateLimit RateLimitConfig
 
@@ -136,6 +141,15 @@ type SecurityConfig struct {
 	AllowedOrigins []string
 }
 
+// ServiceAuthConfig represents the signed tokens the gateway attaches to
+// proxied requests. PrivateKey is a base64 Ed25519 key held only by the
+// gateway; services are given its public key.
+type ServiceAuthConfig struct {
+	TrustDomain string
+	PrivateKey  string
+	TokenTTL    time.Duration
+}
+
 // RateLimitConfig represents rate limiting configuration
 type RateLimitConfig struct {
 	Enabled       bool
@@ -251,6 +265,12 @@ func Load() *Config {
 			AllowedOrigins:  parseStringSlice(getEnv("ALLOWED_ORIGINS", "http://localhost:3000")),
 		},
 
+		ServiceAuth: ServiceAuthConfig{
+			TrustDomain: getEnv("SERVICE_TRUST_DOMAIN", "atlas.internal"),
+			PrivateKey:  getEnv("SERVICE_TOKEN_PRIVATE_KEY", servicetoken.DefaultPrivateKey),
+			TokenTTL:    getEnvDuration("SERVICE_TOKEN_TTL", 60*time.Second),
+		},
+
 		RateLimit: RateLimitConfig{
 			Enabled:          getEnvBool("RATE_LIMIT_ENABLED", true),
 			RequestsPerSecond: getEnvInt("RATE_LIMIT_RPS", 100),
@@ -31,7 +31,12 @@ func ValidateEmail(email string) error {
 	return nil
 }
 
-// ValidatePassword validates a password
+// ValidatePassword performs a basic length/special-character check.
+//
+// Deprecated: the IAM service owns the password policy (length, character
+// classes, banned words, username similarity and breach screening) and
+// publishes it at GET /api/v1/auth/password-policy; password-setting requests
+// are forwarded to IAM, which enforces it.
 func ValidatePassword(password string, minLength int, requireSpecial bool) error {
 	if password == "" {
 		return fmt.Errorf("password is required")
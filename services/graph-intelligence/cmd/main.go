@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"atlas-core-api/pkg/servicetoken"
 	"atlas-core-api/services/graph-intelligence/internal/api/handlers"
 	"atlas-core-api/services/graph-intelligence/internal/api/middleware"
 	service "atlas-core-api/services/graph-intelligence/internal/application"
@@ -19,6 +20,15 @@ import (
 
 func main() {
 	cfg := config.Load()
+	if cfg.Environment == "production" {
+		if err := servicetoken.CheckKey(cfg.ServiceTokenPublicKey); err != nil {
+			log.Fatalf("SERVICE_TOKEN_PUBLIC_KEY must be set in production: %v", err)
+		}
+	}
+	gatewayKeys, err := servicetoken.ParsePublicKeys(cfg.ServiceTokenPublicKey, cfg.ServiceTokenPreviousPublicKey)
+	if err != nil {
+		log.Fatalf("Invalid SERVICE_TOKEN_PUBLIC_KEY: %v", err)
+	}
 
 	// Initialize Neo4j client
 	neo4jClient := graphdb.NewNeo4jClient(cfg.Neo4jURI, cfg.Neo4jUser, cfg.Neo4jPassword)
@@ -41,6 +51,7 @@ func main() {
 
 	// API routes
 	api := router.Group("/api/v1")
+	api.Use(servicetoken.Require(cfg.TrustDomain, cfg.ServiceName, gatewayKeys...))
 	{
 		// Entity resolution
 		api.POST("/graph/entities/resolve", graphHandler.ResolveEntities)
@@ -3,6 +3,7 @@ module atlas-core-api/services/graph-intelligence
 go 1.21
 
 require (
+	atlas-core-api/pkg/servicetoken v0.0.0
 	github.com/gin-gonic/gin v1.9.1
 	github.com/google/uuid v1.5.0
 )
@@ -33,3 +34,5 @@ require (
 	google.golang.org/protobuf v1.30.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
+
+replace atlas-core-api/pkg/servicetoken => ../../pkg/servicetoken
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+
+	"atlas-core-api/pkg/servicetoken"
 )
 
 type Config struct {
@@ -9,6 +11,15 @@ type Config struct {
 	Neo4jURI   string
 	Neo4jUser  string
 	Neo4jPassword string
+
+	// Environment is "production" in production deployments
+	Environment string
+
+	// Service-to-service authentication
+	ServiceName                   string
+	TrustDomain                   string
+	ServiceTokenPublicKey         string
+	ServiceTokenPreviousPublicKey string
 }
 
 func Load() *Config {
@@ -17,6 +28,11 @@ func Load() *Config {
 		Neo4jURI:     getEnv("NEO4J_URI", "bolt://localhost:7687"),
 		Neo4jUser:    getEnv("NEO4J_USER", "neo4j"),
 		Neo4jPassword: getEnv("NEO4J_PASSWORD", "neo4j"),
+		Environment:                   getEnv("ENVIRONMENT", "development"),
+		ServiceName:                   getEnv("SERVICE_NAME", "graph-intelligence"),
+		TrustDomain:                   getEnv("SERVICE_TRUST_DOMAIN", "atlas.internal"),
+		ServiceTokenPublicKey:         getEnv("SERVICE_TOKEN_PUBLIC_KEY", servicetoken.DefaultPublicKey),
+		ServiceTokenPreviousPublicKey: getEnv("SERVICE_TOKEN_PREVIOUS_PUBLIC_KEY", ""),
 	}
 }
 
@@ -11,13 +11,21 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
 	"atlas-core-api/services/iam/internal/api/handlers"
 	"atlas-core-api/services/iam/internal/api/middleware"
 	service "atlas-core-api/services/iam/internal/application"
+	apphandlers "atlas-core-api/services/iam/internal/application/handlers"
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+	"atlas-core-api/services/iam/internal/infrastructure/breach"
 	"atlas-core-api/services/iam/internal/infrastructure/config"
+	"atlas-core-api/services/iam/internal/infrastructure/mail"
+	"atlas-core-api/services/iam/internal/infrastructure/messaging"
 	"atlas-core-api/services/iam/internal/infrastructure/repository"
+	"atlas-core-api/services/iam/internal/infrastructure/webhook"
 )
 
 const version = "2.0.0"
@@ -37,6 +45,9 @@ func main() {
 	if cfg.Environment == "production" && cfg.JWTSecret == "change-me-in-production" {
 		logger.Fatal("JWT_SECRET must be changed in production")
 	}
+	if cfg.Environment == "production" && cfg.Mail.Driver == "log" {
+		logger.Fatal("MAIL_DRIVER must be smtp or file in production")
+	}
 
 	// Initialize database with connection pooling
 	db, err := repository.NewPostgresDB(cfg.DatabaseURL)
@@ -47,15 +58,156 @@ func main() {
 
 	// Initialize repositories (both receive db connection)
 	userRepo := repository.NewUserRepository(db)
+	userAggregates := repository.NewUserAggregateRepository(db)
 	roleRepo := repository.NewRoleRepository(db)
+	roleCatalog := repository.NewDomainRoleRepository(roleRepo)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	credentialRepo := repository.NewCredentialRepository(db)
+	roleGovernanceRepo := repository.NewRoleGovernanceRepository(db)
+	elevationRepo := repository.NewElevationRepository(db)
+	settingsRepo := repository.NewSettingsRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+
+	// Initialize event publisher
+	// Domain events go through the transactional outbox; the relay delivers
+	// them to Kafka in per-aggregate order
+	outboxRepo := repository.NewOutboxRepository(db)
+	eventPublisher := messaging.NewOutboxEventPublisher(outboxRepo)
+
+	kafkaProducer := messaging.NewKafkaEventPublisher(cfg.KafkaBrokers)
+	defer kafkaProducer.Close()
+
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		messaging.NewOutboxRelay(outboxRepo, kafkaProducer, messaging.DefaultRelayConfig(), logger).Run(relayCtx)
+	}()
+
+	// Initialize mail delivery
+	var mailer mail.Sender
+	switch cfg.Mail.Driver {
+	case "smtp":
+		mailer = mail.NewSMTPSender(cfg.Mail.SMTPHost, cfg.Mail.SMTPPort, cfg.Mail.SMTPUsername, cfg.Mail.SMTPPassword, cfg.Mail.From)
+	case "file":
+		fileSender, err := mail.NewFileSender(cfg.Mail.OutboxDir, cfg.Mail.From)
+		if err != nil {
+			logger.Fatal("Failed to initialize mail outbox", zap.Error(err))
+		}
+		mailer = fileSender
+	case "log":
+		mailer = mail.NewLogSender(logger)
+	default:
+		logger.Fatal("Unknown MAIL_DRIVER", zap.String("driver", cfg.Mail.Driver))
+	}
+
+	// Password policy, shared by the legacy services and the domain value objects
+	passwordPolicy := valueobjects.PasswordPolicy{
+		MinLength:             cfg.Password.MinLength,
+		MaxLength:             cfg.Password.MaxLength,
+		RequireUpper:          cfg.Password.RequireUpper,
+		RequireLower:          cfg.Password.RequireLower,
+		RequireDigit:          cfg.Password.RequireDigit,
+		RequireSpecial:        cfg.Password.RequireSpecial,
+		MaxAge:                cfg.Password.MaxAge,
+		HistoryDepth:          cfg.Password.HistoryDepth,
+		BannedWords:           cfg.Password.BannedWords,
+		MaxUsernameSimilarity: cfg.Password.MaxUsernameSimilarity,
+	}
+	valueobjects.SetPasswordPolicy(passwordPolicy)
+
+	var breachedPasswords service.BreachedPasswordSource
+	if cfg.Password.BreachedListPath != "" {
+		src, err := breach.LoadLocalRangeSource(cfg.Password.BreachedListPath)
+		if err != nil {
+			logger.Fatal("Failed to load breached password list", zap.Error(err))
+		}
+		logger.Info("Loaded breached password list", zap.Int("hashes", src.Size()))
+		breachedPasswords = src
+	}
+	passwordValidator := service.NewPasswordValidator(passwordPolicy, breachedPasswords, cfg.Password.BreachedMinCount, logger)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, cfg.JWTSecret, logger)
-	userService := service.NewUserService(userRepo, roleRepo)
+	lockoutPolicy := service.DefaultLoginProtectionPolicy()
+	lockoutPolicy.MaxFailuresPerUsername = cfg.Lockout.MaxFailuresPerUsername
+	lockoutPolicy.MaxFailuresPerIP = cfg.Lockout.MaxFailuresPerIP
+	lockoutPolicy.Window = cfg.Lockout.Window
+	lockoutPolicy.LockoutDuration = cfg.Lockout.Duration
+	lockoutPolicy.MaxLockoutDuration = cfg.Lockout.MaxDuration
+	loginProtection := service.NewLoginProtectionService(loginAttemptRepo, eventPublisher, lockoutPolicy, logger)
+
+	// Command and query handlers for the user aggregate
+	createUserHandler := apphandlers.NewCreateUserHandler(userAggregates)
+	loginUserHandler := apphandlers.NewLoginUserHandler(userAggregates)
+	getUserHandler := apphandlers.NewGetUserHandler(userAggregates)
+	listUsersHandler := apphandlers.NewListUsersHandler(userAggregates)
+
+	authService := service.NewAuthService(userRepo, cfg.JWTSecret, logger).
+		WithUserCommands(createUserHandler, loginUserHandler).
+		WithLoginProtection(loginProtection).
+		WithPasswordValidator(passwordValidator)
+	userService := service.NewUserService(userRepo, roleRepo).WithPasswordValidator(passwordValidator)
+	credentialService := service.NewCredentialService(userRepo, credentialRepo, mailer, passwordValidator, service.CredentialConfig{
+		BaseURL:              cfg.Credentials.AppBaseURL,
+		ResetTokenTTL:        cfg.Credentials.ResetTokenTTL,
+		VerificationTokenTTL: cfg.Credentials.VerificationTokenTTL,
+	}, logger)
+
+	roleGovernance := service.NewRoleGovernanceService(userRepo, roleRepo, roleGovernanceRepo, logger)
+	elevationService := service.NewElevationService(userRepo, roleRepo, elevationRepo, mailer, logger)
+	settingsService := service.NewSettingsService(userRepo, settingsRepo, logger).
+		WithInsecureTargets(cfg.Environment == "development")
+
+	// Webhooks: platform events are queued per subscription as they are
+	// consumed and delivered, signed, by a separate loop
+	webhookConfig := service.DefaultWebhookConfig()
+	webhookConfig.BatchSize = cfg.Webhooks.BatchSize
+	webhookConfig.PollInterval = cfg.Webhooks.PollInterval
+	webhookConfig.BaseBackoff = cfg.Webhooks.RetryBase
+	webhookConfig.MaxBackoff = cfg.Webhooks.RetryMax
+	webhookConfig.DisableAfter = cfg.Webhooks.DisableAfter
+	webhookConfig.AllowInsecureURLs = cfg.Environment == "development"
+	webhookPoster := webhook.NewHTTPPoster(webhook.PosterConfig{CaptureResponseBody: cfg.Webhooks.CaptureResponseBody})
+	webhookService := service.NewWebhookService(userRepo, webhookRepo, webhookPoster, webhookConfig, logger)
+
+	eventConsumer := messaging.NewEventConsumer(cfg.KafkaBrokers, cfg.Webhooks.ConsumerGroup, models.WebhookEventTypes, webhookService, logger)
+	defer eventConsumer.Close()
+
+	webhookCtx, stopWebhooks := context.WithCancel(context.Background())
+	defer stopWebhooks()
+	webhooksDone := make(chan struct{}, 2)
+	go func() {
+		defer func() { webhooksDone <- struct{}{} }()
+		eventConsumer.Run(webhookCtx)
+	}()
+	go func() {
+		defer func() { webhooksDone <- struct{}{} }()
+		webhookService.Run(webhookCtx)
+	}()
+
+	// Expired assignments stop granting access as soon as they lapse; the
+	// sweep removes them and publishes the revocations
+	sweepCtx, stopSweep := context.WithCancel(context.Background())
+	defer stopSweep()
+	sweepDone := make(chan struct{})
+	go func() {
+		defer close(sweepDone)
+		roleGovernance.RunExpirySweep(sweepCtx, cfg.Roles.ExpirySweepInterval)
+	}()
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
-	userHandler := handlers.NewUserHandler(userService)
+	authHandler := handlers.NewAuthHandler(authService, credentialService)
+	userHandler := handlers.NewUserHandler(userService, getUserHandler, listUsersHandler).
+		WithUserTransfer(
+			apphandlers.NewImportUsersHandler(userAggregates, roleCatalog, passwordValidator, credentialService).
+				WithRoleConstraints(roleGovernance),
+			apphandlers.NewExportUsersHandler(userAggregates),
+		).
+		WithRoleGovernance(roleGovernance).
+		WithElevations(elevationService)
+	settingsHandler := handlers.NewSettingsHandler(settingsService)
+	webhookHandler := handlers.NewWebhookHandler(webhookService)
 
 	// Set Gin mode
 	if cfg.Environment == "production" {
@@ -64,11 +216,18 @@ func main() {
 
 	// Initialize router with enterprise middleware
 	r := gin.New()
+	// Login lockouts are keyed on the client IP, so only the gateway may
+	// set it through X-Forwarded-For
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logger.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
 	r.Use(gin.Recovery())
 	r.Use(middleware.RequestID())
 	r.Use(middleware.Logger(logger))
 
 	// Health check (unauthenticated)
+	r.GET("/metrics", gin.WrapH(promhttp.Handler()))
+
 	r.GET("/health", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
 			"status":  "healthy",
@@ -86,6 +245,10 @@ func main() {
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
 			auth.POST("/refresh", authHandler.RefreshToken)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.GET("/password-policy", authHandler.GetPasswordPolicy)
 		}
 
 		// Authenticated routes
@@ -94,24 +257,89 @@ func main() {
 		{
 			// Logout requires auth
 			authenticated.POST("/auth/logout", authHandler.Logout)
+			authenticated.POST("/auth/change-password", authHandler.ChangePassword)
+			authenticated.POST("/auth/resend-verification", authHandler.ResendVerification)
 
 			// User self-management
 			authenticated.GET("/users/me", userHandler.GetCurrentUser)
 			authenticated.GET("/users/:id", userHandler.GetUser)
 			authenticated.PUT("/users/:id", userHandler.UpdateUser)
 
+			// Role assignment: admins, or holders of a delegating role. Users
+			// may also list their own roles.
+			authenticated.GET("/users/:id/roles", userHandler.ListUserRoles)
+			authenticated.POST("/users/:id/roles/:roleId", userHandler.AssignRole)
+			authenticated.DELETE("/users/:id/roles/:roleId", userHandler.RemoveRole)
+
+			// Just-in-time elevation
+			authenticated.POST("/elevations", userHandler.RequestElevation)
+			authenticated.GET("/elevations/mine", userHandler.ListMyElevations)
+			authenticated.POST("/elevations/:elevationId/end", userHandler.EndElevation)
+
+			// Settings and notification preferences
+			authenticated.GET("/settings/schema", settingsHandler.GetSettingDefinitions)
+			authenticated.GET("/settings/user", settingsHandler.GetUserSettings)
+			authenticated.PUT("/settings/user", settingsHandler.UpdateUserSettings)
+			authenticated.GET("/settings/user/history", settingsHandler.GetUserSettingsHistory)
+			authenticated.DELETE("/settings/user/:category/:key", settingsHandler.ResetUserSetting)
+			authenticated.GET("/settings/notifications", settingsHandler.GetNotificationPreferences)
+			authenticated.PUT("/settings/notifications", settingsHandler.UpdateNotificationPreferences)
+			authenticated.GET("/settings/system", settingsHandler.GetSystemSettings)
+
 			// Admin-only: user management
 			admin := authenticated.Group("")
 			admin.Use(middleware.RequireRole("admin"))
 			{
 				admin.GET("/users", userHandler.ListUsers)
+				admin.POST("/users/import", userHandler.ImportUsers)
+				admin.GET("/users/export", userHandler.ExportUsers)
 				admin.DELETE("/users/:id", userHandler.DeleteUser)
 
 				// Role management
 				admin.GET("/roles", userHandler.ListRoles)
 				admin.POST("/roles", userHandler.CreateRole)
-				admin.POST("/users/:id/roles/:roleId", userHandler.AssignRole)
-				admin.DELETE("/users/:id/roles/:roleId", userHandler.RemoveRole)
+
+				// Role governance
+				admin.GET("/roles/requests", userHandler.ListRoleRequests)
+				admin.POST("/roles/requests/:requestId/approve", userHandler.ApproveRoleRequest)
+				admin.POST("/roles/requests/:requestId/reject", userHandler.RejectRoleRequest)
+				admin.GET("/roles/delegations", userHandler.ListRoleDelegations)
+				admin.POST("/roles/delegations", userHandler.CreateRoleDelegation)
+				admin.DELETE("/roles/delegations/:delegationId", userHandler.DeleteRoleDelegation)
+				admin.GET("/roles/exclusions", userHandler.ListRoleExclusions)
+				admin.POST("/roles/exclusions", userHandler.CreateRoleExclusion)
+				admin.DELETE("/roles/exclusions/:exclusionId", userHandler.DeleteRoleExclusion)
+
+				// Elevation approvals, policies and compliance reporting
+				admin.GET("/elevations", userHandler.ListElevations)
+				admin.GET("/elevations/report", userHandler.ElevationReport)
+				admin.POST("/elevations/:elevationId/approve", userHandler.ApproveElevation)
+				admin.POST("/elevations/:elevationId/reject", userHandler.RejectElevation)
+				admin.GET("/elevations/policies", userHandler.ListElevationPolicies)
+				admin.PUT("/elevations/policies/:roleId", userHandler.SaveElevationPolicy)
+				admin.DELETE("/elevations/policies/:roleId", userHandler.DeleteElevationPolicy)
+				admin.GET("/elevations/break-glass", userHandler.ListBreakGlassAccounts)
+				admin.PUT("/elevations/break-glass/:userId", userHandler.DesignateBreakGlassAccount)
+				admin.DELETE("/elevations/break-glass/:userId", userHandler.RevokeBreakGlassAccount)
+
+				// System configuration
+				admin.PUT("/settings/system/:namespace/:key", settingsHandler.UpdateSystemSetting)
+				admin.GET("/settings/system/history", settingsHandler.GetSystemSettingsHistory)
+
+				// Brute-force protection
+				admin.GET("/auth/login-attempts", authHandler.ListLoginAttempts)
+				admin.GET("/auth/lockouts", authHandler.ListLockouts)
+				admin.POST("/auth/unlock", authHandler.UnlockAccount)
+
+				// Webhook subscriptions, their delivery log and redelivery
+				admin.GET("/webhooks/events", webhookHandler.ListEventTypes)
+				admin.GET("/webhooks", webhookHandler.ListWebhooks)
+				admin.POST("/webhooks", webhookHandler.CreateWebhook)
+				admin.GET("/webhooks/:webhookId", webhookHandler.GetWebhook)
+				admin.PUT("/webhooks/:webhookId", webhookHandler.UpdateWebhook)
+				admin.DELETE("/webhooks/:webhookId", webhookHandler.DeleteWebhook)
+				admin.GET("/webhooks/:webhookId/deliveries", webhookHandler.ListDeliveries)
+				admin.POST("/webhooks/:webhookId/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
 			}
 		}
 	}
@@ -154,5 +382,14 @@ func main() {
 		logger.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
+	// Stop background work before the producer and database close
+	stopSweep()
+	<-sweepDone
+	stopWebhooks()
+	<-webhooksDone
+	<-webhooksDone
+	stopRelay()
+	<-relayDone
+
 	logger.Info("Server exited gracefully")
 }
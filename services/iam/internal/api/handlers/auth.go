@@ -1,19 +1,25 @@
 package handlers
 
 import (
+	"errors"
+	"math"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	service "atlas-core-api/services/iam/internal/application"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
+	authService       *service.AuthService
+	credentialService *service.CredentialService
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
-	return &AuthHandler{authService: authService}
+func NewAuthHandler(authService *service.AuthService, credentialService *service.CredentialService) *AuthHandler {
+	return &AuthHandler{authService: authService, credentialService: credentialService}
 }
 
 type LoginRequest struct {
@@ -32,8 +38,26 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	user, accessToken, refreshToken, err := h.authService.Login(req.Username, req.Password)
+	user, accessToken, refreshToken, err := h.authService.Login(
+		c.Request.Context(), req.Username, req.Password, c.ClientIP(), c.Request.UserAgent(),
+	)
 	if err != nil {
+		var lockoutErr *service.LockoutError
+		if errors.As(err, &lockoutErr) {
+			retryAfter := int(math.Ceil(lockoutErr.RetryAfter.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.JSON(http.StatusTooManyRequests, gin.H{
+				"error":       "too_many_requests",
+				"message":     "Too many failed login attempts. Please try again later.",
+				"retry_after": retryAfter,
+			})
+			return
+		}
+		var expiredErr *service.PasswordExpiredError
+		if errors.As(err, &expiredErr) {
+			h.respondPasswordExpired(c, expiredErr)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "unauthorized",
 			"message": "Invalid credentials",
@@ -41,9 +65,6 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// Update last login
-	h.authService.RecordLogin(user.ID)
-
 	// Set httpOnly cookies
 	c.SetCookie("access_token", accessToken, 3600, "/", "", true, true)
 	c.SetCookie("refresh_token", refreshToken, 86400*7, "/", "", true, true)
@@ -115,6 +136,11 @@ func (h *AuthHandler) RefreshToken(c *gin.Context) {
 	// Validate refresh token and generate new pair
 	accessToken, newRefreshToken, err := h.authService.RefreshTokens(refreshToken)
 	if err != nil {
+		var expiredErr *service.PasswordExpiredError
+		if errors.As(err, &expiredErr) {
+			h.respondPasswordExpired(c, expiredErr)
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{
 			"error":   "unauthorized",
 			"message": "Invalid or expired refresh token",
@@ -152,8 +178,11 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.authService.Register(req.Username, req.Email, req.Password, req.FirstName, req.LastName)
+	user, err := h.authService.Register(c.Request.Context(), req.Username, req.Email, req.Password, req.FirstName, req.LastName)
 	if err != nil {
+		if respondPolicyViolation(c, err) {
+			return
+		}
 		if err.Error() == "user already exists" {
 			c.JSON(http.StatusConflict, gin.H{
 				"error":   "conflict",
@@ -168,12 +197,130 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		return
 	}
 
+	if err := h.credentialService.SendVerification(c.Request.Context(), user.ID, user.Username, user.Email); err != nil {
+		// The account exists; the user can request another link later
+		c.Error(err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
 		"data": gin.H{
 			"id":       user.ID,
 			"username": user.Username,
 			"email":    user.Email,
 		},
-		"message": "Account created successfully",
+		"message": "Account created successfully. Check your email to verify your address.",
 	})
 }
+
+// ListLoginAttempts returns recorded login attempts, filterable by username and IP
+func (h *AuthHandler) ListLoginAttempts(c *gin.Context) {
+	protection := h.authService.LoginProtection()
+	if protection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "unavailable",
+			"message": "Login attempt tracking is not enabled",
+		})
+		return
+	}
+
+	filter := repository.LoginAttemptFilter{
+		Username:  c.Query("username"),
+		IPAddress: c.Query("ip_address"),
+	}
+	filter.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if since := c.Query("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "bad_request",
+				"message": "since must be an RFC3339 timestamp",
+			})
+			return
+		}
+		filter.Since = &t
+	}
+
+	attempts, err := protection.History(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to list login attempts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": attempts,
+		"meta": gin.H{"count": len(attempts)},
+	})
+}
+
+// ListLockouts returns lockout records; pass active=false to include expired ones
+func (h *AuthHandler) ListLockouts(c *gin.Context) {
+	protection := h.authService.LoginProtection()
+	if protection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "unavailable",
+			"message": "Login attempt tracking is not enabled",
+		})
+		return
+	}
+
+	activeOnly := c.DefaultQuery("active", "true") != "false"
+	lockouts, err := protection.Lockouts(c.Request.Context(), activeOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to list lockouts",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": lockouts,
+		"meta": gin.H{"count": len(lockouts)},
+	})
+}
+
+type UnlockRequest struct {
+	Scope   string `json:"scope" binding:"required,oneof=username ip"`
+	Subject string `json:"subject" binding:"required"`
+}
+
+// UnlockAccount clears a username or IP lockout
+func (h *AuthHandler) UnlockAccount(c *gin.Context) {
+	protection := h.authService.LoginProtection()
+	if protection == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error":   "unavailable",
+			"message": "Login attempt tracking is not enabled",
+		})
+		return
+	}
+
+	var req UnlockRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := protection.Unlock(c.Request.Context(), req.Scope, req.Subject, c.GetString("user_id")); err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "bad_request",
+				"message": err.Error(),
+			})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to unlock",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Lockout cleared"})
+}
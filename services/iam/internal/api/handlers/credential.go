@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/iam/internal/application"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type ChangePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// ForgotPassword always answers 202 so callers cannot probe which emails exist
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req ForgotPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.credentialService.RequestPasswordReset(c.Request.Context(), req.Email, c.ClientIP()); err != nil {
+		c.Error(err)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.credentialService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		h.handleCredentialError(c, err, "Failed to reset password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password reset successfully"})
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	if err := h.credentialService.ChangePassword(c.Request.Context(), userID, req.OldPassword, req.NewPassword); err != nil {
+		h.handleCredentialError(c, err, "Failed to change password")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed successfully"})
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	if err := h.credentialService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		h.handleCredentialError(c, err, "Failed to verify email")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Email verified successfully"})
+}
+
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	if err := h.credentialService.ResendVerification(c.Request.Context(), c.GetString("user_id")); err != nil {
+		h.handleCredentialError(c, err, "Failed to send verification email")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"message": "Verification email sent"})
+}
+
+// GetPasswordPolicy publishes the active password rules so clients can
+// validate before submitting
+func (h *AuthHandler) GetPasswordPolicy(c *gin.Context) {
+	policy := h.credentialService.PasswordPolicy()
+	c.JSON(http.StatusOK, gin.H{
+		"data": gin.H{
+			"min_length":              policy.MinLength,
+			"max_length":              policy.MaxLength,
+			"require_upper":           policy.RequireUpper,
+			"require_lower":           policy.RequireLower,
+			"require_digit":           policy.RequireDigit,
+			"require_special":         policy.RequireSpecial,
+			"history_depth":           policy.HistoryDepth,
+			"max_age_days":            int(policy.MaxAge.Hours() / 24),
+			"max_username_similarity": policy.MaxUsernameSimilarity,
+		},
+	})
+}
+
+// respondPasswordExpired hands the user a one-time reset token so they can set
+// a new password without an access token
+func (h *AuthHandler) respondPasswordExpired(c *gin.Context, expiredErr *service.PasswordExpiredError) {
+	token, err := h.credentialService.IssueExpiredPasswordToken(c.Request.Context(), expiredErr.UserID, c.ClientIP())
+	if err != nil {
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": "Failed to start password change",
+		})
+		return
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{
+		"error":       "password_expired",
+		"message":     "Password has expired and must be changed",
+		"reset_token": token,
+	})
+}
+
+// respondPolicyViolation writes a 400 listing policy violations and reports
+// whether err was one
+func respondPolicyViolation(c *gin.Context, err error) bool {
+	var violation *valueobjects.PolicyViolationError
+	if !errors.As(err, &violation) {
+		return false
+	}
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error":      "password_policy",
+		"message":    "Password does not meet the password policy",
+		"violations": violation.Violations,
+	})
+	return true
+}
+
+func (h *AuthHandler) handleCredentialError(c *gin.Context, err error, fallback string) {
+	if respondPolicyViolation(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, service.ErrInvalidToken):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_token",
+			"message": "Token is invalid, expired or already used",
+		})
+	case errors.Is(err, service.ErrInvalidPassword):
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "unauthorized",
+			"message": "Current password is incorrect",
+		})
+	case errors.Is(err, service.ErrPasswordReused):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"error":   "password_reused",
+			"message": "New password must differ from recently used passwords",
+		})
+	case errors.Is(err, service.ErrAlreadyVerified):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "conflict",
+			"message": "Email is already verified",
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "User not found",
+		})
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": fallback,
+		})
+	}
+}
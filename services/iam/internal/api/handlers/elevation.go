@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/iam/internal/application"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var elevationReportColumns = []string{
+	"id", "user_id", "username", "role", "reason", "incident_ref", "duration_seconds", "break_glass",
+	"status", "decided_by", "decision_note", "requested_at", "activated_at", "expires_at", "ended_by", "ended_at",
+}
+
+// WithElevations exposes just-in-time role elevation
+func (h *UserHandler) WithElevations(elevations *service.ElevationService) *UserHandler {
+	h.elevations = elevations
+	return h
+}
+
+// RequestElevation asks for temporary use of a privileged role. Break-glass
+// requests from designated accounts are granted at once.
+func (h *UserHandler) RequestElevation(c *gin.Context) {
+	var req service.ElevationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	elevation, err := h.elevations.Request(c.Request.Context(), c.GetString("user_id"), c.ClientIP(), req)
+	if err != nil {
+		respondElevationError(c, err, "Failed to request elevation")
+		return
+	}
+
+	if elevation.BreakGlass {
+		c.JSON(http.StatusCreated, gin.H{
+			"data":    elevation,
+			"message": "Break-glass elevation active; admins have been alerted",
+		})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"data":    elevation,
+		"message": "Elevation requested and awaiting approval",
+	})
+}
+
+// ListMyElevations returns the caller's elevations
+func (h *UserHandler) ListMyElevations(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	elevations, err := h.elevations.Mine(c.Request.Context(), c.GetString("user_id"), limit)
+	if err != nil {
+		respondElevationError(c, err, "Failed to list elevations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": elevations,
+		"meta": gin.H{"count": len(elevations)},
+	})
+}
+
+// EndElevation withdraws a pending elevation or ends an active one early
+func (h *UserHandler) EndElevation(c *gin.Context) {
+	elevation, err := h.elevations.End(c.Request.Context(), c.GetString("user_id"), c.Param("elevationId"))
+	if err != nil {
+		respondElevationError(c, err, "Failed to end elevation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    elevation,
+		"message": "Elevation ended",
+	})
+}
+
+// ListElevations returns elevations filtered by user_id, role_id, status,
+// break_glass, since and until (admin only)
+func (h *UserHandler) ListElevations(c *gin.Context) {
+	filter, ok := bindElevationFilter(c)
+	if !ok {
+		return
+	}
+
+	elevations, err := h.elevations.List(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		respondElevationError(c, err, "Failed to list elevations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": elevations,
+		"meta": gin.H{"count": len(elevations)},
+	})
+}
+
+// ApproveElevation grants the elevated role for the requested duration
+// (admin only, not the requester)
+func (h *UserHandler) ApproveElevation(c *gin.Context) {
+	var req roleDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	elevation, err := h.elevations.Approve(c.Request.Context(), c.GetString("user_id"), c.Param("elevationId"), req.Note)
+	if err != nil {
+		respondElevationError(c, err, "Failed to approve elevation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    elevation,
+		"message": "Elevation approved",
+	})
+}
+
+// RejectElevation closes a pending elevation (admin only)
+func (h *UserHandler) RejectElevation(c *gin.Context) {
+	var req roleDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	elevation, err := h.elevations.Reject(c.Request.Context(), c.GetString("user_id"), c.Param("elevationId"), req.Note)
+	if err != nil {
+		respondElevationError(c, err, "Failed to reject elevation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    elevation,
+		"message": "Elevation rejected",
+	})
+}
+
+// ElevationReport returns elevations with totals by status and role as JSON
+// or, with format=csv, as a CSV attachment for compliance (admin only)
+func (h *UserHandler) ElevationReport(c *gin.Context) {
+	filter, ok := bindElevationFilter(c)
+	if !ok {
+		return
+	}
+	if c.Query("limit") == "" {
+		filter.Limit = 5000
+	}
+
+	report, err := h.elevations.Report(c.Request.Context(), c.GetString("user_id"), filter)
+	if err != nil {
+		respondElevationError(c, err, "Failed to build elevation report")
+		return
+	}
+
+	if c.DefaultQuery("format", "json") != "csv" {
+		c.JSON(http.StatusOK, gin.H{
+			"data": report.Elevations,
+			"meta": report.Summary,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="atlas-elevations-%s.csv"`, time.Now().UTC().Format("20060102")))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write(elevationReportColumns)
+	for _, e := range report.Elevations {
+		_ = w.Write([]string{
+			e.ID,
+			e.UserID,
+			csvSafe(e.Username),
+			e.RoleName,
+			csvSafe(e.Reason),
+			csvSafe(e.IncidentRef),
+			strconv.Itoa(e.DurationSecs),
+			strconv.FormatBool(e.BreakGlass),
+			e.Status,
+			e.DecidedBy,
+			csvSafe(e.DecisionNote),
+			e.RequestedAt.UTC().Format(time.RFC3339),
+			formatOptionalTime(e.ActivatedAt),
+			formatOptionalTime(e.ExpiresAt),
+			e.EndedBy,
+			formatOptionalTime(e.EndedAt),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		c.Error(err)
+	}
+}
+
+// ListElevationPolicies returns the roles eligible for elevation (admin only)
+func (h *UserHandler) ListElevationPolicies(c *gin.Context) {
+	policies, err := h.elevations.Policies(c.Request.Context())
+	if err != nil {
+		respondElevationError(c, err, "Failed to list elevation policies")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": policies})
+}
+
+// SaveElevationPolicy makes a role eligible for elevation (admin only)
+func (h *UserHandler) SaveElevationPolicy(c *gin.Context) {
+	var req service.ElevationPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	policy, err := h.elevations.SavePolicy(c.Request.Context(), c.GetString("user_id"), c.Param("roleId"), req)
+	if err != nil {
+		respondElevationError(c, err, "Failed to save elevation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    policy,
+		"message": "Elevation policy saved",
+	})
+}
+
+// DeleteElevationPolicy stops a role from being elevated into (admin only)
+func (h *UserHandler) DeleteElevationPolicy(c *gin.Context) {
+	if err := h.elevations.DeletePolicy(c.Request.Context(), c.GetString("user_id"), c.Param("roleId")); err != nil {
+		respondElevationError(c, err, "Failed to delete elevation policy")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Elevation policy deleted"})
+}
+
+// ListBreakGlassAccounts returns the accounts allowed to skip approval (admin only)
+func (h *UserHandler) ListBreakGlassAccounts(c *gin.Context) {
+	accounts, err := h.elevations.BreakGlassAccounts(c.Request.Context())
+	if err != nil {
+		respondElevationError(c, err, "Failed to list break-glass accounts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": accounts})
+}
+
+// DesignateBreakGlassAccount allows a user to elevate without approval (admin only)
+func (h *UserHandler) DesignateBreakGlassAccount(c *gin.Context) {
+	var req service.BreakGlassAccountRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	account, err := h.elevations.DesignateBreakGlass(c.Request.Context(), c.GetString("user_id"), c.Param("userId"), req)
+	if err != nil {
+		respondElevationError(c, err, "Failed to designate break-glass account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    account,
+		"message": "Break-glass account designated",
+	})
+}
+
+// RevokeBreakGlassAccount withdraws a break-glass designation (admin only)
+func (h *UserHandler) RevokeBreakGlassAccount(c *gin.Context) {
+	if err := h.elevations.RevokeBreakGlass(c.Request.Context(), c.GetString("user_id"), c.Param("userId")); err != nil {
+		respondElevationError(c, err, "Failed to revoke break-glass account")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Break-glass designation revoked"})
+}
+
+// bindElevationFilter reads listing filters from the query string; since and
+// until are RFC 3339 timestamps
+func bindElevationFilter(c *gin.Context) (repository.ElevationFilter, bool) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	filter := repository.ElevationFilter{
+		UserID: c.Query("user_id"),
+		RoleID: c.Query("role_id"),
+		Status: c.Query("status"),
+		Limit:  limit,
+	}
+
+	if v := c.Query("break_glass"); v != "" {
+		breakGlass, err := strconv.ParseBool(v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "bad_request",
+				"message": "break_glass must be true or false",
+			})
+			return filter, false
+		}
+		filter.BreakGlass = &breakGlass
+	}
+	for name, dst := range map[string]**time.Time{"since": &filter.Since, "until": &filter.Until} {
+		v := c.Query(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "bad_request",
+				"message": name + " must be an RFC 3339 timestamp",
+			})
+			return filter, false
+		}
+		*dst = &t
+	}
+	return filter, true
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
+
+func respondElevationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrElevationNotFound), errors.Is(err, service.ErrRoleNotElevatable):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrElevationOpen),
+		errors.Is(err, service.ErrElevationNotPending),
+		errors.Is(err, service.ErrElevationClosed),
+		errors.Is(err, service.ErrRoleAlreadyHeld):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "conflict",
+			"message": err.Error(),
+		})
+	default:
+		respondGovernanceError(c, err, fallback)
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/iam/internal/application"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// roleDecisionRequest is the optional body of an approve or reject call
+type roleDecisionRequest struct {
+	Note string `json:"note"`
+}
+
+// WithRoleGovernance routes role changes through delegation, separation of
+// duties and approval rules
+func (h *UserHandler) WithRoleGovernance(governance *service.RoleGovernanceService) *UserHandler {
+	h.governance = governance
+	return h
+}
+
+// ListUserRoles returns a user's active role assignments with their expiry
+// (the user, admins or holders of a delegation)
+func (h *UserHandler) ListUserRoles(c *gin.Context) {
+	assignments, err := h.governance.UserRoles(c.Request.Context(), c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to list role assignments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": assignments})
+}
+
+// ListRoleRequests returns grant requests, filtered by status and user_id (admin only)
+func (h *UserHandler) ListRoleRequests(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	requests, err := h.governance.GrantRequests(c.Request.Context(), repository.RoleGrantRequestFilter{
+		Status: c.Query("status"),
+		UserID: c.Query("user_id"),
+		Limit:  limit,
+	})
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to list role requests")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": requests,
+		"meta": gin.H{"count": len(requests)},
+	})
+}
+
+// ApproveRoleRequest grants the requested role (admin only, four-eyes)
+func (h *UserHandler) ApproveRoleRequest(c *gin.Context) {
+	var req roleDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	grant, err := h.governance.ApproveRequest(c.Request.Context(), c.GetString("user_id"), c.Param("requestId"), req.Note)
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to approve role request")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    grant,
+		"message": "Role request approved",
+	})
+}
+
+// RejectRoleRequest closes a pending request without granting the role (admin only)
+func (h *UserHandler) RejectRoleRequest(c *gin.Context) {
+	var req roleDecisionRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	grant, err := h.governance.RejectRequest(c.Request.Context(), c.GetString("user_id"), c.Param("requestId"), req.Note)
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to reject role request")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    grant,
+		"message": "Role request rejected",
+	})
+}
+
+// ListRoleDelegations returns which roles may assign which (admin only)
+func (h *UserHandler) ListRoleDelegations(c *gin.Context) {
+	delegations, err := h.governance.Delegations(c.Request.Context())
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to list role delegations")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": delegations})
+}
+
+// CreateRoleDelegation lets holders of one role manage another (admin only)
+func (h *UserHandler) CreateRoleDelegation(c *gin.Context) {
+	var req service.RoleDelegationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	delegation, err := h.governance.CreateDelegation(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to create role delegation")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    delegation,
+		"message": "Role delegation created",
+	})
+}
+
+// DeleteRoleDelegation removes a delegation (admin only)
+func (h *UserHandler) DeleteRoleDelegation(c *gin.Context) {
+	if err := h.governance.DeleteDelegation(c.Request.Context(), c.Param("delegationId")); err != nil {
+		respondGovernanceError(c, err, "Failed to delete role delegation")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role delegation deleted"})
+}
+
+// ListRoleExclusions returns the separation-of-duties rules (admin only)
+func (h *UserHandler) ListRoleExclusions(c *gin.Context) {
+	exclusions, err := h.governance.Exclusions(c.Request.Context())
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to list role exclusions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": exclusions})
+}
+
+// CreateRoleExclusion makes two roles mutually exclusive (admin only)
+func (h *UserHandler) CreateRoleExclusion(c *gin.Context) {
+	var req service.RoleExclusionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	exclusion, err := h.governance.CreateExclusion(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to create role exclusion")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    exclusion,
+		"message": "Role exclusion created",
+	})
+}
+
+// DeleteRoleExclusion removes a separation-of-duties rule (admin only)
+func (h *UserHandler) DeleteRoleExclusion(c *gin.Context) {
+	if err := h.governance.DeleteExclusion(c.Request.Context(), c.Param("exclusionId")); err != nil {
+		respondGovernanceError(c, err, "Failed to delete role exclusion")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Role exclusion deleted"})
+}
+
+// bindOptionalJSON binds a request body when one was sent
+func bindOptionalJSON(c *gin.Context, obj interface{}) bool {
+	if c.Request.ContentLength == 0 {
+		return true
+	}
+	if err := c.ShouldBindJSON(obj); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func respondGovernanceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrInvalidInput):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrForbidden), errors.Is(err, service.ErrSelfApproval):
+		c.JSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": "User not found",
+		})
+	case errors.Is(err, service.ErrRoleNotFound),
+		errors.Is(err, service.ErrRoleNotAssigned),
+		errors.Is(err, service.ErrRequestNotFound),
+		errors.Is(err, service.ErrRuleNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrSeparationOfDuties):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "separation_of_duties",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrRequestDecided),
+		errors.Is(err, service.ErrRequestPending),
+		errors.Is(err, service.ErrRuleExists):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "conflict",
+			"message": err.Error(),
+		})
+	default:
+		c.Error(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "internal_error",
+			"message": fallback,
+		})
+	}
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/iam/internal/application"
+)
+
+type SettingsHandler struct {
+	settingsService *service.SettingsService
+}
+
+func NewSettingsHandler(settingsService *service.SettingsService) *SettingsHandler {
+	return &SettingsHandler{settingsService: settingsService}
+}
+
+// NotificationPreferencesRequest sets the listed notification preferences
+type NotificationPreferencesRequest struct {
+	Preferences []service.NotificationPreferenceRequest `json:"preferences" binding:"required,dive"`
+}
+
+// GetSettingDefinitions lists every user setting with its JSON schema and
+// current default
+func (h *SettingsHandler) GetSettingDefinitions(c *gin.Context) {
+	defs, err := h.settingsService.Definitions(c.Request.Context())
+	if err != nil {
+		respondSettingsError(c, err, "Failed to list setting definitions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": defs})
+}
+
+// GetUserSettings returns the caller's effective settings grouped by
+// category, optionally for one category
+func (h *SettingsHandler) GetUserSettings(c *gin.Context) {
+	effective, err := h.settingsService.UserSettings(c.Request.Context(), c.GetString("user_id"), c.Query("category"))
+	if err != nil {
+		respondSettingsError(c, err, "Failed to load settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": service.GroupSettings(effective),
+		"meta": gin.H{"settings": effective},
+	})
+}
+
+// UpdateUserSettings applies a partial update such as
+// {"appearance": {"theme": "dark"}}; null resets a setting to its default
+func (h *SettingsHandler) UpdateUserSettings(c *gin.Context) {
+	var patch service.UserSettingsPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	effective, err := h.settingsService.UpdateUserSettings(c.Request.Context(), userID, userID, patch)
+	if err != nil {
+		respondSettingsError(c, err, "Failed to update settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    service.GroupSettings(effective),
+		"meta":    gin.H{"settings": effective},
+		"message": "Settings updated",
+	})
+}
+
+// ResetUserSetting restores one of the caller's settings to its default
+func (h *SettingsHandler) ResetUserSetting(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if err := h.settingsService.ResetUserSetting(c.Request.Context(), userID, userID, c.Param("category"), c.Param("key")); err != nil {
+		respondSettingsError(c, err, "Failed to reset setting")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Setting reset to default"})
+}
+
+// GetUserSettingsHistory returns changes to the caller's settings and
+// notification preferences
+func (h *SettingsHandler) GetUserSettingsHistory(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	changes, err := h.settingsService.UserHistory(c.Request.Context(), c.GetString("user_id"), limit)
+	if err != nil {
+		respondSettingsError(c, err, "Failed to load settings history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": changes,
+		"meta": gin.H{"count": len(changes)},
+	})
+}
+
+// GetNotificationPreferences returns the caller's notification preferences
+func (h *SettingsHandler) GetNotificationPreferences(c *gin.Context) {
+	prefs, err := h.settingsService.NotificationPreferences(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		respondSettingsError(c, err, "Failed to load notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": prefs})
+}
+
+// UpdateNotificationPreferences upserts the listed preferences; others are
+// left as they are
+func (h *SettingsHandler) UpdateNotificationPreferences(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	userID := c.GetString("user_id")
+	prefs, err := h.settingsService.UpdateNotificationPreferences(c.Request.Context(), userID, userID, req.Preferences)
+	if err != nil {
+		respondSettingsError(c, err, "Failed to update notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    prefs,
+		"message": "Notification preferences updated",
+	})
+}
+
+// GetSystemSettings returns system configuration, optionally for one namespace
+func (h *SettingsHandler) GetSystemSettings(c *gin.Context) {
+	values, err := h.settingsService.SystemSettings(c.Request.Context(), c.GetString("user_id"), c.Query("namespace"))
+	if err != nil {
+		respondSettingsError(c, err, "Failed to load system settings")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": values})
+}
+
+// UpdateSystemSetting sets a registered system setting (admin only)
+func (h *SettingsHandler) UpdateSystemSetting(c *gin.Context) {
+	var req service.SystemSettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	setting, err := h.settingsService.UpdateSystemSetting(c.Request.Context(), c.GetString("user_id"), c.Param("namespace"), c.Param("key"), req)
+	if err != nil {
+		respondSettingsError(c, err, "Failed to update system setting")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    setting,
+		"message": "System setting updated",
+	})
+}
+
+// GetSystemSettingsHistory returns changes to system settings (admin only)
+func (h *SettingsHandler) GetSystemSettingsHistory(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "100"))
+
+	changes, err := h.settingsService.SystemHistory(c.Request.Context(), c.GetString("user_id"), c.Query("namespace"), limit)
+	if err != nil {
+		respondSettingsError(c, err, "Failed to load system settings history")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": changes,
+		"meta": gin.H{"count": len(changes)},
+	})
+}
+
+func respondSettingsError(c *gin.Context, err error, fallback string) {
+	var invalid *service.SettingsValidationError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":      "invalid_settings",
+			"message":    "One or more settings are invalid",
+			"violations": invalid.Errors,
+		})
+	case errors.Is(err, service.ErrUnknownSetting):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrSettingReadOnly):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "read_only",
+			"message": err.Error(),
+		})
+	default:
+		respondGovernanceError(c, err, fallback)
+	}
+}
@@ -7,14 +7,23 @@ import (
 
 	"github.com/gin-gonic/gin"
 	service "atlas-core-api/services/iam/internal/application"
+	apphandlers "atlas-core-api/services/iam/internal/application/handlers"
+	"atlas-core-api/services/iam/internal/application/queries"
+	"atlas-core-api/services/iam/internal/domain/repositories"
 )
 
 type UserHandler struct {
 	userService *service.UserService
+	getUser     *apphandlers.GetUserHandler
+	listUsers   *apphandlers.ListUsersHandler
+	importUsers *apphandlers.ImportUsersHandler
+	exportUsers *apphandlers.ExportUsersHandler
+	governance  *service.RoleGovernanceService
+	elevations  *service.ElevationService
 }
 
-func NewUserHandler(userService *service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *service.UserService, getUser *apphandlers.GetUserHandler, listUsers *apphandlers.ListUsersHandler) *UserHandler {
+	return &UserHandler{userService: userService, getUser: getUser, listUsers: listUsers}
 }
 
 // GetCurrentUser returns the authenticated user's profile
@@ -28,9 +37,9 @@ func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetByID(userID.(string))
+	user, err := h.getUser.HandleByID(c.Request.Context(), queries.GetUserByIDQuery{UserID: userID.(string)})
 	if err != nil {
-		if errors.Is(err, service.ErrUserNotFound) {
+		if errors.Is(err, repositories.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "not_found",
 				"message": "User not found",
@@ -58,9 +67,9 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.GetByID(id)
+	user, err := h.getUser.HandleByID(c.Request.Context(), queries.GetUserByIDQuery{UserID: id})
 	if err != nil {
-		if errors.Is(err, service.ErrUserNotFound) {
+		if errors.Is(err, repositories.ErrUserNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error":   "not_found",
 				"message": "User not found",
@@ -92,9 +101,9 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	currentUserID, _ := c.Get("user_id")
 	roles, _ := c.Get("roles")
 	isAdmin := false
-	if userRoles, ok := roles.([]interface{}); ok {
+	if userRoles, ok := roles.([]string); ok {
 		for _, r := range userRoles {
-			if rs, ok := r.(string); ok && rs == "admin" {
+			if r == "admin" {
 				isAdmin = true
 				break
 			}
@@ -181,7 +190,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
 
-	result, err := h.userService.List(page, limit)
+	result, err := h.listUsers.Handle(c.Request.Context(), queries.ListUsersQuery{Page: page, PageSize: limit})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "internal_error",
@@ -195,7 +204,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		"meta": gin.H{
 			"total": result.Total,
 			"page":  result.Page,
-			"limit": result.Limit,
+			"limit": result.PageSize,
 		},
 	})
 }
@@ -247,7 +256,10 @@ func (h *UserHandler) CreateRole(c *gin.Context) {
 	})
 }
 
-// AssignRole assigns a role to a user (admin only)
+// AssignRole assigns a role to a user. Admins may assign any role and
+// delegated administrators the roles delegated to them. An optional body sets
+// expires_at and reason; roles that require approval answer 202 with the
+// grant request that was filed.
 func (h *UserHandler) AssignRole(c *gin.Context) {
 	userID := c.Param("id")
 	roleID := c.Param("roleId")
@@ -260,17 +272,21 @@ func (h *UserHandler) AssignRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.AssignRole(userID, roleID); err != nil {
-		if errors.Is(err, service.ErrUserNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error":   "not_found",
-				"message": "User not found",
-			})
-			return
-		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to assign role",
+	var req service.RoleAssignmentRequest
+	if !bindOptionalJSON(c, &req) {
+		return
+	}
+
+	grant, err := h.governance.AssignRole(c.Request.Context(), c.GetString("user_id"), userID, roleID, req)
+	if err != nil {
+		respondGovernanceError(c, err, "Failed to assign role")
+		return
+	}
+
+	if grant != nil {
+		c.JSON(http.StatusAccepted, gin.H{
+			"data":    grant,
+			"message": "Role requires approval; grant request submitted",
 		})
 		return
 	}
@@ -278,7 +294,9 @@ func (h *UserHandler) AssignRole(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Role assigned successfully"})
 }
 
-// RemoveRole removes a role from a user (admin only)
+// RemoveRole removes a role from a user, subject to the same delegation
+// rules as AssignRole. The optional reason query parameter is recorded on the
+// UserRoleRevoked event.
 func (h *UserHandler) RemoveRole(c *gin.Context) {
 	userID := c.Param("id")
 	roleID := c.Param("roleId")
@@ -291,11 +309,8 @@ func (h *UserHandler) RemoveRole(c *gin.Context) {
 		return
 	}
 
-	if err := h.userService.RemoveRole(userID, roleID); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "internal_error",
-			"message": "Failed to remove role",
-		})
+	if err := h.governance.RevokeRole(c.Request.Context(), c.GetString("user_id"), userID, roleID, c.Query("reason")); err != nil {
+		respondGovernanceError(c, err, "Failed to remove role")
 		return
 	}
 
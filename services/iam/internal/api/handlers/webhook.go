@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/iam/internal/application"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// maxDeliveryPageSize caps a page of the webhook delivery log
+const maxDeliveryPageSize = 100
+
+type WebhookHandler struct {
+	webhooks *service.WebhookService
+}
+
+func NewWebhookHandler(webhooks *service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhooks: webhooks}
+}
+
+// ListEventTypes returns the events a webhook can subscribe to
+func (h *WebhookHandler) ListEventTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.webhooks.EventTypes()})
+}
+
+// CreateWebhook subscribes a URL to events. The response carries the
+// signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req service.WebhookSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.webhooks.Create(c.Request.Context(), c.GetString("user_id"), req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to create webhook")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"data":    sub,
+		"message": "Webhook created; store the secret, it will not be shown again",
+	})
+}
+
+// ListWebhooks returns the caller's webhooks, or all webhooks for admins
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	subs, err := h.webhooks.List(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to list webhooks")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": subs,
+		"meta": gin.H{"count": len(subs)},
+	})
+}
+
+// GetWebhook returns one webhook
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	sub, err := h.webhooks.Get(c.Request.Context(), c.GetString("user_id"), c.Param("webhookId"))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to load webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": sub})
+}
+
+// UpdateWebhook changes a webhook; is_active re-enables a disabled one and
+// rotate_secret returns a new signing secret
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	var req service.WebhookSubscriptionUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "bad_request",
+			"message": err.Error(),
+		})
+		return
+	}
+
+	sub, err := h.webhooks.Update(c.Request.Context(), c.GetString("user_id"), c.Param("webhookId"), req)
+	if err != nil {
+		respondWebhookError(c, err, "Failed to update webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data":    sub,
+		"message": "Webhook updated",
+	})
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	if err := h.webhooks.Delete(c.Request.Context(), c.GetString("user_id"), c.Param("webhookId")); err != nil {
+		respondWebhookError(c, err, "Failed to delete webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook deleted"})
+}
+
+// ListDeliveries returns a page of a webhook's delivery log, filtered by
+// status and event_type
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	offset, _ := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if limit <= 0 || limit > maxDeliveryPageSize {
+		limit = maxDeliveryPageSize
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	deliveries, total, err := h.webhooks.Deliveries(c.Request.Context(), c.GetString("user_id"), repository.WebhookDeliveryFilter{
+		SubscriptionID: c.Param("webhookId"),
+		Status:         c.Query("status"),
+		EventType:      c.Query("event_type"),
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		respondWebhookError(c, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": deliveries,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+// RedeliverWebhook sends a logged delivery again
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	delivery, err := h.webhooks.Redeliver(c.Request.Context(), c.GetString("user_id"), c.Param("webhookId"), c.Param("deliveryId"))
+	if err != nil {
+		respondWebhookError(c, err, "Failed to redeliver webhook")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"data":    delivery,
+		"message": "Redelivery queued",
+	})
+}
+
+func respondWebhookError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound), errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		c.JSON(http.StatusNotFound, gin.H{
+			"error":   "not_found",
+			"message": err.Error(),
+		})
+	case errors.Is(err, service.ErrWebhookDisabled):
+		c.JSON(http.StatusConflict, gin.H{
+			"error":   "conflict",
+			"message": err.Error(),
+		})
+	default:
+		respondGovernanceError(c, err, fallback)
+	}
+}
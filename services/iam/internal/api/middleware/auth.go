@@ -40,7 +40,14 @@ func Authenticate(jwtSecret string) gin.HandlerFunc {
 		if username, ok := claims["username"].(string); ok {
 			c.Set("username", username)
 		}
-		if roles, ok := claims["roles"].([]string); ok {
+		// JSON arrays decode as []interface{}; downstream checks expect []string
+		if claimRoles, ok := claims["roles"].([]interface{}); ok {
+			roles := make([]string, 0, len(claimRoles))
+			for _, r := range claimRoles {
+				if role, ok := r.(string); ok {
+					roles = append(roles, role)
+				}
+			}
 			c.Set("roles", roles)
 		}
 
@@ -17,14 +17,18 @@ func Logger(logger *zap.Logger) gin.HandlerFunc {
 		c.Next()
 
 		latency := time.Since(start)
-		logger.Info("HTTP Request",
+		fields := []zap.Field{
 			zap.Int("status", c.Writer.Status()),
 			zap.String("method", c.Request.Method),
 			zap.String("path", path),
 			zap.String("query", query),
 			zap.String("ip", c.ClientIP()),
 			zap.Duration("latency", latency),
-		)
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+		logger.Info("HTTP Request", fields...)
 	}
 }
 
@@ -1,15 +1,19 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"go.uber.org/zap"
-	"golang.org/x/crypto/bcrypt"
 
-	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/application/commands"
+	"atlas-core-api/services/iam/internal/application/dto"
+	"atlas-core-api/services/iam/internal/application/handlers"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
 	"atlas-core-api/services/iam/internal/infrastructure/repository"
 )
 
@@ -17,6 +21,10 @@ type AuthService struct {
 	userRepo       repository.UserRepository
 	jwtSecret      string
 	logger         *zap.Logger
+	protection     *LoginProtectionService
+	createUser     *handlers.CreateUserHandler
+	loginUser      *handlers.LoginUserHandler
+	passwords      *PasswordValidator
 	blacklistedMu  sync.RWMutex
 	blacklistedTkn map[string]time.Time
 }
@@ -38,25 +46,82 @@ func NewAuthService(userRepo repository.UserRepository, jwtSecret string, logger
 	return svc
 }
 
-func (s *AuthService) Login(username, password string) (*models.User, string, string, error) {
-	s.logger.Info("Login attempt", zap.String("username", username))
+// WithUserCommands routes Register and Login through the user aggregate's
+// command handlers
+func (s *AuthService) WithUserCommands(createUser *handlers.CreateUserHandler, loginUser *handlers.LoginUserHandler) *AuthService {
+	s.createUser = createUser
+	s.loginUser = loginUser
+	return s
+}
 
-	user, err := s.userRepo.GetByUsername(username)
-	if err != nil {
-		s.logger.Error("User not found", zap.String("username", username), zap.Error(err))
-		return nil, "", "", errors.New("invalid credentials")
-	}
+// WithLoginProtection enables attempt tracking and lockouts on Login
+func (s *AuthService) WithLoginProtection(protection *LoginProtectionService) *AuthService {
+	s.protection = protection
+	return s
+}
 
-	if !user.Active {
-		s.logger.Warn("Login attempt for deactivated account", zap.String("username", username))
-		return nil, "", "", errors.New("account deactivated")
+// WithPasswordValidator sets the policy used for registration and password expiry
+func (s *AuthService) WithPasswordValidator(passwords *PasswordValidator) *AuthService {
+	s.passwords = passwords
+	return s
+}
+
+// LoginProtection returns the attached protection service, or nil if none
+func (s *AuthService) LoginProtection() *LoginProtectionService {
+	return s.protection
+}
+
+func (s *AuthService) Login(ctx context.Context, username, password, ipAddress, userAgent string) (*dto.UserDTO, string, string, error) {
+	s.logger.Info("Login attempt", zap.String("username", username), zap.String("ip_address", ipAddress))
+
+	if s.protection != nil {
+		if err := s.protection.Check(ctx, username, ipAddress); err != nil {
+			var lockoutErr *LockoutError
+			if errors.As(err, &lockoutErr) {
+				s.logger.Warn("Login refused by lockout",
+					zap.String("username", username),
+					zap.String("ip_address", ipAddress),
+					zap.String("scope", lockoutErr.Scope),
+				)
+				if err := s.protection.RecordRefused(ctx, username, ipAddress, userAgent); err != nil {
+					s.logger.Warn("Failed to record login attempt", zap.String("username", username), zap.Error(err))
+				}
+			}
+			return nil, "", "", err
+		}
 	}
 
-	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		s.logger.Error("Password verification failed", zap.String("username", username))
+	user, err := s.loginUser.Handle(ctx, commands.LoginUserCommand{
+		Username:  username,
+		Password:  password,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	})
+	if err != nil {
+		var failed *handlers.LoginFailedError
+		if !errors.As(err, &failed) {
+			s.logger.Error("Login failed", zap.String("username", username), zap.Error(err))
+			return nil, "", "", err
+		}
+
+		if errors.Is(failed, handlers.ErrPasswordExpired) {
+			// The password was correct, so clear the failure window before
+			// asking for a new one
+			s.recordSuccess(ctx, username, ipAddress, userAgent)
+			s.logger.Info("Password expired", zap.String("username", username))
+			return nil, "", "", &PasswordExpiredError{UserID: failed.UserID}
+		}
+
+		s.logger.Warn("Login rejected", zap.String("username", username), zap.String("reason", failed.Reason))
+		s.recordFailure(ctx, failed.UserID, username, ipAddress, userAgent, failed.Reason)
+		if errors.Is(failed, handlers.ErrAccountDeactivated) {
+			return nil, "", "", errors.New("account deactivated")
+		}
 		return nil, "", "", errors.New("invalid credentials")
 	}
 
+	s.recordSuccess(ctx, username, ipAddress, userAgent)
+
 	accessToken, err := s.generateAccessToken(user.ID, user.Username, user.Roles)
 	if err != nil {
 		return nil, "", "", err
@@ -71,34 +136,56 @@ func (s *AuthService) Login(username, password string) (*models.User, string, st
 	return user, accessToken, refreshToken, nil
 }
 
-func (s *AuthService) Register(username, email, password, firstName, lastName string) (*models.User, error) {
-	if len(password) < 8 {
-		return nil, errors.New("password must be at least 8 characters")
+// PasswordExpiredError is returned by Login and RefreshTokens when the
+// credentials are valid but the password is older than the policy's maximum age
+type PasswordExpiredError struct {
+	UserID string
+}
+
+func (e *PasswordExpiredError) Error() string { return "password expired" }
+
+func (s *AuthService) recordFailure(ctx context.Context, userID, username, ipAddress, userAgent, reason string) {
+	if s.protection == nil {
+		return
 	}
+	if err := s.protection.RecordFailure(ctx, userID, username, ipAddress, userAgent, reason); err != nil {
+		s.logger.Warn("Failed to record login attempt", zap.String("username", username), zap.Error(err))
+	}
+}
 
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	if err != nil {
-		return nil, errors.New("failed to hash password")
+func (s *AuthService) recordSuccess(ctx context.Context, username, ipAddress, userAgent string) {
+	if s.protection == nil {
+		return
+	}
+	if err := s.protection.RecordSuccess(ctx, username, ipAddress, userAgent); err != nil {
+		s.logger.Warn("Failed to record login attempt", zap.String("username", username), zap.Error(err))
 	}
+}
 
-	user := &models.User{
-		Username:     username,
-		Email:        email,
-		PasswordHash: string(hashedPassword),
-		FirstName:    firstName,
-		LastName:     lastName,
-		Active:       true,
+func (s *AuthService) Register(ctx context.Context, username, email, password, firstName, lastName string) (*dto.UserDTO, error) {
+	if err := s.passwords.Validate(ctx, password, username); err != nil {
+		return nil, err
 	}
 
-	if err := s.userRepo.Create(user); err != nil {
-		if errors.Is(err, repository.ErrUserAlreadyExists) {
+	user, err := s.createUser.Handle(ctx, commands.CreateUserCommand{
+		Username:  username,
+		Email:     email,
+		Password:  password,
+		FirstName: firstName,
+		LastName:  lastName,
+	})
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserAlreadyExists) {
 			return nil, errors.New("user already exists")
 		}
+		var violation *valueobjects.PolicyViolationError
+		if errors.As(err, &violation) {
+			return nil, violation
+		}
 		s.logger.Error("Failed to create user", zap.Error(err))
 		return nil, errors.New("failed to create user")
 	}
 
-	user.PasswordHash = ""
 	return user, nil
 }
 
@@ -141,6 +228,18 @@ func (s *AuthService) RefreshTokens(refreshTokenStr string) (string, string, err
 		return "", "", errors.New("account deactivated")
 	}
 
+	// A password change or reset revokes the sessions started before it
+	if issuedAt, err := claims.GetIssuedAt(); err != nil || issuedAt == nil ||
+		(user.PasswordChangedAt != nil && issuedAt.Before(user.PasswordChangedAt.Truncate(time.Second))) {
+		return "", "", errors.New("token has been revoked")
+	}
+
+	// An expired password ends the session just as it blocks a new login
+	if s.passwords.Policy().IsExpired(user.PasswordChangedAt, time.Now()) {
+		s.logger.Info("Refresh refused, password expired", zap.String("user_id", user.ID))
+		return "", "", &PasswordExpiredError{UserID: user.ID}
+	}
+
 	// Blacklist old refresh token (rotation)
 	s.BlacklistToken(refreshTokenStr)
 
@@ -171,12 +270,6 @@ func (s *AuthService) IsTokenBlacklisted(tokenStr string) bool {
 	return exists
 }
 
-func (s *AuthService) RecordLogin(userID string) {
-	if err := s.userRepo.UpdateLastLogin(userID); err != nil {
-		s.logger.Warn("Failed to record login time", zap.String("user_id", userID), zap.Error(err))
-	}
-}
-
 func (s *AuthService) ValidateToken(tokenString string) (map[string]interface{}, error) {
 	if s.IsTokenBlacklisted(tokenString) {
 		return nil, errors.New("token has been revoked")
@@ -35,3 +35,23 @@ type RevokeRoleCommand struct {
 	UserID string
 	RoleID string
 }
+
+// ImportUsersCommand upserts users by email. With DryRun set every row is
+// validated and classified but nothing is written.
+type ImportUsersCommand struct {
+	Rows   []UserImportRow
+	DryRun bool
+}
+
+// UserImportRow is one user from a bulk import file. Empty fields leave an
+// existing user's value unchanged; a non-empty Roles list replaces the user's
+// roles. Password is only used for new users.
+type UserImportRow struct {
+	Line      int
+	Username  string
+	Email     string
+	FirstName string
+	LastName  string
+	Password  string
+	Roles     []string
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+	"atlas-core-api/services/iam/internal/infrastructure/mail"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var (
+	ErrInvalidToken    = errors.New("invalid or expired token")
+	ErrInvalidPassword = errors.New("current password is incorrect")
+	ErrPasswordReused  = errors.New("password was used recently")
+	ErrAlreadyVerified = errors.New("email already verified")
+)
+
+// Reasons recorded on PasswordChanged events
+const (
+	PasswordChangeReasonReset  = "reset"
+	PasswordChangeReasonChange = "change"
+)
+
+// resetMailTimeout bounds the background delivery of a reset link, which
+// outlives the request that asked for it
+const resetMailTimeout = 30 * time.Second
+
+// CredentialConfig controls token lifetimes
+type CredentialConfig struct {
+	BaseURL              string
+	ResetTokenTTL        time.Duration
+	VerificationTokenTTL time.Duration
+}
+
+// CredentialService handles password reset, password change and email verification
+type CredentialService struct {
+	userRepo  repository.UserRepository
+	credRepo  repository.CredentialRepository
+	mailer    mail.Sender
+	passwords *PasswordValidator
+	config    CredentialConfig
+	logger    *zap.Logger
+	now       func() time.Time
+	// background runs work the caller must not wait for
+	background func(func())
+}
+
+func NewCredentialService(
+	userRepo repository.UserRepository,
+	credRepo repository.CredentialRepository,
+	mailer mail.Sender,
+	passwords *PasswordValidator,
+	config CredentialConfig,
+	logger *zap.Logger,
+) *CredentialService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &CredentialService{
+		userRepo:  userRepo,
+		credRepo:  credRepo,
+		mailer:    mailer,
+		passwords: passwords,
+		config:    config,
+		logger:    logger,
+		now:       time.Now,
+		background: func(work func()) {
+			go work()
+		},
+	}
+}
+
+// RequestPasswordReset mails a reset link if the email belongs to an active
+// account. It never reveals whether the account exists: the link is issued
+// and mailed in the background, so a known address answers as quickly as
+// an unknown one, and failures are only logged.
+func (s *CredentialService) RequestPasswordReset(ctx context.Context, email, ipAddress string) error {
+	user, err := s.userRepo.GetByEmail(strings.TrimSpace(email))
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			s.logger.Info("Password reset requested for unknown email", zap.String("ip_address", ipAddress))
+			return nil
+		}
+		return err
+	}
+	if !user.Active {
+		s.logger.Info("Password reset requested for inactive account",
+			zap.String("user_id", user.ID),
+			zap.String("ip_address", ipAddress),
+		)
+		return nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	s.background(func() {
+		ctx, cancel := context.WithTimeout(ctx, resetMailTimeout)
+		defer cancel()
+		if err := s.sendResetLink(ctx, user, ipAddress); err != nil {
+			s.logger.Error("Failed to send password reset link", zap.String("user_id", user.ID), zap.Error(err))
+		}
+	})
+	return nil
+}
+
+func (s *CredentialService) sendResetLink(ctx context.Context, user *models.User, ipAddress string) error {
+	token, err := s.issueToken(ctx, user.ID, models.TokenPurposePasswordReset, s.config.ResetTokenTTL, ipAddress)
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your ATLAS password",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s/reset-password?token=%s\n\nIf you did not request this, you can ignore this email.\n",
+			user.Username, s.config.ResetTokenTTL, s.config.BaseURL, token,
+		),
+	})
+}
+
+// ResetPassword sets a new password using a reset token. Changing the
+// password ends every refresh session issued before it, so a stolen session
+// does not outlive the reset. Tokens of deactivated accounts are refused.
+func (s *CredentialService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hash := hashToken(token)
+	issued, err := s.credRepo.FindToken(ctx, models.TokenPurposePasswordReset, hash)
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenInvalid) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if !issued.ExpiresAt.After(s.now()) {
+		return ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetByID(issued.UserID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+	if !user.Active {
+		return ErrInvalidToken
+	}
+
+	if err := s.passwords.Validate(ctx, newPassword, user.Username); err != nil {
+		return err
+	}
+	if err := s.checkReuse(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	// Consume only once the new password is acceptable, so a rejected
+	// password does not burn the link
+	if _, err := s.credRepo.ConsumeToken(ctx, models.TokenPurposePasswordReset, hash); err != nil {
+		if errors.Is(err, repository.ErrTokenInvalid) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	if err := s.setPassword(ctx, user, newPassword, PasswordChangeReasonReset); err != nil {
+		return err
+	}
+	return s.credRepo.InvalidateTokens(ctx, user.ID, models.TokenPurposePasswordReset)
+}
+
+// ChangePassword replaces the password of an authenticated user
+func (s *CredentialService) ChangePassword(ctx context.Context, userID, currentPassword, newPassword string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(currentPassword)); err != nil {
+		return ErrInvalidPassword
+	}
+
+	if err := s.passwords.Validate(ctx, newPassword, user.Username); err != nil {
+		return err
+	}
+	if err := s.checkReuse(ctx, user, newPassword); err != nil {
+		return err
+	}
+
+	return s.setPassword(ctx, user, newPassword, PasswordChangeReasonChange)
+}
+
+// PasswordPolicy returns the policy applied to new passwords
+func (s *CredentialService) PasswordPolicy() valueobjects.PasswordPolicy {
+	return s.passwords.Policy()
+}
+
+// IssueExpiredPasswordToken returns a reset token directly to a user who has
+// just proven their current password but must replace it because it expired
+func (s *CredentialService) IssueExpiredPasswordToken(ctx context.Context, userID, ipAddress string) (string, error) {
+	return s.issueToken(ctx, userID, models.TokenPurposePasswordReset, s.config.ResetTokenTTL, ipAddress)
+}
+
+// SendVerification mails an email verification link to the user
+func (s *CredentialService) SendVerification(ctx context.Context, userID, username, email string) error {
+	token, err := s.issueToken(ctx, userID, models.TokenPurposeEmailVerification, s.config.VerificationTokenTTL, "")
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Verify your ATLAS email address",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nConfirm your email address by opening the link below. It expires in %s.\n\n%s/verify-email?token=%s\n",
+			username, s.config.VerificationTokenTTL, s.config.BaseURL, token,
+		),
+	})
+}
+
+// SendPasswordSetup mails an imported user a link to choose their first
+// password. It is a reset token with the longer verification lifetime, since
+// the recipient did not ask for it.
+func (s *CredentialService) SendPasswordSetup(ctx context.Context, userID, username, email string) error {
+	token, err := s.issueToken(ctx, userID, models.TokenPurposePasswordReset, s.config.VerificationTokenTTL, "")
+	if err != nil {
+		return err
+	}
+
+	return s.mailer.Send(ctx, mail.Message{
+		To:      email,
+		Subject: "Your ATLAS account is ready",
+		Body: fmt.Sprintf(
+			"Hello %s,\n\nAn ATLAS account has been created for you. Choose your password using the link below. It expires in %s and can only be used once.\n\n%s/reset-password?token=%s\n",
+			username, s.config.VerificationTokenTTL, s.config.BaseURL, token,
+		),
+	})
+}
+
+// ResendVerification issues a fresh verification link for an unverified user
+func (s *CredentialService) ResendVerification(ctx context.Context, userID string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return ErrUserNotFound
+		}
+		return err
+	}
+	if user.Verified {
+		return ErrAlreadyVerified
+	}
+	return s.SendVerification(ctx, user.ID, user.Username, user.Email)
+}
+
+// VerifyEmail marks the token owner's email as verified
+func (s *CredentialService) VerifyEmail(ctx context.Context, token string) error {
+	issued, err := s.credRepo.ConsumeToken(ctx, models.TokenPurposeEmailVerification, hashToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrTokenInvalid) {
+			return ErrInvalidToken
+		}
+		return err
+	}
+
+	if err := s.userRepo.MarkVerified(issued.UserID); err != nil {
+		return err
+	}
+	return s.credRepo.InvalidateTokens(ctx, issued.UserID, models.TokenPurposeEmailVerification)
+}
+
+func (s *CredentialService) issueToken(ctx context.Context, userID, purpose string, ttl time.Duration, ipAddress string) (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate token: %w", err)
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	// Only the latest link of each purpose stays valid
+	if err := s.credRepo.InvalidateTokens(ctx, userID, purpose); err != nil {
+		return "", err
+	}
+
+	if err := s.credRepo.CreateToken(ctx, &models.UserToken{
+		UserID:      userID,
+		Purpose:     purpose,
+		TokenHash:   hashToken(token),
+		ExpiresAt:   s.now().Add(ttl),
+		RequestedIP: ipAddress,
+	}); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// checkReuse rejects the current password and the last N from history
+func (s *CredentialService) checkReuse(ctx context.Context, user *models.User, newPassword string) error {
+	if bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(newPassword)) == nil {
+		return ErrPasswordReused
+	}
+
+	depth := s.passwords.Policy().HistoryDepth
+	if depth <= 0 {
+		return nil
+	}
+	previous, err := s.credRepo.RecentPasswordHashes(ctx, user.ID, depth)
+	if err != nil {
+		return err
+	}
+	for _, hash := range previous {
+		if bcrypt.CompareHashAndPassword([]byte(hash), []byte(newPassword)) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+func (s *CredentialService) setPassword(ctx context.Context, user *models.User, newPassword, reason string) error {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	// The repository records the PasswordChanged event in the same transaction
+	if err := s.userRepo.UpdatePassword(user.ID, string(hashed), reason); err != nil {
+		return err
+	}
+	if err := s.credRepo.AddPasswordHistory(ctx, user.ID, user.PasswordHash); err != nil {
+		s.logger.Warn("Failed to record password history", zap.String("user_id", user.ID), zap.Error(err))
+	}
+
+	s.logger.Info("Password changed", zap.String("user_id", user.ID), zap.String("reason", reason))
+	return nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
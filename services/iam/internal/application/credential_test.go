@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+	"atlas-core-api/services/iam/internal/infrastructure/mail"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// fakeCredentials keeps tokens in memory and, like the Postgres
+// repository, only finds tokens that are unused and unexpired
+type fakeCredentials struct {
+	repository.CredentialRepository
+	tokens []*models.UserToken
+	now    func() time.Time
+}
+
+func (r *fakeCredentials) CreateToken(ctx context.Context, token *models.UserToken) error {
+	c := *token
+	r.tokens = append(r.tokens, &c)
+	return nil
+}
+
+func (r *fakeCredentials) FindToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	for _, token := range r.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash && token.UsedAt == nil && token.ExpiresAt.After(r.now()) {
+			c := *token
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrTokenInvalid
+}
+
+func (r *fakeCredentials) ConsumeToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	token, err := r.FindToken(ctx, purpose, tokenHash)
+	if err != nil {
+		return nil, err
+	}
+	r.markUsed(func(t *models.UserToken) bool { return t.TokenHash == tokenHash })
+	return token, nil
+}
+
+func (r *fakeCredentials) InvalidateTokens(ctx context.Context, userID, purpose string) error {
+	r.markUsed(func(t *models.UserToken) bool { return t.UserID == userID && t.Purpose == purpose })
+	return nil
+}
+
+func (r *fakeCredentials) markUsed(match func(*models.UserToken) bool) {
+	now := r.now()
+	for _, token := range r.tokens {
+		if token.UsedAt == nil && match(token) {
+			token.UsedAt = &now
+		}
+	}
+}
+
+func (r *fakeCredentials) AddPasswordHistory(ctx context.Context, userID, passwordHash string) error {
+	return nil
+}
+
+func (r *fakeCredentials) RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error) {
+	return nil, nil
+}
+
+// fakeMailer captures sent messages
+type fakeMailer struct {
+	sent []mail.Message
+}
+
+func (m *fakeMailer) Send(ctx context.Context, msg mail.Message) error {
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// linkToken returns the token from the link in a mailed message
+func linkToken(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	_, rest, found := strings.Cut(msg.Body, "token=")
+	require.True(t, found, "no link in %q", msg.Body)
+	return strings.Fields(rest)[0]
+}
+
+type credentialFixture struct {
+	service *CredentialService
+	users   *fakeUsers
+	creds   *fakeCredentials
+	mailer  *fakeMailer
+	clock   *time.Time
+}
+
+func newCredentialFixture(t *testing.T) *credentialFixture {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("Original9Pass"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	clock := time.Now().Truncate(time.Second)
+	now := func() time.Time { return clock }
+	users := newFakeUsers(&models.User{
+		ID: "user-1", Username: "analyst", Email: "analyst@example.com", PasswordHash: string(hash), Active: true,
+	})
+	users.now = now
+	creds := &fakeCredentials{now: now}
+	mailer := &fakeMailer{}
+
+	s := NewCredentialService(users, creds, mailer,
+		NewPasswordValidator(valueobjects.DefaultPasswordPolicy(), nil, 1, nil),
+		CredentialConfig{BaseURL: "https://atlas.example.com", ResetTokenTTL: time.Hour, VerificationTokenTTL: 24 * time.Hour},
+		nil,
+	)
+	s.now = now
+	// Deliver reset links before RequestPasswordReset returns
+	s.background = func(work func()) { work() }
+	return &credentialFixture{service: s, users: users, creds: creds, mailer: mailer, clock: &clock}
+}
+
+func (f *credentialFixture) requestReset(t *testing.T) string {
+	t.Helper()
+	require.NoError(t, f.service.RequestPasswordReset(context.Background(), "analyst@example.com", "203.0.113.7"))
+	require.NotEmpty(t, f.mailer.sent)
+	return linkToken(t, f.mailer.sent[len(f.mailer.sent)-1])
+}
+
+func TestCredentialService_ResetTokenIsSingleUse(t *testing.T) {
+	f := newCredentialFixture(t)
+	token := f.requestReset(t)
+
+	require.NoError(t, f.service.ResetPassword(context.Background(), token, "Replaced7Secret"))
+	assert.ErrorIs(t, f.service.ResetPassword(context.Background(), token, "Another8Secret"), ErrInvalidToken)
+
+	user, err := f.users.GetByID("user-1")
+	require.NoError(t, err)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte("Replaced7Secret")))
+}
+
+func TestCredentialService_RejectedPasswordKeepsToken(t *testing.T) {
+	f := newCredentialFixture(t)
+	token := f.requestReset(t)
+
+	var violation *valueobjects.PolicyViolationError
+	assert.ErrorAs(t, f.service.ResetPassword(context.Background(), token, "short"), &violation)
+	assert.ErrorIs(t, f.service.ResetPassword(context.Background(), token, "Original9Pass"), ErrPasswordReused)
+	assert.NoError(t, f.service.ResetPassword(context.Background(), token, "Replaced7Secret"))
+}
+
+func TestCredentialService_ResetTokenExpires(t *testing.T) {
+	f := newCredentialFixture(t)
+	token := f.requestReset(t)
+
+	*f.clock = f.clock.Add(time.Hour)
+	assert.ErrorIs(t, f.service.ResetPassword(context.Background(), token, "Replaced7Secret"), ErrInvalidToken)
+}
+
+func TestCredentialService_OnlyLatestResetTokenWorks(t *testing.T) {
+	f := newCredentialFixture(t)
+	first := f.requestReset(t)
+	second := f.requestReset(t)
+
+	assert.ErrorIs(t, f.service.ResetPassword(context.Background(), first, "Replaced7Secret"), ErrInvalidToken)
+	assert.NoError(t, f.service.ResetPassword(context.Background(), second, "Replaced7Secret"))
+}
+
+func TestCredentialService_StoresOnlyTokenHashes(t *testing.T) {
+	f := newCredentialFixture(t)
+	token := f.requestReset(t)
+
+	require.Len(t, f.creds.tokens, 1)
+	stored := f.creds.tokens[0]
+	sum := sha256.Sum256([]byte(token))
+	assert.Equal(t, hex.EncodeToString(sum[:]), stored.TokenHash)
+	assert.NotContains(t, stored.TokenHash, token)
+	assert.Equal(t, f.clock.Add(time.Hour), stored.ExpiresAt)
+	assert.Equal(t, "203.0.113.7", stored.RequestedIP)
+}
+
+func TestCredentialService_ResetRequestDoesNotRevealAccounts(t *testing.T) {
+	f := newCredentialFixture(t)
+
+	unknownErr := f.service.RequestPasswordReset(context.Background(), "nobody@example.com", "203.0.113.7")
+	knownErr := f.service.RequestPasswordReset(context.Background(), "analyst@example.com", "203.0.113.7")
+
+	assert.NoError(t, unknownErr)
+	assert.NoError(t, knownErr)
+	require.Len(t, f.mailer.sent, 1)
+	assert.Equal(t, "analyst@example.com", f.mailer.sent[0].To)
+	assert.Len(t, f.creds.tokens, 1)
+}
+
+func TestCredentialService_InactiveAccountsCannotReset(t *testing.T) {
+	f := newCredentialFixture(t)
+	token := f.requestReset(t)
+	f.users.users["user-1"].Active = false
+
+	assert.NoError(t, f.service.RequestPasswordReset(context.Background(), "analyst@example.com", "203.0.113.7"))
+	assert.Len(t, f.mailer.sent, 1, "no link is mailed to a deactivated account")
+	assert.ErrorIs(t, f.service.ResetPassword(context.Background(), token, "Replaced7Secret"), ErrInvalidToken)
+}
+
+func TestCredentialService_ResetLinkIsMailedInBackground(t *testing.T) {
+	f := newCredentialFixture(t)
+	var pending []func()
+	f.service.background = func(work func()) { pending = append(pending, work) }
+	ctx, cancel := context.WithCancel(context.Background())
+
+	require.NoError(t, f.service.RequestPasswordReset(ctx, "analyst@example.com", "203.0.113.7"))
+	assert.Empty(t, f.mailer.sent)
+
+	// The request has ended by the time the link goes out
+	cancel()
+	require.Len(t, pending, 1)
+	pending[0]()
+	require.Len(t, f.mailer.sent, 1)
+	assert.NoError(t, f.service.ResetPassword(context.Background(), linkToken(t, f.mailer.sent[0]), "Replaced7Secret"))
+}
+
+func TestCredentialService_ResetRevokesRefreshSessions(t *testing.T) {
+	f := newCredentialFixture(t)
+	auth := NewAuthService(f.users, "test-jwt-secret")
+
+	// A session started an hour before the reset
+	claims := jwt.MapClaims{
+		"user_id": "user-1",
+		"type":    "refresh",
+		"iat":     f.clock.Add(-time.Hour).Unix(),
+		"exp":     f.clock.Add(24 * time.Hour).Unix(),
+	}
+	stolen, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-jwt-secret"))
+	require.NoError(t, err)
+
+	token := f.requestReset(t)
+	require.NoError(t, f.service.ResetPassword(context.Background(), token, "Replaced7Secret"))
+
+	_, _, err = auth.RefreshTokens(stolen)
+	assert.EqualError(t, err, "token has been revoked")
+
+	// Sessions started after the reset keep working
+	fresh, err := auth.generateRefreshToken("user-1")
+	require.NoError(t, err)
+	_, _, err = auth.RefreshTokens(fresh)
+	assert.NoError(t, err)
+}
+
+func TestAuthService_RefreshRefusesExpiredPassword(t *testing.T) {
+	f := newCredentialFixture(t)
+	changedAt := f.clock.Add(-48 * time.Hour)
+	f.users.users["user-1"].PasswordChangedAt = &changedAt
+
+	policy := valueobjects.DefaultPasswordPolicy()
+	policy.MaxAge = 24 * time.Hour
+	auth := NewAuthService(f.users, "test-jwt-secret").
+		WithPasswordValidator(NewPasswordValidator(policy, nil, 1, nil))
+
+	refresh, err := auth.generateRefreshToken("user-1")
+	require.NoError(t, err)
+
+	_, _, err = auth.RefreshTokens(refresh)
+	var expiredErr *PasswordExpiredError
+	require.ErrorAs(t, err, &expiredErr)
+	assert.Equal(t, "user-1", expiredErr.UserID)
+
+	policy.MaxAge = 72 * time.Hour
+	auth.WithPasswordValidator(NewPasswordValidator(policy, nil, 1, nil))
+	_, _, err = auth.RefreshTokens(refresh)
+	assert.NoError(t, err, "passwords within the maximum age keep refreshing")
+}
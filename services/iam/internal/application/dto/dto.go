@@ -61,3 +61,24 @@ func UserFromAggregate(u *aggregates.UserAggregate) UserDTO {
 		UpdatedAt:   u.UpdatedAt(),
 	}
 }
+
+// BatchOperationResponse summarises a bulk operation with one error per failed row
+type BatchOperationResponse struct {
+	Total      int          `json:"total"`
+	Successful int          `json:"successful"`
+	Failed     int          `json:"failed"`
+	Created    int          `json:"created"`
+	Updated    int          `json:"updated"`
+	Unchanged  int          `json:"unchanged"`
+	Invited    int          `json:"invited"`
+	DryRun     bool         `json:"dry_run"`
+	Errors     []BatchError `json:"errors,omitempty"`
+}
+
+// BatchError describes why a row failed. ID is the row's line number in the
+// import file.
+type BatchError struct {
+	ID      string `json:"id"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
@@ -0,0 +1,459 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/mail"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var (
+	ErrElevationNotFound   = errors.New("elevation not found")
+	ErrElevationOpen       = errors.New("an elevation for this role is already pending or active")
+	ErrElevationNotPending = errors.New("elevation is no longer pending")
+	ErrElevationClosed     = errors.New("elevation has already ended")
+	ErrRoleNotElevatable   = errors.New("role has no elevation policy")
+	ErrRoleAlreadyHeld     = errors.New("user already holds the role")
+)
+
+// ElevationRequest asks for temporary use of a privileged role. Duration is
+// a Go duration string such as "30m" or "2h".
+type ElevationRequest struct {
+	RoleID      string `json:"role_id" binding:"required"`
+	Reason      string `json:"reason" binding:"required"`
+	Duration    string `json:"duration" binding:"required"`
+	IncidentRef string `json:"incident_ref"`
+	BreakGlass  bool   `json:"break_glass"`
+}
+
+// ElevationPolicyRequest sets how long a role may be elevated into
+type ElevationPolicyRequest struct {
+	MaxDuration string `json:"max_duration" binding:"required"`
+}
+
+// BreakGlassAccountRequest designates a user for emergency access
+type BreakGlassAccountRequest struct {
+	Reason string `json:"reason"`
+}
+
+// ElevationSummary aggregates a set of elevations for compliance review
+type ElevationSummary struct {
+	Total      int            `json:"total"`
+	BreakGlass int            `json:"break_glass"`
+	ByStatus   map[string]int `json:"by_status"`
+	ByRole     map[string]int `json:"by_role"`
+}
+
+// ElevationReport is the compliance view of elevations in a period
+type ElevationReport struct {
+	Elevations []*models.Elevation `json:"elevations"`
+	Summary    ElevationSummary    `json:"summary"`
+}
+
+// ElevationService grants privileged roles just in time. Only roles with an
+// elevation policy can be requested, for no longer than the policy allows.
+// A request waits for an admin other than the requester; designated
+// break-glass accounts skip approval, at the price of a high-severity audit
+// record and an alert to every admin. Either way the role is granted as an
+// expiring assignment, so the role expiry sweep revokes it.
+type ElevationService struct {
+	userRepo   repository.UserRepository
+	roleRepo   repository.RoleRepository
+	elevations repository.ElevationRepository
+	mailer     mail.Sender
+	logger     *zap.Logger
+}
+
+func NewElevationService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	elevations repository.ElevationRepository,
+	mailer mail.Sender,
+	logger *zap.Logger,
+) *ElevationService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &ElevationService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		elevations: elevations,
+		mailer:     mailer,
+		logger:     logger,
+	}
+}
+
+// Request files an elevation for actorID, or activates it at once when
+// break-glass is asked for by a designated account
+func (s *ElevationService) Request(ctx context.Context, actorID, ipAddress string, req ElevationRequest) (*models.Elevation, error) {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("%w: reason is required", ErrInvalidInput)
+	}
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil || duration < time.Minute {
+		return nil, fmt.Errorf("%w: duration must be at least 1m", ErrInvalidInput)
+	}
+
+	role, err := s.roleRepo.GetByID(req.RoleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	policy, err := s.elevations.GetPolicy(ctx, role.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrElevationPolicyNotFound) {
+			return nil, ErrRoleNotElevatable
+		}
+		return nil, err
+	}
+	if duration > policy.MaxDuration() {
+		return nil, fmt.Errorf("%w: %s may be elevated for at most %s", ErrInvalidInput, role.Name, policy.MaxDuration())
+	}
+	if actor.HasRole(role.Name) {
+		return nil, ErrRoleAlreadyHeld
+	}
+
+	elevation := &models.Elevation{
+		UserID:       actor.ID,
+		RoleID:       role.ID,
+		Reason:       reason,
+		IncidentRef:  strings.TrimSpace(req.IncidentRef),
+		DurationSecs: int(duration / time.Second),
+		BreakGlass:   req.BreakGlass,
+	}
+
+	if !req.BreakGlass {
+		if err := s.elevations.Create(ctx, elevation); err != nil {
+			return nil, mapElevationError(err)
+		}
+		s.logger.Info("Elevation requested",
+			zap.String("elevation_id", elevation.ID),
+			zap.String("user_id", actor.ID),
+			zap.String("role", role.Name),
+			zap.Duration("duration", duration),
+		)
+		return elevation, nil
+	}
+
+	designated, err := s.elevations.IsBreakGlassAccount(ctx, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !designated {
+		return nil, fmt.Errorf("%w: account is not designated for break-glass access", ErrForbidden)
+	}
+	if err := s.elevations.ActivateBreakGlass(ctx, elevation, ipAddress); err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Warn("Break-glass elevation activated",
+		zap.String("elevation_id", elevation.ID),
+		zap.String("user_id", actor.ID),
+		zap.String("role", role.Name),
+		zap.String("incident_ref", elevation.IncidentRef),
+		zap.Timep("expires_at", elevation.ExpiresAt),
+	)
+	s.alertAdmins(ctx, elevation)
+	return elevation, nil
+}
+
+// Mine lists the elevations requested by actorID
+func (s *ElevationService) Mine(ctx context.Context, actorID string, limit int) ([]*models.Elevation, error) {
+	return s.elevations.List(ctx, repository.ElevationFilter{UserID: actorID, Limit: limit})
+}
+
+// List returns elevations matching filter (admin only)
+func (s *ElevationService) List(ctx context.Context, actorID string, filter repository.ElevationFilter) ([]*models.Elevation, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	if err := validateElevationFilter(filter); err != nil {
+		return nil, err
+	}
+	return s.elevations.List(ctx, filter)
+}
+
+// Approve activates a pending elevation. The approver must be an admin
+// other than the requester.
+func (s *ElevationService) Approve(ctx context.Context, actorID, id, note string) (*models.Elevation, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	elevation, err := s.elevations.Get(ctx, id)
+	if err != nil {
+		return nil, mapElevationError(err)
+	}
+	if elevation.UserID == actorID {
+		return nil, fmt.Errorf("%w: an elevation cannot be approved by its requester", ErrSelfApproval)
+	}
+
+	approved, err := s.elevations.Approve(ctx, id, actorID, strings.TrimSpace(note))
+	if err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Info("Elevation approved",
+		zap.String("elevation_id", id),
+		zap.String("user_id", approved.UserID),
+		zap.String("role", approved.RoleName),
+		zap.String("approved_by", actorID),
+		zap.Timep("expires_at", approved.ExpiresAt),
+	)
+	return approved, nil
+}
+
+// Reject closes a pending elevation without granting the role
+func (s *ElevationService) Reject(ctx context.Context, actorID, id, note string) (*models.Elevation, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.elevations.Reject(ctx, id, actorID, strings.TrimSpace(note))
+	if err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Info("Elevation rejected",
+		zap.String("elevation_id", id),
+		zap.String("rejected_by", actorID),
+	)
+	return rejected, nil
+}
+
+// End withdraws or ends an elevation early. The elevated user and admins
+// may end it.
+func (s *ElevationService) End(ctx context.Context, actorID, id string) (*models.Elevation, error) {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return nil, err
+	}
+	elevation, err := s.elevations.Get(ctx, id)
+	if err != nil {
+		return nil, mapElevationError(err)
+	}
+	if elevation.UserID != actor.ID && !actor.IsAdmin() {
+		return nil, ErrForbidden
+	}
+
+	ended, err := s.elevations.End(ctx, id, actorID)
+	if err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Info("Elevation ended",
+		zap.String("elevation_id", id),
+		zap.String("user_id", ended.UserID),
+		zap.String("role", ended.RoleName),
+		zap.String("ended_by", actorID),
+	)
+	return ended, nil
+}
+
+// Report returns the elevations in a period with totals by status and role
+// (admin only)
+func (s *ElevationService) Report(ctx context.Context, actorID string, filter repository.ElevationFilter) (*ElevationReport, error) {
+	elevations, err := s.List(ctx, actorID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &ElevationReport{
+		Elevations: elevations,
+		Summary:    SummarizeElevations(elevations),
+	}, nil
+}
+
+// Policies lists the roles eligible for elevation
+func (s *ElevationService) Policies(ctx context.Context) ([]*models.ElevationPolicy, error) {
+	return s.elevations.ListPolicies(ctx)
+}
+
+// SavePolicy makes a role eligible for elevation (admin only)
+func (s *ElevationService) SavePolicy(ctx context.Context, actorID, roleID string, req ElevationPolicyRequest) (*models.ElevationPolicy, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	maxDuration, err := time.ParseDuration(req.MaxDuration)
+	if err != nil || maxDuration < time.Minute || maxDuration > 24*time.Hour {
+		return nil, fmt.Errorf("%w: max_duration must be between 1m and 24h", ErrInvalidInput)
+	}
+
+	policy := &models.ElevationPolicy{
+		RoleID:             roleID,
+		MaxDurationMinutes: int(maxDuration / time.Minute),
+		UpdatedBy:          actorID,
+	}
+	if err := s.elevations.SavePolicy(ctx, policy); err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Info("Elevation policy saved",
+		zap.String("role", policy.RoleName),
+		zap.Duration("max_duration", policy.MaxDuration()),
+		zap.String("updated_by", actorID),
+	)
+	return policy, nil
+}
+
+// DeletePolicy stops a role from being elevated into (admin only). Active
+// elevations run until they expire.
+func (s *ElevationService) DeletePolicy(ctx context.Context, actorID, roleID string) error {
+	if err := s.requireAdmin(actorID); err != nil {
+		return err
+	}
+	return mapElevationError(s.elevations.DeletePolicy(ctx, roleID))
+}
+
+// BreakGlassAccounts lists the accounts designated for emergency access
+func (s *ElevationService) BreakGlassAccounts(ctx context.Context) ([]*models.BreakGlassAccount, error) {
+	return s.elevations.ListBreakGlassAccounts(ctx)
+}
+
+// DesignateBreakGlass allows a user to elevate without approval (admin only)
+func (s *ElevationService) DesignateBreakGlass(ctx context.Context, actorID, userID string, req BreakGlassAccountRequest) (*models.BreakGlassAccount, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	account := &models.BreakGlassAccount{
+		UserID:       userID,
+		Reason:       strings.TrimSpace(req.Reason),
+		DesignatedBy: actorID,
+	}
+	if err := s.elevations.AddBreakGlassAccount(ctx, account); err != nil {
+		return nil, mapElevationError(err)
+	}
+	s.logger.Warn("Break-glass account designated",
+		zap.String("user_id", userID),
+		zap.String("designated_by", actorID),
+	)
+	return account, nil
+}
+
+// RevokeBreakGlass withdraws a break-glass designation (admin only)
+func (s *ElevationService) RevokeBreakGlass(ctx context.Context, actorID, userID string) error {
+	if err := s.requireAdmin(actorID); err != nil {
+		return err
+	}
+	return mapElevationError(s.elevations.RemoveBreakGlassAccount(ctx, userID))
+}
+
+// SummarizeElevations counts elevations by status and role
+func SummarizeElevations(elevations []*models.Elevation) ElevationSummary {
+	summary := ElevationSummary{
+		Total:    len(elevations),
+		ByStatus: map[string]int{},
+		ByRole:   map[string]int{},
+	}
+	for _, e := range elevations {
+		if e.BreakGlass {
+			summary.BreakGlass++
+		}
+		summary.ByStatus[e.Status]++
+		summary.ByRole[e.RoleName]++
+	}
+	return summary
+}
+
+// alertAdmins emails every admin about a break-glass activation. The in-app
+// notification is already committed with the grant, so mail is best effort.
+func (s *ElevationService) alertAdmins(ctx context.Context, elevation *models.Elevation) {
+	emails, err := s.elevations.AdminEmails(ctx)
+	if err != nil {
+		s.logger.Error("Failed to load admin emails for break-glass alert", zap.Error(err))
+		return
+	}
+	sort.Strings(emails)
+
+	body := fmt.Sprintf(
+		"Break-glass access was used.\n\nUser: %s\nRole: %s\nReason: %s\nIncident: %s\nExpires: %s\n\nReview the elevation in the IAM elevation report.\n",
+		elevation.Username, elevation.RoleName, elevation.Reason, elevation.IncidentRef,
+		elevation.ExpiresAt.UTC().Format(time.RFC3339),
+	)
+	for _, email := range emails {
+		err := s.mailer.Send(ctx, mail.Message{
+			To:      email,
+			Subject: fmt.Sprintf("[ATLAS] Break-glass elevation to %s by %s", elevation.RoleName, elevation.Username),
+			Body:    body,
+		})
+		if err != nil {
+			s.logger.Error("Failed to send break-glass alert", zap.String("to", email), zap.Error(err))
+		}
+	}
+}
+
+func (s *ElevationService) activeUser(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrForbidden
+	}
+	return user, nil
+}
+
+func (s *ElevationService) requireAdmin(actorID string) error {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.IsAdmin() {
+		return fmt.Errorf("%w: admin role required", ErrForbidden)
+	}
+	return nil
+}
+
+func validateElevationFilter(filter repository.ElevationFilter) error {
+	switch filter.Status {
+	case "", models.ElevationPending, models.ElevationActive, models.ElevationRejected,
+		models.ElevationExpired, models.ElevationEnded:
+	default:
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidInput, filter.Status)
+	}
+	if filter.Since != nil && filter.Until != nil && !filter.Until.After(*filter.Since) {
+		return fmt.Errorf("%w: until must be after since", ErrInvalidInput)
+	}
+	return nil
+}
+
+func mapElevationError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, repository.ErrRoleNotFound):
+		return ErrRoleNotFound
+	case errors.Is(err, repository.ErrElevationNotFound):
+		return ErrElevationNotFound
+	case errors.Is(err, repository.ErrElevationOpen):
+		return ErrElevationOpen
+	case errors.Is(err, repository.ErrElevationNotPending):
+		return ErrElevationNotPending
+	case errors.Is(err, repository.ErrElevationClosed):
+		return ErrElevationClosed
+	case errors.Is(err, repository.ErrRoleAlreadyHeld):
+		return ErrRoleAlreadyHeld
+	case errors.Is(err, repository.ErrElevationPolicyNotFound):
+		return ErrRoleNotElevatable
+	case errors.Is(err, repository.ErrBreakGlassAccountNotFound):
+		return fmt.Errorf("%w: %v", ErrUserNotFound, err)
+	case errors.Is(err, repository.ErrRoleExcluded):
+		return fmt.Errorf("%w: %v", ErrSeparationOfDuties, err)
+	}
+	return err
+}
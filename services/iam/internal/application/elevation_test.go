@@ -0,0 +1,275 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// breakGlassAudit is the audit record written when break-glass is activated
+type breakGlassAudit struct {
+	elevationID string
+	ipAddress   string
+}
+
+// fakeElevations keeps elevations in memory and, like the real repository,
+// grants an activated elevation as an expiring assignment
+type fakeElevations struct {
+	repository.ElevationRepository
+	governance *fakeGovernance
+	users      *fakeUsers
+	policies   map[string]*models.ElevationPolicy
+	breakGlass map[string]bool
+	elevations map[string]*models.Elevation
+	audits     []breakGlassAudit
+	admins     []string
+}
+
+func newFakeElevations(governance *fakeGovernance, users *fakeUsers) *fakeElevations {
+	return &fakeElevations{
+		governance: governance,
+		users:      users,
+		policies:   make(map[string]*models.ElevationPolicy),
+		breakGlass: make(map[string]bool),
+		elevations: make(map[string]*models.Elevation),
+	}
+}
+
+func (r *fakeElevations) GetPolicy(ctx context.Context, roleID string) (*models.ElevationPolicy, error) {
+	policy, ok := r.policies[roleID]
+	if !ok {
+		return nil, repository.ErrElevationPolicyNotFound
+	}
+	return policy, nil
+}
+
+func (r *fakeElevations) IsBreakGlassAccount(ctx context.Context, userID string) (bool, error) {
+	return r.breakGlass[userID], nil
+}
+
+func (r *fakeElevations) Create(ctx context.Context, elevation *models.Elevation) error {
+	elevation.ID = fmt.Sprintf("elevation-%d", len(r.elevations)+1)
+	elevation.Status = models.ElevationPending
+	elevation.RequestedAt = r.governance.now()
+	if err := r.describe(elevation); err != nil {
+		return err
+	}
+	c := *elevation
+	r.elevations[c.ID] = &c
+	return nil
+}
+
+func (r *fakeElevations) ActivateBreakGlass(ctx context.Context, elevation *models.Elevation, ipAddress string) error {
+	if err := r.Create(ctx, elevation); err != nil {
+		return err
+	}
+	activated, err := r.activate(ctx, elevation.ID, elevation.UserID)
+	if err != nil {
+		return err
+	}
+	*elevation = *activated
+	r.audits = append(r.audits, breakGlassAudit{elevationID: elevation.ID, ipAddress: ipAddress})
+	return nil
+}
+
+func (r *fakeElevations) Approve(ctx context.Context, id, decidedBy, note string) (*models.Elevation, error) {
+	elevation, ok := r.elevations[id]
+	if !ok {
+		return nil, repository.ErrElevationNotFound
+	}
+	if elevation.Status != models.ElevationPending {
+		return nil, repository.ErrElevationNotPending
+	}
+	elevation.DecidedBy = decidedBy
+	elevation.DecisionNote = note
+	return r.activate(ctx, id, decidedBy)
+}
+
+func (r *fakeElevations) Get(ctx context.Context, id string) (*models.Elevation, error) {
+	elevation, ok := r.elevations[id]
+	if !ok {
+		return nil, repository.ErrElevationNotFound
+	}
+	c := *elevation
+	return &c, nil
+}
+
+func (r *fakeElevations) AdminEmails(ctx context.Context) ([]string, error) {
+	return r.admins, nil
+}
+
+func (r *fakeElevations) activate(ctx context.Context, id, grantedBy string) (*models.Elevation, error) {
+	elevation := r.elevations[id]
+	now := r.governance.now()
+	expiresAt := now.Add(elevation.Duration())
+	elevation.Status = models.ElevationActive
+	elevation.ActivatedAt = &now
+	elevation.ExpiresAt = &expiresAt
+	if err := r.governance.GrantRole(ctx, elevation.UserID, elevation.RoleID, grantedBy, &expiresAt); err != nil {
+		return nil, err
+	}
+	c := *elevation
+	return &c, nil
+}
+
+func (r *fakeElevations) describe(elevation *models.Elevation) error {
+	user, err := r.users.GetByID(elevation.UserID)
+	if err != nil {
+		return err
+	}
+	role, err := r.governance.roles.GetByID(elevation.RoleID)
+	if err != nil {
+		return err
+	}
+	elevation.Username = user.Username
+	elevation.RoleName = role.Name
+	return nil
+}
+
+type elevationFixture struct {
+	service    *ElevationService
+	governance *RoleGovernanceService
+	elevations *fakeElevations
+	mailer     *fakeMailer
+	clock      *time.Time
+}
+
+func newElevationFixture(t *testing.T) *elevationFixture {
+	t.Helper()
+	clock := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := func() time.Time { return clock }
+
+	users := newFakeUsers(
+		&models.User{ID: "admin-1", Username: "alice", Roles: []string{"admin"}, Active: true},
+		&models.User{ID: "admin-2", Username: "bob", Roles: []string{"admin"}, Active: true},
+		&models.User{ID: "oncall-1", Username: "carol", Roles: []string{"analyst"}, Active: true},
+	)
+	roles := newFakeRoles(&models.Role{ID: "role-operator", Name: "operator"})
+	governance := newFakeGovernance(roles, now)
+
+	elevations := newFakeElevations(governance, users)
+	elevations.policies["role-operator"] = &models.ElevationPolicy{
+		RoleID: "role-operator", RoleName: "operator", MaxDurationMinutes: 120,
+	}
+	elevations.admins = []string{"bob@example.com", "alice@example.com"}
+
+	mailer := &fakeMailer{}
+	governanceService := NewRoleGovernanceService(users, roles, governance, nil)
+	governanceService.now = now
+
+	return &elevationFixture{
+		service:    NewElevationService(users, roles, elevations, mailer, nil),
+		governance: governanceService,
+		elevations: elevations,
+		mailer:     mailer,
+		clock:      &clock,
+	}
+}
+
+func TestElevationService_RejectsSelfApproval(t *testing.T) {
+	ctx := context.Background()
+	f := newElevationFixture(t)
+
+	elevation, err := f.service.Request(ctx, "admin-1", "", ElevationRequest{
+		RoleID: "role-operator", Reason: "rotate keys", Duration: "30m",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationPending, elevation.Status)
+
+	_, err = f.service.Approve(ctx, "admin-1", elevation.ID, "")
+	assert.ErrorIs(t, err, ErrSelfApproval)
+	assert.Empty(t, f.elevations.governance.assignments["admin-1"], "the role must not be granted")
+
+	approved, err := f.service.Approve(ctx, "admin-2", elevation.ID, "ok")
+	require.NoError(t, err)
+	assert.Equal(t, models.ElevationActive, approved.Status)
+	assert.Equal(t, "admin-2", approved.DecidedBy)
+}
+
+func TestElevationService_ExpiresAutomatically(t *testing.T) {
+	ctx := context.Background()
+	f := newElevationFixture(t)
+
+	_, err := f.service.Request(ctx, "oncall-1", "", ElevationRequest{
+		RoleID: "role-operator", Reason: "deploy", Duration: "3h",
+	})
+	assert.ErrorIs(t, err, ErrInvalidInput, "longer than the policy allows")
+
+	elevation, err := f.service.Request(ctx, "oncall-1", "", ElevationRequest{
+		RoleID: "role-operator", Reason: "deploy", Duration: "45m",
+	})
+	require.NoError(t, err)
+
+	*f.clock = f.clock.Add(10 * time.Minute)
+	approved, err := f.service.Approve(ctx, "admin-1", elevation.ID, "")
+	require.NoError(t, err)
+	require.NotNil(t, approved.ExpiresAt)
+	assert.Equal(t, f.clock.Add(45*time.Minute), *approved.ExpiresAt, "runs from approval, not request")
+
+	roles, err := f.governance.UserRoles(ctx, "oncall-1", "oncall-1")
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, approved.ExpiresAt, roles[0].ExpiresAt, "granted as an expiring assignment")
+
+	*f.clock = f.clock.Add(44 * time.Minute)
+	n, err := f.governance.ExpireAssignments(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n)
+
+	*f.clock = f.clock.Add(time.Minute)
+	n, err = f.governance.ExpireAssignments(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	roles, err = f.governance.UserRoles(ctx, "oncall-1", "oncall-1")
+	require.NoError(t, err)
+	assert.Empty(t, roles)
+}
+
+func TestElevationService_BreakGlass(t *testing.T) {
+	ctx := context.Background()
+	f := newElevationFixture(t)
+	req := ElevationRequest{
+		RoleID: "role-operator", Reason: "outage", Duration: "1h", IncidentRef: "INC-42", BreakGlass: true,
+	}
+
+	t.Run("refused for accounts that are not designated", func(t *testing.T) {
+		_, err := f.service.Request(ctx, "oncall-1", "10.0.0.7", req)
+		assert.ErrorIs(t, err, ErrForbidden)
+		assert.Empty(t, f.elevations.audits)
+		assert.Empty(t, f.mailer.sent)
+	})
+
+	t.Run("activates at once, audits and alerts every admin", func(t *testing.T) {
+		f.elevations.breakGlass["oncall-1"] = true
+
+		elevation, err := f.service.Request(ctx, "oncall-1", "10.0.0.7", req)
+		require.NoError(t, err)
+		assert.Equal(t, models.ElevationActive, elevation.Status)
+		assert.True(t, elevation.BreakGlass)
+		assert.Empty(t, elevation.DecidedBy, "no approval is involved")
+
+		assert.Equal(t, []breakGlassAudit{{elevationID: elevation.ID, ipAddress: "10.0.0.7"}}, f.elevations.audits)
+
+		require.Len(t, f.mailer.sent, 2)
+		assert.Equal(t, "alice@example.com", f.mailer.sent[0].To)
+		assert.Equal(t, "bob@example.com", f.mailer.sent[1].To)
+		for _, msg := range f.mailer.sent {
+			assert.Equal(t, "[ATLAS] Break-glass elevation to operator by carol", msg.Subject)
+			assert.Contains(t, msg.Body, "Reason: outage")
+			assert.Contains(t, msg.Body, "Incident: INC-42")
+		}
+
+		roles, err := f.governance.UserRoles(ctx, "oncall-1", "oncall-1")
+		require.NoError(t, err)
+		require.Len(t, roles, 1)
+		assert.Equal(t, elevation.ExpiresAt, roles[0].ExpiresAt, "break-glass expires like any elevation")
+	})
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// fakeUsers keeps users by ID. Password changes are stamped with now when
+// it is set.
+type fakeUsers struct {
+	repository.UserRepository
+	users map[string]*models.User
+	now   func() time.Time
+}
+
+func newFakeUsers(users ...*models.User) *fakeUsers {
+	r := &fakeUsers{users: make(map[string]*models.User), now: time.Now}
+	for _, user := range users {
+		r.users[user.ID] = user
+	}
+	return r
+}
+
+func (r *fakeUsers) GetByID(id string) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, repository.ErrUserNotFound
+	}
+	c := *user
+	return &c, nil
+}
+
+func (r *fakeUsers) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if strings.EqualFold(user.Email, email) {
+			c := *user
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrUserNotFound
+}
+
+func (r *fakeUsers) UpdatePassword(id, passwordHash, reason string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	changedAt := r.now()
+	user.PasswordHash = passwordHash
+	user.PasswordChangedAt = &changedAt
+	return nil
+}
+
+func (r *fakeUsers) MarkVerified(id string) error {
+	user, ok := r.users[id]
+	if !ok {
+		return repository.ErrUserNotFound
+	}
+	user.Verified = true
+	return nil
+}
+
+// fakeRoles keeps roles by ID
+type fakeRoles struct {
+	repository.RoleRepository
+	roles map[string]*models.Role
+}
+
+func newFakeRoles(roles ...*models.Role) *fakeRoles {
+	r := &fakeRoles{roles: make(map[string]*models.Role)}
+	for _, role := range roles {
+		r.roles[role.ID] = role
+	}
+	return r
+}
+
+func (r *fakeRoles) GetByID(id string) (*models.Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, repository.ErrRoleNotFound
+	}
+	c := *role
+	return &c, nil
+}
+
+func (r *fakeRoles) GetByName(name string) (*models.Role, error) {
+	for _, role := range r.roles {
+		if role.Name == name {
+			c := *role
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrRoleNotFound
+}
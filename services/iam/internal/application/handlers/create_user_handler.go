@@ -13,12 +13,13 @@ import (
 
 // CreateUserHandler handles the CreateUserCommand
 type CreateUserHandler struct {
-	userRepo  repositories.UserRepository
-	publisher EventPublisher
+	userRepo repositories.UserRepository
 }
 
-func NewCreateUserHandler(repo repositories.UserRepository, pub EventPublisher) *CreateUserHandler {
-	return &CreateUserHandler{userRepo: repo, publisher: pub}
+// NewCreateUserHandler builds the handler; the repository writes the
+// UserCreated event to the outbox together with the new row
+func NewCreateUserHandler(repo repositories.UserRepository) *CreateUserHandler {
+	return &CreateUserHandler{userRepo: repo}
 }
 
 func (h *CreateUserHandler) Handle(ctx context.Context, cmd commands.CreateUserCommand) (*dto.UserDTO, error) {
@@ -29,32 +30,26 @@ func (h *CreateUserHandler) Handle(ctx context.Context, cmd commands.CreateUserC
 
 	existing, _ := h.userRepo.FindByUsername(ctx, cmd.Username)
 	if existing != nil {
-		return nil, fmt.Errorf("username already exists: %s", cmd.Username)
+		return nil, fmt.Errorf("%w: username %s", repositories.ErrUserAlreadyExists, cmd.Username)
 	}
 
 	existingEmail, _ := h.userRepo.FindByEmail(ctx, email)
 	if existingEmail != nil {
-		return nil, fmt.Errorf("email already registered: %s", cmd.Email)
+		return nil, fmt.Errorf("%w: email %s", repositories.ErrUserAlreadyExists, cmd.Email)
 	}
 
-	hashedPassword, err := valueobjects.NewHashedPassword(cmd.Password)
+	hashedPassword, err := valueobjects.NewHashedPasswordFor(cmd.Password, cmd.Username)
 	if err != nil {
 		return nil, fmt.Errorf("password validation failed: %w", err)
 	}
 
 	user := aggregates.NewUserAggregate(cmd.Username, email, hashedPassword)
+	user.UpdateProfile(cmd.FirstName, cmd.LastName)
 
 	if err := h.userRepo.Save(ctx, user); err != nil {
 		return nil, fmt.Errorf("failed to save user: %w", err)
 	}
 
-	for _, event := range user.DomainEvents() {
-		if err := h.publisher.Publish(ctx, event); err != nil {
-			_ = err // Log but don't fail - eventual consistency
-		}
-	}
-	user.ClearEvents()
-
 	result := dto.UserFromAggregate(user)
 	return &result, nil
 }
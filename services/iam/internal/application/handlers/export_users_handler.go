@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"atlas-core-api/services/iam/internal/application/dto"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+)
+
+// exportPageSize is how many users are loaded per repository call
+const exportPageSize = 500
+
+// ExportUsersHandler returns every active user with roles, last login and
+// MFA state, in the same field set the import accepts
+type ExportUsersHandler struct {
+	userRepo repositories.UserRepository
+}
+
+func NewExportUsersHandler(repo repositories.UserRepository) *ExportUsersHandler {
+	return &ExportUsersHandler{userRepo: repo}
+}
+
+func (h *ExportUsersHandler) Handle(ctx context.Context) ([]dto.UserDTO, error) {
+	var result []dto.UserDTO
+	for offset := 0; ; offset += exportPageSize {
+		users, total, err := h.userRepo.List(ctx, offset, exportPageSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export users: %w", err)
+		}
+		for _, u := range users {
+			result = append(result, dto.UserFromAggregate(u))
+		}
+		if len(users) < exportPageSize || offset+len(users) >= total {
+			return result, nil
+		}
+	}
+}
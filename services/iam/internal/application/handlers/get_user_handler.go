@@ -22,7 +22,8 @@ func NewGetUserHandler(repo repositories.UserRepository) *GetUserHandler {
 func (h *GetUserHandler) HandleByID(ctx context.Context, q queries.GetUserByIDQuery) (*dto.UserDTO, error) {
 	userID, err := valueobjects.UserIDFromString(q.UserID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid user ID: %w", err)
+		// A malformed ID cannot match any user
+		return nil, fmt.Errorf("%w: invalid user ID", repositories.ErrUserNotFound)
 	}
 
 	user, err := h.userRepo.FindByID(ctx, userID)
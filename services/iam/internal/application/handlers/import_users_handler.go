@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"atlas-core-api/services/iam/internal/application/commands"
+	"atlas-core-api/services/iam/internal/application/dto"
+	"atlas-core-api/services/iam/internal/domain/aggregates"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+// MaxImportRows bounds a single import request
+const MaxImportRows = 1000
+
+var ErrTooManyRows = errors.New("too many rows in import")
+
+// Error codes reported per row in an import result
+const (
+	ImportErrorInvalidRow       = "invalid_row"
+	ImportErrorDuplicateRow     = "duplicate_row"
+	ImportErrorUnknownRole      = "unknown_role"
+	ImportErrorApprovalRequired = "approval_required"
+	ImportErrorRoleConflict     = "role_conflict"
+	ImportErrorUsernameConflict = "username_conflict"
+	ImportErrorDeactivated      = "account_deactivated"
+	ImportErrorPasswordPolicy   = "password_policy"
+	ImportErrorConflict         = "conflict"
+	ImportErrorInviteFailed     = "invite_failed"
+	ImportErrorInternal         = "internal_error"
+)
+
+// unusablePasswordHash never matches a bcrypt comparison; imported users
+// without a password must set one through their invite link
+const unusablePasswordHash = "!"
+
+// PasswordChecker validates a new password, including any breach screening
+type PasswordChecker interface {
+	Validate(ctx context.Context, password, username string) error
+}
+
+// UserInviter sends a newly imported user a link to choose their password
+type UserInviter interface {
+	SendPasswordSetup(ctx context.Context, userID, username, email string) error
+}
+
+// RoleConstraints rejects role sets that break separation-of-duties rules
+type RoleConstraints interface {
+	CheckRoles(ctx context.Context, roles []string) error
+}
+
+type importOutcome int
+
+const (
+	importCreated importOutcome = iota
+	importUpdated
+	importUnchanged
+)
+
+// importRowError is a row-level failure reported in the batch result rather
+// than aborting the import
+type importRowError struct {
+	Code    string
+	Message string
+}
+
+func (e *importRowError) Error() string { return e.Message }
+
+func rowError(code, format string, args ...interface{}) error {
+	return &importRowError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// ImportUsersHandler upserts users by email from a bulk import file. Each row
+// is written in its own transaction, so a failed row does not roll back the
+// others and re-running the same file is a no-op.
+type ImportUsersHandler struct {
+	userRepo  repositories.UserRepository
+	roleRepo  repositories.RoleRepository
+	passwords PasswordChecker
+	inviter   UserInviter
+	roleRules RoleConstraints
+}
+
+func NewImportUsersHandler(
+	userRepo repositories.UserRepository,
+	roleRepo repositories.RoleRepository,
+	passwords PasswordChecker,
+	inviter UserInviter,
+) *ImportUsersHandler {
+	return &ImportUsersHandler{userRepo: userRepo, roleRepo: roleRepo, passwords: passwords, inviter: inviter}
+}
+
+// WithRoleConstraints applies separation-of-duties rules to imported roles
+func (h *ImportUsersHandler) WithRoleConstraints(rules RoleConstraints) *ImportUsersHandler {
+	h.roleRules = rules
+	return h
+}
+
+func (h *ImportUsersHandler) Handle(ctx context.Context, cmd commands.ImportUsersCommand) (*dto.BatchOperationResponse, error) {
+	if len(cmd.Rows) > MaxImportRows {
+		return nil, fmt.Errorf("%w: %d rows, limit is %d", ErrTooManyRows, len(cmd.Rows), MaxImportRows)
+	}
+
+	roles, err := h.roleRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load roles: %w", err)
+	}
+	roleIDs := make(map[string]string, len(roles))
+	privileged := make(map[string]bool)
+	for _, role := range roles {
+		roleIDs[role.Name] = role.ID
+		if role.RequiresApproval {
+			privileged[role.Name] = true
+		}
+	}
+
+	result := &dto.BatchOperationResponse{Total: len(cmd.Rows), DryRun: cmd.DryRun}
+	seenEmails := make(map[string]int)
+	seenUsernames := make(map[string]int)
+
+	for _, row := range cmd.Rows {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		outcome, user, err := h.importRow(ctx, row, roleIDs, privileged, seenEmails, seenUsernames, cmd.DryRun)
+		if err != nil {
+			var rowErr *importRowError
+			if !errors.As(err, &rowErr) {
+				rowErr = &importRowError{Code: ImportErrorInternal, Message: err.Error()}
+			}
+			result.Failed++
+			result.Errors = append(result.Errors, dto.BatchError{
+				ID:      strconv.Itoa(row.Line),
+				Error:   rowErr.Code,
+				Message: rowErr.Message,
+			})
+			continue
+		}
+
+		result.Successful++
+		switch outcome {
+		case importCreated:
+			result.Created++
+		case importUpdated:
+			result.Updated++
+		default:
+			result.Unchanged++
+		}
+
+		// New users without a password get a link to choose one. An invite
+		// failure does not undo the row; the user can use forgot-password.
+		if outcome == importCreated && row.Password == "" && h.inviter != nil {
+			if cmd.DryRun {
+				result.Invited++
+				continue
+			}
+			if err := h.inviter.SendPasswordSetup(ctx, user.ID().String(), user.Username(), user.Email().String()); err != nil {
+				result.Errors = append(result.Errors, dto.BatchError{
+					ID:      strconv.Itoa(row.Line),
+					Error:   ImportErrorInviteFailed,
+					Message: fmt.Sprintf("user created but invite could not be sent: %v", err),
+				})
+				continue
+			}
+			result.Invited++
+		}
+	}
+
+	return result, nil
+}
+
+func (h *ImportUsersHandler) importRow(
+	ctx context.Context,
+	row commands.UserImportRow,
+	roleIDs map[string]string,
+	privileged map[string]bool,
+	seenEmails, seenUsernames map[string]int,
+	dryRun bool,
+) (importOutcome, *aggregates.UserAggregate, error) {
+	email, err := valueobjects.NewEmail(row.Email)
+	if err != nil {
+		return 0, nil, rowError(ImportErrorInvalidRow, "invalid email %q", row.Email)
+	}
+	if line, ok := seenEmails[email.String()]; ok {
+		return 0, nil, rowError(ImportErrorDuplicateRow, "email %s already appears on line %d", email, line)
+	}
+	seenEmails[email.String()] = row.Line
+
+	username := strings.TrimSpace(row.Username)
+	if username != "" {
+		key := strings.ToLower(username)
+		if line, ok := seenUsernames[key]; ok {
+			return 0, nil, rowError(ImportErrorDuplicateRow, "username %s already appears on line %d", username, line)
+		}
+		seenUsernames[key] = row.Line
+	}
+
+	roles, err := normalizeImportRoles(row.Roles, roleIDs)
+	if err != nil {
+		return 0, nil, err
+	}
+	if roles != nil && h.roleRules != nil {
+		if err := h.roleRules.CheckRoles(ctx, roles); err != nil {
+			return 0, nil, rowError(ImportErrorRoleConflict, "%v", err)
+		}
+	}
+
+	existing, err := h.userRepo.FindByEmail(ctx, email)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+		return 0, nil, err
+	}
+
+	// Roles that require approval cannot be granted by an import; ones the
+	// user already holds may stay
+	for _, name := range roles {
+		if privileged[name] && (existing == nil || !existing.HasRole(name)) {
+			return 0, nil, rowError(ImportErrorApprovalRequired, "role %s requires an approved grant request", name)
+		}
+	}
+	if existing != nil {
+		return h.updateExisting(ctx, existing, row, username, roles, roleIDs, dryRun)
+	}
+	return h.createNew(ctx, row, username, email, roles, roleIDs, dryRun)
+}
+
+func (h *ImportUsersHandler) updateExisting(
+	ctx context.Context,
+	user *aggregates.UserAggregate,
+	row commands.UserImportRow,
+	username string,
+	roles []string,
+	roleIDs map[string]string,
+	dryRun bool,
+) (importOutcome, *aggregates.UserAggregate, error) {
+	if !user.IsActive() {
+		return 0, nil, rowError(ImportErrorDeactivated, "account for %s is deactivated", user.Email())
+	}
+	if username != "" && !strings.EqualFold(username, user.Username()) {
+		return 0, nil, rowError(ImportErrorUsernameConflict, "email %s belongs to user %s", user.Email(), user.Username())
+	}
+
+	changed := applyImportProfile(user, row)
+	if roles != nil && applyImportRoles(user, roles, roleIDs) {
+		changed = true
+	}
+	if !changed {
+		return importUnchanged, user, nil
+	}
+	if dryRun {
+		return importUpdated, user, nil
+	}
+
+	if err := h.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, repositories.ErrConcurrentModification) {
+			return 0, nil, rowError(ImportErrorConflict, "user %s was modified during the import, retry the row", user.Username())
+		}
+		return 0, nil, err
+	}
+	return importUpdated, user, nil
+}
+
+func (h *ImportUsersHandler) createNew(
+	ctx context.Context,
+	row commands.UserImportRow,
+	username string,
+	email valueobjects.Email,
+	roles []string,
+	roleIDs map[string]string,
+	dryRun bool,
+) (importOutcome, *aggregates.UserAggregate, error) {
+	if len(username) < 3 || len(username) > 50 {
+		return 0, nil, rowError(ImportErrorInvalidRow, "username must be 3 to 50 characters for new users")
+	}
+
+	taken, err := h.userRepo.FindByUsername(ctx, username)
+	if err != nil && !errors.Is(err, repositories.ErrUserNotFound) {
+		return 0, nil, err
+	}
+	if taken != nil {
+		return 0, nil, rowError(ImportErrorUsernameConflict, "username %s belongs to %s", username, taken.Email())
+	}
+
+	password := valueobjects.HashedPasswordFromStore(unusablePasswordHash)
+	if row.Password != "" {
+		if err := h.validatePassword(ctx, row.Password, username); err != nil {
+			return 0, nil, rowError(ImportErrorPasswordPolicy, "%v", err)
+		}
+		if !dryRun {
+			if password, err = valueobjects.NewHashedPasswordFor(row.Password, username); err != nil {
+				return 0, nil, rowError(ImportErrorPasswordPolicy, "%v", err)
+			}
+		}
+	}
+
+	user := aggregates.NewUserAggregate(username, email, password)
+	applyImportProfile(user, row)
+	if roles != nil {
+		applyImportRoles(user, roles, roleIDs)
+	}
+	if dryRun {
+		return importCreated, user, nil
+	}
+
+	if err := h.userRepo.Save(ctx, user); err != nil {
+		if errors.Is(err, repositories.ErrUserAlreadyExists) {
+			return 0, nil, rowError(ImportErrorConflict, "user %s was created concurrently, retry the row", username)
+		}
+		return 0, nil, err
+	}
+	return importCreated, user, nil
+}
+
+func (h *ImportUsersHandler) validatePassword(ctx context.Context, password, username string) error {
+	if h.passwords == nil {
+		return valueobjects.CurrentPasswordPolicy().Validate(password, username)
+	}
+	return h.passwords.Validate(ctx, password, username)
+}
+
+// normalizeImportRoles trims and de-duplicates role names and rejects unknown
+// ones. It returns nil when the row leaves roles unchanged.
+func normalizeImportRoles(names []string, roleIDs map[string]string) ([]string, error) {
+	var roles []string
+	seen := make(map[string]bool)
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" || seen[name] {
+			continue
+		}
+		if _, ok := roleIDs[name]; !ok {
+			return nil, rowError(ImportErrorUnknownRole, "role %q does not exist", name)
+		}
+		seen[name] = true
+		roles = append(roles, name)
+	}
+	return roles, nil
+}
+
+// applyImportProfile copies the non-empty name fields and reports whether
+// anything changed
+func applyImportProfile(user *aggregates.UserAggregate, row commands.UserImportRow) bool {
+	firstName, lastName := user.FirstName(), user.LastName()
+	if v := strings.TrimSpace(row.FirstName); v != "" {
+		firstName = v
+	}
+	if v := strings.TrimSpace(row.LastName); v != "" {
+		lastName = v
+	}
+	if firstName == user.FirstName() && lastName == user.LastName() {
+		return false
+	}
+	user.UpdateProfile(firstName, lastName)
+	return true
+}
+
+// applyImportRoles makes the user's roles exactly match roles and reports
+// whether anything changed
+func applyImportRoles(user *aggregates.UserAggregate, roles []string, roleIDs map[string]string) bool {
+	want := make(map[string]bool, len(roles))
+	changed := false
+	for _, name := range roles {
+		want[name] = true
+		if !user.HasRole(name) {
+			user.AssignRole(name, roleIDs[name])
+			changed = true
+		}
+	}
+
+	current := append([]string(nil), user.Roles()...)
+	for _, name := range current {
+		if !want[name] {
+			user.RevokeRole(name, roleIDs[name])
+			changed = true
+		}
+	}
+	return changed
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"atlas-core-api/services/iam/internal/application/commands"
+	"atlas-core-api/services/iam/internal/domain/aggregates"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+type memoryUserRepo struct {
+	repositories.UserRepository
+	users  map[string]*aggregates.UserAggregate
+	writes int
+}
+
+func (r *memoryUserRepo) FindByEmail(ctx context.Context, email valueobjects.Email) (*aggregates.UserAggregate, error) {
+	if u, ok := r.users[email.String()]; ok {
+		return u, nil
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+func (r *memoryUserRepo) FindByUsername(ctx context.Context, username string) (*aggregates.UserAggregate, error) {
+	for _, u := range r.users {
+		if u.Username() == username {
+			return u, nil
+		}
+	}
+	return nil, repositories.ErrUserNotFound
+}
+
+func (r *memoryUserRepo) Save(ctx context.Context, user *aggregates.UserAggregate) error {
+	r.writes++
+	r.users[user.Email().String()] = user
+	user.ClearEvents()
+	return nil
+}
+
+func (r *memoryUserRepo) Update(ctx context.Context, user *aggregates.UserAggregate) error {
+	r.writes++
+	user.ClearEvents()
+	return nil
+}
+
+type staticRoles struct {
+	repositories.RoleRepository
+}
+
+func (staticRoles) List(ctx context.Context) ([]*repositories.Role, error) {
+	return []*repositories.Role{
+		{ID: "r-viewer", Name: "viewer"},
+		{ID: "r-analyst", Name: "analyst"},
+		{ID: "r-auditor", Name: "auditor"},
+		{ID: "r-admin", Name: "admin", RequiresApproval: true},
+	}, nil
+}
+
+type exclusiveRoles struct{ a, b string }
+
+func (e exclusiveRoles) CheckRoles(ctx context.Context, roles []string) error {
+	held := map[string]bool{}
+	for _, r := range roles {
+		held[r] = true
+	}
+	if held[e.a] && held[e.b] {
+		return errors.New(e.a + " and " + e.b + " are mutually exclusive")
+	}
+	return nil
+}
+
+type recordingInviter struct{ sent []string }
+
+func (i *recordingInviter) SendPasswordSetup(ctx context.Context, userID, username, email string) error {
+	i.sent = append(i.sent, email)
+	return nil
+}
+
+func importRows() []commands.UserImportRow {
+	return []commands.UserImportRow{
+		{Line: 2, Username: "ana", Email: "ana@example.com", FirstName: "Ana", Roles: []string{"analyst"}},
+		{Line: 3, Username: "ben", Email: "BEN@example.com", Password: "Sturdy7Lantern"},
+		{Line: 4, Username: "ana2", Email: "ana@example.com"},
+		{Line: 5, Username: "cy", Email: "cy@example.com", Roles: []string{"root"}},
+		{Line: 6, Username: "dee", Email: "not-an-email"},
+	}
+}
+
+func TestImportUsersHandler_DryRunWritesNothing(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]*aggregates.UserAggregate{}}
+	inviter := &recordingInviter{}
+	h := NewImportUsersHandler(repo, staticRoles{}, nil, inviter)
+
+	result, err := h.Handle(context.Background(), commands.ImportUsersCommand{Rows: importRows(), DryRun: true})
+	require.NoError(t, err)
+
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 5, result.Total)
+	assert.Equal(t, 2, result.Created)
+	assert.Equal(t, 1, result.Invited)
+	assert.Equal(t, 3, result.Failed)
+	assert.Zero(t, repo.writes)
+	assert.Empty(t, inviter.sent)
+
+	codes := map[string]string{}
+	for _, e := range result.Errors {
+		codes[e.ID] = e.Error
+	}
+	assert.Equal(t, map[string]string{
+		"4": ImportErrorDuplicateRow,
+		"5": ImportErrorUnknownRole,
+		"6": ImportErrorInvalidRow,
+	}, codes)
+}
+
+func TestImportUsersHandler_UpsertIsIdempotent(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]*aggregates.UserAggregate{}}
+	inviter := &recordingInviter{}
+	h := NewImportUsersHandler(repo, staticRoles{}, nil, inviter)
+	rows := importRows()[:2]
+
+	first, err := h.Handle(context.Background(), commands.ImportUsersCommand{Rows: rows})
+	require.NoError(t, err)
+	assert.Equal(t, 2, first.Created)
+	assert.Equal(t, []string{"ana@example.com"}, inviter.sent)
+
+	ana := repo.users["ana@example.com"]
+	require.NotNil(t, ana)
+	assert.Equal(t, []string{"analyst"}, ana.Roles())
+	assert.Equal(t, "Ana", ana.FirstName())
+	assert.False(t, ana.VerifyPassword(""), "imported users without a password cannot log in")
+
+	second, err := h.Handle(context.Background(), commands.ImportUsersCommand{Rows: rows})
+	require.NoError(t, err)
+	assert.Equal(t, 2, second.Unchanged)
+	assert.Equal(t, 2, repo.writes)
+
+	rows[0].Roles = []string{"viewer", "analyst"}
+	third, err := h.Handle(context.Background(), commands.ImportUsersCommand{Rows: rows})
+	require.NoError(t, err)
+	assert.Equal(t, 1, third.Updated)
+	assert.ElementsMatch(t, []string{"viewer", "analyst"}, ana.Roles())
+}
+
+func TestImportUsersHandler_EnforcesRoleGovernance(t *testing.T) {
+	repo := &memoryUserRepo{users: map[string]*aggregates.UserAggregate{}}
+	h := NewImportUsersHandler(repo, staticRoles{}, nil, nil).
+		WithRoleConstraints(exclusiveRoles{a: "analyst", b: "auditor"})
+
+	rows := []commands.UserImportRow{
+		{Line: 2, Username: "eve", Email: "eve@example.com", Roles: []string{"analyst", "auditor"}},
+		{Line: 3, Username: "fay", Email: "fay@example.com", Roles: []string{"admin"}},
+		{Line: 4, Username: "gil", Email: "gil@example.com", Roles: []string{"auditor"}},
+	}
+	result, err := h.Handle(context.Background(), commands.ImportUsersCommand{Rows: rows})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, result.Created)
+	codes := map[string]string{}
+	for _, e := range result.Errors {
+		codes[e.ID] = e.Error
+	}
+	assert.Equal(t, map[string]string{
+		"2": ImportErrorRoleConflict,
+		"3": ImportErrorApprovalRequired,
+	}, codes)
+}
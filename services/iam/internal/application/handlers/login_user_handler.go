@@ -2,45 +2,97 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"atlas-core-api/services/iam/internal/application/commands"
+	"atlas-core-api/services/iam/internal/application/dto"
 	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
 )
 
+var (
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrAccountDeactivated = errors.New("account is deactivated")
+	ErrPasswordExpired    = errors.New("password expired")
+)
+
+// Login failure reasons, recorded with each rejected attempt
+const (
+	LoginFailureUnknownUser     = "unknown_user"
+	LoginFailureDeactivated     = "account_deactivated"
+	LoginFailureInvalidPassword = "invalid_password"
+)
+
+// maxLoginUpdateAttempts bounds retries when a concurrent write bumps the
+// user's version between load and update
+const maxLoginUpdateAttempts = 3
+
+// LoginFailedError describes a rejected login. UserID is empty when the
+// username is unknown; for ErrPasswordExpired the credentials were correct.
+type LoginFailedError struct {
+	UserID string
+	Reason string
+	Err    error
+}
+
+func (e *LoginFailedError) Error() string { return e.Err.Error() }
+func (e *LoginFailedError) Unwrap() error { return e.Err }
+
 type LoginUserHandler struct {
-	userRepo  repositories.UserRepository
-	publisher EventPublisher
+	userRepo repositories.UserRepository
 }
 
-func NewLoginUserHandler(repo repositories.UserRepository, pub EventPublisher) *LoginUserHandler {
-	return &LoginUserHandler{userRepo: repo, publisher: pub}
+func NewLoginUserHandler(repo repositories.UserRepository) *LoginUserHandler {
+	return &LoginUserHandler{userRepo: repo}
 }
 
-func (h *LoginUserHandler) Handle(ctx context.Context, cmd commands.LoginUserCommand) error {
+// Handle verifies the credentials and records the login on the aggregate.
+// Passwords past the configured maximum age are rejected before the login is
+// recorded.
+func (h *LoginUserHandler) Handle(ctx context.Context, cmd commands.LoginUserCommand) (*dto.UserDTO, error) {
 	user, err := h.userRepo.FindByUsername(ctx, cmd.Username)
 	if err != nil {
-		return fmt.Errorf("invalid credentials")
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, &LoginFailedError{Reason: LoginFailureUnknownUser, Err: ErrInvalidCredentials}
+		}
+		return nil, err
 	}
 
 	if !user.IsActive() {
-		return fmt.Errorf("account is deactivated")
+		return nil, &LoginFailedError{UserID: user.ID().String(), Reason: LoginFailureDeactivated, Err: ErrAccountDeactivated}
 	}
 
 	if !user.VerifyPassword(cmd.Password) {
-		return fmt.Errorf("invalid credentials")
+		return nil, &LoginFailedError{UserID: user.ID().String(), Reason: LoginFailureInvalidPassword, Err: ErrInvalidCredentials}
 	}
 
-	user.RecordLogin(cmd.IPAddress, cmd.UserAgent)
-
-	if err := h.userRepo.Update(ctx, user); err != nil {
-		return fmt.Errorf("failed to update login record: %w", err)
+	if user.PasswordExpired(valueobjects.CurrentPasswordPolicy(), time.Now()) {
+		return nil, &LoginFailedError{UserID: user.ID().String(), Err: ErrPasswordExpired}
 	}
 
-	for _, event := range user.DomainEvents() {
-		_ = h.publisher.Publish(ctx, event)
+	for attempt := 1; ; attempt++ {
+		user.RecordLogin(cmd.IPAddress, cmd.UserAgent)
+
+		err := h.userRepo.Update(ctx, user)
+		if err == nil {
+			break
+		}
+		if !errors.Is(err, repositories.ErrConcurrentModification) || attempt == maxLoginUpdateAttempts {
+			return nil, fmt.Errorf("failed to update login record: %w", err)
+		}
+
+		// The password was already verified; only the login record needs
+		// to be applied to the latest version
+		if user, err = h.userRepo.FindByID(ctx, user.ID()); err != nil {
+			return nil, fmt.Errorf("failed to update login record: %w", err)
+		}
+		if !user.IsActive() {
+			return nil, &LoginFailedError{UserID: user.ID().String(), Reason: LoginFailureDeactivated, Err: ErrAccountDeactivated}
+		}
 	}
-	user.ClearEvents()
 
-	return nil
+	result := dto.UserFromAggregate(user)
+	return &result, nil
 }
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"atlas-core-api/services/iam/internal/application/commands"
+	"atlas-core-api/services/iam/internal/domain/aggregates"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+// fakeUserRepo keeps one stored user and can fail the next N updates with a
+// version conflict
+type fakeUserRepo struct {
+	repositories.UserRepository
+	user      *aggregates.UserAggregate
+	conflicts int
+	updates   int
+}
+
+func (r *fakeUserRepo) FindByUsername(ctx context.Context, username string) (*aggregates.UserAggregate, error) {
+	if r.user == nil || r.user.Username() != username {
+		return nil, repositories.ErrUserNotFound
+	}
+	return r.user, nil
+}
+
+func (r *fakeUserRepo) FindByID(ctx context.Context, id valueobjects.UserID) (*aggregates.UserAggregate, error) {
+	return r.user, nil
+}
+
+func (r *fakeUserRepo) Update(ctx context.Context, user *aggregates.UserAggregate) error {
+	r.updates++
+	if r.conflicts > 0 {
+		r.conflicts--
+		return repositories.ErrConcurrentModification
+	}
+	user.ClearEvents()
+	return nil
+}
+
+func newStoredUser(t *testing.T, passwordChangedAt time.Time) *aggregates.UserAggregate {
+	t.Helper()
+	password, err := valueobjects.NewHashedPassword("Correct9Horse")
+	require.NoError(t, err)
+	return aggregates.ReconstructUserAggregate(
+		"7f9c2a52-43a8-4d4b-9d0e-0a4f6b1f7c11", "analyst", "analyst@example.com", password.String(),
+		"", "", []string{"viewer"}, nil, true, true, false, nil,
+		time.Now(), time.Now(), &passwordChangedAt, 3,
+	)
+}
+
+func TestLoginUserHandler_FailureReasons(t *testing.T) {
+	repo := &fakeUserRepo{user: newStoredUser(t, time.Now())}
+	h := NewLoginUserHandler(repo)
+
+	_, err := h.Handle(context.Background(), commands.LoginUserCommand{Username: "nobody", Password: "x"})
+	var failed *LoginFailedError
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, LoginFailureUnknownUser, failed.Reason)
+	assert.Empty(t, failed.UserID)
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = h.Handle(context.Background(), commands.LoginUserCommand{Username: "analyst", Password: "wrong"})
+	require.True(t, errors.As(err, &failed))
+	assert.Equal(t, LoginFailureInvalidPassword, failed.Reason)
+	assert.Equal(t, repo.user.ID().String(), failed.UserID)
+	assert.Zero(t, repo.updates)
+}
+
+func TestLoginUserHandler_ExpiredPasswordIsNotRecorded(t *testing.T) {
+	policy := valueobjects.CurrentPasswordPolicy()
+	expiring := policy
+	expiring.MaxAge = 24 * time.Hour
+	valueobjects.SetPasswordPolicy(expiring)
+	defer valueobjects.SetPasswordPolicy(policy)
+
+	repo := &fakeUserRepo{user: newStoredUser(t, time.Now().Add(-48*time.Hour))}
+	_, err := NewLoginUserHandler(repo).Handle(context.Background(), commands.LoginUserCommand{
+		Username: "analyst", Password: "Correct9Horse",
+	})
+
+	assert.ErrorIs(t, err, ErrPasswordExpired)
+	assert.Zero(t, repo.updates)
+	assert.Nil(t, repo.user.LastLoginAt())
+}
+
+func TestLoginUserHandler_RetriesVersionConflicts(t *testing.T) {
+	repo := &fakeUserRepo{user: newStoredUser(t, time.Now()), conflicts: 1}
+	user, err := NewLoginUserHandler(repo).Handle(context.Background(), commands.LoginUserCommand{
+		Username: "analyst", Password: "Correct9Horse", IPAddress: "10.0.0.1",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, repo.updates)
+	assert.NotNil(t, user.LastLoginAt)
+
+	repo.conflicts = maxLoginUpdateAttempts
+	repo.updates = 0
+	_, err = NewLoginUserHandler(repo).Handle(context.Background(), commands.LoginUserCommand{
+		Username: "analyst", Password: "Correct9Horse",
+	})
+	assert.ErrorIs(t, err, repositories.ErrConcurrentModification)
+	assert.Equal(t, maxLoginUpdateAttempts, repo.updates)
+}
@@ -0,0 +1,346 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"atlas-core-api/services/iam/internal/application/handlers"
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/events"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var ErrLoginThrottled = errors.New("too many failed login attempts")
+
+// FailureReasonLocked marks attempts refused while a lockout or delay was in
+// effect; they are kept for history but do not count towards new lockouts
+const FailureReasonLocked = "locked"
+
+// LockoutError is returned when a login is refused because the username or
+// client IP is locked out or still inside its progressive delay
+type LockoutError struct {
+	Scope      string
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LockoutError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("%s locked out, retry after %s", e.Scope, e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("%s throttled, retry after %s", e.Scope, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LockoutError) Unwrap() error { return ErrLoginThrottled }
+
+// LoginProtectionPolicy configures progressive delays and temporary lockouts
+type LoginProtectionPolicy struct {
+	MaxFailuresPerUsername int
+	MaxFailuresPerIP       int
+	Window                 time.Duration
+	LockoutDuration        time.Duration
+	MaxLockoutDuration     time.Duration
+	DelayBase              time.Duration
+	MaxDelay               time.Duration
+}
+
+// DefaultLoginProtectionPolicy mirrors the gateway's 5 attempts / 15 minutes rule
+func DefaultLoginProtectionPolicy() LoginProtectionPolicy {
+	return LoginProtectionPolicy{
+		MaxFailuresPerUsername: 5,
+		MaxFailuresPerIP:       20,
+		Window:                 15 * time.Minute,
+		LockoutDuration:        15 * time.Minute,
+		MaxLockoutDuration:     24 * time.Hour,
+		DelayBase:              time.Second,
+		MaxDelay:               30 * time.Second,
+	}
+}
+
+// delayFor returns the wait required after the given number of consecutive failures
+func (p LoginProtectionPolicy) delayFor(failures int) time.Duration {
+	if failures <= 1 || p.DelayBase <= 0 {
+		return 0
+	}
+	return doubled(p.DelayBase, failures-2, p.MaxDelay)
+}
+
+// lockoutFor escalates the lockout duration each time a subject is locked again
+func (p LoginProtectionPolicy) lockoutFor(previousLockouts int) time.Duration {
+	return doubled(p.LockoutDuration, previousLockouts, p.MaxLockoutDuration)
+}
+
+// doubled returns base doubled times times, or max once that would exceed
+// max. The bound is checked before shifting so large counts cannot wrap.
+func doubled(base time.Duration, times int, max time.Duration) time.Duration {
+	if base <= 0 || times >= 63 || base > max>>uint(times) {
+		return max
+	}
+	return base << uint(times)
+}
+
+// LoginProtectionService records login attempts and enforces lockouts per
+// username and per client IP
+type LoginProtectionService struct {
+	repo      repository.LoginAttemptRepository
+	publisher handlers.EventPublisher
+	policy    LoginProtectionPolicy
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+func NewLoginProtectionService(
+	repo repository.LoginAttemptRepository,
+	publisher handlers.EventPublisher,
+	policy LoginProtectionPolicy,
+	logger *zap.Logger,
+) *LoginProtectionService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LoginProtectionService{
+		repo:      repo,
+		publisher: publisher,
+		policy:    policy,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Check returns a *LockoutError if the attempt must be refused before the
+// password is even verified
+func (s *LoginProtectionService) Check(ctx context.Context, username, ipAddress string) error {
+	now := s.now()
+	subjects := map[string]string{
+		models.LockoutScopeUsername: normalizeUsername(username),
+		models.LockoutScopeIP:       ipAddress,
+	}
+
+	for scope, subject := range subjects {
+		if subject == "" {
+			continue
+		}
+		lockout, err := s.repo.GetLockout(ctx, scope, subject)
+		if err != nil {
+			return err
+		}
+		if lockout.IsLocked(now) {
+			return &LockoutError{Scope: scope, RetryAfter: lockout.LockedUntil.Sub(now), Locked: true}
+		}
+	}
+
+	// Progressive delay only applies per username so a shared NAT address is
+	// not slowed down by one user's typos
+	subject := subjects[models.LockoutScopeUsername]
+	if subject == "" {
+		return nil
+	}
+	lockout, err := s.repo.GetLockout(ctx, models.LockoutScopeUsername, subject)
+	if err != nil {
+		return err
+	}
+	failures, last, err := s.repo.FailureStats(ctx, models.LockoutScopeUsername, subject, s.windowStart(lockout, now))
+	if err != nil {
+		return err
+	}
+	if last != nil {
+		if wait := last.Add(s.policy.delayFor(failures + 1)).Sub(now); wait > 0 {
+			return &LockoutError{Scope: models.LockoutScopeUsername, RetryAfter: wait}
+		}
+	}
+
+	return nil
+}
+
+// RecordFailure stores a failed attempt, applies lockouts when thresholds are
+// crossed and emits a UserLoginFailed event
+func (s *LoginProtectionService) RecordFailure(ctx context.Context, userID, username, ipAddress, userAgent, reason string) error {
+	now := s.now()
+	subject := normalizeUsername(username)
+
+	attempt := &models.LoginAttempt{
+		Username:      subject,
+		IPAddress:     ipAddress,
+		Success:       false,
+		UserAgent:     userAgent,
+		FailureReason: reason,
+	}
+	if err := s.repo.Record(ctx, attempt); err != nil {
+		return err
+	}
+
+	userFailures, lockedUntil, err := s.applyThreshold(ctx, models.LockoutScopeUsername, subject, s.policy.MaxFailuresPerUsername, now)
+	if err != nil {
+		return err
+	}
+	if _, ipLockedUntil, err := s.applyThreshold(ctx, models.LockoutScopeIP, ipAddress, s.policy.MaxFailuresPerIP, now); err != nil {
+		return err
+	} else if lockedUntil == nil {
+		lockedUntil = ipLockedUntil
+	}
+
+	aggregateID := userID
+	if aggregateID == "" {
+		aggregateID = subject
+	}
+	event := events.UserLoginFailed{
+		BaseEvent: events.BaseEvent{
+			ID:          uuid.New().String(),
+			Type:        "atlas.user.login_failed",
+			AggregateId: aggregateID,
+			Timestamp:   now,
+			Version:     1,
+		},
+		Username:      subject,
+		IPAddress:     ipAddress,
+		FailureReason: reason,
+		AttemptCount:  userFailures,
+		LockedUntil:   lockedUntil,
+	}
+	if s.publisher != nil {
+		if err := s.publisher.Publish(ctx, event); err != nil {
+			s.logger.Warn("Failed to publish login failure event", zap.Error(err))
+		}
+	}
+
+	return nil
+}
+
+// RecordRefused stores an attempt that was rejected without checking the password
+func (s *LoginProtectionService) RecordRefused(ctx context.Context, username, ipAddress, userAgent string) error {
+	return s.repo.Record(ctx, &models.LoginAttempt{
+		Username:      normalizeUsername(username),
+		IPAddress:     ipAddress,
+		Success:       false,
+		UserAgent:     userAgent,
+		FailureReason: FailureReasonLocked,
+	})
+}
+
+// RecordSuccess stores a successful attempt and resets the username's failure window
+func (s *LoginProtectionService) RecordSuccess(ctx context.Context, username, ipAddress, userAgent string) error {
+	now := s.now()
+	subject := normalizeUsername(username)
+
+	if err := s.repo.Record(ctx, &models.LoginAttempt{
+		Username:  subject,
+		IPAddress: ipAddress,
+		Success:   true,
+		UserAgent: userAgent,
+	}); err != nil {
+		return err
+	}
+
+	lockout, err := s.repo.GetLockout(ctx, models.LockoutScopeUsername, subject)
+	if err != nil {
+		return err
+	}
+	lockout.ResetAt = &now
+	lockout.LockedUntil = nil
+	lockout.LockoutCount = 0
+	return s.repo.SaveLockout(ctx, lockout)
+}
+
+// Unlock clears an active lockout and resets the failure window for the subject
+func (s *LoginProtectionService) Unlock(ctx context.Context, scope, subject, unlockedBy string) error {
+	if scope != models.LockoutScopeUsername && scope != models.LockoutScopeIP {
+		return fmt.Errorf("%w: scope must be %q or %q", ErrInvalidInput, models.LockoutScopeUsername, models.LockoutScopeIP)
+	}
+	if scope == models.LockoutScopeUsername {
+		subject = normalizeUsername(subject)
+	}
+	if subject == "" {
+		return fmt.Errorf("%w: subject is required", ErrInvalidInput)
+	}
+
+	now := s.now()
+	lockout, err := s.repo.GetLockout(ctx, scope, subject)
+	if err != nil {
+		return err
+	}
+	lockout.LockedUntil = nil
+	lockout.ResetAt = &now
+	lockout.UnlockedBy = unlockedBy
+	lockout.UnlockedAt = &now
+
+	if err := s.repo.SaveLockout(ctx, lockout); err != nil {
+		return err
+	}
+
+	s.logger.Info("Lockout cleared",
+		zap.String("scope", scope),
+		zap.String("subject", subject),
+		zap.String("unlocked_by", unlockedBy),
+	)
+	return nil
+}
+
+// History returns recorded attempts, newest first
+func (s *LoginProtectionService) History(ctx context.Context, filter repository.LoginAttemptFilter) ([]*models.LoginAttempt, error) {
+	if filter.Username != "" {
+		filter.Username = normalizeUsername(filter.Username)
+	}
+	return s.repo.List(ctx, filter)
+}
+
+// Lockouts returns lockout records, optionally only those currently in effect
+func (s *LoginProtectionService) Lockouts(ctx context.Context, activeOnly bool) ([]*models.AccountLockout, error) {
+	return s.repo.ListLockouts(ctx, activeOnly)
+}
+
+func (s *LoginProtectionService) applyThreshold(ctx context.Context, scope, subject string, max int, now time.Time) (int, *time.Time, error) {
+	if subject == "" || max <= 0 {
+		return 0, nil, nil
+	}
+
+	lockout, err := s.repo.GetLockout(ctx, scope, subject)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	failures, _, err := s.repo.FailureStats(ctx, scope, subject, s.windowStart(lockout, now))
+	if err != nil {
+		return 0, nil, err
+	}
+	if failures < max {
+		return failures, nil, nil
+	}
+
+	lockedUntil := now.Add(s.policy.lockoutFor(lockout.LockoutCount))
+	lockout.LockedUntil = &lockedUntil
+	lockout.LockoutCount++
+	lockout.ResetAt = &now
+	lockout.Reason = fmt.Sprintf("%d failed attempts within %s", failures, s.policy.Window)
+	lockout.UnlockedBy = ""
+	lockout.UnlockedAt = nil
+	if err := s.repo.SaveLockout(ctx, lockout); err != nil {
+		return failures, nil, err
+	}
+
+	s.logger.Warn("Login lockout applied",
+		zap.String("scope", scope),
+		zap.String("subject", subject),
+		zap.Int("failures", failures),
+		zap.Time("locked_until", lockedUntil),
+	)
+	return failures, &lockedUntil, nil
+}
+
+// windowStart counts failures only inside the window and after the last reset
+func (s *LoginProtectionService) windowStart(lockout *models.AccountLockout, now time.Time) time.Time {
+	start := now.Add(-s.policy.Window)
+	if lockout.ResetAt != nil && lockout.ResetAt.After(start) {
+		return *lockout.ResetAt
+	}
+	return start
+}
+
+func normalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
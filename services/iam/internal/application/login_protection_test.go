@@ -0,0 +1,206 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// fakeLoginAttempts keeps attempts and lockouts in memory, stamping each
+// attempt with the test clock
+type fakeLoginAttempts struct {
+	repository.LoginAttemptRepository
+	attempts []*models.LoginAttempt
+	lockouts map[string]*models.AccountLockout
+	now      func() time.Time
+}
+
+func newFakeLoginAttempts(now func() time.Time) *fakeLoginAttempts {
+	return &fakeLoginAttempts{lockouts: make(map[string]*models.AccountLockout), now: now}
+}
+
+func (r *fakeLoginAttempts) Record(ctx context.Context, attempt *models.LoginAttempt) error {
+	c := *attempt
+	c.CreatedAt = r.now()
+	r.attempts = append(r.attempts, &c)
+	return nil
+}
+
+func (r *fakeLoginAttempts) FailureStats(ctx context.Context, scope, subject string, since time.Time) (int, *time.Time, error) {
+	var count int
+	var last *time.Time
+	for _, a := range r.attempts {
+		matches := a.Username == subject
+		if scope == models.LockoutScopeIP {
+			matches = a.IPAddress == subject
+		}
+		if !matches || a.Success || !a.CreatedAt.After(since) || a.FailureReason == FailureReasonLocked {
+			continue
+		}
+		count++
+		at := a.CreatedAt
+		last = &at
+	}
+	return count, last, nil
+}
+
+func (r *fakeLoginAttempts) GetLockout(ctx context.Context, scope, subject string) (*models.AccountLockout, error) {
+	if lockout, ok := r.lockouts[scope+"/"+subject]; ok {
+		c := *lockout
+		return &c, nil
+	}
+	return &models.AccountLockout{Scope: scope, Subject: subject}, nil
+}
+
+func (r *fakeLoginAttempts) SaveLockout(ctx context.Context, lockout *models.AccountLockout) error {
+	c := *lockout
+	r.lockouts[lockout.Scope+"/"+lockout.Subject] = &c
+	return nil
+}
+
+func TestLoginProtectionPolicy_DelayFor(t *testing.T) {
+	policy := DefaultLoginProtectionPolicy()
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{0, 0},
+		{1, 0},
+		{2, time.Second},
+		{3, 2 * time.Second},
+		{4, 4 * time.Second},
+		{6, 16 * time.Second},
+		{7, 30 * time.Second},
+		{40, 30 * time.Second},
+		{64, 30 * time.Second},
+		{65, 30 * time.Second},
+		{1 << 20, 30 * time.Second},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.delayFor(tt.failures), "failures=%d", tt.failures)
+	}
+
+	// Shifting this base past 63 bits would wrap to a tiny positive delay
+	policy.DelayBase = time.Duration(1<<40 + 1)
+	policy.MaxDelay = time.Duration(1 << 62)
+	for failures := 2; failures < 200; failures++ {
+		delay := policy.delayFor(failures)
+		assert.Positive(t, delay, "failures=%d", failures)
+		assert.LessOrEqual(t, delay, policy.MaxDelay, "failures=%d", failures)
+		assert.GreaterOrEqual(t, delay, policy.delayFor(failures-1), "failures=%d", failures)
+	}
+}
+
+func TestLoginProtectionPolicy_LockoutFor(t *testing.T) {
+	policy := DefaultLoginProtectionPolicy()
+
+	tests := []struct {
+		previousLockouts int
+		want             time.Duration
+	}{
+		{0, 15 * time.Minute},
+		{1, 30 * time.Minute},
+		{2, time.Hour},
+		{6, 16 * time.Hour},
+		{7, 24 * time.Hour},
+		{50, 24 * time.Hour},
+		{100, 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, policy.lockoutFor(tt.previousLockouts), "previousLockouts=%d", tt.previousLockouts)
+	}
+}
+
+func newTestLoginProtection(clock *time.Time) (*LoginProtectionService, *fakeLoginAttempts) {
+	now := func() time.Time { return *clock }
+	repo := newFakeLoginAttempts(now)
+	s := NewLoginProtectionService(repo, nil, DefaultLoginProtectionPolicy(), nil)
+	s.now = now
+	return s, repo
+}
+
+func TestLoginProtectionService_ProgressiveDelayThenLockout(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := newTestLoginProtection(&clock)
+
+	// After n failures the next attempt waits DelayBase << (n-1), then
+	// goes ahead
+	for failures, wantWait := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second} {
+		require.NoError(t, s.RecordFailure(ctx, "", "Analyst", "203.0.113.7", "test", "invalid_password"))
+
+		var lockoutErr *LockoutError
+		require.True(t, errors.As(s.Check(ctx, "analyst", "203.0.113.7"), &lockoutErr), "after %d failures", failures+1)
+		assert.False(t, lockoutErr.Locked)
+		assert.Equal(t, models.LockoutScopeUsername, lockoutErr.Scope)
+		assert.Equal(t, wantWait, lockoutErr.RetryAfter, "after %d failures", failures+1)
+
+		clock = clock.Add(wantWait)
+		require.NoError(t, s.Check(ctx, "analyst", "203.0.113.7"), "after %d failures", failures+1)
+	}
+
+	// The fifth failure locks the username for LockoutDuration
+	require.NoError(t, s.RecordFailure(ctx, "", "analyst", "203.0.113.7", "test", "invalid_password"))
+	var lockoutErr *LockoutError
+	require.True(t, errors.As(s.Check(ctx, "analyst", "198.51.100.1"), &lockoutErr))
+	assert.True(t, lockoutErr.Locked)
+	assert.Equal(t, 15*time.Minute, lockoutErr.RetryAfter)
+	assert.ErrorIs(t, lockoutErr, ErrLoginThrottled)
+
+	// Once the lockout passes the count starts again, and the next
+	// lockout is twice as long
+	clock = clock.Add(15 * time.Minute)
+	require.NoError(t, s.Check(ctx, "analyst", "198.51.100.1"))
+	for i := 0; i < 5; i++ {
+		clock = clock.Add(time.Minute)
+		require.NoError(t, s.RecordFailure(ctx, "", "analyst", "198.51.100.1", "test", "invalid_password"))
+	}
+	require.True(t, errors.As(s.Check(ctx, "analyst", "198.51.100.1"), &lockoutErr))
+	assert.True(t, lockoutErr.Locked)
+	assert.Equal(t, 30*time.Minute, lockoutErr.RetryAfter)
+}
+
+func TestLoginProtectionService_SuccessResetsFailures(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, repo := newTestLoginProtection(&clock)
+
+	for i := 0; i < 4; i++ {
+		clock = clock.Add(time.Minute)
+		require.NoError(t, s.RecordFailure(ctx, "", "analyst", "203.0.113.7", "test", "invalid_password"))
+	}
+	clock = clock.Add(time.Minute)
+	require.NoError(t, s.RecordSuccess(ctx, "analyst", "203.0.113.7", "test"))
+
+	// Without the reset this failure would be the fifth and lock the user
+	clock = clock.Add(time.Second)
+	require.NoError(t, s.RecordFailure(ctx, "", "analyst", "203.0.113.7", "test", "invalid_password"))
+	var lockoutErr *LockoutError
+	require.True(t, errors.As(s.Check(ctx, "analyst", "203.0.113.7"), &lockoutErr))
+	assert.False(t, lockoutErr.Locked)
+	assert.Equal(t, time.Second, lockoutErr.RetryAfter, "one failure since the reset")
+
+	lockout, err := repo.GetLockout(ctx, models.LockoutScopeUsername, "analyst")
+	require.NoError(t, err)
+	assert.Nil(t, lockout.LockedUntil)
+	assert.Zero(t, lockout.LockoutCount)
+}
+
+func TestLoginProtectionService_RefusedAttemptsDoNotCount(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, _ := newTestLoginProtection(&clock)
+
+	for i := 0; i < 10; i++ {
+		require.NoError(t, s.RecordRefused(ctx, "analyst", "203.0.113.7", "test"))
+	}
+	assert.NoError(t, s.Check(ctx, "analyst", "203.0.113.7"))
+}
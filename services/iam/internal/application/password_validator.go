@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+// BreachedPasswordSource returns SHA-1 suffixes and counts for a five
+// character hash prefix (k-anonymity range lookup)
+type BreachedPasswordSource interface {
+	Range(ctx context.Context, prefix string) (map[string]int, error)
+}
+
+// PasswordValidator applies the configured password policy and screens
+// candidates against a breached-password corpus
+type PasswordValidator struct {
+	policy         valueobjects.PasswordPolicy
+	breaches       BreachedPasswordSource
+	minBreachCount int
+	logger         *zap.Logger
+}
+
+func NewPasswordValidator(policy valueobjects.PasswordPolicy, breaches BreachedPasswordSource, minBreachCount int, logger *zap.Logger) *PasswordValidator {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	if minBreachCount < 1 {
+		minBreachCount = 1
+	}
+	return &PasswordValidator{
+		policy:         policy,
+		breaches:       breaches,
+		minBreachCount: minBreachCount,
+		logger:         logger,
+	}
+}
+
+// Policy returns the active policy. A nil validator falls back to the
+// process-wide policy so services work without one being wired.
+func (v *PasswordValidator) Policy() valueobjects.PasswordPolicy {
+	if v == nil {
+		return valueobjects.CurrentPasswordPolicy()
+	}
+	return v.policy
+}
+
+// Validate returns a *valueobjects.PolicyViolationError listing every failed rule
+func (v *PasswordValidator) Validate(ctx context.Context, password, username string) error {
+	err := v.Policy().Validate(password, username)
+	if v == nil || v.breaches == nil {
+		return err
+	}
+
+	var violation *valueobjects.PolicyViolationError
+	if err != nil && !errors.As(err, &violation) {
+		return err
+	}
+
+	breached, lookupErr := v.isBreached(ctx, password)
+	if lookupErr != nil {
+		// Fail open: an unavailable corpus must not block every password change
+		v.logger.Warn("Breached password lookup failed", zap.Error(lookupErr))
+	}
+	if breached {
+		if violation == nil {
+			violation = &valueobjects.PolicyViolationError{}
+		}
+		violation.Violations = append(violation.Violations, "appears in a known data breach")
+	}
+
+	if violation != nil {
+		return violation
+	}
+	return nil
+}
+
+func (v *PasswordValidator) isBreached(ctx context.Context, password string) (bool, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := v.breaches.Range(ctx, hash[:5])
+	if err != nil {
+		return false, err
+	}
+	return suffixes[hash[5:]] >= v.minBreachCount, nil
+}
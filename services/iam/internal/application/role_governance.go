@@ -0,0 +1,481 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var (
+	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleNotAssigned    = errors.New("role is not assigned to the user")
+	ErrSeparationOfDuties = errors.New("separation of duties violation")
+	ErrSelfApproval       = errors.New("a grant request cannot be approved by its requester or grantee")
+	ErrRequestNotFound    = errors.New("role grant request not found")
+	ErrRequestDecided     = errors.New("role grant request is no longer pending")
+	ErrRequestPending     = errors.New("a grant request for this role is already pending")
+	ErrRuleNotFound       = errors.New("rule not found")
+	ErrRuleExists         = errors.New("rule already exists")
+)
+
+// expiryBatchSize bounds the assignments removed per sweep transaction
+const expiryBatchSize = 500
+
+// RoleAssignmentRequest carries the optional terms of a role assignment
+type RoleAssignmentRequest struct {
+	ExpiresAt *time.Time `json:"expires_at"`
+	Reason    string     `json:"reason"`
+}
+
+// RoleDelegationRequest lets holders of one role manage another
+type RoleDelegationRequest struct {
+	GrantorRoleID    string `json:"grantor_role_id"`
+	AssignableRoleID string `json:"assignable_role_id"`
+}
+
+// RoleExclusionRequest declares two roles mutually exclusive
+type RoleExclusionRequest struct {
+	RoleAID string `json:"role_a_id"`
+	RoleBID string `json:"role_b_id"`
+	Reason  string `json:"reason"`
+}
+
+// RoleGovernanceService decides who may change whose roles. Admins manage
+// every role; other users manage only the roles delegated to one of their
+// roles, and never their own. Separation-of-duties rules apply to everyone,
+// and roles that require approval are granted only through a request that a
+// second admin approves.
+type RoleGovernanceService struct {
+	userRepo   repository.UserRepository
+	roleRepo   repository.RoleRepository
+	governance repository.RoleGovernanceRepository
+	logger     *zap.Logger
+	now        func() time.Time
+}
+
+func NewRoleGovernanceService(
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	governance repository.RoleGovernanceRepository,
+	logger *zap.Logger,
+) *RoleGovernanceService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &RoleGovernanceService{
+		userRepo:   userRepo,
+		roleRepo:   roleRepo,
+		governance: governance,
+		logger:     logger,
+		now:        time.Now,
+	}
+}
+
+// AssignRole grants a role on behalf of actorID. For roles that require
+// approval it files a grant request instead and returns it; otherwise the
+// returned request is nil.
+func (s *RoleGovernanceService) AssignRole(ctx context.Context, actorID, userID, roleID string, req RoleAssignmentRequest) (*models.RoleGrantRequest, error) {
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(s.now()) {
+		return nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidInput)
+	}
+
+	role, err := s.role(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.user(userID); err != nil {
+		return nil, err
+	}
+	if err := s.authorize(ctx, actorID, userID, role.Name); err != nil {
+		return nil, err
+	}
+	if err := s.checkExclusions(ctx, userID, role); err != nil {
+		return nil, err
+	}
+
+	if role.RequiresApproval {
+		grant := &models.RoleGrantRequest{
+			UserID:         userID,
+			RoleID:         role.ID,
+			RequestedBy:    actorID,
+			Reason:         strings.TrimSpace(req.Reason),
+			GrantExpiresAt: req.ExpiresAt,
+		}
+		if err := s.governance.CreateGrantRequest(ctx, grant); err != nil {
+			return nil, mapGovernanceError(err)
+		}
+		s.logger.Info("Role grant requested",
+			zap.String("request_id", grant.ID),
+			zap.String("user_id", userID),
+			zap.String("role", role.Name),
+			zap.String("requested_by", actorID),
+		)
+		return grant, nil
+	}
+
+	if err := s.governance.GrantRole(ctx, userID, role.ID, actorID, req.ExpiresAt); err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	s.logger.Info("Role assigned",
+		zap.String("user_id", userID),
+		zap.String("role", role.Name),
+		zap.String("assigned_by", actorID),
+	)
+	return nil, nil
+}
+
+// RevokeRole removes a role on behalf of actorID
+func (s *RoleGovernanceService) RevokeRole(ctx context.Context, actorID, userID, roleID, reason string) error {
+	role, err := s.role(roleID)
+	if err != nil {
+		return err
+	}
+	if err := s.authorize(ctx, actorID, userID, role.Name); err != nil {
+		return err
+	}
+
+	if err := s.governance.RevokeRole(ctx, userID, role.ID, actorID, strings.TrimSpace(reason)); err != nil {
+		return mapGovernanceError(err)
+	}
+	s.logger.Info("Role revoked",
+		zap.String("user_id", userID),
+		zap.String("role", role.Name),
+		zap.String("revoked_by", actorID),
+	)
+	return nil
+}
+
+// UserRoles returns the user's active assignments with their expiry. Users
+// see their own; admins and holders of a delegation, who manage other
+// users' roles, see anyone's.
+func (s *RoleGovernanceService) UserRoles(ctx context.Context, actorID, userID string) ([]*models.RoleAssignment, error) {
+	if err := s.authorizeView(ctx, actorID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.user(userID); err != nil {
+		return nil, err
+	}
+	return s.governance.UserAssignments(ctx, userID)
+}
+
+// GrantRequests lists grant requests, optionally by status
+func (s *RoleGovernanceService) GrantRequests(ctx context.Context, filter repository.RoleGrantRequestFilter) ([]*models.RoleGrantRequest, error) {
+	switch filter.Status {
+	case "", models.GrantRequestPending, models.GrantRequestApproved, models.GrantRequestRejected:
+	default:
+		return nil, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, filter.Status)
+	}
+	return s.governance.ListGrantRequests(ctx, filter)
+}
+
+// ApproveRequest grants the requested role. The approver must be an admin
+// other than the requester and the grantee.
+func (s *RoleGovernanceService) ApproveRequest(ctx context.Context, actorID, requestID, note string) (*models.RoleGrantRequest, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	grant, err := s.governance.GetGrantRequest(ctx, requestID)
+	if err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	if grant.Status != models.GrantRequestPending {
+		return nil, ErrRequestDecided
+	}
+	if actorID == grant.RequestedBy || actorID == grant.UserID {
+		return nil, ErrSelfApproval
+	}
+
+	approved, err := s.governance.ApproveGrantRequest(ctx, requestID, actorID, strings.TrimSpace(note))
+	if err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	s.logger.Info("Role grant approved",
+		zap.String("request_id", requestID),
+		zap.String("user_id", approved.UserID),
+		zap.String("role", approved.RoleName),
+		zap.String("approved_by", actorID),
+	)
+	return approved, nil
+}
+
+// RejectRequest closes a pending request without granting the role
+func (s *RoleGovernanceService) RejectRequest(ctx context.Context, actorID, requestID, note string) (*models.RoleGrantRequest, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	rejected, err := s.governance.RejectGrantRequest(ctx, requestID, actorID, strings.TrimSpace(note))
+	if err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	s.logger.Info("Role grant rejected",
+		zap.String("request_id", requestID),
+		zap.String("rejected_by", actorID),
+	)
+	return rejected, nil
+}
+
+func (s *RoleGovernanceService) Delegations(ctx context.Context) ([]*models.RoleDelegation, error) {
+	return s.governance.ListDelegations(ctx)
+}
+
+// CreateDelegation lets holders of the grantor role assign and revoke the
+// assignable role
+func (s *RoleGovernanceService) CreateDelegation(ctx context.Context, actorID string, req RoleDelegationRequest) (*models.RoleDelegation, error) {
+	if req.GrantorRoleID == "" || req.AssignableRoleID == "" {
+		return nil, fmt.Errorf("%w: grantor_role_id and assignable_role_id are required", ErrInvalidInput)
+	}
+	if req.GrantorRoleID == req.AssignableRoleID {
+		return nil, fmt.Errorf("%w: a role cannot be delegated to itself", ErrInvalidInput)
+	}
+
+	delegation := &models.RoleDelegation{
+		GrantorRoleID:    req.GrantorRoleID,
+		AssignableRoleID: req.AssignableRoleID,
+		CreatedBy:        actorID,
+	}
+	if err := s.governance.CreateDelegation(ctx, delegation); err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	return delegation, nil
+}
+
+func (s *RoleGovernanceService) DeleteDelegation(ctx context.Context, id string) error {
+	return mapGovernanceError(s.governance.DeleteDelegation(ctx, id))
+}
+
+func (s *RoleGovernanceService) Exclusions(ctx context.Context) ([]*models.RoleExclusion, error) {
+	return s.governance.ListExclusions(ctx)
+}
+
+// CreateExclusion makes two roles mutually exclusive. Users who already hold
+// both keep them; the rule applies to later assignments.
+func (s *RoleGovernanceService) CreateExclusion(ctx context.Context, actorID string, req RoleExclusionRequest) (*models.RoleExclusion, error) {
+	if req.RoleAID == "" || req.RoleBID == "" {
+		return nil, fmt.Errorf("%w: role_a_id and role_b_id are required", ErrInvalidInput)
+	}
+	if req.RoleAID == req.RoleBID {
+		return nil, fmt.Errorf("%w: a role cannot exclude itself", ErrInvalidInput)
+	}
+
+	exclusion := &models.RoleExclusion{
+		RoleAID:   req.RoleAID,
+		RoleBID:   req.RoleBID,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: actorID,
+	}
+	if err := s.governance.CreateExclusion(ctx, exclusion); err != nil {
+		return nil, mapGovernanceError(err)
+	}
+	return exclusion, nil
+}
+
+func (s *RoleGovernanceService) DeleteExclusion(ctx context.Context, id string) error {
+	return mapGovernanceError(s.governance.DeleteExclusion(ctx, id))
+}
+
+// CheckRoles reports a separation-of-duties violation within a complete set
+// of role names, as written by bulk import
+func (s *RoleGovernanceService) CheckRoles(ctx context.Context, roles []string) error {
+	exclusions, err := s.governance.ListExclusions(ctx)
+	if err != nil {
+		return err
+	}
+	if pair := excludedPair(exclusions, roles); pair != nil {
+		return fmt.Errorf("%w: %s and %s are mutually exclusive", ErrSeparationOfDuties, pair.RoleA, pair.RoleB)
+	}
+	return nil
+}
+
+// ExpireAssignments removes every lapsed assignment and returns how many
+func (s *RoleGovernanceService) ExpireAssignments(ctx context.Context) (int, error) {
+	total := 0
+	for {
+		n, err := s.governance.ExpireAssignments(ctx, expiryBatchSize)
+		total += n
+		if err != nil || n < expiryBatchSize {
+			return total, err
+		}
+	}
+}
+
+// RunExpirySweep expires lapsed assignments every interval until ctx is done.
+// A non-positive interval disables the sweep.
+func (s *RoleGovernanceService) RunExpirySweep(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.ExpireAssignments(ctx)
+		if err != nil && ctx.Err() == nil {
+			s.logger.Error("Role expiry sweep failed", zap.Error(err))
+		}
+		if n > 0 {
+			s.logger.Info("Expired role assignments", zap.Int("count", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// authorize checks that actorID may change roleName on userID
+func (s *RoleGovernanceService) authorize(ctx context.Context, actorID, userID, roleName string) error {
+	actor, err := s.actor(actorID)
+	if err != nil {
+		return err
+	}
+	if actor.IsAdmin() {
+		return nil
+	}
+	if actor.ID == userID {
+		return fmt.Errorf("%w: you cannot change your own roles", ErrForbidden)
+	}
+
+	delegated, err := s.governance.DelegatedRoles(ctx, actor.Roles)
+	if err != nil {
+		return err
+	}
+	for _, name := range delegated {
+		if name == roleName {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: role %s is outside your delegated scope", ErrForbidden, roleName)
+}
+
+// authorizeView checks that actorID may see userID's role assignments
+func (s *RoleGovernanceService) authorizeView(ctx context.Context, actorID, userID string) error {
+	actor, err := s.actor(actorID)
+	if err != nil {
+		return err
+	}
+	if actor.IsAdmin() || actor.ID == userID {
+		return nil
+	}
+
+	delegated, err := s.governance.DelegatedRoles(ctx, actor.Roles)
+	if err != nil {
+		return err
+	}
+	if len(delegated) == 0 {
+		return fmt.Errorf("%w: you can only view your own roles", ErrForbidden)
+	}
+	return nil
+}
+
+func (s *RoleGovernanceService) checkExclusions(ctx context.Context, userID string, role *models.Role) error {
+	conflicts, err := s.governance.ConflictingRoles(ctx, userID, role.ID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s conflicts with %s", ErrSeparationOfDuties, role.Name, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+func (s *RoleGovernanceService) requireAdmin(actorID string) error {
+	actor, err := s.actor(actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.IsAdmin() {
+		return fmt.Errorf("%w: only admins can decide grant requests", ErrForbidden)
+	}
+	return nil
+}
+
+// actor loads the acting user so decisions use current roles rather than
+// the ones in their token
+func (s *RoleGovernanceService) actor(actorID string) (*models.User, error) {
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if !actor.Active {
+		return nil, ErrForbidden
+	}
+	return actor, nil
+}
+
+func (s *RoleGovernanceService) user(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (s *RoleGovernanceService) role(roleID string) (*models.Role, error) {
+	role, err := s.roleRepo.GetByID(roleID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRoleNotFound) {
+			return nil, ErrRoleNotFound
+		}
+		return nil, err
+	}
+	return role, nil
+}
+
+// excludedPair returns the first exclusion violated by holding all of roles
+func excludedPair(exclusions []*models.RoleExclusion, roles []string) *models.RoleExclusion {
+	held := make(map[string]bool, len(roles))
+	for _, name := range roles {
+		held[name] = true
+	}
+	for _, e := range exclusions {
+		if held[e.RoleA] && held[e.RoleB] {
+			return e
+		}
+	}
+	return nil
+}
+
+func mapGovernanceError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, repository.ErrRoleNotFound):
+		return ErrRoleNotFound
+	case errors.Is(err, repository.ErrRoleNotAssigned):
+		return ErrRoleNotAssigned
+	case errors.Is(err, repository.ErrRoleExcluded):
+		return fmt.Errorf("%w: %v", ErrSeparationOfDuties, err)
+	case errors.Is(err, repository.ErrGrantRequestNotFound):
+		return ErrRequestNotFound
+	case errors.Is(err, repository.ErrGrantRequestDecided):
+		return ErrRequestDecided
+	case errors.Is(err, repository.ErrGrantRequestPending):
+		return ErrRequestPending
+	case errors.Is(err, repository.ErrGrantRequestExpired):
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	case errors.Is(err, repository.ErrRoleRuleNotFound):
+		return ErrRuleNotFound
+	case errors.Is(err, repository.ErrRoleRuleExists):
+		return ErrRuleExists
+	}
+	return err
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+// fakeGovernance keeps delegations by role name and assignments by user,
+// expiring assignments against the test clock
+type fakeGovernance struct {
+	repository.RoleGovernanceRepository
+	roles       *fakeRoles
+	delegations map[string][]string
+	exclusions  []*models.RoleExclusion
+	assignments map[string][]*models.RoleAssignment
+	now         func() time.Time
+}
+
+func newFakeGovernance(roles *fakeRoles, now func() time.Time) *fakeGovernance {
+	return &fakeGovernance{
+		roles:       roles,
+		delegations: make(map[string][]string),
+		assignments: make(map[string][]*models.RoleAssignment),
+		now:         now,
+	}
+}
+
+func (r *fakeGovernance) DelegatedRoles(ctx context.Context, grantorRoles []string) ([]string, error) {
+	var names []string
+	for _, grantor := range grantorRoles {
+		names = append(names, r.delegations[grantor]...)
+	}
+	return names, nil
+}
+
+func (r *fakeGovernance) ListExclusions(ctx context.Context) ([]*models.RoleExclusion, error) {
+	return r.exclusions, nil
+}
+
+func (r *fakeGovernance) ConflictingRoles(ctx context.Context, userID, roleID string) ([]string, error) {
+	role, err := r.roles.GetByID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	var conflicts []string
+	for _, e := range r.exclusions {
+		other, ok := e.Other(role.Name)
+		if !ok {
+			continue
+		}
+		for _, a := range r.assignments[userID] {
+			if a.RoleName == other {
+				conflicts = append(conflicts, other)
+			}
+		}
+	}
+	return conflicts, nil
+}
+
+func (r *fakeGovernance) UserAssignments(ctx context.Context, userID string) ([]*models.RoleAssignment, error) {
+	return r.assignments[userID], nil
+}
+
+func (r *fakeGovernance) GrantRole(ctx context.Context, userID, roleID, assignedBy string, expiresAt *time.Time) error {
+	role, err := r.roles.GetByID(roleID)
+	if err != nil {
+		return err
+	}
+	r.assignments[userID] = append(r.assignments[userID], &models.RoleAssignment{
+		RoleID:     role.ID,
+		RoleName:   role.Name,
+		AssignedBy: assignedBy,
+		AssignedAt: r.now(),
+		ExpiresAt:  expiresAt,
+	})
+	return nil
+}
+
+func (r *fakeGovernance) ExpireAssignments(ctx context.Context, limit int) (int, error) {
+	expired := 0
+	for userID, assignments := range r.assignments {
+		kept := assignments[:0]
+		for _, a := range assignments {
+			if a.ExpiresAt != nil && !a.ExpiresAt.After(r.now()) && expired < limit {
+				expired++
+				continue
+			}
+			kept = append(kept, a)
+		}
+		r.assignments[userID] = kept
+	}
+	return expired, nil
+}
+
+func newTestGovernanceService(t *testing.T) (*RoleGovernanceService, *fakeGovernance, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	users := newFakeUsers(
+		&models.User{ID: "admin-1", Roles: []string{"admin"}, Active: true},
+		&models.User{ID: "lead-1", Roles: []string{"team_lead"}, Active: true},
+		&models.User{ID: "user-1", Roles: []string{"viewer"}, Active: true},
+	)
+	roles := newFakeRoles(
+		&models.Role{ID: "role-analyst", Name: "analyst"},
+		&models.Role{ID: "role-auditor", Name: "auditor"},
+		&models.Role{ID: "role-approver", Name: "approver"},
+	)
+	governance := newFakeGovernance(roles, clock)
+	governance.delegations["team_lead"] = []string{"analyst"}
+	governance.exclusions = []*models.RoleExclusion{
+		{RoleAID: "role-analyst", RoleA: "analyst", RoleBID: "role-auditor", RoleB: "auditor"},
+	}
+
+	s := NewRoleGovernanceService(users, roles, governance, nil)
+	s.now = clock
+	return s, governance, &now
+}
+
+func TestRoleGovernanceService_SeparationOfDuties(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestGovernanceService(t)
+
+	_, err := s.AssignRole(ctx, "admin-1", "user-1", "role-analyst", RoleAssignmentRequest{})
+	require.NoError(t, err)
+
+	_, err = s.AssignRole(ctx, "admin-1", "user-1", "role-auditor", RoleAssignmentRequest{})
+	assert.ErrorIs(t, err, ErrSeparationOfDuties, "admins are bound by exclusions too")
+
+	roles, err := s.UserRoles(ctx, "admin-1", "user-1")
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "analyst", roles[0].RoleName)
+
+	assert.ErrorIs(t, s.CheckRoles(ctx, []string{"viewer", "auditor", "analyst"}), ErrSeparationOfDuties)
+	assert.NoError(t, s.CheckRoles(ctx, []string{"viewer", "analyst"}))
+}
+
+func TestRoleGovernanceService_DelegatedScope(t *testing.T) {
+	ctx := context.Background()
+	s, governance, _ := newTestGovernanceService(t)
+
+	_, err := s.AssignRole(ctx, "lead-1", "user-1", "role-analyst", RoleAssignmentRequest{})
+	require.NoError(t, err, "team leads may grant the role delegated to them")
+	assert.Len(t, governance.assignments["user-1"], 1)
+
+	_, err = s.AssignRole(ctx, "lead-1", "user-1", "role-approver", RoleAssignmentRequest{})
+	assert.ErrorIs(t, err, ErrForbidden, "approver is not delegated to team leads")
+
+	_, err = s.AssignRole(ctx, "lead-1", "lead-1", "role-analyst", RoleAssignmentRequest{})
+	assert.ErrorIs(t, err, ErrForbidden, "delegates cannot change their own roles")
+
+	_, err = s.AssignRole(ctx, "user-1", "admin-1", "role-analyst", RoleAssignmentRequest{})
+	assert.ErrorIs(t, err, ErrForbidden, "viewers hold no delegation")
+
+	assert.Len(t, governance.assignments["user-1"], 1)
+	assert.Empty(t, governance.assignments["lead-1"])
+	assert.Empty(t, governance.assignments["admin-1"])
+}
+
+func TestRoleGovernanceService_UserRolesVisibility(t *testing.T) {
+	ctx := context.Background()
+	s, _, _ := newTestGovernanceService(t)
+	_, err := s.AssignRole(ctx, "admin-1", "user-1", "role-analyst", RoleAssignmentRequest{})
+	require.NoError(t, err)
+
+	for _, actor := range []string{"user-1", "admin-1", "lead-1"} {
+		roles, err := s.UserRoles(ctx, actor, "user-1")
+		require.NoError(t, err, actor)
+		assert.Len(t, roles, 1, actor)
+	}
+
+	_, err = s.UserRoles(ctx, "user-1", "lead-1")
+	assert.ErrorIs(t, err, ErrForbidden, "viewers see only their own roles")
+	_, err = s.UserRoles(ctx, "user-1", "missing")
+	assert.ErrorIs(t, err, ErrForbidden, "nor learn which users exist")
+	_, err = s.UserRoles(ctx, "gone-1", "gone-1")
+	assert.ErrorIs(t, err, ErrForbidden, "unknown actors see nothing")
+}
+
+func TestRoleGovernanceService_Expiry(t *testing.T) {
+	ctx := context.Background()
+	s, _, now := newTestGovernanceService(t)
+
+	past := now.Add(-time.Minute)
+	_, err := s.AssignRole(ctx, "admin-1", "user-1", "role-analyst", RoleAssignmentRequest{ExpiresAt: &past})
+	assert.ErrorIs(t, err, ErrInvalidInput)
+
+	expiresAt := now.Add(time.Hour)
+	_, err = s.AssignRole(ctx, "admin-1", "user-1", "role-analyst", RoleAssignmentRequest{ExpiresAt: &expiresAt})
+	require.NoError(t, err)
+	_, err = s.AssignRole(ctx, "admin-1", "user-1", "role-approver", RoleAssignmentRequest{})
+	require.NoError(t, err)
+
+	n, err := s.ExpireAssignments(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, n, "nothing has lapsed yet")
+
+	*now = now.Add(time.Hour)
+	n, err = s.ExpireAssignments(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	roles, err := s.UserRoles(ctx, "admin-1", "user-1")
+	require.NoError(t, err)
+	require.Len(t, roles, 1, "permanent assignments survive the sweep")
+	assert.Equal(t, "approver", roles[0].RoleName)
+}
@@ -0,0 +1,518 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"atlas-core-api/pkg/safehttp"
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/settings"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+)
+
+var (
+	ErrUnknownSetting  = errors.New("unknown setting")
+	ErrSettingReadOnly = errors.New("setting is read-only")
+)
+
+// Setting value sources, from lowest to highest precedence
+const (
+	SettingSourceDefault = "default"
+	SettingSourceSystem  = "system"
+	SettingSourceUser    = "user"
+)
+
+var (
+	notificationTypes    = []string{"alert", "info", "warning", "success", "task", "mention", "system", "report_ready", "compliance", "threat", "risk_change"}
+	notificationChannels = []string{"in_app", "email", "sms", "push", "webhook", "slack"}
+	notificationPriority = []string{"low", "normal", "high", "urgent"}
+	clockPattern         = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+)
+
+// SettingsValidationError lists every setting a change was refused for,
+// keyed by "<category>.<key>"
+type SettingsValidationError struct {
+	Errors map[string]string
+}
+
+func (e *SettingsValidationError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + ": " + e.Errors[name]
+	}
+	return "invalid settings: " + strings.Join(parts, "; ")
+}
+
+func (e *SettingsValidationError) Unwrap() error {
+	return ErrInvalidInput
+}
+
+// UserSettingsPatch maps category to key to new value. A null value resets
+// the setting to its default.
+type UserSettingsPatch map[string]map[string]json.RawMessage
+
+// EffectiveSetting is the value a user sees and where it came from
+type EffectiveSetting struct {
+	Category  string          `json:"category"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	Source    string          `json:"source"`
+	UpdatedAt *time.Time      `json:"updated_at,omitempty"`
+}
+
+// SettingDefinition describes a user setting with its current default
+type SettingDefinition struct {
+	settings.Definition
+	DefaultSource string `json:"default_source"`
+}
+
+// NotificationPreferenceRequest sets delivery of one notification type on
+// one channel. Omitted is_enabled means enabled. Target is the URL the
+// webhook and slack channels post to; other channels take no target.
+type NotificationPreferenceRequest struct {
+	NotificationType string `json:"notification_type" binding:"required"`
+	Channel          string `json:"channel" binding:"required"`
+	Enabled          *bool  `json:"is_enabled"`
+	QuietHoursStart  string `json:"quiet_hours_start"`
+	QuietHoursEnd    string `json:"quiet_hours_end"`
+	MinPriority      string `json:"min_priority"`
+	Target           string `json:"target"`
+}
+
+// SystemSettingRequest sets a system setting
+type SystemSettingRequest struct {
+	Value       json.RawMessage `json:"value" binding:"required"`
+	Description string          `json:"description"`
+}
+
+// SettingsService serves user settings, notification preferences and system
+// configuration. Every key is declared with a JSON schema in the settings
+// registry and values are validated before they are stored. A user's
+// effective value is their own, else the organisation default from the
+// user_defaults system namespace, else the built-in default.
+type SettingsService struct {
+	userRepo repository.UserRepository
+	settings repository.SettingsRepository
+	logger   *zap.Logger
+
+	allowInsecureTargets bool
+}
+
+func NewSettingsService(userRepo repository.UserRepository, settingsRepo repository.SettingsRepository, logger *zap.Logger) *SettingsService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &SettingsService{
+		userRepo: userRepo,
+		settings: settingsRepo,
+		logger:   logger,
+	}
+}
+
+// WithInsecureTargets allows http notification targets, for local
+// development
+func (s *SettingsService) WithInsecureTargets(allow bool) *SettingsService {
+	s.allowInsecureTargets = allow
+	return s
+}
+
+// Definitions lists every user setting with its schema and current default
+func (s *SettingsService) Definitions(ctx context.Context) ([]*SettingDefinition, error) {
+	defaults, err := s.userDefaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := settings.UserDefinitions()
+	result := make([]*SettingDefinition, 0, len(defs))
+	for _, d := range defs {
+		def := &SettingDefinition{Definition: d, DefaultSource: SettingSourceDefault}
+		if value, ok := defaults[d.Name()]; ok {
+			def.Default = value
+			def.DefaultSource = SettingSourceSystem
+		}
+		result = append(result, def)
+	}
+	return result, nil
+}
+
+// UserSettings returns the effective value of every user setting, optionally
+// for one category
+func (s *SettingsService) UserSettings(ctx context.Context, userID, category string) ([]*EffectiveSetting, error) {
+	if category != "" && !settings.Category(category).Valid() {
+		return nil, fmt.Errorf("%w: unknown category %q", ErrInvalidInput, category)
+	}
+
+	defaults, err := s.userDefaults(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := s.settings.UserSettings(ctx, userID)
+	if err != nil {
+		return nil, mapSettingsError(err)
+	}
+
+	overrides := make(map[string]*models.UserSetting, len(stored))
+	for _, us := range stored {
+		overrides[us.Category+"."+us.Key] = us
+	}
+
+	var result []*EffectiveSetting
+	for _, d := range settings.UserDefinitions() {
+		if category != "" && string(d.Category) != category {
+			continue
+		}
+		effective := &EffectiveSetting{Category: string(d.Category), Key: d.Key, Value: d.Default, Source: SettingSourceDefault}
+		if value, ok := defaults[d.Name()]; ok {
+			effective.Value, effective.Source = value, SettingSourceSystem
+		}
+		if us, ok := overrides[d.Name()]; ok {
+			if err := d.Schema.Validate(us.Value); err != nil {
+				// The schema tightened after the value was saved
+				s.logger.Warn("Ignoring stored setting that no longer validates",
+					zap.String("user_id", userID),
+					zap.String("setting", d.Name()),
+					zap.Error(err),
+				)
+			} else {
+				updatedAt := us.UpdatedAt
+				effective.Value, effective.Source, effective.UpdatedAt = us.Value, SettingSourceUser, &updatedAt
+			}
+		}
+		result = append(result, effective)
+	}
+	return result, nil
+}
+
+// UpdateUserSettings validates every value in patch and applies them
+// together, or none of them
+func (s *SettingsService) UpdateUserSettings(ctx context.Context, actorID, userID string, patch UserSettingsPatch) ([]*EffectiveSetting, error) {
+	if len(patch) == 0 {
+		return nil, fmt.Errorf("%w: no settings given", ErrInvalidInput)
+	}
+
+	invalid := map[string]string{}
+	var values []*models.UserSetting
+	for category, keys := range patch {
+		for key, value := range keys {
+			name := category + "." + key
+			def, ok := settings.LookupUser(settings.Category(category), key)
+			if !ok {
+				invalid[name] = ErrUnknownSetting.Error()
+				continue
+			}
+			if isJSONNull(value) {
+				values = append(values, &models.UserSetting{Category: category, Key: key})
+				continue
+			}
+			if err := def.Schema.Validate(value); err != nil {
+				invalid[name] = err.Error()
+				continue
+			}
+			values = append(values, &models.UserSetting{Category: category, Key: key, Value: value})
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, &SettingsValidationError{Errors: invalid}
+	}
+
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Category != values[j].Category {
+			return values[i].Category < values[j].Category
+		}
+		return values[i].Key < values[j].Key
+	})
+	if err := s.settings.SaveUserSettings(ctx, userID, actorID, values); err != nil {
+		return nil, mapSettingsError(err)
+	}
+	s.logger.Info("User settings updated",
+		zap.String("user_id", userID),
+		zap.String("changed_by", actorID),
+		zap.Int("count", len(values)),
+	)
+	return s.UserSettings(ctx, userID, "")
+}
+
+// ResetUserSetting removes a user's override so the default applies again
+func (s *SettingsService) ResetUserSetting(ctx context.Context, actorID, userID, category, key string) error {
+	if _, ok := settings.LookupUser(settings.Category(category), key); !ok {
+		return ErrUnknownSetting
+	}
+	return mapSettingsError(s.settings.SaveUserSettings(ctx, userID, actorID, []*models.UserSetting{{Category: category, Key: key}}))
+}
+
+// UserHistory returns changes to a user's settings and notification
+// preferences, newest first
+func (s *SettingsService) UserHistory(ctx context.Context, userID string, limit int) ([]*models.SettingChange, error) {
+	return s.settings.History(ctx, repository.SettingChangeFilter{UserID: userID, Limit: limit})
+}
+
+// NotificationPreferences returns a user's stored preferences. Types and
+// channels without a preference are delivered at every priority.
+func (s *SettingsService) NotificationPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error) {
+	prefs, err := s.settings.NotificationPreferences(ctx, userID)
+	return prefs, mapSettingsError(err)
+}
+
+// UpdateNotificationPreferences validates and upserts preferences together
+func (s *SettingsService) UpdateNotificationPreferences(ctx context.Context, actorID, userID string, reqs []NotificationPreferenceRequest) ([]*models.NotificationPreference, error) {
+	if len(reqs) == 0 {
+		return nil, fmt.Errorf("%w: no preferences given", ErrInvalidInput)
+	}
+
+	invalid := map[string]string{}
+	seen := map[string]bool{}
+	prefs := make([]*models.NotificationPreference, 0, len(reqs))
+	for _, req := range reqs {
+		name := req.NotificationType + "." + req.Channel
+		if seen[name] {
+			invalid[name] = "given more than once"
+			continue
+		}
+		seen[name] = true
+
+		pref, err := notificationPreference(req, s.allowInsecureTargets)
+		if err != nil {
+			invalid[name] = err.Error()
+			continue
+		}
+		prefs = append(prefs, pref)
+	}
+	if len(invalid) > 0 {
+		return nil, &SettingsValidationError{Errors: invalid}
+	}
+
+	if err := s.settings.SaveNotificationPreferences(ctx, userID, actorID, prefs); err != nil {
+		return nil, mapSettingsError(err)
+	}
+	s.logger.Info("Notification preferences updated",
+		zap.String("user_id", userID),
+		zap.String("changed_by", actorID),
+		zap.Int("count", len(prefs)),
+	)
+	return s.NotificationPreferences(ctx, userID)
+}
+
+// SystemSettings returns system configuration, optionally for one namespace.
+// Sensitive values are never returned, and only admins see that they exist.
+func (s *SettingsService) SystemSettings(ctx context.Context, actorID, namespace string) ([]*models.SystemSetting, error) {
+	isAdmin, err := s.isAdmin(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.settings.SystemSettings(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.SystemSetting, 0, len(stored))
+	for _, setting := range stored {
+		if setting.Sensitive {
+			if !isAdmin {
+				continue
+			}
+			setting.Value = nil
+		}
+		result = append(result, setting)
+	}
+	return result, nil
+}
+
+// UpdateSystemSetting sets a registered system setting (admin only)
+func (s *SettingsService) UpdateSystemSetting(ctx context.Context, actorID, namespace, key string, req SystemSettingRequest) (*models.SystemSetting, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+
+	def, ok := settings.LookupSystem(namespace, key)
+	if !ok {
+		return nil, ErrUnknownSetting
+	}
+	if err := def.Schema.Validate(req.Value); err != nil {
+		return nil, &SettingsValidationError{Errors: map[string]string{namespace + "." + key: err.Error()}}
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if description == "" {
+		description = def.Description
+	}
+	setting := &models.SystemSetting{
+		Namespace:   namespace,
+		Key:         key,
+		Value:       req.Value,
+		Description: description,
+		UpdatedBy:   actorID,
+	}
+	if err := s.settings.SaveSystemSetting(ctx, setting); err != nil {
+		return nil, mapSettingsError(err)
+	}
+	s.logger.Info("System setting updated",
+		zap.String("namespace", namespace),
+		zap.String("key", key),
+		zap.String("updated_by", actorID),
+	)
+	if setting.Sensitive {
+		setting.Value = nil
+	}
+	return setting, nil
+}
+
+// SystemHistory returns changes to system settings, newest first (admin only)
+func (s *SettingsService) SystemHistory(ctx context.Context, actorID, namespace string, limit int) ([]*models.SettingChange, error) {
+	if err := s.requireAdmin(actorID); err != nil {
+		return nil, err
+	}
+	return s.settings.History(ctx, repository.SettingChangeFilter{
+		Scope:    models.SettingScopeSystem,
+		Category: namespace,
+		Limit:    limit,
+	})
+}
+
+// GroupSettings arranges effective settings as category to key to value
+func GroupSettings(effective []*EffectiveSetting) map[string]map[string]json.RawMessage {
+	grouped := make(map[string]map[string]json.RawMessage)
+	for _, e := range effective {
+		if grouped[e.Category] == nil {
+			grouped[e.Category] = make(map[string]json.RawMessage)
+		}
+		grouped[e.Category][e.Key] = e.Value
+	}
+	return grouped
+}
+
+// userDefaults returns the valid organisation defaults keyed by setting name
+func (s *SettingsService) userDefaults(ctx context.Context) (map[string]json.RawMessage, error) {
+	stored, err := s.settings.SystemSettings(ctx, settings.UserDefaultsNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	defaults := make(map[string]json.RawMessage, len(stored))
+	for _, setting := range stored {
+		def, ok := settings.LookupSystem(settings.UserDefaultsNamespace, setting.Key)
+		if !ok {
+			continue
+		}
+		if err := def.Schema.Validate(setting.Value); err != nil {
+			s.logger.Warn("Ignoring invalid user default", zap.String("setting", setting.Key), zap.Error(err))
+			continue
+		}
+		defaults[setting.Key] = setting.Value
+	}
+	return defaults, nil
+}
+
+func (s *SettingsService) isAdmin(actorID string) (bool, error) {
+	actor, err := s.userRepo.GetByID(actorID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return false, ErrForbidden
+		}
+		return false, err
+	}
+	return actor.Active && actor.IsAdmin(), nil
+}
+
+func (s *SettingsService) requireAdmin(actorID string) error {
+	isAdmin, err := s.isAdmin(actorID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return fmt.Errorf("%w: admin role required", ErrForbidden)
+	}
+	return nil
+}
+
+func notificationPreference(req NotificationPreferenceRequest, allowInsecureTargets bool) (*models.NotificationPreference, error) {
+	if !contains(notificationTypes, req.NotificationType) {
+		return nil, fmt.Errorf("notification_type must be one of %s", strings.Join(notificationTypes, ", "))
+	}
+	if !contains(notificationChannels, req.Channel) {
+		return nil, fmt.Errorf("channel must be one of %s", strings.Join(notificationChannels, ", "))
+	}
+
+	minPriority := req.MinPriority
+	if minPriority == "" {
+		minPriority = "low"
+	}
+	if !contains(notificationPriority, minPriority) {
+		return nil, fmt.Errorf("min_priority must be one of %s", strings.Join(notificationPriority, ", "))
+	}
+	if (req.QuietHoursStart == "") != (req.QuietHoursEnd == "") {
+		return nil, errors.New("quiet_hours_start and quiet_hours_end must be set together")
+	}
+	if req.QuietHoursStart != "" && (!clockPattern.MatchString(req.QuietHoursStart) || !clockPattern.MatchString(req.QuietHoursEnd)) {
+		return nil, errors.New("quiet hours must be HH:MM")
+	}
+	// The risk service posts to the target from inside the platform
+	// network, so it is held to the same rules as webhook subscriptions
+	switch req.Channel {
+	case "webhook", "slack":
+		if req.Target == "" {
+			return nil, fmt.Errorf("target is required for the %s channel", req.Channel)
+		}
+		if err := safehttp.ValidateURL(req.Target, allowInsecureTargets); err != nil {
+			return nil, fmt.Errorf("target: %v", err)
+		}
+	default:
+		if req.Target != "" {
+			return nil, fmt.Errorf("the %s channel does not take a target", req.Channel)
+		}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	return &models.NotificationPreference{
+		NotificationType: req.NotificationType,
+		Channel:          req.Channel,
+		Enabled:          enabled,
+		QuietHoursStart:  req.QuietHoursStart,
+		QuietHoursEnd:    req.QuietHoursEnd,
+		MinPriority:      minPriority,
+		Target:           req.Target,
+	}, nil
+}
+
+func isJSONNull(value json.RawMessage) bool {
+	return len(value) == 0 || strings.TrimSpace(string(value)) == "null"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func mapSettingsError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrUserNotFound
+	case errors.Is(err, repository.ErrSettingReadOnly):
+		return ErrSettingReadOnly
+	}
+	return err
+}
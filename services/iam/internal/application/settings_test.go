@@ -0,0 +1,45 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotificationPreference_Target(t *testing.T) {
+	tests := []struct {
+		name          string
+		channel       string
+		target        string
+		allowInsecure bool
+		wantErr       string
+	}{
+		{name: "slack webhook", channel: "slack", target: "https://hooks.slack.com/services/T0/B0/secret"},
+		{name: "webhook", channel: "webhook", target: "https://hooks.example.com/atlas"},
+		{name: "email needs none", channel: "email"},
+		{name: "missing target", channel: "webhook", wantErr: "target is required"},
+		{name: "plain http", channel: "webhook", target: "http://hooks.example.com/atlas", wantErr: "https"},
+		{name: "plain http in development", channel: "webhook", target: "http://hooks.example.com/atlas", allowInsecure: true},
+		{name: "metadata endpoint", channel: "webhook", target: "https://169.254.169.254/latest/meta-data", wantErr: "not allowed"},
+		{name: "loopback", channel: "slack", target: "https://127.0.0.1:8081/api/v1/users", wantErr: "not allowed"},
+		{name: "localhost", channel: "webhook", target: "https://localhost/hook", allowInsecure: true, wantErr: "not allowed"},
+		{name: "private network", channel: "webhook", target: "https://10.0.0.12/hook", wantErr: "not allowed"},
+		{name: "target on another channel", channel: "email", target: "someone@example.com", wantErr: "does not take a target"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pref, err := notificationPreference(NotificationPreferenceRequest{
+				NotificationType: "alert",
+				Channel:          tt.channel,
+				Target:           tt.target,
+			}, tt.allowInsecure)
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.target, pref.Target)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -18,8 +19,9 @@ var (
 )
 
 type UserService struct {
-	userRepo repository.UserRepository
-	roleRepo repository.RoleRepository
+	userRepo  repository.UserRepository
+	roleRepo  repository.RoleRepository
+	passwords *PasswordValidator
 }
 
 func NewUserService(userRepo repository.UserRepository, roleRepo repository.RoleRepository) *UserService {
@@ -29,6 +31,12 @@ func NewUserService(userRepo repository.UserRepository, roleRepo repository.Role
 	}
 }
 
+// WithPasswordValidator sets the policy applied to admin-created passwords
+func (s *UserService) WithPasswordValidator(passwords *PasswordValidator) *UserService {
+	s.passwords = passwords
+	return s
+}
+
 func (s *UserService) GetByID(id string) (*models.User, error) {
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
@@ -67,8 +75,8 @@ func (s *UserService) Create(req CreateUserRequest) (*models.User, error) {
 		return nil, fmt.Errorf("%w: username, email and password are required", ErrInvalidInput)
 	}
 
-	if len(req.Password) < 8 {
-		return nil, fmt.Errorf("%w: password must be at least 8 characters", ErrInvalidInput)
+	if err := s.passwords.Validate(context.Background(), req.Password, req.Username); err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
@@ -195,8 +203,9 @@ func (s *UserService) GetRole(id string) (*models.Role, error) {
 }
 
 type CreateRoleRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	RequiresApproval bool   `json:"requires_approval"`
 }
 
 func (s *UserService) CreateRole(req CreateRoleRequest) (*models.Role, error) {
@@ -205,8 +214,9 @@ func (s *UserService) CreateRole(req CreateRoleRequest) (*models.Role, error) {
 	}
 
 	role := &models.Role{
-		Name:        req.Name,
-		Description: req.Description,
+		Name:             req.Name,
+		Description:      req.Description,
+		RequiresApproval: req.RequiresApproval,
 	}
 
 	if err := s.roleRepo.Create(role); err != nil {
@@ -0,0 +1,605 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"atlas-core-api/pkg/safehttp"
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+	"atlas-core-api/services/iam/internal/infrastructure/webhook"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrWebhookDisabled         = errors.New("webhook subscription is disabled")
+)
+
+// Limits on what a subscription may ask for
+const (
+	defaultWebhookRetries = 3
+	maxWebhookRetries     = 10
+	defaultWebhookTimeout = 30
+	maxWebhookTimeout     = 60
+)
+
+// WebhookSubscriptionRequest subscribes a URL to platform events. A secret
+// is generated when none is given.
+type WebhookSubscriptionRequest struct {
+	Name           string   `json:"name" binding:"required"`
+	URL            string   `json:"url" binding:"required"`
+	Events         []string `json:"events" binding:"required,min=1"`
+	Secret         string   `json:"secret"`
+	RetryCount     int      `json:"retry_count"`
+	TimeoutSeconds int      `json:"timeout_seconds"`
+}
+
+// WebhookSubscriptionUpdate changes the fields that are set. Activating a
+// disabled subscription clears its failure count; RotateSecret issues a
+// new signing secret.
+type WebhookSubscriptionUpdate struct {
+	Name           *string  `json:"name"`
+	URL            *string  `json:"url"`
+	Events         []string `json:"events"`
+	IsActive       *bool    `json:"is_active"`
+	RetryCount     *int     `json:"retry_count"`
+	TimeoutSeconds *int     `json:"timeout_seconds"`
+	RotateSecret   bool     `json:"rotate_secret"`
+}
+
+// WebhookConfig tunes delivery
+type WebhookConfig struct {
+	BatchSize    int
+	PollInterval time.Duration
+	ClaimTimeout time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	// DisableAfter consecutive failed deliveries disable a subscription;
+	// zero never disables
+	DisableAfter int
+	// AllowInsecureURLs accepts http subscription URLs, for development
+	AllowInsecureURLs bool
+}
+
+// DefaultWebhookConfig returns conservative polling and backoff settings
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		BatchSize:    50,
+		PollInterval: 5 * time.Second,
+		ClaimTimeout: 2 * time.Minute,
+		BaseBackoff:  30 * time.Second,
+		MaxBackoff:   time.Hour,
+		DisableAfter: 5,
+	}
+}
+
+// WebhookService manages webhook subscriptions and delivers platform events
+// to them. Events are queued per subscription as they arrive and sent by
+// the delivery loop, signed with the subscription's secret the same way
+// the API gateway verifies signed requests. Failed attempts are retried
+// with exponential backoff; a delivery that runs out of attempts counts
+// against its subscription, which is disabled after too many in a row.
+// Subscriptions are managed by admins, and an event is only queued for a
+// subscription whose owner is still allowed to see it.
+type WebhookService struct {
+	userRepo repository.UserRepository
+	webhooks repository.WebhookRepository
+	poster   webhook.Poster
+	config   WebhookConfig
+	logger   *zap.Logger
+	now      func() time.Time
+}
+
+func NewWebhookService(
+	userRepo repository.UserRepository,
+	webhooks repository.WebhookRepository,
+	poster webhook.Poster,
+	config WebhookConfig,
+	logger *zap.Logger,
+) *WebhookService {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &WebhookService{
+		userRepo: userRepo,
+		webhooks: webhooks,
+		poster:   poster,
+		config:   config,
+		logger:   logger,
+		now:      time.Now,
+	}
+}
+
+// EventTypes lists the events that can be subscribed to
+func (s *WebhookService) EventTypes() []string {
+	return models.WebhookEventTypes
+}
+
+// Create subscribes a URL owned by actorID. The secret is only returned
+// here and when it is rotated.
+func (s *WebhookService) Create(ctx context.Context, actorID string, req WebhookSubscriptionRequest) (*models.WebhookSubscription, error) {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	sub := &models.WebhookSubscription{
+		Name:           strings.TrimSpace(req.Name),
+		URL:            strings.TrimSpace(req.URL),
+		Secret:         req.Secret,
+		Events:         req.Events,
+		OwnerID:        actorID,
+		IsActive:       true,
+		RetryCount:     req.RetryCount,
+		TimeoutSeconds: req.TimeoutSeconds,
+	}
+	if sub.RetryCount == 0 {
+		sub.RetryCount = defaultWebhookRetries
+	}
+	if sub.TimeoutSeconds == 0 {
+		sub.TimeoutSeconds = defaultWebhookTimeout
+	}
+	if sub.Secret == "" {
+		secret, err := newWebhookSecret()
+		if err != nil {
+			return nil, err
+		}
+		sub.Secret = secret
+	}
+	if err := s.validateSubscription(sub); err != nil {
+		return nil, err
+	}
+	if err := checkWebhookEvents(actor, sub.Events); err != nil {
+		return nil, err
+	}
+
+	if err := s.webhooks.CreateSubscription(ctx, sub); err != nil {
+		return nil, mapWebhookError(err)
+	}
+	s.logger.Info("Webhook subscription created",
+		zap.String("subscription_id", sub.ID),
+		zap.String("owner_id", actorID),
+		zap.Strings("events", sub.Events),
+	)
+	return sub, nil
+}
+
+// List returns the actor's subscriptions, or every subscription for admins
+func (s *WebhookService) List(ctx context.Context, actorID string) ([]*models.WebhookSubscription, error) {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return nil, err
+	}
+	ownerID := actorID
+	if actor.IsAdmin() {
+		ownerID = ""
+	}
+
+	subs, err := s.webhooks.ListSubscriptions(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sub := range subs {
+		sub.Secret = ""
+	}
+	return subs, nil
+}
+
+// Get returns one subscription the actor may manage
+func (s *WebhookService) Get(ctx context.Context, actorID, id string) (*models.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+	sub.Secret = ""
+	return sub, nil
+}
+
+// Update changes a subscription the actor may manage
+func (s *WebhookService) Update(ctx context.Context, actorID, id string, req WebhookSubscriptionUpdate) (*models.WebhookSubscription, error) {
+	sub, err := s.subscription(ctx, actorID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		sub.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.URL != nil {
+		sub.URL = strings.TrimSpace(*req.URL)
+	}
+	if req.Events != nil {
+		sub.Events = req.Events
+	}
+	if req.RetryCount != nil {
+		sub.RetryCount = *req.RetryCount
+	}
+	if req.TimeoutSeconds != nil {
+		sub.TimeoutSeconds = *req.TimeoutSeconds
+	}
+	if req.IsActive != nil {
+		if *req.IsActive && !sub.IsActive {
+			sub.FailureCount = 0
+			sub.DisabledAt = nil
+			sub.DisabledReason = ""
+		}
+		sub.IsActive = *req.IsActive
+	}
+	if req.RotateSecret {
+		if sub.Secret, err = newWebhookSecret(); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.validateSubscription(sub); err != nil {
+		return nil, err
+	}
+	if req.Events != nil {
+		owner, err := s.userRepo.GetByID(sub.OwnerID)
+		if err != nil {
+			return nil, mapWebhookError(err)
+		}
+		if err := checkWebhookEvents(owner, sub.Events); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.webhooks.UpdateSubscription(ctx, sub); err != nil {
+		return nil, mapWebhookError(err)
+	}
+	if !req.RotateSecret {
+		sub.Secret = ""
+	}
+	return sub, nil
+}
+
+// Delete removes a subscription and its delivery log
+func (s *WebhookService) Delete(ctx context.Context, actorID, id string) error {
+	if _, err := s.subscription(ctx, actorID, id); err != nil {
+		return err
+	}
+	if err := s.webhooks.DeleteSubscription(ctx, id); err != nil {
+		return mapWebhookError(err)
+	}
+	s.logger.Info("Webhook subscription deleted", zap.String("subscription_id", id), zap.String("actor_id", actorID))
+	return nil
+}
+
+// Deliveries returns a page of a subscription's delivery log, newest first,
+// and the number of matching deliveries
+func (s *WebhookService) Deliveries(ctx context.Context, actorID string, filter repository.WebhookDeliveryFilter) ([]*models.WebhookDelivery, int, error) {
+	if _, err := s.subscription(ctx, actorID, filter.SubscriptionID); err != nil {
+		return nil, 0, err
+	}
+	switch filter.Status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		return nil, 0, fmt.Errorf("%w: unknown status %q", ErrInvalidInput, filter.Status)
+	}
+
+	deliveries, total, err := s.webhooks.ListDeliveries(ctx, filter)
+	if err != nil {
+		return nil, 0, mapWebhookError(err)
+	}
+	return deliveries, total, nil
+}
+
+// Redeliver queues a logged delivery to be sent again as a new delivery
+func (s *WebhookService) Redeliver(ctx context.Context, actorID, subscriptionID, deliveryID string) (*models.WebhookDelivery, error) {
+	sub, err := s.subscription(ctx, actorID, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if !sub.IsActive {
+		return nil, ErrWebhookDisabled
+	}
+	original, err := s.webhooks.GetDelivery(ctx, subscriptionID, deliveryID)
+	if err != nil {
+		return nil, mapWebhookError(err)
+	}
+
+	now := s.now()
+	delivery := &models.WebhookDelivery{
+		SubscriptionID: subscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  &now,
+		RedeliveryOf:   original.ID,
+	}
+	if _, err := s.webhooks.EnqueueDelivery(ctx, delivery); err != nil {
+		return nil, mapWebhookError(err)
+	}
+	s.logger.Info("Webhook redelivery queued",
+		zap.String("subscription_id", subscriptionID),
+		zap.String("delivery_id", delivery.ID),
+		zap.String("redelivery_of", original.ID),
+	)
+	return delivery, nil
+}
+
+// HandleEvent queues an encoded event envelope for every active
+// subscription to its type whose owner may see it. An event seen again is
+// not queued twice.
+func (s *WebhookService) HandleEvent(ctx context.Context, eventType, eventID string, payload []byte) error {
+	subs, err := s.webhooks.SubscriptionsFor(ctx, eventType)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	owners := make(map[string]bool)
+	for _, sub := range subs {
+		allowed, seen := owners[sub.OwnerID]
+		if !seen {
+			owner, err := s.userRepo.GetByID(sub.OwnerID)
+			switch {
+			case errors.Is(err, repository.ErrUserNotFound):
+			case err != nil:
+				return err
+			default:
+				allowed = models.MayReceiveWebhookEvent(owner, eventType)
+			}
+			owners[sub.OwnerID] = allowed
+		}
+		if !allowed {
+			s.logger.Debug("Webhook event withheld from subscription owner",
+				zap.String("subscription_id", sub.ID),
+				zap.String("owner_id", sub.OwnerID),
+				zap.String("event_type", eventType),
+			)
+			continue
+		}
+
+		delivery := &models.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        eventID,
+			EventType:      eventType,
+			Payload:        payload,
+			Status:         models.WebhookDeliveryPending,
+			NextAttemptAt:  &now,
+		}
+		if _, err := s.webhooks.EnqueueDelivery(ctx, delivery); err != nil && !errors.Is(err, repository.ErrWebhookNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run delivers queued events until ctx is cancelled
+func (s *WebhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.PollInterval)
+	defer ticker.Stop()
+
+	s.logger.Info("Webhook delivery started", zap.Duration("poll_interval", s.config.PollInterval))
+	for {
+		// Drain full batches back-to-back, then wait for the next tick
+		for {
+			n, err := s.DeliverDue(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.Warn("Webhook delivery pass failed", zap.Error(err))
+			}
+			if n < s.config.BatchSize || err != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Webhook delivery stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue claims and attempts one batch of due deliveries, returning how
+// many were claimed
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.webhooks.ClaimDueDeliveries(ctx, s.config.BatchSize, s.config.ClaimTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	subs := make(map[string]*models.WebhookSubscription)
+	for _, delivery := range deliveries {
+		sub, ok := subs[delivery.SubscriptionID]
+		if !ok {
+			if sub, err = s.webhooks.GetSubscription(ctx, delivery.SubscriptionID); err != nil {
+				if errors.Is(err, repository.ErrWebhookNotFound) {
+					// Deleted since the delivery was claimed
+					continue
+				}
+				return len(deliveries), err
+			}
+			subs[delivery.SubscriptionID] = sub
+		}
+		if err := s.attempt(ctx, sub, delivery); err != nil {
+			return len(deliveries), err
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome
+func (s *WebhookService) attempt(ctx context.Context, sub *models.WebhookSubscription, delivery *models.WebhookDelivery) error {
+	if !sub.IsActive {
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextAttemptAt = nil
+		delivery.ErrorMessage = ErrWebhookDisabled.Error()
+		return s.webhooks.RecordAttempt(ctx, delivery)
+	}
+
+	resp, sendErr := s.poster.Post(ctx, webhook.Request{
+		URL:        sub.URL,
+		Secret:     sub.Secret,
+		EventType:  delivery.EventType,
+		DeliveryID: delivery.ID,
+		Body:       delivery.Payload,
+		Timeout:    sub.Timeout(),
+	})
+	now := s.now()
+	delivery.Attempts++
+	delivery.ResponseStatus = resp.StatusCode
+	delivery.ResponseBody = resp.Body
+	delivery.DurationMs = int(resp.Duration / time.Millisecond)
+
+	if sendErr == nil {
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.NextAttemptAt = nil
+		delivery.ErrorMessage = ""
+		if err := s.webhooks.RecordAttempt(ctx, delivery); err != nil {
+			return err
+		}
+		_, err := s.webhooks.RecordOutcome(ctx, sub.ID, true, s.config.DisableAfter)
+		return err
+	}
+
+	delivery.ErrorMessage = sendErr.Error()
+	if delivery.Attempts < sub.RetryCount {
+		next := now.Add(s.backoff(delivery.Attempts))
+		delivery.NextAttemptAt = &next
+		s.logger.Debug("Webhook delivery attempt failed",
+			zap.String("delivery_id", delivery.ID),
+			zap.Int("attempts", delivery.Attempts),
+			zap.Time("retry_at", next),
+			zap.Error(sendErr),
+		)
+		return s.webhooks.RecordAttempt(ctx, delivery)
+	}
+
+	delivery.Status = models.WebhookDeliveryFailed
+	delivery.NextAttemptAt = nil
+	if err := s.webhooks.RecordAttempt(ctx, delivery); err != nil {
+		return err
+	}
+	s.logger.Warn("Webhook delivery failed",
+		zap.String("subscription_id", sub.ID),
+		zap.String("delivery_id", delivery.ID),
+		zap.String("event_type", delivery.EventType),
+		zap.Int("attempts", delivery.Attempts),
+		zap.Error(sendErr),
+	)
+
+	disabled, err := s.webhooks.RecordOutcome(ctx, sub.ID, false, s.config.DisableAfter)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		// Later deliveries claimed in this batch fail without being sent
+		sub.IsActive = false
+		s.logger.Warn("Webhook subscription disabled after repeated failures",
+			zap.String("subscription_id", sub.ID),
+			zap.String("owner_id", sub.OwnerID),
+			zap.Int("failed_deliveries", s.config.DisableAfter),
+		)
+	}
+	return nil
+}
+
+func (s *WebhookService) backoff(attempt int) time.Duration {
+	return doubled(s.config.BaseBackoff, attempt-1, s.config.MaxBackoff)
+}
+
+// subscription loads a subscription the actor may manage. Other users'
+// subscriptions are reported as not found.
+func (s *WebhookService) subscription(ctx context.Context, actorID, id string) (*models.WebhookSubscription, error) {
+	actor, err := s.activeUser(actorID)
+	if err != nil {
+		return nil, err
+	}
+	sub, err := s.webhooks.GetSubscription(ctx, id)
+	if err != nil {
+		return nil, mapWebhookError(err)
+	}
+	if sub.OwnerID != actorID && !actor.IsAdmin() {
+		return nil, ErrWebhookNotFound
+	}
+	return sub, nil
+}
+
+func (s *WebhookService) activeUser(userID string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrUserNotFound) {
+			return nil, ErrForbidden
+		}
+		return nil, err
+	}
+	if !user.Active {
+		return nil, ErrForbidden
+	}
+	return user, nil
+}
+
+// checkWebhookEvents refuses events the subscription owner may not see
+func checkWebhookEvents(owner *models.User, events []string) error {
+	for _, eventType := range events {
+		if !models.MayReceiveWebhookEvent(owner, eventType) {
+			return fmt.Errorf("%w: the owner may not receive %q events", ErrForbidden, eventType)
+		}
+	}
+	return nil
+}
+
+func (s *WebhookService) validateSubscription(sub *models.WebhookSubscription) error {
+	if sub.Name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidInput)
+	}
+	if err := safehttp.ValidateURL(sub.URL, s.config.AllowInsecureURLs); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidInput, err)
+	}
+	if len(sub.Events) == 0 {
+		return fmt.Errorf("%w: at least one event is required", ErrInvalidInput)
+	}
+	seen := make(map[string]bool, len(sub.Events))
+	for _, eventType := range sub.Events {
+		if !models.IsWebhookEventType(eventType) {
+			return fmt.Errorf("%w: unknown event %q", ErrInvalidInput, eventType)
+		}
+		if seen[eventType] {
+			return fmt.Errorf("%w: event %q is listed twice", ErrInvalidInput, eventType)
+		}
+		seen[eventType] = true
+	}
+	if sub.RetryCount < 1 || sub.RetryCount > maxWebhookRetries {
+		return fmt.Errorf("%w: retry_count must be between 1 and %d", ErrInvalidInput, maxWebhookRetries)
+	}
+	if sub.TimeoutSeconds < 1 || sub.TimeoutSeconds > maxWebhookTimeout {
+		return fmt.Errorf("%w: timeout_seconds must be between 1 and %d", ErrInvalidInput, maxWebhookTimeout)
+	}
+	if len(sub.Secret) < 16 {
+		return fmt.Errorf("%w: secret must be at least 16 characters", ErrInvalidInput)
+	}
+	return nil
+}
+
+func newWebhookSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+func mapWebhookError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrWebhookNotFound):
+		return ErrWebhookNotFound
+	case errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		return ErrWebhookDeliveryNotFound
+	case errors.Is(err, repository.ErrUserNotFound):
+		return ErrUserNotFound
+	default:
+		return err
+	}
+}
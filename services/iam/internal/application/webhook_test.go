@@ -0,0 +1,274 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/infrastructure/repository"
+	"atlas-core-api/services/iam/internal/infrastructure/webhook"
+)
+
+// fakeWebhooks keeps subscriptions and deliveries in memory
+type fakeWebhooks struct {
+	repository.WebhookRepository
+	subs       map[string]*models.WebhookSubscription
+	deliveries []*models.WebhookDelivery
+	now        func() time.Time
+}
+
+func newFakeWebhooks(now func() time.Time, subs ...*models.WebhookSubscription) *fakeWebhooks {
+	r := &fakeWebhooks{subs: make(map[string]*models.WebhookSubscription), now: now}
+	for _, sub := range subs {
+		r.subs[sub.ID] = sub
+	}
+	return r
+}
+
+func (r *fakeWebhooks) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	sub, ok := r.subs[id]
+	if !ok {
+		return nil, repository.ErrWebhookNotFound
+	}
+	c := *sub
+	return &c, nil
+}
+
+func (r *fakeWebhooks) SubscriptionsFor(ctx context.Context, eventType string) ([]*models.WebhookSubscription, error) {
+	var subs []*models.WebhookSubscription
+	for _, sub := range r.subs {
+		if sub.IsActive && sub.Subscribes(eventType) {
+			c := *sub
+			subs = append(subs, &c)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].ID < subs[j].ID })
+	return subs, nil
+}
+
+func (r *fakeWebhooks) RecordOutcome(ctx context.Context, subscriptionID string, delivered bool, disableAfter int) (bool, error) {
+	sub := r.subs[subscriptionID]
+	if delivered {
+		sub.FailureCount = 0
+		return false, nil
+	}
+	sub.FailureCount++
+	if disableAfter > 0 && sub.IsActive && sub.FailureCount >= disableAfter {
+		sub.IsActive = false
+		return true, nil
+	}
+	return false, nil
+}
+
+func (r *fakeWebhooks) EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	delivery.ID = fmt.Sprintf("delivery-%d", len(r.deliveries)+1)
+	c := *delivery
+	r.deliveries = append(r.deliveries, &c)
+	return true, nil
+}
+
+func (r *fakeWebhooks) ClaimDueDeliveries(ctx context.Context, limit int, claimFor time.Duration) ([]*models.WebhookDelivery, error) {
+	var due []*models.WebhookDelivery
+	for _, delivery := range r.deliveries {
+		if delivery.Status == models.WebhookDeliveryPending && !delivery.NextAttemptAt.After(r.now()) && len(due) < limit {
+			c := *delivery
+			due = append(due, &c)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeWebhooks) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	for i, stored := range r.deliveries {
+		if stored.ID == delivery.ID {
+			c := *delivery
+			r.deliveries[i] = &c
+			return nil
+		}
+	}
+	return repository.ErrWebhookDeliveryNotFound
+}
+
+func (r *fakeWebhooks) GetDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error) {
+	for _, delivery := range r.deliveries {
+		if delivery.ID == id && delivery.SubscriptionID == subscriptionID {
+			c := *delivery
+			return &c, nil
+		}
+	}
+	return nil, repository.ErrWebhookDeliveryNotFound
+}
+
+// fakePoster answers every attempt with err and counts the attempts
+type fakePoster struct {
+	err   error
+	posts []webhook.Request
+}
+
+func (p *fakePoster) Post(ctx context.Context, req webhook.Request) (webhook.Response, error) {
+	p.posts = append(p.posts, req)
+	if p.err != nil {
+		return webhook.Response{StatusCode: 503}, p.err
+	}
+	return webhook.Response{StatusCode: 204}, nil
+}
+
+var (
+	webhookAdmin   = &models.User{ID: "admin-1", Roles: []string{"admin"}, Active: true}
+	webhookAnalyst = &models.User{ID: "analyst-1", Roles: []string{"analyst"}, Active: true}
+)
+
+func newTestSubscription(id, ownerID string, retries int, events ...string) *models.WebhookSubscription {
+	return &models.WebhookSubscription{
+		ID:             id,
+		Name:           id,
+		URL:            "https://hooks.example.com/" + id,
+		Secret:         "whsec_test_secret_value",
+		Events:         events,
+		OwnerID:        ownerID,
+		IsActive:       true,
+		RetryCount:     retries,
+		TimeoutSeconds: 5,
+	}
+}
+
+func newTestWebhookService(clock *time.Time, poster webhook.Poster, config WebhookConfig, subs ...*models.WebhookSubscription) (*WebhookService, *fakeWebhooks) {
+	now := func() time.Time { return *clock }
+	webhooks := newFakeWebhooks(now, subs...)
+	s := NewWebhookService(newFakeUsers(webhookAdmin, webhookAnalyst), webhooks, poster, config, nil)
+	s.now = now
+	return s, webhooks
+}
+
+func TestWebhookService_RetriesWithExponentialBackoff(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultWebhookConfig()
+	config.BaseBackoff = 30 * time.Second
+	config.MaxBackoff = 90 * time.Second
+	poster := &fakePoster{err: errors.New("endpoint responded with status 503")}
+	s, webhooks := newTestWebhookService(&clock, poster, config,
+		newTestSubscription("sub-1", webhookAdmin.ID, 5, "atlas.risk.assessed"))
+
+	require.NoError(t, s.HandleEvent(ctx, "atlas.risk.assessed", "event-1", []byte(`{}`)))
+
+	// 30s, 60s, then capped at MaxBackoff
+	for attempt, wantDelay := range []time.Duration{30 * time.Second, 60 * time.Second, 90 * time.Second, 90 * time.Second} {
+		n, err := s.DeliverDue(ctx)
+		require.NoError(t, err)
+		require.Equal(t, 1, n, "attempt %d", attempt+1)
+
+		delivery := webhooks.deliveries[0]
+		assert.Equal(t, attempt+1, delivery.Attempts)
+		assert.Equal(t, models.WebhookDeliveryPending, delivery.Status)
+		require.NotNil(t, delivery.NextAttemptAt)
+		assert.Equal(t, wantDelay, delivery.NextAttemptAt.Sub(clock), "attempt %d", attempt+1)
+
+		// Nothing is due until the backoff has passed
+		n, err = s.DeliverDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, n)
+		clock = *delivery.NextAttemptAt
+	}
+
+	// The fifth attempt is the last one the subscription allows
+	_, err := s.DeliverDue(ctx)
+	require.NoError(t, err)
+	delivery := webhooks.deliveries[0]
+	assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	assert.Nil(t, delivery.NextAttemptAt)
+	assert.Len(t, poster.posts, 5)
+}
+
+func TestWebhookService_DisablesAfterRepeatedFailures(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	config := DefaultWebhookConfig()
+	config.DisableAfter = 2
+	poster := &fakePoster{err: errors.New("connection refused")}
+	s, webhooks := newTestWebhookService(&clock, poster, config,
+		newTestSubscription("sub-1", webhookAdmin.ID, 1, "atlas.risk.assessed"))
+
+	for _, eventID := range []string{"event-1", "event-2", "event-3"} {
+		require.NoError(t, s.HandleEvent(ctx, "atlas.risk.assessed", eventID, []byte(`{}`)))
+	}
+	_, err := s.DeliverDue(ctx)
+	require.NoError(t, err)
+
+	assert.False(t, webhooks.subs["sub-1"].IsActive)
+	// The third delivery in the batch fails without being sent
+	assert.Len(t, poster.posts, 2)
+	for _, delivery := range webhooks.deliveries {
+		assert.Equal(t, models.WebhookDeliveryFailed, delivery.Status)
+	}
+	assert.Equal(t, ErrWebhookDisabled.Error(), webhooks.deliveries[2].ErrorMessage)
+
+	// Disabled subscriptions are not queued for new events
+	require.NoError(t, s.HandleEvent(ctx, "atlas.risk.assessed", "event-4", []byte(`{}`)))
+	assert.Len(t, webhooks.deliveries, 3)
+}
+
+func TestWebhookService_HandleEventFiltersByTypeAndOwner(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, webhooks := newTestWebhookService(&clock, &fakePoster{}, DefaultWebhookConfig(),
+		newTestSubscription("admin-logins", webhookAdmin.ID, 3, "atlas.user.login_failed"),
+		// Subscribed while its owner was an admin
+		newTestSubscription("analyst-logins", webhookAnalyst.ID, 3, "atlas.user.login_failed", "atlas.risk.assessed"),
+		newTestSubscription("analyst-alerts", webhookAnalyst.ID, 3, "atlas.alert.triggered"),
+	)
+
+	require.NoError(t, s.HandleEvent(ctx, "atlas.user.login_failed", "event-1", []byte(`{}`)))
+	require.Len(t, webhooks.deliveries, 1)
+	assert.Equal(t, "admin-logins", webhooks.deliveries[0].SubscriptionID)
+
+	require.NoError(t, s.HandleEvent(ctx, "atlas.risk.assessed", "event-2", []byte(`{}`)))
+	require.Len(t, webhooks.deliveries, 2)
+	assert.Equal(t, "analyst-logins", webhooks.deliveries[1].SubscriptionID)
+	assert.Equal(t, "atlas.risk.assessed", webhooks.deliveries[1].EventType)
+}
+
+func TestWebhookService_CreateRefusesEventsTheOwnerMayNotSee(t *testing.T) {
+	clock := time.Now()
+	s, _ := newTestWebhookService(&clock, &fakePoster{}, DefaultWebhookConfig())
+
+	_, err := s.Create(context.Background(), webhookAnalyst.ID, WebhookSubscriptionRequest{
+		Name:   "logins",
+		URL:    "https://hooks.example.com/logins",
+		Events: []string{"atlas.user.login_failed"},
+	})
+	assert.ErrorIs(t, err, ErrForbidden)
+}
+
+func TestWebhookService_RedeliverRefusesOtherUsersDeliveries(t *testing.T) {
+	ctx := context.Background()
+	clock := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	s, webhooks := newTestWebhookService(&clock, &fakePoster{}, DefaultWebhookConfig(),
+		newTestSubscription("admin-sub", webhookAdmin.ID, 3, "atlas.risk.assessed"),
+		newTestSubscription("analyst-sub", webhookAnalyst.ID, 3, "atlas.risk.assessed"),
+	)
+	require.NoError(t, s.HandleEvent(ctx, "atlas.risk.assessed", "event-1", []byte(`{}`)))
+	require.Len(t, webhooks.deliveries, 2)
+	adminDelivery := webhooks.deliveries[0]
+	require.Equal(t, "admin-sub", adminDelivery.SubscriptionID)
+
+	// Through the owner's subscription
+	_, err := s.Redeliver(ctx, webhookAnalyst.ID, "admin-sub", adminDelivery.ID)
+	assert.ErrorIs(t, err, ErrWebhookNotFound)
+
+	// Through the actor's own subscription
+	_, err = s.Redeliver(ctx, webhookAnalyst.ID, "analyst-sub", adminDelivery.ID)
+	assert.ErrorIs(t, err, ErrWebhookDeliveryNotFound)
+	assert.Len(t, webhooks.deliveries, 2)
+
+	delivery, err := s.Redeliver(ctx, webhookAdmin.ID, "admin-sub", adminDelivery.ID)
+	require.NoError(t, err)
+	assert.Equal(t, adminDelivery.ID, delivery.RedeliveryOf)
+}
@@ -26,6 +26,12 @@ type UserAggregate struct {
 	createdAt    time.Time
 	updatedAt    time.Time
 
+	passwordChangedAt *time.Time
+
+	// version is the persisted revision used for optimistic concurrency;
+	// zero until the aggregate has been saved
+	version int
+
 	domainEvents []events.DomainEvent
 }
 
@@ -44,6 +50,8 @@ func NewUserAggregate(username string, email valueobjects.Email, password valueo
 		roles:        []string{"viewer"},
 		createdAt:    now,
 		updatedAt:    now,
+
+		passwordChangedAt: &now,
 	}
 
 	user.addEvent(events.UserCreated{
@@ -70,6 +78,8 @@ func ReconstructUserAggregate(
 	active, verified, mfaEnabled bool,
 	lastLoginAt *time.Time,
 	createdAt, updatedAt time.Time,
+	passwordChangedAt *time.Time,
+	version int,
 ) *UserAggregate {
 	emailVO, _ := valueobjects.NewEmail(email)
 	userID, _ := valueobjects.UserIDFromString(id)
@@ -89,6 +99,9 @@ func ReconstructUserAggregate(
 		lastLoginAt:  lastLoginAt,
 		createdAt:    createdAt,
 		updatedAt:    updatedAt,
+
+		passwordChangedAt: passwordChangedAt,
+		version:           version,
 	}
 }
 
@@ -96,6 +109,18 @@ func (u *UserAggregate) VerifyPassword(plaintext string) bool {
 	return u.passwordHash.Verify(plaintext)
 }
 
+// PasswordExpired reports whether the current password has outlived the policy
+func (u *UserAggregate) PasswordExpired(policy valueobjects.PasswordPolicy, now time.Time) bool {
+	return policy.IsExpired(u.passwordChangedAt, now)
+}
+
+// UpdateProfile sets the display name fields
+func (u *UserAggregate) UpdateProfile(firstName, lastName string) {
+	u.firstName = firstName
+	u.lastName = lastName
+	u.updatedAt = time.Now()
+}
+
 func (u *UserAggregate) RecordLogin(ipAddress, userAgent string) {
 	now := time.Now()
 	u.lastLoginAt = &now
@@ -198,7 +223,7 @@ func (u *UserAggregate) IsAdmin() bool  { return u.HasRole("admin") }
 func (u *UserAggregate) IsActive() bool { return u.active }
 
 // Getters
-func (u *UserAggregate) ID() valueobjects.UserID  { return u.id }
+func (u *UserAggregate) ID() valueobjects.UserID   { return u.id }
 func (u *UserAggregate) Username() string          { return u.username }
 func (u *UserAggregate) Email() valueobjects.Email { return u.email }
 func (u *UserAggregate) PasswordHash() string      { return u.passwordHash.String() }
@@ -212,6 +237,14 @@ func (u *UserAggregate) MFAEnabled() bool          { return u.mfaEnabled }
 func (u *UserAggregate) LastLoginAt() *time.Time   { return u.lastLoginAt }
 func (u *UserAggregate) CreatedAt() time.Time      { return u.createdAt }
 func (u *UserAggregate) UpdatedAt() time.Time      { return u.updatedAt }
+func (u *UserAggregate) Version() int              { return u.version }
+
+func (u *UserAggregate) PasswordChangedAt() *time.Time { return u.passwordChangedAt }
+
+// SetVersion records the revision stored by the repository after a successful write
+func (u *UserAggregate) SetVersion(version int) {
+	u.version = version
+}
 
 func (u *UserAggregate) FullName() string {
 	if u.firstName == "" && u.lastName == "" {
@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// Elevation statuses
+const (
+	ElevationPending  = "pending"
+	ElevationActive   = "active"
+	ElevationRejected = "rejected"
+	ElevationExpired  = "expired"
+	ElevationEnded    = "ended"
+)
+
+// Elevation is a temporary grant of a privileged role. It is active from
+// approval (or immediately, for break-glass) until it expires or is ended.
+type Elevation struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"user_id"`
+	Username     string     `json:"username,omitempty"`
+	RoleID       string     `json:"role_id"`
+	RoleName     string     `json:"role_name"`
+	Reason       string     `json:"reason"`
+	IncidentRef  string     `json:"incident_ref,omitempty"`
+	DurationSecs int        `json:"duration_seconds"`
+	BreakGlass   bool       `json:"break_glass"`
+	Status       string     `json:"status"`
+	DecidedBy    string     `json:"decided_by,omitempty"`
+	DecisionNote string     `json:"decision_note,omitempty"`
+	DecidedAt    *time.Time `json:"decided_at,omitempty"`
+	ActivatedAt  *time.Time `json:"activated_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	EndedBy      string     `json:"ended_by,omitempty"`
+	EndedAt      *time.Time `json:"ended_at,omitempty"`
+	RequestedAt  time.Time  `json:"requested_at"`
+}
+
+// Duration is the requested length of the elevation
+func (e *Elevation) Duration() time.Duration {
+	return time.Duration(e.DurationSecs) * time.Second
+}
+
+// ElevationPolicy makes a role eligible for elevation up to MaxDuration
+type ElevationPolicy struct {
+	RoleID             string    `json:"role_id"`
+	RoleName           string    `json:"role_name"`
+	MaxDurationMinutes int       `json:"max_duration_minutes"`
+	UpdatedBy          string    `json:"updated_by,omitempty"`
+	UpdatedAt          time.Time `json:"updated_at"`
+}
+
+// MaxDuration is the longest elevation the policy allows
+func (p *ElevationPolicy) MaxDuration() time.Duration {
+	return time.Duration(p.MaxDurationMinutes) * time.Minute
+}
+
+// BreakGlassAccount may elevate without approval in an emergency
+type BreakGlassAccount struct {
+	UserID       string    `json:"user_id"`
+	Username     string    `json:"username"`
+	Reason       string    `json:"reason,omitempty"`
+	DesignatedBy string    `json:"designated_by,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
@@ -17,6 +17,7 @@ type BaseEvent struct {
 	Version     int       `json:"version"`
 }
 
+func (e BaseEvent) EventID() string       { return e.ID }
 func (e BaseEvent) EventType() string     { return e.Type }
 func (e BaseEvent) OccurredAt() time.Time { return e.Timestamp }
 func (e BaseEvent) AggregateID() string   { return e.AggregateId }
@@ -44,14 +45,18 @@ type UserLoggedOut struct {
 
 type UserRoleAssigned struct {
 	BaseEvent
-	RoleName string `json:"role_name"`
-	RoleID   string `json:"role_id"`
+	RoleName   string     `json:"role_name"`
+	RoleID     string     `json:"role_id"`
+	AssignedBy string     `json:"assigned_by,omitempty"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
 }
 
 type UserRoleRevoked struct {
 	BaseEvent
-	RoleName string `json:"role_name"`
-	RoleID   string `json:"role_id"`
+	RoleName  string `json:"role_name"`
+	RoleID    string `json:"role_id"`
+	RevokedBy string `json:"revoked_by,omitempty"`
+	Reason    string `json:"reason,omitempty"`
 }
 
 type UserDeactivated struct {
@@ -62,12 +67,38 @@ type UserDeactivated struct {
 type PasswordChanged struct {
 	BaseEvent
 	Username string `json:"username"`
+	Reason   string `json:"reason,omitempty"`
 }
 
-type LoginFailed struct {
+type UserLoginFailed struct {
 	BaseEvent
-	Username      string `json:"username"`
-	IPAddress     string `json:"ip_address"`
-	FailureReason string `json:"failure_reason"`
-	AttemptCount  int    `json:"attempt_count"`
+	Username      string     `json:"username"`
+	IPAddress     string     `json:"ip_address"`
+	FailureReason string     `json:"failure_reason"`
+	AttemptCount  int        `json:"attempt_count"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"`
+}
+
+// Privileged Access Events
+
+type ElevationRequested struct {
+	BaseEvent
+	ElevationID     string `json:"elevation_id"`
+	RoleName        string `json:"role_name"`
+	RoleID          string `json:"role_id"`
+	Reason          string `json:"reason"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// BreakGlassActivated is raised when a break-glass account elevates without
+// approval; it is always high severity
+type BreakGlassActivated struct {
+	BaseEvent
+	ElevationID string    `json:"elevation_id"`
+	RoleName    string    `json:"role_name"`
+	RoleID      string    `json:"role_id"`
+	Reason      string    `json:"reason"`
+	IncidentRef string    `json:"incident_ref,omitempty"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	Severity    string    `json:"severity"`
 }
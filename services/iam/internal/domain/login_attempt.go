@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// Lockout scopes
+const (
+	LockoutScopeUsername = "username"
+	LockoutScopeIP       = "ip"
+)
+
+// LoginAttempt is a single recorded authentication attempt
+type LoginAttempt struct {
+	ID            string    `json:"id"`
+	Username      string    `json:"username"`
+	IPAddress     string    `json:"ip_address"`
+	Success       bool      `json:"success"`
+	UserAgent     string    `json:"user_agent,omitempty"`
+	FailureReason string    `json:"failure_reason,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// AccountLockout tracks lockout state for a username or IP address
+type AccountLockout struct {
+	Scope        string     `json:"scope"`
+	Subject      string     `json:"subject"`
+	LockedUntil  *time.Time `json:"locked_until,omitempty"`
+	LockoutCount int        `json:"lockout_count"`
+	ResetAt      *time.Time `json:"reset_at,omitempty"`
+	Reason       string     `json:"reason,omitempty"`
+	UnlockedBy   string     `json:"unlocked_by,omitempty"`
+	UnlockedAt   *time.Time `json:"unlocked_at,omitempty"`
+}
+
+// IsLocked reports whether the lockout is in effect at the given time
+func (l *AccountLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && now.Before(*l.LockedUntil)
+}
@@ -1,12 +1,19 @@
 package repositories
 
-import "context"
+import (
+	"context"
+	"errors"
+)
+
+var ErrRoleNotFound = errors.New("role not found")
 
 type Role struct {
 	ID          string
 	Name        string
 	Description string
 	Permissions []string
+	// RequiresApproval means the role is only granted through an approved request
+	RequiresApproval bool
 }
 
 type RoleRepository interface {
@@ -2,10 +2,22 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"atlas-core-api/services/iam/internal/domain/aggregates"
 	"atlas-core-api/services/iam/internal/domain/valueobjects"
 )
 
+var (
+	ErrUserNotFound      = errors.New("user not found")
+	ErrUserAlreadyExists = errors.New("user already exists")
+
+	// ErrConcurrentModification means the aggregate changed since it was loaded
+	ErrConcurrentModification = errors.New("user was modified concurrently")
+)
+
+// UserRepository persists the user aggregate. Save and Update write the
+// aggregate's pending domain events atomically with its state and clear them;
+// Update fails with ErrConcurrentModification if the stored version moved on.
 type UserRepository interface {
 	FindByID(ctx context.Context, id valueobjects.UserID) (*aggregates.UserAggregate, error)
 	FindByUsername(ctx context.Context, username string) (*aggregates.UserAggregate, error)
@@ -6,8 +6,10 @@ type Role struct {
 	Name        string
 	Description string
 	Permissions []string
-	CreatedAt   string
-	UpdatedAt   string
+	// RequiresApproval routes grants of this role through a grant request
+	RequiresApproval bool
+	CreatedAt        string
+	UpdatedAt        string
 }
 
 // Permission represents a granular permission
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// Role grant request statuses
+const (
+	GrantRequestPending  = "pending"
+	GrantRequestApproved = "approved"
+	GrantRequestRejected = "rejected"
+)
+
+// RoleDelegation lets holders of the grantor role assign and revoke the
+// assignable role without being a global admin
+type RoleDelegation struct {
+	ID               string    `json:"id"`
+	GrantorRoleID    string    `json:"grantor_role_id"`
+	GrantorRole      string    `json:"grantor_role"`
+	AssignableRoleID string    `json:"assignable_role_id"`
+	AssignableRole   string    `json:"assignable_role"`
+	CreatedBy        string    `json:"created_by,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// RoleExclusion is a separation-of-duties rule: no user may hold both roles
+type RoleExclusion struct {
+	ID        string    `json:"id"`
+	RoleAID   string    `json:"role_a_id"`
+	RoleA     string    `json:"role_a"`
+	RoleBID   string    `json:"role_b_id"`
+	RoleB     string    `json:"role_b"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Other returns the role the exclusion pairs with the given role name
+func (e *RoleExclusion) Other(role string) (string, bool) {
+	switch role {
+	case e.RoleA:
+		return e.RoleB, true
+	case e.RoleB:
+		return e.RoleA, true
+	}
+	return "", false
+}
+
+// RoleAssignment is a role currently held by a user
+type RoleAssignment struct {
+	RoleID     string     `json:"role_id"`
+	RoleName   string     `json:"role_name"`
+	AssignedBy string     `json:"assigned_by,omitempty"`
+	AssignedAt time.Time  `json:"assigned_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+}
+
+// RoleGrantRequest asks for a role that requires approval to be granted
+type RoleGrantRequest struct {
+	ID             string     `json:"id"`
+	UserID         string     `json:"user_id"`
+	RoleID         string     `json:"role_id"`
+	RoleName       string     `json:"role_name"`
+	RequestedBy    string     `json:"requested_by,omitempty"`
+	Reason         string     `json:"reason,omitempty"`
+	GrantExpiresAt *time.Time `json:"grant_expires_at,omitempty"`
+	Status         string     `json:"status"`
+	DecidedBy      string     `json:"decided_by,omitempty"`
+	DecisionNote   string     `json:"decision_note,omitempty"`
+	DecidedAt      *time.Time `json:"decided_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Setting change scopes
+const (
+	SettingScopeUser         = "user"
+	SettingScopeSystem       = "system"
+	SettingScopeNotification = "notification"
+)
+
+// UserSetting is a value a user chose, overriding the default
+type UserSetting struct {
+	Category  string          `json:"category"`
+	Key       string          `json:"key"`
+	Value     json.RawMessage `json:"value"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// SystemSetting is a global configuration value. Sensitive values are never
+// returned by the API; read-only values cannot be changed through it.
+type SystemSetting struct {
+	Namespace   string          `json:"namespace"`
+	Key         string          `json:"key"`
+	Value       json.RawMessage `json:"value"`
+	Description string          `json:"description,omitempty"`
+	Sensitive   bool            `json:"is_sensitive"`
+	ReadOnly    bool            `json:"is_readonly"`
+	UpdatedBy   string          `json:"updated_by,omitempty"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+// NotificationPreference controls delivery of one notification type on one
+// channel. Quiet hours are "HH:MM" in the user's time zone.
+type NotificationPreference struct {
+	NotificationType string    `json:"notification_type"`
+	Channel          string    `json:"channel"`
+	Enabled          bool      `json:"is_enabled"`
+	QuietHoursStart  string    `json:"quiet_hours_start,omitempty"`
+	QuietHoursEnd    string    `json:"quiet_hours_end,omitempty"`
+	MinPriority      string    `json:"min_priority"`
+	Target           string    `json:"target,omitempty"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// SettingChange records one change to a user setting, system setting or
+// notification preference. Values of sensitive settings are not kept.
+type SettingChange struct {
+	ID        string          `json:"id"`
+	Scope     string          `json:"scope"`
+	UserID    string          `json:"user_id,omitempty"`
+	Category  string          `json:"category"`
+	Key       string          `json:"key"`
+	OldValue  json.RawMessage `json:"old_value,omitempty"`
+	NewValue  json.RawMessage `json:"new_value,omitempty"`
+	Redacted  bool            `json:"redacted,omitempty"`
+	ChangedBy string          `json:"changed_by,omitempty"`
+	ChangedAt time.Time       `json:"changed_at"`
+}
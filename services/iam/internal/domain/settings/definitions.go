@@ -0,0 +1,214 @@
+package settings
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// Category groups user settings
+type Category string
+
+const (
+	CategoryGeneral       Category = "general"
+	CategoryAppearance    Category = "appearance"
+	CategoryDashboard     Category = "dashboard"
+	CategoryNotifications Category = "notifications"
+	CategorySecurity      Category = "security"
+)
+
+// Categories lists the user setting categories in display order
+func Categories() []Category {
+	return []Category{CategoryGeneral, CategoryAppearance, CategoryDashboard, CategoryNotifications, CategorySecurity}
+}
+
+// Valid reports whether c is a known category
+func (c Category) Valid() bool {
+	for _, known := range Categories() {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
+
+// UserDefaultsNamespace is the system_config namespace whose keys
+// ("<category>.<key>") override the built-in defaults of user settings
+const UserDefaultsNamespace = "user_defaults"
+
+// Definition declares a setting: its schema and built-in default
+type Definition struct {
+	Category    Category        `json:"category,omitempty"`
+	Namespace   string          `json:"namespace,omitempty"`
+	Key         string          `json:"key"`
+	Description string          `json:"description"`
+	Schema      *Schema         `json:"schema"`
+	Default     json.RawMessage `json:"default"`
+}
+
+// Name is the dotted name of a user setting, as used in user_defaults
+func (d Definition) Name() string {
+	return string(d.Category) + "." + d.Key
+}
+
+var userDefinitions = []Definition{
+	{
+		Category: CategoryGeneral, Key: "language",
+		Description: "Interface language as an ISO 639-1 code with optional region",
+		Schema:      &Schema{Type: "string", Pattern: `^[a-z]{2}(-[A-Z]{2})?$`},
+		Default:     json.RawMessage(`"en"`),
+	},
+	{
+		Category: CategoryGeneral, Key: "timezone",
+		Description: "IANA time zone used to display timestamps",
+		Schema:      &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(64), Pattern: `^[A-Za-z_]+(/[A-Za-z0-9_+\-]+)*$`},
+		Default:     json.RawMessage(`"UTC"`),
+	},
+	{
+		Category: CategoryGeneral, Key: "date_format",
+		Description: "How dates are displayed",
+		Schema:      &Schema{Type: "string", Enum: []interface{}{"YYYY-MM-DD", "DD/MM/YYYY", "MM/DD/YYYY"}},
+		Default:     json.RawMessage(`"YYYY-MM-DD"`),
+	},
+	{
+		Category: CategoryAppearance, Key: "theme",
+		Description: "Colour theme",
+		Schema:      &Schema{Type: "string", Enum: []interface{}{"light", "dark", "system"}},
+		Default:     json.RawMessage(`"system"`),
+	},
+	{
+		Category: CategoryAppearance, Key: "density",
+		Description: "Spacing of tables and lists",
+		Schema:      &Schema{Type: "string", Enum: []interface{}{"comfortable", "compact"}},
+		Default:     json.RawMessage(`"comfortable"`),
+	},
+	{
+		Category: CategoryDashboard, Key: "default_view",
+		Description: "Page shown after login",
+		Schema:      &Schema{Type: "string", Enum: []interface{}{"overview", "risk", "threats", "geospatial", "compliance"}},
+		Default:     json.RawMessage(`"overview"`),
+	},
+	{
+		Category: CategoryDashboard, Key: "refresh_interval_seconds",
+		Description: "How often dashboards refresh",
+		Schema:      &Schema{Type: "integer", Minimum: floatPtr(10), Maximum: floatPtr(3600)},
+		Default:     json.RawMessage(`60`),
+	},
+	{
+		Category: CategoryDashboard, Key: "pinned_widgets",
+		Description: "Widgets pinned to the overview, in order",
+		Schema: &Schema{
+			Type:        "array",
+			Items:       &Schema{Type: "string", MinLength: intPtr(1), MaxLength: intPtr(64)},
+			MaxItems:    intPtr(20),
+			UniqueItems: true,
+		},
+		Default: json.RawMessage(`[]`),
+	},
+	{
+		Category: CategoryNotifications, Key: "email_digest",
+		Description: "How often to receive an email digest of unread notifications",
+		Schema:      &Schema{Type: "string", Enum: []interface{}{"off", "daily", "weekly"}},
+		Default:     json.RawMessage(`"daily"`),
+	},
+	{
+		Category: CategoryNotifications, Key: "sound",
+		Description: "Play a sound for urgent in-app notifications",
+		Schema:      &Schema{Type: "boolean"},
+		Default:     json.RawMessage(`true`),
+	},
+	{
+		Category: CategorySecurity, Key: "session_timeout_minutes",
+		Description: "Idle time before the web client signs out",
+		Schema:      &Schema{Type: "integer", Minimum: floatPtr(5), Maximum: floatPtr(1440)},
+		Default:     json.RawMessage(`60`),
+	},
+	{
+		Category: CategorySecurity, Key: "login_alerts",
+		Description: "Email on sign-in from a new IP address",
+		Schema:      &Schema{Type: "boolean"},
+		Default:     json.RawMessage(`true`),
+	},
+}
+
+var systemDefinitions = []Definition{
+	{
+		Namespace: "platform", Key: "maintenance_mode",
+		Description: "Show the maintenance banner and block non-admin writes in clients",
+		Schema:      &Schema{Type: "boolean"},
+		Default:     json.RawMessage(`false`),
+	},
+	{
+		Namespace: "platform", Key: "banner_message",
+		Description: "Message shown to every user at the top of the client",
+		Schema:      &Schema{Type: "string", MaxLength: intPtr(500)},
+		Default:     json.RawMessage(`""`),
+	},
+	{
+		Namespace: "platform", Key: "support_email",
+		Description: "Contact address shown in the client",
+		Schema:      &Schema{Type: "string", MaxLength: intPtr(254), Pattern: `^$|^[^@\s]+@[^@\s]+\.[^@\s]+$`},
+		Default:     json.RawMessage(`""`),
+	},
+	{
+		Namespace: "retention", Key: "notification_days",
+		Description: "Days read notifications are kept",
+		Schema:      &Schema{Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(3650)},
+		Default:     json.RawMessage(`90`),
+	},
+}
+
+// UserDefinitions returns every user setting, ordered by category and key
+func UserDefinitions() []Definition {
+	defs := append([]Definition(nil), userDefinitions...)
+	order := make(map[Category]int)
+	for i, c := range Categories() {
+		order[c] = i
+	}
+	sort.SliceStable(defs, func(i, j int) bool {
+		if defs[i].Category != defs[j].Category {
+			return order[defs[i].Category] < order[defs[j].Category]
+		}
+		return defs[i].Key < defs[j].Key
+	})
+	return defs
+}
+
+// LookupUser finds a user setting definition
+func LookupUser(category Category, key string) (Definition, bool) {
+	for _, d := range userDefinitions {
+		if d.Category == category && d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+// SystemDefinitions returns every system setting, including one user_defaults
+// entry per user setting
+func SystemDefinitions() []Definition {
+	defs := append([]Definition(nil), systemDefinitions...)
+	for _, d := range UserDefinitions() {
+		defs = append(defs, Definition{
+			Namespace:   UserDefaultsNamespace,
+			Key:         d.Name(),
+			Description: "Default for " + d.Name() + ": " + d.Description,
+			Schema:      d.Schema,
+			Default:     d.Default,
+		})
+	}
+	return defs
+}
+
+// LookupSystem finds a system setting definition
+func LookupSystem(namespace, key string) (Definition, bool) {
+	for _, d := range SystemDefinitions() {
+		if d.Namespace == namespace && d.Key == key {
+			return d, true
+		}
+	}
+	return Definition{}, false
+}
+
+func intPtr(v int) *int { return &v }
+
+func floatPtr(v float64) *float64 { return &v }
@@ -0,0 +1,214 @@
+package settings
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema used to validate setting values:
+// type, enum, numeric and length bounds, pattern, array items and object
+// properties
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+}
+
+// ValidationError reports where a value broke its schema
+type ValidationError struct {
+	Path    string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+// Validate checks a raw JSON value against the schema
+func (s *Schema) Validate(raw json.RawMessage) error {
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Message: "value is not valid JSON"}
+	}
+	if decoder.More() {
+		return &ValidationError{Message: "value must be a single JSON document"}
+	}
+	return s.validate("", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if s.Type != "" && !matchesType(s.Type, value) {
+		return &ValidationError{Path: path, Message: "must be of type " + s.Type}
+	}
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		return &ValidationError{Path: path, Message: "must be one of " + describeEnum(s.Enum)}
+	}
+
+	switch v := value.(type) {
+	case json.Number:
+		f, _ := v.Float64()
+		if s.Minimum != nil && f < *s.Minimum {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %v", *s.Minimum)}
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %v", *s.Maximum)}
+		}
+	case string:
+		length := utf8.RuneCountInString(v)
+		if s.MinLength != nil && length < *s.MinLength {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at least %d characters", *s.MinLength)}
+		}
+		if s.MaxLength != nil && length > *s.MaxLength {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must be at most %d characters", *s.MaxLength)}
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err != nil {
+				return &ValidationError{Path: path, Message: "schema pattern is invalid"}
+			}
+			if !re.MatchString(v) {
+				return &ValidationError{Path: path, Message: "must match " + s.Pattern}
+			}
+		}
+	case []interface{}:
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			return &ValidationError{Path: path, Message: fmt.Sprintf("must have at most %d items", *s.MaxItems)}
+		}
+		seen := make(map[string]bool, len(v))
+		for i, item := range v {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			if s.Items != nil {
+				if err := s.Items.validate(itemPath, item); err != nil {
+					return err
+				}
+			}
+			if s.UniqueItems {
+				key := canonical(item)
+				if seen[key] {
+					return &ValidationError{Path: itemPath, Message: "duplicates an earlier item"}
+				}
+				seen[key] = true
+			}
+		}
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Path: joinPath(path, name), Message: "is required"}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					return &ValidationError{Path: joinPath(path, name), Message: "is not allowed"}
+				}
+				continue
+			}
+			if err := prop.validate(joinPath(path, name), v[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func matchesType(schemaType string, value interface{}) bool {
+	switch schemaType {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	}
+	return false
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	key := canonical(value)
+	for _, candidate := range enum {
+		if canonical(candidate) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// canonical renders a value so that equal JSON values compare equal;
+// encoding/json sorts map keys and json.Number keeps its literal
+func canonical(value interface{}) string {
+	if n, ok := value.(json.Number); ok {
+		if f, err := n.Float64(); err == nil {
+			return fmt.Sprintf("%v", f)
+		}
+	}
+	if i, ok := value.(int); ok {
+		return fmt.Sprintf("%v", float64(i))
+	}
+	if f, ok := value.(float64); ok {
+		return fmt.Sprintf("%v", f)
+	}
+	b, _ := json.Marshal(value)
+	return string(b)
+}
+
+func describeEnum(enum []interface{}) string {
+	parts := make([]string, len(enum))
+	for i, v := range enum {
+		b, _ := json.Marshal(v)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
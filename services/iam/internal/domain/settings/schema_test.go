@@ -0,0 +1,66 @@
+package settings
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchema_Validate(t *testing.T) {
+	schema := &Schema{
+		Type:     "object",
+		Required: []string{"mode"},
+		Properties: map[string]*Schema{
+			"mode":  {Type: "string", Enum: []interface{}{"auto", "manual"}},
+			"limit": {Type: "integer", Minimum: floatPtr(1), Maximum: floatPtr(10)},
+			"tags":  {Type: "array", Items: &Schema{Type: "string", MaxLength: intPtr(3)}, UniqueItems: true},
+		},
+		AdditionalProperties: boolPtr(false),
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		valid bool
+		path  string
+	}{
+		{name: "valid", value: `{"mode":"auto","limit":3,"tags":["a","b"]}`, valid: true},
+		{name: "integral float is an integer", value: `{"mode":"auto","limit":3.0}`, valid: true},
+		{name: "wrong type", value: `"auto"`},
+		{name: "missing required", value: `{"limit":3}`, path: "mode"},
+		{name: "not in enum", value: `{"mode":"off"}`, path: "mode"},
+		{name: "not an integer", value: `{"mode":"auto","limit":2.5}`, path: "limit"},
+		{name: "above maximum", value: `{"mode":"auto","limit":11}`, path: "limit"},
+		{name: "item too long", value: `{"mode":"auto","tags":["abcd"]}`, path: "tags[0]"},
+		{name: "duplicate item", value: `{"mode":"auto","tags":["a","a"]}`, path: "tags[1]"},
+		{name: "unknown property", value: `{"mode":"auto","extra":true}`, path: "extra"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Validate(json.RawMessage(tt.value))
+			if tt.valid {
+				assert.NoError(t, err)
+				return
+			}
+
+			var invalid *ValidationError
+			require.ErrorAs(t, err, &invalid)
+			assert.Equal(t, tt.path, invalid.Path)
+		})
+	}
+}
+
+func TestDefinitions_DefaultsMatchSchemas(t *testing.T) {
+	for _, d := range SystemDefinitions() {
+		assert.NoError(t, d.Schema.Validate(d.Default), "%s.%s", d.Namespace, d.Key)
+	}
+
+	def, ok := LookupSystem(UserDefaultsNamespace, "appearance.theme")
+	require.True(t, ok)
+	assert.Error(t, def.Schema.Validate(json.RawMessage(`"neon"`)))
+}
+
+func boolPtr(v bool) *bool { return &v }
@@ -18,6 +18,8 @@ type User struct {
 	LastLoginAt  *time.Time
 	CreatedAt    string
 	UpdatedAt    string
+
+	PasswordChangedAt *time.Time
 }
 
 // FullName returns the user's full name
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Token purposes
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+)
+
+// UserToken is a single-use token issued to a user; only its hash is stored
+type UserToken struct {
+	ID          string
+	UserID      string
+	Purpose     string
+	TokenHash   string
+	ExpiresAt   time.Time
+	UsedAt      *time.Time
+	RequestedIP string
+	CreatedAt   time.Time
+}
@@ -2,7 +2,6 @@ package valueobjects
 
 import (
 	"errors"
-	"unicode"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -12,7 +11,13 @@ type HashedPassword struct {
 }
 
 func NewHashedPassword(plaintext string) (HashedPassword, error) {
-	if err := validatePasswordPolicy(plaintext); err != nil {
+	return NewHashedPasswordFor(plaintext, "")
+}
+
+// NewHashedPasswordFor validates the password against the configured policy,
+// including the username similarity check, and hashes it
+func NewHashedPasswordFor(plaintext, username string) (HashedPassword, error) {
+	if err := CurrentPasswordPolicy().Validate(plaintext, username); err != nil {
 		return HashedPassword{}, err
 	}
 	hashed, err := bcrypt.GenerateFromPassword([]byte(plaintext), bcrypt.DefaultCost)
@@ -31,24 +36,3 @@ func (p HashedPassword) Verify(plaintext string) bool {
 }
 
 func (p HashedPassword) String() string { return p.hash }
-
-func validatePasswordPolicy(password string) error {
-	if len(password) < 8 {
-		return errors.New("password must be at least 8 characters")
-	}
-	var hasUpper, hasLower, hasDigit bool
-	for _, c := range password {
-		switch {
-		case unicode.IsUpper(c):
-			hasUpper = true
-		case unicode.IsLower(c):
-			hasLower = true
-		case unicode.IsDigit(c):
-			hasDigit = true
-		}
-	}
-	if !hasUpper || !hasLower || !hasDigit {
-		return errors.New("password must contain uppercase, lowercase, and digit")
-	}
-	return nil
-}
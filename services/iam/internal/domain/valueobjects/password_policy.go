@@ -0,0 +1,190 @@
+package valueobjects
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// PasswordPolicy describes the rules a new password must satisfy
+type PasswordPolicy struct {
+	MinLength      int           `json:"min_length"`
+	MaxLength      int           `json:"max_length"`
+	RequireUpper   bool          `json:"require_upper"`
+	RequireLower   bool          `json:"require_lower"`
+	RequireDigit   bool          `json:"require_digit"`
+	RequireSpecial bool          `json:"require_special"`
+	MaxAge         time.Duration `json:"-"`
+	HistoryDepth   int           `json:"history_depth"`
+	BannedWords    []string      `json:"-"`
+
+	// MaxUsernameSimilarity rejects passwords whose edit-distance similarity to
+	// the username is at or above this ratio (0 disables the check)
+	MaxUsernameSimilarity float64 `json:"max_username_similarity"`
+}
+
+// DefaultPasswordPolicy keeps the historical 8 chars + upper/lower/digit rule
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:             8,
+		MaxLength:             128,
+		RequireUpper:          true,
+		RequireLower:          true,
+		RequireDigit:          true,
+		HistoryDepth:          5,
+		BannedWords:           []string{"password", "atlas", "welcome", "qwerty", "letmein"},
+		MaxUsernameSimilarity: 0.7,
+	}
+}
+
+// PolicyViolationError lists every rule a password failed
+type PolicyViolationError struct {
+	Violations []string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return "password does not meet policy: " + strings.Join(e.Violations, "; ")
+}
+
+// Validate checks a password against the policy. username may be empty when
+// it is not known yet.
+func (p PasswordPolicy) Validate(password, username string) error {
+	var violations []string
+
+	length := len([]rune(password))
+	if length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters", p.MaxLength))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, c := range password {
+		switch {
+		case unicode.IsUpper(c):
+			hasUpper = true
+		case unicode.IsLower(c):
+			hasLower = true
+		case unicode.IsDigit(c):
+			hasDigit = true
+		case unicode.IsPunct(c) || unicode.IsSymbol(c) || unicode.IsSpace(c):
+			hasSpecial = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSpecial && !hasSpecial {
+		violations = append(violations, "must contain a special character")
+	}
+
+	normalized := normalizeLeet(password)
+	for _, word := range p.BannedWords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" && strings.Contains(normalized, word) {
+			violations = append(violations, "must not contain common words")
+			break
+		}
+	}
+
+	if username != "" && p.MaxUsernameSimilarity > 0 && tooSimilar(normalized, strings.ToLower(username), p.MaxUsernameSimilarity) {
+		violations = append(violations, "must not be similar to the username")
+	}
+
+	if len(violations) > 0 {
+		return &PolicyViolationError{Violations: violations}
+	}
+	return nil
+}
+
+// IsExpired reports whether a password set at changedAt has outlived MaxAge
+func (p PasswordPolicy) IsExpired(changedAt *time.Time, now time.Time) bool {
+	if p.MaxAge <= 0 || changedAt == nil {
+		return false
+	}
+	return now.After(changedAt.Add(p.MaxAge))
+}
+
+var (
+	activePolicyMu sync.RWMutex
+	activePolicy   = DefaultPasswordPolicy()
+)
+
+// SetPasswordPolicy replaces the policy enforced by NewHashedPassword
+func SetPasswordPolicy(policy PasswordPolicy) {
+	activePolicyMu.Lock()
+	defer activePolicyMu.Unlock()
+	activePolicy = policy
+}
+
+// CurrentPasswordPolicy returns the policy configured at startup
+func CurrentPasswordPolicy() PasswordPolicy {
+	activePolicyMu.RLock()
+	defer activePolicyMu.RUnlock()
+	return activePolicy
+}
+
+var leetReplacer = strings.NewReplacer(
+	"0", "o", "1", "l", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s", "!", "i",
+)
+
+func normalizeLeet(s string) string {
+	return leetReplacer.Replace(strings.ToLower(s))
+}
+
+func tooSimilar(password, username string, threshold float64) bool {
+	if len(username) >= 3 {
+		if strings.Contains(password, username) || strings.Contains(password, reverse(username)) {
+			return true
+		}
+	}
+	return similarity(password, username) >= threshold
+}
+
+// similarity is 1 - levenshtein(a, b) / max(len(a), len(b))
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return 1 - float64(prev[len(rb)])/float64(longest)
+}
+
+func reverse(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
@@ -0,0 +1,72 @@
+package valueobjects
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+
+	tests := []struct {
+		name      string
+		password  string
+		username  string
+		violation string
+	}{
+		{name: "valid", password: "Blue-Horizon42", username: "jsmith"},
+		{name: "too short", password: "Ab1", violation: "must be at least 8 characters"},
+		{name: "missing upper", password: "lowercase42x", violation: "must contain an uppercase letter"},
+		{name: "missing digit", password: "NoDigitsHere", violation: "must contain a digit"},
+		{name: "banned word", password: "MyPassword123", violation: "must not contain common words"},
+		{name: "banned word leet", password: "P4ssw0rd!Xy9", violation: "must not contain common words"},
+		{name: "contains username", password: "Jsmith2024!", username: "jsmith", violation: "must not be similar to the username"},
+		{name: "reversed username", password: "Htimsj9999", username: "jsmith", violation: "must not be similar to the username"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.username)
+			if tt.violation == "" {
+				assert.NoError(t, err)
+				return
+			}
+
+			var violation *PolicyViolationError
+			require.ErrorAs(t, err, &violation)
+			assert.Contains(t, violation.Violations, tt.violation)
+		})
+	}
+}
+
+func TestPasswordPolicy_RequireSpecial(t *testing.T) {
+	policy := DefaultPasswordPolicy()
+	policy.RequireSpecial = true
+
+	assert.Error(t, policy.Validate("Horizon4242", ""))
+	assert.NoError(t, policy.Validate("Horizon#4242", ""))
+}
+
+func TestPasswordPolicy_IsExpired(t *testing.T) {
+	now := time.Now()
+	changed := now.Add(-91 * 24 * time.Hour)
+
+	policy := DefaultPasswordPolicy()
+	assert.False(t, policy.IsExpired(&changed, now), "max age disabled by default")
+
+	policy.MaxAge = 90 * 24 * time.Hour
+	assert.True(t, policy.IsExpired(&changed, now))
+	assert.False(t, policy.IsExpired(nil, now))
+
+	recent := now.Add(-time.Hour)
+	assert.False(t, policy.IsExpired(&recent, now))
+}
+
+func TestSimilarity(t *testing.T) {
+	assert.Equal(t, 1.0, similarity("atlas", "atlas"))
+	assert.InDelta(t, 0.8, similarity("atlas", "atlaz"), 0.001)
+	assert.Less(t, similarity("Blue-Horizon42", "jsmith"), 0.3)
+}
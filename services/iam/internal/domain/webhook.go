@@ -0,0 +1,155 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// WebhookEventTypes are the platform events a webhook may subscribe to. They
+// mirror the topic constants in pkg/events and must be kept in sync with it.
+var WebhookEventTypes = []string{
+	// IAM
+	"atlas.user.created",
+	"atlas.user.logged_in",
+	"atlas.user.login_failed",
+	"atlas.user.role_assigned",
+	"atlas.user.role_revoked",
+	"atlas.user.deactivated",
+	"atlas.user.password_changed",
+	"atlas.user.elevation_requested",
+	"atlas.user.break_glass_activated",
+
+	// Risk assessment
+	"atlas.risk.assessed",
+	"atlas.alert.triggered",
+	"atlas.alert.resolved",
+	"atlas.alert.acknowledged",
+	"atlas.risk.threshold_breached",
+
+	// Other services
+	"atlas.simulation.completed",
+	"atlas.osint.collected",
+	"atlas.nlp.analyzed",
+	"atlas.graph.updated",
+	"atlas.compliance.violation",
+	"atlas.ingestion.completed",
+
+	// Sanctions and trade intelligence
+	"atlas.sanctions.screened",
+	"atlas.sanctions.match_found",
+	"atlas.sanctions.list_synced",
+	"atlas.trade.restriction_found",
+	"atlas.trade.advisory_issued",
+}
+
+// IsWebhookEventType reports whether eventType can be subscribed to
+func IsWebhookEventType(eventType string) bool {
+	for _, t := range WebhookEventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEventRoles lists, by topic prefix, the roles besides admin whose
+// holders may receive an event by webhook. User lifecycle and login events
+// are for admins only.
+var webhookEventRoles = map[string][]string{
+	"atlas.risk.":       {"analyst", "operator"},
+	"atlas.alert.":      {"analyst", "operator"},
+	"atlas.simulation.": {"analyst", "operator"},
+	"atlas.osint.":      {"analyst"},
+	"atlas.nlp.":        {"analyst"},
+	"atlas.graph.":      {"analyst"},
+	"atlas.ingestion.":  {"analyst", "operator"},
+	"atlas.compliance.": {"analyst"},
+	"atlas.sanctions.":  {"analyst"},
+	"atlas.trade.":      {"analyst"},
+}
+
+// MayReceiveWebhookEvent reports whether user is allowed to see events of
+// eventType delivered to a subscription they own
+func MayReceiveWebhookEvent(user *User, eventType string) bool {
+	if !user.Active {
+		return false
+	}
+	if user.IsAdmin() {
+		return true
+	}
+	for prefix, roles := range webhookEventRoles {
+		if !strings.HasPrefix(eventType, prefix) {
+			continue
+		}
+		for _, role := range roles {
+			if user.HasRole(role) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WebhookSubscription sends the listed event types to URL, signed with
+// Secret. Each delivery is tried up to RetryCount times; the subscription
+// is disabled once FailureCount consecutive deliveries have failed.
+type WebhookSubscription struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	URL             string     `json:"url"`
+	Secret          string     `json:"secret,omitempty"`
+	Events          []string   `json:"events"`
+	OwnerID         string     `json:"owner_id"`
+	IsActive        bool       `json:"is_active"`
+	RetryCount      int        `json:"retry_count"`
+	TimeoutSeconds  int        `json:"timeout_seconds"`
+	FailureCount    int        `json:"failure_count"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	DisabledAt      *time.Time `json:"disabled_at,omitempty"`
+	DisabledReason  string     `json:"disabled_reason,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	UpdatedAt       time.Time  `json:"updated_at"`
+}
+
+// Subscribes reports whether the subscription wants events of eventType
+func (s *WebhookSubscription) Subscribes(eventType string) bool {
+	for _, t := range s.Events {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Timeout is how long one delivery attempt may take
+func (s *WebhookSubscription) Timeout() time.Duration {
+	return time.Duration(s.TimeoutSeconds) * time.Second
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// WebhookDelivery is one event sent to one subscription. The response
+// fields describe the most recent attempt.
+type WebhookDelivery struct {
+	ID             string          `json:"id"`
+	SubscriptionID string          `json:"subscription_id"`
+	EventID        string          `json:"event_id,omitempty"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	ResponseBody   string          `json:"response_body,omitempty"`
+	DurationMs     int             `json:"duration_ms,omitempty"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	RedeliveryOf   string          `json:"redelivery_of,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
@@ -0,0 +1,77 @@
+package breach
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LocalRangeSource serves SHA-1 hash ranges from a locally loaded breach corpus.
+// Lookups follow the k-anonymity range contract: callers send only the first
+// five hex characters of the hash and match the returned suffixes themselves,
+// so a remote range API can be dropped in without changing the caller.
+type LocalRangeSource struct {
+	ranges map[string]map[string]int
+	size   int
+}
+
+// LoadLocalRangeSource reads a file of uppercase or lowercase SHA-1 hashes,
+// one per line, optionally followed by ":count" as in published breach dumps.
+// Blank lines and lines starting with # are ignored.
+func LoadLocalRangeSource(path string) (*LocalRangeSource, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password list: %w", err)
+	}
+	defer f.Close()
+
+	src := &LocalRangeSource{ranges: make(map[string]map[string]int)}
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+
+		hash, countStr, hasCount := strings.Cut(entry, ":")
+		hash = strings.ToUpper(strings.TrimSpace(hash))
+		if len(hash) != 40 {
+			return nil, fmt.Errorf("breached password list line %d: expected a 40-character SHA-1 hash", line)
+		}
+
+		count := 1
+		if hasCount {
+			count, err = strconv.Atoi(strings.TrimSpace(countStr))
+			if err != nil {
+				return nil, fmt.Errorf("breached password list line %d: invalid count: %w", line, err)
+			}
+		}
+
+		prefix, suffix := hash[:5], hash[5:]
+		if src.ranges[prefix] == nil {
+			src.ranges[prefix] = make(map[string]int)
+		}
+		src.ranges[prefix][suffix] += count
+		src.size++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password list: %w", err)
+	}
+
+	return src, nil
+}
+
+// Range returns the suffixes and occurrence counts sharing the given prefix
+func (s *LocalRangeSource) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	return s.ranges[strings.ToUpper(prefix)], nil
+}
+
+// Size returns the number of hashes loaded
+func (s *LocalRangeSource) Size() int {
+	return s.size
+}
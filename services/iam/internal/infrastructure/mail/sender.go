@@ -0,0 +1,125 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers email messages
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// render builds an RFC 5322 message
+func render(from string, msg Message) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	return buf.Bytes()
+}
+
+// SMTPSender delivers mail through an SMTP relay
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, msg Message) error {
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, s.from, []string{msg.To}, render(s.from, msg))
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("smtp send failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// FileSender writes each message as an .eml file, for local development and tests
+type FileSender struct {
+	dir  string
+	from string
+}
+
+func NewFileSender(dir, from string) (*FileSender, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create outbox dir: %w", err)
+	}
+	return &FileSender{dir: dir, from: from}, nil
+}
+
+func (s *FileSender) Send(ctx context.Context, msg Message) error {
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405"), uuid.New().String())
+	return os.WriteFile(filepath.Join(s.dir, name), render(s.from, msg), 0o640)
+}
+
+// LogSender logs that a message would have been sent, for local
+// development. The body is never logged, since it can hold reset links and
+// temporary passwords, and nothing is kept.
+type LogSender struct {
+	logger *zap.Logger
+}
+
+func NewLogSender(logger *zap.Logger) *LogSender {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, msg Message) error {
+	s.logger.Info("Mail message not sent (log driver)",
+		zap.String("to", msg.To),
+		zap.String("subject", msg.Subject),
+	)
+	return nil
+}
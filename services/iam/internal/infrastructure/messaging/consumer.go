@@ -0,0 +1,116 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// EventHandler acts on an encoded event envelope read from Kafka
+type EventHandler interface {
+	HandleEvent(ctx context.Context, eventType, eventID string, payload []byte) error
+}
+
+// EventConsumer reads platform events from Kafka in a consumer group and
+// hands each one to a handler. A message the handler fails on is retried
+// before its offset is committed; handlers deduplicate on event_id, since a
+// message may still be read twice after a restart.
+type EventConsumer struct {
+	reader  *kafka.Reader
+	handler EventHandler
+	logger  *zap.Logger
+}
+
+func NewEventConsumer(brokers []string, groupID string, topics []string, handler EventHandler, logger *zap.Logger) *EventConsumer {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &EventConsumer{
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers:     brokers,
+			GroupID:     groupID,
+			GroupTopics: topics,
+			MinBytes:    1,
+			MaxBytes:    10e6,
+			MaxWait:     time.Second,
+			StartOffset: kafka.LastOffset,
+		}),
+		handler: handler,
+		logger:  logger,
+	}
+}
+
+// Run consumes until ctx is cancelled
+func (c *EventConsumer) Run(ctx context.Context) {
+	c.logger.Info("Event consumer started", zap.Strings("topics", c.reader.Config().GroupTopics))
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				c.logger.Info("Event consumer stopped")
+				return
+			}
+			c.logger.Warn("Failed to fetch event", zap.Error(err))
+			if !sleep(ctx, time.Second) {
+				return
+			}
+			continue
+		}
+
+		if !c.handle(ctx, msg) {
+			return
+		}
+		if err := c.reader.CommitMessages(ctx, msg); err != nil && ctx.Err() == nil {
+			c.logger.Warn("Failed to commit event offset", zap.String("topic", msg.Topic), zap.Error(err))
+		}
+	}
+}
+
+// handle passes a message to the handler, retrying until it is accepted.
+// It returns false if ctx is cancelled first.
+func (c *EventConsumer) handle(ctx context.Context, msg kafka.Message) bool {
+	// The envelope names the event; fall back to the topic, which is the
+	// event type by convention
+	var envelope struct {
+		EventID   string `json:"event_id"`
+		EventType string `json:"event_type"`
+	}
+	if err := json.Unmarshal(msg.Value, &envelope); err != nil {
+		c.logger.Warn("Skipping undecodable event", zap.String("topic", msg.Topic), zap.Int64("offset", msg.Offset), zap.Error(err))
+		return true
+	}
+	if envelope.EventType == "" {
+		envelope.EventType = msg.Topic
+	}
+
+	for {
+		err := c.handler.HandleEvent(ctx, envelope.EventType, envelope.EventID, msg.Value)
+		if err == nil {
+			return true
+		}
+		c.logger.Warn("Failed to handle event",
+			zap.String("topic", msg.Topic),
+			zap.String("event_id", envelope.EventID),
+			zap.Error(err),
+		)
+		if !sleep(ctx, time.Second) {
+			return false
+		}
+	}
+}
+func (c *EventConsumer) Close() error {
+	return c.reader.Close()
+}
+
+// sleep waits for d, returning false if ctx is cancelled first
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
@@ -2,14 +2,12 @@ package messaging
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"log"
 	"time"
 
-	"atlas-core-api/services/iam/internal/domain/events"
+	"github.com/segmentio/kafka-go"
 
-	"github.com/google/uuid"
+	"atlas-core-api/services/iam/internal/domain/events"
 )
 
 type EventEnvelope struct {
@@ -22,41 +20,50 @@ type EventEnvelope struct {
 	Payload     interface{} `json:"payload"`
 }
 
+// KafkaEventPublisher writes envelopes to the topic named by each event type.
+// Messages are keyed by aggregate ID so all events of one user land on the
+// same partition and keep their order.
 type KafkaEventPublisher struct {
-	brokers []string
-	topic   string
+	writer *kafka.Writer
 }
 
 func NewKafkaEventPublisher(brokers []string) *KafkaEventPublisher {
 	return &KafkaEventPublisher{
-		brokers: brokers,
-		topic:   "atlas.iam.events",
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			MaxAttempts:            3,
+			BatchTimeout:           10 * time.Millisecond,
+			WriteTimeout:           10 * time.Second,
+			AllowAutoTopicCreation: true,
+		},
 	}
 }
 
 func (p *KafkaEventPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
-	envelope := EventEnvelope{
-		EventID:     uuid.New().String(),
-		EventType:   event.EventType(),
-		AggregateID: event.AggregateID(),
-		Timestamp:   event.OccurredAt(),
-		Version:     1,
-		Source:      "iam-service",
-		Payload:     event,
+	_, topic, data, err := EncodeEvent(event)
+	if err != nil {
+		return err
 	}
+	return p.Produce(ctx, topic, event.AggregateID(), data)
+}
 
-	data, err := json.Marshal(envelope)
+// Produce writes a pre-encoded message; used by the outbox relay
+func (p *KafkaEventPublisher) Produce(ctx context.Context, topic, key string, value []byte) error {
+	err := p.writer.WriteMessages(ctx, kafka.Message{
+		Topic: topic,
+		Key:   []byte(key),
+		Value: value,
+	})
 	if err != nil {
-		return fmt.Errorf("failed to marshal event: %w", err)
+		return fmt.Errorf("failed to publish to %s: %w", topic, err)
 	}
-
-	// TODO: Replace with actual Kafka producer (segmentio/kafka-go or confluent-kafka-go)
-	log.Printf("[Kafka] Publishing to %s: %s", p.topic, string(data))
 	return nil
 }
 
 func (p *KafkaEventPublisher) Close() error {
-	return nil
+	return p.writer.Close()
 }
 
 // InMemoryEventPublisher for testing and development
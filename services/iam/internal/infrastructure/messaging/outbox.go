@@ -0,0 +1,89 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"atlas-core-api/services/iam/internal/domain/events"
+)
+
+const eventSource = "iam-service"
+
+// OutboxMessage is an encoded event waiting in the outbox
+type OutboxMessage struct {
+	ID          int64
+	EventID     string
+	AggregateID string
+	Topic       string
+	Payload     []byte
+	Attempts    int
+	CreatedAt   time.Time
+}
+
+// OutboxStore persists events for the relay. Implementations must only return
+// a message from ClaimPending once every earlier message for the same
+// aggregate has been published.
+type OutboxStore interface {
+	Enqueue(ctx context.Context, evts ...events.DomainEvent) error
+	ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]OutboxMessage, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, cause error, retryAt time.Time) error
+	Stats(ctx context.Context) (OutboxStats, error)
+}
+
+// OutboxStats summarises the unpublished backlog
+type OutboxStats struct {
+	Pending       int
+	OldestPending *time.Time
+}
+
+// EncodeEvent wraps a domain event in the standard envelope. The event type
+// doubles as the Kafka topic (atlas.user.created, atlas.user.login_failed, ...).
+func EncodeEvent(event events.DomainEvent) (id, topic string, payload []byte, err error) {
+	envelope := EventEnvelope{
+		EventID:     EventID(event),
+		EventType:   event.EventType(),
+		AggregateID: event.AggregateID(),
+		Timestamp:   event.OccurredAt(),
+		Version:     1,
+		Source:      eventSource,
+		Payload:     event,
+	}
+
+	payload, err = json.Marshal(envelope)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("failed to marshal event: %w", err)
+	}
+	return envelope.EventID, event.EventType(), payload, nil
+}
+
+// EventID returns the identifier carried by the event, or a new one if the
+// event does not have one, so outbox rows can be deduplicated
+func EventID(event events.DomainEvent) string {
+	if identified, ok := event.(interface{ EventID() string }); ok && identified.EventID() != "" {
+		return identified.EventID()
+	}
+	return uuid.New().String()
+}
+
+// OutboxEventPublisher satisfies handlers.EventPublisher by writing to the
+// outbox; the relay delivers to Kafka asynchronously
+type OutboxEventPublisher struct {
+	store OutboxStore
+}
+
+func NewOutboxEventPublisher(store OutboxStore) *OutboxEventPublisher {
+	return &OutboxEventPublisher{store: store}
+}
+
+func (p *OutboxEventPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	return p.store.Enqueue(ctx, event)
+}
+
+func (p *OutboxEventPublisher) Close() error {
+	return nil
+}
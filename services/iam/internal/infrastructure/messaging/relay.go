@@ -0,0 +1,166 @@
+package messaging
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+)
+
+// Producer delivers an encoded message to a topic
+type Producer interface {
+	Produce(ctx context.Context, topic, key string, value []byte) error
+}
+
+// RelayConfig tunes the outbox relay
+type RelayConfig struct {
+	BatchSize    int
+	PollInterval time.Duration
+	ClaimTimeout time.Duration
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+}
+
+// DefaultRelayConfig returns conservative polling and backoff settings
+func DefaultRelayConfig() RelayConfig {
+	return RelayConfig{
+		BatchSize:    100,
+		PollInterval: time.Second,
+		ClaimTimeout: 30 * time.Second,
+		BaseBackoff:  time.Second,
+		MaxBackoff:   5 * time.Minute,
+	}
+}
+
+var (
+	outboxPending = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "atlas_iam_outbox_pending",
+		Help: "Number of domain events waiting in the outbox",
+	})
+	outboxLag = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "atlas_iam_outbox_lag_seconds",
+		Help: "Age of the oldest unpublished outbox event",
+	})
+	outboxPublished = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "atlas_iam_outbox_published_total",
+		Help: "Outbox events delivered to Kafka",
+	}, []string{"topic"})
+	outboxFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "atlas_iam_outbox_failures_total",
+		Help: "Failed attempts to deliver outbox events",
+	}, []string{"topic"})
+)
+
+func init() {
+	prometheus.MustRegister(outboxPending, outboxLag, outboxPublished, outboxFailures)
+}
+
+// OutboxRelay polls the outbox and publishes pending events. Failed events are
+// retried with exponential backoff; because the store withholds later events
+// of the same aggregate, a failing event delays its aggregate but not others.
+type OutboxRelay struct {
+	store    OutboxStore
+	producer Producer
+	config   RelayConfig
+	logger   *zap.Logger
+}
+
+func NewOutboxRelay(store OutboxStore, producer Producer, config RelayConfig, logger *zap.Logger) *OutboxRelay {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &OutboxRelay{
+		store:    store,
+		producer: producer,
+		config:   config,
+		logger:   logger,
+	}
+}
+
+// Run relays until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	r.logger.Info("Outbox relay started", zap.Duration("poll_interval", r.config.PollInterval))
+	for {
+		// Drain full batches back-to-back, then wait for the next tick
+		for {
+			n, err := r.RelayOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				r.logger.Warn("Outbox relay pass failed", zap.Error(err))
+			}
+			if n < r.config.BatchSize || err != nil {
+				break
+			}
+		}
+		r.updateMetrics(ctx)
+
+		select {
+		case <-ctx.Done():
+			r.logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce claims and publishes a single batch, returning how many messages
+// were claimed
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	messages, err := r.store.ClaimPending(ctx, r.config.BatchSize, r.config.ClaimTimeout)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, msg := range messages {
+		if err := r.producer.Produce(ctx, msg.Topic, msg.AggregateID, msg.Payload); err != nil {
+			outboxFailures.WithLabelValues(msg.Topic).Inc()
+			retryAt := time.Now().Add(r.backoff(msg.Attempts + 1))
+			r.logger.Warn("Failed to relay outbox event",
+				zap.Int64("outbox_id", msg.ID),
+				zap.String("topic", msg.Topic),
+				zap.Int("attempts", msg.Attempts+1),
+				zap.Time("retry_at", retryAt),
+				zap.Error(err),
+			)
+			if markErr := r.store.MarkFailed(ctx, msg.ID, err, retryAt); markErr != nil {
+				return len(messages), markErr
+			}
+			continue
+		}
+
+		if err := r.store.MarkPublished(ctx, msg.ID); err != nil {
+			// The event went out; the claim will lapse and it may be sent
+			// again, which consumers tolerate by deduplicating on event_id
+			return len(messages), err
+		}
+		outboxPublished.WithLabelValues(msg.Topic).Inc()
+	}
+
+	return len(messages), nil
+}
+
+func (r *OutboxRelay) backoff(attempt int) time.Duration {
+	delay := r.config.BaseBackoff << uint(attempt-1)
+	if delay <= 0 || delay > r.config.MaxBackoff {
+		return r.config.MaxBackoff
+	}
+	return delay
+}
+
+func (r *OutboxRelay) updateMetrics(ctx context.Context) {
+	stats, err := r.store.Stats(ctx)
+	if err != nil {
+		r.logger.Debug("Failed to read outbox stats", zap.Error(err))
+		return
+	}
+
+	outboxPending.Set(float64(stats.Pending))
+	if stats.OldestPending != nil {
+		outboxLag.Set(time.Since(*stats.OldestPending).Seconds())
+	} else {
+		outboxLag.Set(0)
+	}
+}
@@ -0,0 +1,86 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"atlas-core-api/services/iam/internal/domain/events"
+)
+
+type fakeStore struct {
+	pending   []OutboxMessage
+	published []int64
+	failed    map[int64]time.Time
+}
+
+func (s *fakeStore) Enqueue(ctx context.Context, evts ...events.DomainEvent) error { return nil }
+
+func (s *fakeStore) ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]OutboxMessage, error) {
+	batch := s.pending
+	s.pending = nil
+	return batch, nil
+}
+
+func (s *fakeStore) MarkPublished(ctx context.Context, id int64) error {
+	s.published = append(s.published, id)
+	return nil
+}
+
+func (s *fakeStore) MarkFailed(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	s.failed[id] = retryAt
+	return nil
+}
+
+func (s *fakeStore) Stats(ctx context.Context) (OutboxStats, error) { return OutboxStats{}, nil }
+
+type fakeProducer struct {
+	sent    []string
+	failKey string
+}
+
+func (p *fakeProducer) Produce(ctx context.Context, topic, key string, value []byte) error {
+	if key == p.failKey {
+		return errors.New("broker unavailable")
+	}
+	p.sent = append(p.sent, topic+"/"+key)
+	return nil
+}
+
+func TestOutboxRelay_RelayOnce(t *testing.T) {
+	store := &fakeStore{
+		pending: []OutboxMessage{
+			{ID: 1, AggregateID: "user-1", Topic: "atlas.user.created"},
+			{ID: 2, AggregateID: "user-2", Topic: "atlas.user.created", Attempts: 2},
+			{ID: 3, AggregateID: "user-3", Topic: "atlas.user.logged_in"},
+		},
+		failed: make(map[int64]time.Time),
+	}
+	producer := &fakeProducer{failKey: "user-2"}
+	relay := NewOutboxRelay(store, producer, DefaultRelayConfig(), nil)
+
+	before := time.Now()
+	n, err := relay.RelayOnce(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []int64{1, 3}, store.published)
+	assert.Equal(t, []string{"atlas.user.created/user-1", "atlas.user.logged_in/user-3"}, producer.sent)
+
+	// Third attempt backs off 4x the base delay
+	require.Contains(t, store.failed, int64(2))
+	assert.WithinDuration(t, before.Add(4*time.Second), store.failed[2], time.Second)
+}
+
+func TestOutboxRelay_BackoffCapped(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, DefaultRelayConfig(), nil)
+
+	assert.Equal(t, time.Second, relay.backoff(1))
+	assert.Equal(t, 2*time.Second, relay.backoff(2))
+	assert.Equal(t, 5*time.Minute, relay.backoff(20))
+	assert.Equal(t, 5*time.Minute, relay.backoff(100))
+}
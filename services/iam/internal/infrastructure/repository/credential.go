@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	models "atlas-core-api/services/iam/internal/domain"
+)
+
+var ErrTokenInvalid = errors.New("token invalid or expired")
+
+// CredentialRepository stores single-use user tokens and password history
+type CredentialRepository interface {
+	CreateToken(ctx context.Context, token *models.UserToken) error
+	FindToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	ConsumeToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error)
+	InvalidateTokens(ctx context.Context, userID, purpose string) error
+	AddPasswordHistory(ctx context.Context, userID, passwordHash string) error
+	RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error)
+}
+
+type credentialRepository struct {
+	db *sql.DB
+}
+
+func NewCredentialRepository(db *sql.DB) CredentialRepository {
+	return &credentialRepository{db: db}
+}
+
+func (r *credentialRepository) CreateToken(ctx context.Context, token *models.UserToken) error {
+	query := `
+		INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at, requested_ip)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt, token.RequestedIP,
+	).Scan(&token.ID, &token.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// FindToken returns an unused, unexpired token without consuming it
+func (r *credentialRepository) FindToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, used_at,
+		       COALESCE(requested_ip, ''), created_at
+		FROM user_tokens
+		WHERE purpose = $1 AND token_hash = $2
+		  AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+	`
+
+	return scanToken(r.db.QueryRowContext(ctx, query, purpose, tokenHash))
+}
+
+// ConsumeToken atomically marks an unused, unexpired token as used and returns
+// it. A second call with the same token returns ErrTokenInvalid.
+func (r *credentialRepository) ConsumeToken(ctx context.Context, purpose, tokenHash string) (*models.UserToken, error) {
+	query := `
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE purpose = $1 AND token_hash = $2
+		  AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING id, user_id, purpose, token_hash, expires_at, used_at,
+		          COALESCE(requested_ip, ''), created_at
+	`
+
+	return scanToken(r.db.QueryRowContext(ctx, query, purpose, tokenHash))
+}
+
+// InvalidateTokens marks all outstanding tokens of a purpose as used, so only
+// the most recently issued link works
+func (r *credentialRepository) InvalidateTokens(ctx context.Context, userID, purpose string) error {
+	query := `
+		UPDATE user_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, purpose); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *credentialRepository) AddPasswordHistory(ctx context.Context, userID, passwordHash string) error {
+	query := `INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)`
+
+	if _, err := r.db.ExecContext(ctx, query, userID, passwordHash); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *credentialRepository) RecentPasswordHashes(ctx context.Context, userID string, limit int) ([]string, error) {
+	query := `
+		SELECT password_hash FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err := rows.Scan(&hash); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, rows.Err()
+}
+
+func scanToken(row rowScanner) (*models.UserToken, error) {
+	var t models.UserToken
+	var usedAt sql.NullTime
+	err := row.Scan(
+		&t.ID, &t.UserID, &t.Purpose, &t.TokenHash, &t.ExpiresAt, &usedAt,
+		&t.RequestedIP, &t.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrTokenInvalid
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	return &t, nil
+}
@@ -0,0 +1,676 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/events"
+)
+
+var (
+	ErrElevationNotFound         = errors.New("elevation not found")
+	ErrElevationOpen             = errors.New("an elevation for this role is already pending or active")
+	ErrElevationNotPending       = errors.New("elevation is not pending")
+	ErrElevationClosed           = errors.New("elevation has already ended")
+	ErrRoleAlreadyHeld           = errors.New("user already holds the role")
+	ErrElevationPolicyNotFound   = errors.New("elevation policy not found")
+	ErrBreakGlassAccountNotFound = errors.New("break-glass account not found")
+)
+
+// ElevationFilter narrows elevation listings and reports
+type ElevationFilter struct {
+	UserID     string
+	RoleID     string
+	Status     string
+	BreakGlass *bool
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+}
+
+// ElevationRepository stores elevation policies, break-glass accounts and
+// elevations. Activating an elevation grants its role as a time-bound
+// assignment in the same transaction, so the expiry sweep revokes it.
+type ElevationRepository interface {
+	ListPolicies(ctx context.Context) ([]*models.ElevationPolicy, error)
+	GetPolicy(ctx context.Context, roleID string) (*models.ElevationPolicy, error)
+	SavePolicy(ctx context.Context, policy *models.ElevationPolicy) error
+	DeletePolicy(ctx context.Context, roleID string) error
+
+	ListBreakGlassAccounts(ctx context.Context) ([]*models.BreakGlassAccount, error)
+	IsBreakGlassAccount(ctx context.Context, userID string) (bool, error)
+	AddBreakGlassAccount(ctx context.Context, account *models.BreakGlassAccount) error
+	RemoveBreakGlassAccount(ctx context.Context, userID string) error
+
+	Create(ctx context.Context, elevation *models.Elevation) error
+	ActivateBreakGlass(ctx context.Context, elevation *models.Elevation, ipAddress string) error
+	Approve(ctx context.Context, id, decidedBy, note string) (*models.Elevation, error)
+	Reject(ctx context.Context, id, decidedBy, note string) (*models.Elevation, error)
+	End(ctx context.Context, id, endedBy string) (*models.Elevation, error)
+	Get(ctx context.Context, id string) (*models.Elevation, error)
+	List(ctx context.Context, filter ElevationFilter) ([]*models.Elevation, error)
+	AdminEmails(ctx context.Context) ([]string, error)
+}
+
+type elevationRepository struct {
+	db *sql.DB
+}
+
+func NewElevationRepository(db *sql.DB) ElevationRepository {
+	return &elevationRepository{db: db}
+}
+
+func (r *elevationRepository) ListPolicies(ctx context.Context) ([]*models.ElevationPolicy, error) {
+	query := `
+		SELECT p.role_id, r.name, p.max_duration_minutes, COALESCE(p.updated_by::text, ''),
+		       COALESCE(p.updated_at, CURRENT_TIMESTAMP)
+		FROM role_elevation_policies p
+		JOIN roles r ON r.id = p.role_id
+		ORDER BY r.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	policies := []*models.ElevationPolicy{}
+	for rows.Next() {
+		var p models.ElevationPolicy
+		if err := rows.Scan(&p.RoleID, &p.RoleName, &p.MaxDurationMinutes, &p.UpdatedBy, &p.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		policies = append(policies, &p)
+	}
+	return policies, rows.Err()
+}
+
+func (r *elevationRepository) GetPolicy(ctx context.Context, roleID string) (*models.ElevationPolicy, error) {
+	query := `
+		SELECT p.role_id, r.name, p.max_duration_minutes, COALESCE(p.updated_by::text, ''),
+		       COALESCE(p.updated_at, CURRENT_TIMESTAMP)
+		FROM role_elevation_policies p
+		JOIN roles r ON r.id = p.role_id
+		WHERE p.role_id = $1
+	`
+
+	var p models.ElevationPolicy
+	err := r.db.QueryRowContext(ctx, query, roleID).Scan(&p.RoleID, &p.RoleName, &p.MaxDurationMinutes, &p.UpdatedBy, &p.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return nil, ErrElevationPolicyNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return &p, nil
+}
+
+func (r *elevationRepository) SavePolicy(ctx context.Context, policy *models.ElevationPolicy) error {
+	query := `
+		INSERT INTO role_elevation_policies (role_id, max_duration_minutes, updated_by, updated_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, CURRENT_TIMESTAMP)
+		ON CONFLICT (role_id) DO UPDATE
+		SET max_duration_minutes = EXCLUDED.max_duration_minutes,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = EXCLUDED.updated_at
+		RETURNING updated_at, (SELECT name FROM roles WHERE id = $1)
+	`
+
+	err := r.db.QueryRowContext(ctx, query, policy.RoleID, policy.MaxDurationMinutes, policy.UpdatedBy).
+		Scan(&policy.UpdatedAt, &policy.RoleName)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *elevationRepository) DeletePolicy(ctx context.Context, roleID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM role_elevation_policies WHERE role_id = $1`, roleID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrElevationPolicyNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrElevationPolicyNotFound
+	}
+	return nil
+}
+
+func (r *elevationRepository) ListBreakGlassAccounts(ctx context.Context) ([]*models.BreakGlassAccount, error) {
+	query := `
+		SELECT b.user_id, u.username, COALESCE(b.reason, ''), COALESCE(b.designated_by::text, ''),
+		       COALESCE(b.created_at, CURRENT_TIMESTAMP)
+		FROM break_glass_accounts b
+		JOIN users u ON u.id = b.user_id
+		ORDER BY u.username
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	accounts := []*models.BreakGlassAccount{}
+	for rows.Next() {
+		var a models.BreakGlassAccount
+		if err := rows.Scan(&a.UserID, &a.Username, &a.Reason, &a.DesignatedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		accounts = append(accounts, &a)
+	}
+	return accounts, rows.Err()
+}
+
+func (r *elevationRepository) IsBreakGlassAccount(ctx context.Context, userID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM break_glass_accounts WHERE user_id = $1)`, userID).Scan(&exists)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return exists, nil
+}
+
+func (r *elevationRepository) AddBreakGlassAccount(ctx context.Context, account *models.BreakGlassAccount) error {
+	query := `
+		INSERT INTO break_glass_accounts (user_id, reason, designated_by)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, '')::uuid)
+		ON CONFLICT (user_id) DO UPDATE SET reason = EXCLUDED.reason, designated_by = EXCLUDED.designated_by
+		RETURNING created_at, (SELECT username FROM users WHERE id = $1)
+	`
+
+	err := r.db.QueryRowContext(ctx, query, account.UserID, account.Reason, account.DesignatedBy).
+		Scan(&account.CreatedAt, &account.Username)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *elevationRepository) RemoveBreakGlassAccount(ctx context.Context, userID string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM break_glass_accounts WHERE user_id = $1`, userID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrBreakGlassAccountNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrBreakGlassAccountNotFound
+	}
+	return nil
+}
+
+// Create files a pending elevation and notifies the admins who can approve it
+func (r *elevationRepository) Create(ctx context.Context, elevation *models.Elevation) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH created AS (
+			INSERT INTO role_elevations (user_id, role_id, reason, incident_ref, duration_seconds)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+			RETURNING *
+		)
+	` + selectElevationFrom("created")
+
+	created, err := scanElevation(tx.QueryRowContext(ctx, query,
+		elevation.UserID, elevation.RoleID, elevation.Reason, elevation.IncidentRef, elevation.DurationSecs,
+	))
+	if err != nil {
+		return elevationWriteError(err)
+	}
+	*elevation = *created
+
+	requested := events.ElevationRequested{
+		BaseEvent:       newBaseEvent("atlas.user.elevation_requested", elevation.UserID),
+		ElevationID:     elevation.ID,
+		RoleName:        elevation.RoleName,
+		RoleID:          elevation.RoleID,
+		Reason:          elevation.Reason,
+		DurationSeconds: elevation.DurationSecs,
+	}
+	if err := enqueueEvents(ctx, tx, requested); err != nil {
+		return err
+	}
+	if err := notifyAdmins(ctx, tx, elevation, "task", "high",
+		fmt.Sprintf("Elevation to %s requested by %s", elevation.RoleName, elevation.Username),
+		elevation.Reason,
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// ActivateBreakGlass grants the role immediately. The high-severity audit
+// record, the BreakGlassActivated event and the admin notifications are
+// written in the same transaction as the grant, so none can be skipped.
+func (r *elevationRepository) ActivateBreakGlass(ctx context.Context, elevation *models.Elevation, ipAddress string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH created AS (
+			INSERT INTO role_elevations (
+				user_id, role_id, reason, incident_ref, duration_seconds, break_glass,
+				status, activated_at, expires_at
+			)
+			VALUES ($1, $2, $3, NULLIF($4, ''), $5, true,
+			        'active', CURRENT_TIMESTAMP, CURRENT_TIMESTAMP + $5 * INTERVAL '1 second')
+			RETURNING *
+		)
+	` + selectElevationFrom("created")
+
+	created, err := scanElevation(tx.QueryRowContext(ctx, query,
+		elevation.UserID, elevation.RoleID, elevation.Reason, elevation.IncidentRef, elevation.DurationSecs,
+	))
+	if err != nil {
+		return elevationWriteError(err)
+	}
+	*elevation = *created
+
+	if err := activateElevationTx(ctx, tx, elevation, elevation.UserID); err != nil {
+		return err
+	}
+
+	details, err := json.Marshal(map[string]interface{}{
+		"severity":     "high",
+		"role":         elevation.RoleName,
+		"reason":       elevation.Reason,
+		"incident_ref": elevation.IncidentRef,
+		"expires_at":   elevation.ExpiresAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode audit details: %w", err)
+	}
+	audit := `
+		INSERT INTO audit_logs (user_id, action, resource, resource_id, ip_address, status, details)
+		VALUES ($1, 'break_glass_activated', 'role_elevation', $2, NULLIF($3, ''), 'success', $4)
+	`
+	if _, err := tx.ExecContext(ctx, audit, elevation.UserID, elevation.ID, ipAddress, details); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	activated := events.BreakGlassActivated{
+		BaseEvent:   newBaseEvent("atlas.user.break_glass_activated", elevation.UserID),
+		ElevationID: elevation.ID,
+		RoleName:    elevation.RoleName,
+		RoleID:      elevation.RoleID,
+		Reason:      elevation.Reason,
+		IncidentRef: elevation.IncidentRef,
+		ExpiresAt:   *elevation.ExpiresAt,
+		Severity:    "high",
+	}
+	if err := enqueueEvents(ctx, tx, activated); err != nil {
+		return err
+	}
+	if err := notifyAdmins(ctx, tx, elevation, "alert", "urgent",
+		fmt.Sprintf("Break-glass: %s elevated to %s", elevation.Username, elevation.RoleName),
+		elevation.Reason,
+	); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// Approve activates a pending elevation; it runs for its requested duration
+// from the moment of approval
+func (r *elevationRepository) Approve(ctx context.Context, id, decidedBy, note string) (*models.Elevation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH approved AS (
+			UPDATE role_elevations
+			SET status = 'active', decided_by = NULLIF($2, '')::uuid, decision_note = NULLIF($3, ''),
+			    decided_at = CURRENT_TIMESTAMP, activated_at = CURRENT_TIMESTAMP,
+			    expires_at = CURRENT_TIMESTAMP + duration_seconds * INTERVAL '1 second'
+			WHERE id = $1 AND status = 'pending'
+			RETURNING *
+		)
+	` + selectElevationFrom("approved")
+
+	elevation, err := r.transition(ctx, tx, query, ErrElevationNotPending, id, decidedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := activateElevationTx(ctx, tx, elevation, decidedBy); err != nil {
+		return nil, err
+	}
+	if err := notifyUser(ctx, tx, elevation, "info", "normal",
+		fmt.Sprintf("Elevation to %s approved", elevation.RoleName), note,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return elevation, nil
+}
+
+func (r *elevationRepository) Reject(ctx context.Context, id, decidedBy, note string) (*models.Elevation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH rejected AS (
+			UPDATE role_elevations
+			SET status = 'rejected', decided_by = NULLIF($2, '')::uuid, decision_note = NULLIF($3, ''),
+			    decided_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status = 'pending'
+			RETURNING *
+		)
+	` + selectElevationFrom("rejected")
+
+	elevation, err := r.transition(ctx, tx, query, ErrElevationNotPending, id, decidedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := notifyUser(ctx, tx, elevation, "warning", "normal",
+		fmt.Sprintf("Elevation to %s rejected", elevation.RoleName), note,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return elevation, nil
+}
+
+// End withdraws a pending elevation or ends an active one early, revoking
+// its role
+func (r *elevationRepository) End(ctx context.Context, id, endedBy string) (*models.Elevation, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		WITH ended AS (
+			UPDATE role_elevations
+			SET status = 'ended', ended_by = NULLIF($2, '')::uuid, ended_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status IN ('pending', 'active')
+			RETURNING *
+		)
+	` + selectElevationFrom("ended")
+
+	elevation, err := r.transition(ctx, tx, query, ErrElevationClosed, id, endedBy)
+	if err != nil {
+		return nil, err
+	}
+	if elevation.ActivatedAt != nil {
+		err := revokeRoleTx(ctx, tx, elevation.UserID, elevation.RoleID, endedBy, "elevation_ended")
+		if err != nil && !errors.Is(err, ErrRoleNotAssigned) {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return elevation, nil
+}
+
+func (r *elevationRepository) Get(ctx context.Context, id string) (*models.Elevation, error) {
+	elevation, err := scanElevation(r.db.QueryRowContext(ctx, selectElevationFrom("role_elevations")+` WHERE e.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return nil, ErrElevationNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return elevation, nil
+}
+
+func (r *elevationRepository) List(ctx context.Context, filter ElevationFilter) ([]*models.Elevation, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("e.user_id::text = $%d", len(args)))
+	}
+	if filter.RoleID != "" {
+		args = append(args, filter.RoleID)
+		conditions = append(conditions, fmt.Sprintf("e.role_id::text = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("e.status = $%d", len(args)))
+	}
+	if filter.BreakGlass != nil {
+		args = append(args, *filter.BreakGlass)
+		conditions = append(conditions, fmt.Sprintf("e.break_glass = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("e.requested_at >= $%d", len(args)))
+	}
+	if filter.Until != nil {
+		args = append(args, *filter.Until)
+		conditions = append(conditions, fmt.Sprintf("e.requested_at < $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit < 1 || limit > 5000 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	query := selectElevationFrom("role_elevations")
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY e.requested_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	elevations := []*models.Elevation{}
+	for rows.Next() {
+		elevation, err := scanElevation(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		elevations = append(elevations, elevation)
+	}
+	return elevations, rows.Err()
+}
+
+// AdminEmails returns the addresses of active admins, for out-of-band alerts
+func (r *elevationRepository) AdminEmails(ctx context.Context) ([]string, error) {
+	query := `
+		SELECT DISTINCT u.email
+		FROM users u
+		JOIN user_roles ur ON ur.user_id = u.id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+		JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = 'admin' AND u.is_active = true
+		ORDER BY u.email
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var emails []string
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// transition runs a status-changing statement and tells a missing elevation
+// apart from one in the wrong state
+func (r *elevationRepository) transition(ctx context.Context, tx *sql.Tx, query string, wrongState error, args ...interface{}) (*models.Elevation, error) {
+	elevation, err := scanElevation(tx.QueryRowContext(ctx, query, args...))
+	if err == nil {
+		return elevation, nil
+	}
+	if isInvalidUUID(err) {
+		return nil, ErrElevationNotFound
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM role_elevations WHERE id = $1)`, args[0]).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if !exists {
+		return nil, ErrElevationNotFound
+	}
+	return nil, wrongState
+}
+
+// activateElevationTx grants the elevated role until the elevation expires.
+// Elevating into a role the user already holds is refused, since the expiry
+// would otherwise cut short the existing assignment.
+func activateElevationTx(ctx context.Context, tx *sql.Tx, elevation *models.Elevation, assignedBy string) error {
+	var held bool
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM user_roles
+			WHERE user_id = $1 AND role_id = $2 AND (expires_at IS NULL OR expires_at > NOW())
+		)
+		FROM users WHERE id = $1
+		FOR UPDATE
+	`
+	if err := tx.QueryRowContext(ctx, query, elevation.UserID, elevation.RoleID).Scan(&held); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if held {
+		return ErrRoleAlreadyHeld
+	}
+	return grantRoleTx(ctx, tx, elevation.UserID, elevation.RoleID, assignedBy, elevation.ExpiresAt)
+}
+
+// notifyAdmins leaves an in-app notification for every active admin other
+// than the elevated user
+func notifyAdmins(ctx context.Context, exec execer, elevation *models.Elevation, notificationType, priority, title, message string) error {
+	query := `
+		INSERT INTO notifications (user_id, notification_type, title, message, priority, source_type, source_id)
+		SELECT DISTINCT ur.user_id, $1, $2, NULLIF($3, ''), $4, 'role_elevation', $5::uuid
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		JOIN users u ON u.id = ur.user_id
+		WHERE r.name = 'admin' AND u.is_active = true AND ur.user_id <> $6
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+	`
+	if _, err := exec.ExecContext(ctx, query, notificationType, title, message, priority, elevation.ID, elevation.UserID); err != nil {
+		return fmt.Errorf("%w: failed to notify admins: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// notifyUser leaves an in-app notification for the elevated user
+func notifyUser(ctx context.Context, exec execer, elevation *models.Elevation, notificationType, priority, title, message string) error {
+	query := `
+		INSERT INTO notifications (user_id, notification_type, title, message, priority, source_type, source_id)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, 'role_elevation', $6)
+	`
+	if _, err := exec.ExecContext(ctx, query, elevation.UserID, notificationType, title, message, priority, elevation.ID); err != nil {
+		return fmt.Errorf("%w: failed to notify user: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// selectElevationFrom selects elevations from a table or a CTE of changed rows
+func selectElevationFrom(source string) string {
+	return `
+		SELECT e.id, e.user_id, u.username, e.role_id, r.name, e.reason, COALESCE(e.incident_ref, ''),
+		       e.duration_seconds, e.break_glass, e.status,
+		       COALESCE(e.decided_by::text, ''), COALESCE(e.decision_note, ''), e.decided_at,
+		       e.activated_at, e.expires_at, COALESCE(e.ended_by::text, ''), e.ended_at,
+		       COALESCE(e.requested_at, CURRENT_TIMESTAMP)
+		FROM ` + source + ` e
+		JOIN users u ON u.id = e.user_id
+		JOIN roles r ON r.id = e.role_id
+	`
+}
+
+func scanElevation(row rowScanner) (*models.Elevation, error) {
+	var e models.Elevation
+	var decidedAt, activatedAt, expiresAt, endedAt sql.NullTime
+	if err := row.Scan(
+		&e.ID, &e.UserID, &e.Username, &e.RoleID, &e.RoleName, &e.Reason, &e.IncidentRef,
+		&e.DurationSecs, &e.BreakGlass, &e.Status,
+		&e.DecidedBy, &e.DecisionNote, &decidedAt,
+		&activatedAt, &expiresAt, &e.EndedBy, &endedAt,
+		&e.RequestedAt,
+	); err != nil {
+		return nil, err
+	}
+	e.DecidedAt = nullTimePtr(decidedAt)
+	e.ActivatedAt = nullTimePtr(activatedAt)
+	e.ExpiresAt = nullTimePtr(expiresAt)
+	e.EndedAt = nullTimePtr(endedAt)
+	return &e, nil
+}
+
+func elevationWriteError(err error) error {
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return ErrElevationOpen
+		case "23503", "22P02":
+			return ErrRoleNotFound
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+}
@@ -0,0 +1,233 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	models "atlas-core-api/services/iam/internal/domain"
+)
+
+// LoginAttemptFilter narrows attempt history queries
+type LoginAttemptFilter struct {
+	Username  string
+	IPAddress string
+	Since     *time.Time
+	Limit     int
+}
+
+type LoginAttemptRepository interface {
+	Record(ctx context.Context, attempt *models.LoginAttempt) error
+	List(ctx context.Context, filter LoginAttemptFilter) ([]*models.LoginAttempt, error)
+	FailureStats(ctx context.Context, scope, subject string, since time.Time) (int, *time.Time, error)
+	GetLockout(ctx context.Context, scope, subject string) (*models.AccountLockout, error)
+	SaveLockout(ctx context.Context, lockout *models.AccountLockout) error
+	ListLockouts(ctx context.Context, activeOnly bool) ([]*models.AccountLockout, error)
+}
+
+type loginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepository{db: db}
+}
+
+func (r *loginAttemptRepository) Record(ctx context.Context, attempt *models.LoginAttempt) error {
+	query := `
+		INSERT INTO login_attempts (username, ip_address, success, user_agent, failure_reason)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		attempt.Username, attempt.IPAddress, attempt.Success, attempt.UserAgent, attempt.FailureReason,
+	).Scan(&attempt.ID, &attempt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *loginAttemptRepository) List(ctx context.Context, filter LoginAttemptFilter) ([]*models.LoginAttempt, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Username != "" {
+		args = append(args, filter.Username)
+		conditions = append(conditions, fmt.Sprintf("username = $%d", len(args)))
+	}
+	if filter.IPAddress != "" {
+		args = append(args, filter.IPAddress)
+		conditions = append(conditions, fmt.Sprintf("ip_address = $%d", len(args)))
+	}
+	if filter.Since != nil {
+		args = append(args, *filter.Since)
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, COALESCE(username, ''), ip_address, success,
+		       COALESCE(user_agent, ''), COALESCE(failure_reason, ''), created_at
+		FROM login_attempts
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var attempts []*models.LoginAttempt
+	for rows.Next() {
+		var a models.LoginAttempt
+		if err := rows.Scan(
+			&a.ID, &a.Username, &a.IPAddress, &a.Success,
+			&a.UserAgent, &a.FailureReason, &a.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		attempts = append(attempts, &a)
+	}
+
+	return attempts, rows.Err()
+}
+
+// FailureStats returns the number of failed attempts for a username or IP since
+// the given time, along with the time of the most recent failure. Attempts
+// refused while locked out are not counted.
+func (r *loginAttemptRepository) FailureStats(ctx context.Context, scope, subject string, since time.Time) (int, *time.Time, error) {
+	column := "username"
+	if scope == models.LockoutScopeIP {
+		column = "ip_address"
+	}
+
+	query := fmt.Sprintf(`
+		SELECT COUNT(*), MAX(created_at)
+		FROM login_attempts
+		WHERE %s = $1 AND success = false AND created_at > $2
+		  AND COALESCE(failure_reason, '') <> 'locked'
+	`, column)
+
+	var count int
+	var last sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query, subject, since).Scan(&count, &last); err != nil {
+		return 0, nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	if !last.Valid {
+		return count, nil, nil
+	}
+	return count, &last.Time, nil
+}
+
+// GetLockout returns the lockout state for a subject, or an unlocked zero value
+// if none has been recorded yet
+func (r *loginAttemptRepository) GetLockout(ctx context.Context, scope, subject string) (*models.AccountLockout, error) {
+	query := `
+		SELECT scope, subject, locked_until, lockout_count, reset_at,
+		       COALESCE(reason, ''), COALESCE(unlocked_by::text, ''), unlocked_at
+		FROM account_lockouts
+		WHERE scope = $1 AND subject = $2
+	`
+
+	lockout, err := scanLockout(r.db.QueryRowContext(ctx, query, scope, subject))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return &models.AccountLockout{Scope: scope, Subject: subject}, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return lockout, nil
+}
+
+func (r *loginAttemptRepository) SaveLockout(ctx context.Context, lockout *models.AccountLockout) error {
+	query := `
+		INSERT INTO account_lockouts (scope, subject, locked_until, lockout_count, reset_at, reason, unlocked_by, unlocked_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, '')::uuid, $8)
+		ON CONFLICT (scope, subject) DO UPDATE SET
+			locked_until = EXCLUDED.locked_until,
+			lockout_count = EXCLUDED.lockout_count,
+			reset_at = EXCLUDED.reset_at,
+			reason = EXCLUDED.reason,
+			unlocked_by = EXCLUDED.unlocked_by,
+			unlocked_at = EXCLUDED.unlocked_at,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		lockout.Scope, lockout.Subject, lockout.LockedUntil, lockout.LockoutCount,
+		lockout.ResetAt, lockout.Reason, lockout.UnlockedBy, lockout.UnlockedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *loginAttemptRepository) ListLockouts(ctx context.Context, activeOnly bool) ([]*models.AccountLockout, error) {
+	query := `
+		SELECT scope, subject, locked_until, lockout_count, reset_at,
+		       COALESCE(reason, ''), COALESCE(unlocked_by::text, ''), unlocked_at
+		FROM account_lockouts
+	`
+	if activeOnly {
+		query += " WHERE locked_until > CURRENT_TIMESTAMP"
+	}
+	query += " ORDER BY updated_at DESC"
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var lockouts []*models.AccountLockout
+	for rows.Next() {
+		lockout, err := scanLockout(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		lockouts = append(lockouts, lockout)
+	}
+
+	return lockouts, rows.Err()
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanLockout(row rowScanner) (*models.AccountLockout, error) {
+	var l models.AccountLockout
+	var lockedUntil, resetAt, unlockedAt sql.NullTime
+	if err := row.Scan(
+		&l.Scope, &l.Subject, &lockedUntil, &l.LockoutCount, &resetAt,
+		&l.Reason, &l.UnlockedBy, &unlockedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if lockedUntil.Valid {
+		l.LockedUntil = &lockedUntil.Time
+	}
+	if resetAt.Valid {
+		l.ResetAt = &resetAt.Time
+	}
+	if unlockedAt.Valid {
+		l.UnlockedAt = &unlockedAt.Time
+	}
+	return &l, nil
+}
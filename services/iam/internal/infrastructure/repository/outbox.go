@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"atlas-core-api/services/iam/internal/domain/events"
+	"atlas-core-api/services/iam/internal/infrastructure/messaging"
+)
+
+// execer is satisfied by both *sql.DB and *sql.Tx so events can be written
+// inside the caller's transaction
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+type outboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) messaging.OutboxStore {
+	return &outboxRepository{db: db}
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, evts ...events.DomainEvent) error {
+	return enqueueEvents(ctx, r.db, evts...)
+}
+
+// ClaimPending leases up to limit publishable messages. A message is
+// publishable when it is due and no earlier message for the same aggregate is
+// still unpublished, which preserves per-aggregate ordering across retries.
+func (r *outboxRepository) ClaimPending(ctx context.Context, limit int, claimFor time.Duration) ([]messaging.OutboxMessage, error) {
+	query := `
+		UPDATE event_outbox SET claimed_until = CURRENT_TIMESTAMP + ($2 * INTERVAL '1 millisecond')
+		WHERE id IN (
+			SELECT o.id FROM event_outbox o
+			WHERE o.published_at IS NULL
+			  AND o.next_attempt_at <= CURRENT_TIMESTAMP
+			  AND (o.claimed_until IS NULL OR o.claimed_until < CURRENT_TIMESTAMP)
+			  AND NOT EXISTS (
+				SELECT 1 FROM event_outbox p
+				WHERE p.aggregate_id = o.aggregate_id AND p.published_at IS NULL AND p.id < o.id
+			  )
+			ORDER BY o.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, event_id, aggregate_id, topic, payload, attempts, created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, claimFor.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var messages []messaging.OutboxMessage
+	for rows.Next() {
+		var m messaging.OutboxMessage
+		if err := rows.Scan(&m.ID, &m.EventID, &m.AggregateID, &m.Topic, &m.Payload, &m.Attempts, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		messages = append(messages, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	// RETURNING order is unspecified; publish in insertion order
+	sort.Slice(messages, func(i, j int) bool { return messages[i].ID < messages[j].ID })
+	return messages, nil
+}
+
+func (r *outboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	query := `
+		UPDATE event_outbox
+		SET published_at = CURRENT_TIMESTAMP, claimed_until = NULL, attempts = attempts + 1, last_error = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) MarkFailed(ctx context.Context, id int64, cause error, retryAt time.Time) error {
+	query := `
+		UPDATE event_outbox
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3, claimed_until = NULL
+		WHERE id = $1
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, id, cause.Error(), retryAt); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *outboxRepository) Stats(ctx context.Context) (messaging.OutboxStats, error) {
+	query := `SELECT COUNT(*), MIN(created_at) FROM event_outbox WHERE published_at IS NULL`
+
+	var stats messaging.OutboxStats
+	var oldest sql.NullTime
+	if err := r.db.QueryRowContext(ctx, query).Scan(&stats.Pending, &oldest); err != nil {
+		return stats, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if oldest.Valid {
+		stats.OldestPending = &oldest.Time
+	}
+	return stats, nil
+}
+
+// enqueueEvents writes events to the outbox using exec, which may be a
+// transaction shared with the state change that produced them
+func enqueueEvents(ctx context.Context, exec execer, evts ...events.DomainEvent) error {
+	query := `
+		INSERT INTO event_outbox (event_id, aggregate_id, topic, payload, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (event_id) DO NOTHING
+	`
+
+	for _, event := range evts {
+		id, topic, payload, err := messaging.EncodeEvent(event)
+		if err != nil {
+			return err
+		}
+		if _, err := exec.ExecContext(ctx, query,
+			id, event.AggregateID(), topic, payload, event.OccurredAt(),
+		); err != nil {
+			return fmt.Errorf("%w: failed to enqueue event: %v", ErrDatabaseOperation, err)
+		}
+	}
+	return nil
+}
+
+// newBaseEvent fills the common event fields for events raised by repositories
+func newBaseEvent(eventType, aggregateID string) events.BaseEvent {
+	return events.BaseEvent{
+		ID:          uuid.New().String(),
+		Type:        eventType,
+		AggregateId: aggregateID,
+		Timestamp:   time.Now(),
+		Version:     1,
+	}
+}
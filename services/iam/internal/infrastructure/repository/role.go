@@ -1,12 +1,14 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 
 	"github.com/lib/pq"
 	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/events"
 )
 
 var (
@@ -41,19 +43,19 @@ func (r *roleRepository) GetByID(id string) (*models.Role, error) {
 	}
 
 	query := `
-		SELECT r.id, r.name, r.description, r.created_at::text, r.updated_at::text,
+		SELECT r.id, r.name, r.description, r.requires_approval, r.created_at::text, r.updated_at::text,
 		       COALESCE(array_agg(p.name) FILTER (WHERE p.name IS NOT NULL), '{}') as permissions
 		FROM roles r
 		LEFT JOIN role_permissions rp ON r.id = rp.role_id
 		LEFT JOIN permissions p ON rp.permission_id = p.id
 		WHERE r.id = $1
-		GROUP BY r.id, r.name, r.description, r.created_at, r.updated_at
+		GROUP BY r.id, r.name, r.description, r.requires_approval, r.created_at, r.updated_at
 	`
 
 	var role models.Role
 	var permissions pq.StringArray
 	err := r.db.QueryRow(query, id).Scan(
-		&role.ID, &role.Name, &role.Description,
+		&role.ID, &role.Name, &role.Description, &role.RequiresApproval,
 		&role.CreatedAt, &role.UpdatedAt, &permissions,
 	)
 	if err != nil {
@@ -73,19 +75,19 @@ func (r *roleRepository) GetByName(name string) (*models.Role, error) {
 	}
 
 	query := `
-		SELECT r.id, r.name, r.description, r.created_at::text, r.updated_at::text,
+		SELECT r.id, r.name, r.description, r.requires_approval, r.created_at::text, r.updated_at::text,
 		       COALESCE(array_agg(p.name) FILTER (WHERE p.name IS NOT NULL), '{}') as permissions
 		FROM roles r
 		LEFT JOIN role_permissions rp ON r.id = rp.role_id
 		LEFT JOIN permissions p ON rp.permission_id = p.id
 		WHERE r.name = $1
-		GROUP BY r.id, r.name, r.description, r.created_at, r.updated_at
+		GROUP BY r.id, r.name, r.description, r.requires_approval, r.created_at, r.updated_at
 	`
 
 	var role models.Role
 	var permissions pq.StringArray
 	err := r.db.QueryRow(query, name).Scan(
-		&role.ID, &role.Name, &role.Description,
+		&role.ID, &role.Name, &role.Description, &role.RequiresApproval,
 		&role.CreatedAt, &role.UpdatedAt, &permissions,
 	)
 	if err != nil {
@@ -105,12 +107,12 @@ func (r *roleRepository) List() ([]*models.Role, error) {
 	}
 
 	query := `
-		SELECT r.id, r.name, r.description, r.created_at::text, r.updated_at::text,
+		SELECT r.id, r.name, r.description, r.requires_approval, r.created_at::text, r.updated_at::text,
 		       COALESCE(array_agg(p.name) FILTER (WHERE p.name IS NOT NULL), '{}') as permissions
 		FROM roles r
 		LEFT JOIN role_permissions rp ON r.id = rp.role_id
 		LEFT JOIN permissions p ON rp.permission_id = p.id
-		GROUP BY r.id, r.name, r.description, r.created_at, r.updated_at
+		GROUP BY r.id, r.name, r.description, r.requires_approval, r.created_at, r.updated_at
 		ORDER BY r.name
 	`
 
@@ -125,7 +127,7 @@ func (r *roleRepository) List() ([]*models.Role, error) {
 		var role models.Role
 		var permissions pq.StringArray
 		if err := rows.Scan(
-			&role.ID, &role.Name, &role.Description,
+			&role.ID, &role.Name, &role.Description, &role.RequiresApproval,
 			&role.CreatedAt, &role.UpdatedAt, &permissions,
 		); err != nil {
 			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
@@ -143,12 +145,12 @@ func (r *roleRepository) Create(role *models.Role) error {
 	}
 
 	query := `
-		INSERT INTO roles (name, description)
-		VALUES ($1, $2)
+		INSERT INTO roles (name, description, requires_approval)
+		VALUES ($1, $2, $3)
 		RETURNING id, created_at::text, updated_at::text
 	`
 
-	err := r.db.QueryRow(query, role.Name, role.Description).Scan(
+	err := r.db.QueryRow(query, role.Name, role.Description, role.RequiresApproval).Scan(
 		&role.ID, &role.CreatedAt, &role.UpdatedAt,
 	)
 	if err != nil {
@@ -166,18 +168,44 @@ func (r *roleRepository) AssignToUser(userID, roleID string) error {
 		return ErrDatabaseOperation
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
 	query := `
 		INSERT INTO user_roles (user_id, role_id)
 		VALUES ($1, $2)
-		ON CONFLICT (user_id, role_id) DO NOTHING
+		ON CONFLICT (user_id, role_id) DO UPDATE SET expires_at = NULL, assigned_at = CURRENT_TIMESTAMP
+		WHERE user_roles.expires_at IS NOT NULL AND user_roles.expires_at <= NOW()
 	`
 
-	_, err := r.db.Exec(query, userID, roleID)
+	result, err := tx.Exec(query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
 
-	return nil
+	// Re-assigning an existing role is a no-op and raises no event
+	if n, _ := result.RowsAffected(); n > 0 {
+		if err := touchUser(context.Background(), tx, userID); err != nil {
+			return err
+		}
+		roleName, err := roleNameTx(tx, roleID)
+		if err != nil {
+			return err
+		}
+		assigned := events.UserRoleAssigned{
+			BaseEvent: newBaseEvent("atlas.user.role_assigned", userID),
+			RoleName:  roleName,
+			RoleID:    roleID,
+		}
+		if err := enqueueEvents(context.Background(), tx, assigned); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *roleRepository) RemoveFromUser(userID, roleID string) error {
@@ -185,9 +213,15 @@ func (r *roleRepository) RemoveFromUser(userID, roleID string) error {
 		return ErrDatabaseOperation
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
 	query := `DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2`
 
-	result, err := r.db.Exec(query, userID, roleID)
+	result, err := tx.Exec(query, userID, roleID)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
@@ -196,6 +230,33 @@ func (r *roleRepository) RemoveFromUser(userID, roleID string) error {
 	if rowsAffected == 0 {
 		return ErrRoleNotFound
 	}
+	if err := touchUser(context.Background(), tx, userID); err != nil {
+		return err
+	}
 
-	return nil
+	roleName, err := roleNameTx(tx, roleID)
+	if err != nil {
+		return err
+	}
+	revoked := events.UserRoleRevoked{
+		BaseEvent: newBaseEvent("atlas.user.role_revoked", userID),
+		RoleName:  roleName,
+		RoleID:    roleID,
+	}
+	if err := enqueueEvents(context.Background(), tx, revoked); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func roleNameTx(tx *sql.Tx, roleID string) (string, error) {
+	var name string
+	if err := tx.QueryRow(`SELECT name FROM roles WHERE id = $1`, roleID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrRoleNotFound
+		}
+		return "", fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return name, nil
 }
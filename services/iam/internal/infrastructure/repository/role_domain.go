@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+)
+
+// domainRoleRepository exposes the role table through the domain
+// RoleRepository interface used by the command handlers
+type domainRoleRepository struct {
+	roles RoleRepository
+}
+
+func NewDomainRoleRepository(roles RoleRepository) repositories.RoleRepository {
+	return &domainRoleRepository{roles: roles}
+}
+
+func (r *domainRoleRepository) FindByID(ctx context.Context, id string) (*repositories.Role, error) {
+	role, err := r.roles.GetByID(id)
+	if err != nil {
+		return nil, mapRoleError(err)
+	}
+	return toDomainRole(role), nil
+}
+
+func (r *domainRoleRepository) FindByName(ctx context.Context, name string) (*repositories.Role, error) {
+	role, err := r.roles.GetByName(name)
+	if err != nil {
+		return nil, mapRoleError(err)
+	}
+	return toDomainRole(role), nil
+}
+
+func (r *domainRoleRepository) List(ctx context.Context) ([]*repositories.Role, error) {
+	roles, err := r.roles.List()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]*repositories.Role, len(roles))
+	for i, role := range roles {
+		result[i] = toDomainRole(role)
+	}
+	return result, nil
+}
+
+func (r *domainRoleRepository) Create(ctx context.Context, role *repositories.Role) error {
+	created := &models.Role{Name: role.Name, Description: role.Description, RequiresApproval: role.RequiresApproval}
+	if err := r.roles.Create(created); err != nil {
+		return err
+	}
+	role.ID = created.ID
+	return nil
+}
+
+func (r *domainRoleRepository) AssignToUser(ctx context.Context, userID, roleID string) error {
+	return mapRoleError(r.roles.AssignToUser(userID, roleID))
+}
+
+func (r *domainRoleRepository) RevokeFromUser(ctx context.Context, userID, roleID string) error {
+	return mapRoleError(r.roles.RemoveFromUser(userID, roleID))
+}
+
+func toDomainRole(role *models.Role) *repositories.Role {
+	return &repositories.Role{
+		ID:          role.ID,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: role.Permissions,
+
+		RequiresApproval: role.RequiresApproval,
+	}
+}
+
+func mapRoleError(err error) error {
+	if errors.Is(err, ErrRoleNotFound) {
+		return repositories.ErrRoleNotFound
+	}
+	return err
+}
@@ -0,0 +1,681 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/events"
+)
+
+var (
+	ErrRoleRuleExists       = errors.New("role rule already exists")
+	ErrRoleRuleNotFound     = errors.New("role rule not found")
+	ErrRoleExcluded         = errors.New("role conflicts with a role the user holds")
+	ErrRoleNotAssigned      = errors.New("role is not assigned to the user")
+	ErrGrantRequestNotFound = errors.New("role grant request not found")
+	ErrGrantRequestPending  = errors.New("a grant request for this role is already pending")
+	ErrGrantRequestDecided  = errors.New("role grant request was already decided")
+	ErrGrantRequestExpired  = errors.New("requested assignment has already expired")
+)
+
+// RoleRevokeReasonExpired is recorded on UserRoleRevoked events raised by the
+// expiry sweep
+const RoleRevokeReasonExpired = "expired"
+
+// RoleGrantRequestFilter narrows grant request listings
+type RoleGrantRequestFilter struct {
+	Status string
+	UserID string
+	Limit  int
+}
+
+// RoleGovernanceRepository stores delegation and separation-of-duties rules,
+// time-bound assignments and grant requests. Every change to a user's roles
+// bumps users.version and writes its event to the outbox in one transaction.
+type RoleGovernanceRepository interface {
+	ListDelegations(ctx context.Context) ([]*models.RoleDelegation, error)
+	CreateDelegation(ctx context.Context, delegation *models.RoleDelegation) error
+	DeleteDelegation(ctx context.Context, id string) error
+	DelegatedRoles(ctx context.Context, grantorRoles []string) ([]string, error)
+
+	ListExclusions(ctx context.Context) ([]*models.RoleExclusion, error)
+	CreateExclusion(ctx context.Context, exclusion *models.RoleExclusion) error
+	DeleteExclusion(ctx context.Context, id string) error
+	ConflictingRoles(ctx context.Context, userID, roleID string) ([]string, error)
+
+	UserAssignments(ctx context.Context, userID string) ([]*models.RoleAssignment, error)
+	GrantRole(ctx context.Context, userID, roleID, assignedBy string, expiresAt *time.Time) error
+	RevokeRole(ctx context.Context, userID, roleID, revokedBy, reason string) error
+	ExpireAssignments(ctx context.Context, limit int) (int, error)
+
+	CreateGrantRequest(ctx context.Context, req *models.RoleGrantRequest) error
+	GetGrantRequest(ctx context.Context, id string) (*models.RoleGrantRequest, error)
+	ListGrantRequests(ctx context.Context, filter RoleGrantRequestFilter) ([]*models.RoleGrantRequest, error)
+	ApproveGrantRequest(ctx context.Context, id, decidedBy, note string) (*models.RoleGrantRequest, error)
+	RejectGrantRequest(ctx context.Context, id, decidedBy, note string) (*models.RoleGrantRequest, error)
+}
+
+type roleGovernanceRepository struct {
+	db *sql.DB
+}
+
+func NewRoleGovernanceRepository(db *sql.DB) RoleGovernanceRepository {
+	return &roleGovernanceRepository{db: db}
+}
+
+func (r *roleGovernanceRepository) ListDelegations(ctx context.Context) ([]*models.RoleDelegation, error) {
+	query := `
+		SELECT d.id, d.grantor_role_id, g.name, d.assignable_role_id, a.name,
+		       COALESCE(d.created_by::text, ''), d.created_at
+		FROM role_delegations d
+		JOIN roles g ON g.id = d.grantor_role_id
+		JOIN roles a ON a.id = d.assignable_role_id
+		ORDER BY g.name, a.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	delegations := []*models.RoleDelegation{}
+	for rows.Next() {
+		var d models.RoleDelegation
+		if err := rows.Scan(
+			&d.ID, &d.GrantorRoleID, &d.GrantorRole, &d.AssignableRoleID, &d.AssignableRole,
+			&d.CreatedBy, &d.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		delegations = append(delegations, &d)
+	}
+	return delegations, rows.Err()
+}
+
+func (r *roleGovernanceRepository) CreateDelegation(ctx context.Context, delegation *models.RoleDelegation) error {
+	query := `
+		INSERT INTO role_delegations (grantor_role_id, assignable_role_id, created_by)
+		VALUES ($1, $2, NULLIF($3, '')::uuid)
+		RETURNING id, created_at,
+		          (SELECT name FROM roles WHERE id = $1), (SELECT name FROM roles WHERE id = $2)
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delegation.GrantorRoleID, delegation.AssignableRoleID, delegation.CreatedBy,
+	).Scan(&delegation.ID, &delegation.CreatedAt, &delegation.GrantorRole, &delegation.AssignableRole)
+	return ruleWriteError(err)
+}
+
+func (r *roleGovernanceRepository) DeleteDelegation(ctx context.Context, id string) error {
+	return r.deleteRule(ctx, `DELETE FROM role_delegations WHERE id = $1`, id)
+}
+
+// DelegatedRoles returns the names of the roles that holders of any of
+// grantorRoles may assign
+func (r *roleGovernanceRepository) DelegatedRoles(ctx context.Context, grantorRoles []string) ([]string, error) {
+	query := `
+		SELECT DISTINCT a.name
+		FROM role_delegations d
+		JOIN roles g ON g.id = d.grantor_role_id
+		JOIN roles a ON a.id = d.assignable_role_id
+		WHERE g.name = ANY($1)
+		ORDER BY a.name
+	`
+	return r.names(ctx, query, pq.StringArray(grantorRoles))
+}
+
+func (r *roleGovernanceRepository) ListExclusions(ctx context.Context) ([]*models.RoleExclusion, error) {
+	query := `
+		SELECT e.id, e.role_a_id, a.name, e.role_b_id, b.name,
+		       COALESCE(e.reason, ''), COALESCE(e.created_by::text, ''), e.created_at
+		FROM role_exclusions e
+		JOIN roles a ON a.id = e.role_a_id
+		JOIN roles b ON b.id = e.role_b_id
+		ORDER BY a.name, b.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	exclusions := []*models.RoleExclusion{}
+	for rows.Next() {
+		var e models.RoleExclusion
+		if err := rows.Scan(
+			&e.ID, &e.RoleAID, &e.RoleA, &e.RoleBID, &e.RoleB,
+			&e.Reason, &e.CreatedBy, &e.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		exclusions = append(exclusions, &e)
+	}
+	return exclusions, rows.Err()
+}
+
+// CreateExclusion stores the pair in canonical order so (a, b) and (b, a)
+// are the same rule
+func (r *roleGovernanceRepository) CreateExclusion(ctx context.Context, exclusion *models.RoleExclusion) error {
+	query := `
+		INSERT INTO role_exclusions (role_a_id, role_b_id, reason, created_by)
+		VALUES (LEAST($1::uuid, $2::uuid), GREATEST($1::uuid, $2::uuid), NULLIF($3, ''), NULLIF($4, '')::uuid)
+		RETURNING id, role_a_id, role_b_id, created_at,
+		          (SELECT name FROM roles WHERE id = role_a_id), (SELECT name FROM roles WHERE id = role_b_id)
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		exclusion.RoleAID, exclusion.RoleBID, exclusion.Reason, exclusion.CreatedBy,
+	).Scan(&exclusion.ID, &exclusion.RoleAID, &exclusion.RoleBID, &exclusion.CreatedAt, &exclusion.RoleA, &exclusion.RoleB)
+	return ruleWriteError(err)
+}
+
+func (r *roleGovernanceRepository) DeleteExclusion(ctx context.Context, id string) error {
+	return r.deleteRule(ctx, `DELETE FROM role_exclusions WHERE id = $1`, id)
+}
+
+// ConflictingRoles returns the active roles of the user that are mutually
+// exclusive with roleID
+func (r *roleGovernanceRepository) ConflictingRoles(ctx context.Context, userID, roleID string) ([]string, error) {
+	return conflictingRoles(ctx, r.db, userID, roleID)
+}
+
+func (r *roleGovernanceRepository) UserAssignments(ctx context.Context, userID string) ([]*models.RoleAssignment, error) {
+	query := `
+		SELECT ur.role_id, r.name, COALESCE(ur.assigned_by::text, ''),
+		       COALESCE(ur.assigned_at, CURRENT_TIMESTAMP), ur.expires_at
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1 AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+		ORDER BY r.name
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return []*models.RoleAssignment{}, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	assignments := []*models.RoleAssignment{}
+	for rows.Next() {
+		var a models.RoleAssignment
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&a.RoleID, &a.RoleName, &a.AssignedBy, &a.AssignedAt, &expiresAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		a.ExpiresAt = nullTimePtr(expiresAt)
+		assignments = append(assignments, &a)
+	}
+	return assignments, rows.Err()
+}
+
+// GrantRole assigns the role, or replaces the expiry of an existing
+// assignment. Exclusions are re-checked under the user's row lock so two
+// concurrent grants cannot both succeed.
+func (r *roleGovernanceRepository) GrantRole(ctx context.Context, userID, roleID, assignedBy string, expiresAt *time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	if err := grantRoleTx(ctx, tx, userID, roleID, assignedBy, expiresAt); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *roleGovernanceRepository) RevokeRole(ctx context.Context, userID, roleID, revokedBy, reason string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	if err := revokeRoleTx(ctx, tx, userID, roleID, revokedBy, reason); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+// ExpireAssignments removes up to limit lapsed assignments and records a
+// UserRoleRevoked event for each, and closes elevations that have run out.
+// Expired rows already stop granting access when they lapse; the sweep makes
+// the revocation visible downstream.
+func (r *roleGovernanceRepository) ExpireAssignments(ctx context.Context, limit int) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE r.id = ur.role_id AND (ur.user_id, ur.role_id) IN (
+			SELECT user_id, role_id FROM user_roles
+			WHERE expires_at <= NOW()
+			ORDER BY expires_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ur.user_id, ur.role_id, r.name
+	`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	var revoked []events.DomainEvent
+	users := make(map[string]bool)
+	for rows.Next() {
+		var userID, roleID, roleName string
+		if err := rows.Scan(&userID, &roleID, &roleName); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		users[userID] = true
+		revoked = append(revoked, events.UserRoleRevoked{
+			BaseEvent: newBaseEvent("atlas.user.role_revoked", userID),
+			RoleName:  roleName,
+			RoleID:    roleID,
+			Reason:    RoleRevokeReasonExpired,
+		})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	closeElevations := `
+		UPDATE role_elevations SET status = 'expired', ended_at = expires_at
+		WHERE status = 'active' AND expires_at <= NOW()
+	`
+	if _, err := tx.ExecContext(ctx, closeElevations); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	for userID := range users {
+		if err := touchUser(ctx, tx, userID); err != nil && !errors.Is(err, ErrUserNotFound) {
+			return 0, err
+		}
+	}
+	if len(revoked) > 0 {
+		if err := enqueueEvents(ctx, tx, revoked...); err != nil {
+			return 0, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return len(revoked), nil
+}
+
+func (r *roleGovernanceRepository) CreateGrantRequest(ctx context.Context, req *models.RoleGrantRequest) error {
+	query := `
+		INSERT INTO role_grant_requests (user_id, role_id, requested_by, reason, grant_expires_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, NULLIF($4, ''), $5)
+		RETURNING id, status, created_at, (SELECT name FROM roles WHERE id = $2)
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		req.UserID, req.RoleID, req.RequestedBy, req.Reason, req.GrantExpiresAt,
+	).Scan(&req.ID, &req.Status, &req.CreatedAt, &req.RoleName)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return ErrGrantRequestPending
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+const selectGrantRequest = `
+	SELECT g.id, g.user_id, g.role_id, r.name, COALESCE(g.requested_by::text, ''),
+	       COALESCE(g.reason, ''), g.grant_expires_at, g.status,
+	       COALESCE(g.decided_by::text, ''), COALESCE(g.decision_note, ''), g.decided_at, g.created_at
+	FROM role_grant_requests g
+	JOIN roles r ON r.id = g.role_id
+`
+
+func (r *roleGovernanceRepository) GetGrantRequest(ctx context.Context, id string) (*models.RoleGrantRequest, error) {
+	req, err := scanGrantRequest(r.db.QueryRowContext(ctx, selectGrantRequest+` WHERE g.id = $1`, id))
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return nil, ErrGrantRequestNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return req, nil
+}
+
+func (r *roleGovernanceRepository) ListGrantRequests(ctx context.Context, filter RoleGrantRequestFilter) ([]*models.RoleGrantRequest, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Status != "" {
+		args = append(args, filter.Status)
+		conditions = append(conditions, fmt.Sprintf("g.status = $%d", len(args)))
+	}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("g.user_id::text = $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit < 1 || limit > 500 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	query := selectGrantRequest
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY g.created_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	requests := []*models.RoleGrantRequest{}
+	for rows.Next() {
+		req, err := scanGrantRequest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		requests = append(requests, req)
+	}
+	return requests, rows.Err()
+}
+
+// ApproveGrantRequest marks a pending request approved and grants the role in
+// the same transaction
+func (r *roleGovernanceRepository) ApproveGrantRequest(ctx context.Context, id, decidedBy, note string) (*models.RoleGrantRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	req, err := decideGrantRequest(ctx, tx, id, models.GrantRequestApproved, decidedBy, note)
+	if err != nil {
+		return nil, err
+	}
+	if req.GrantExpiresAt != nil && !req.GrantExpiresAt.After(time.Now()) {
+		return nil, ErrGrantRequestExpired
+	}
+
+	assignedBy := req.RequestedBy
+	if assignedBy == "" {
+		assignedBy = decidedBy
+	}
+	if err := grantRoleTx(ctx, tx, req.UserID, req.RoleID, assignedBy, req.GrantExpiresAt); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return req, nil
+}
+
+func (r *roleGovernanceRepository) RejectGrantRequest(ctx context.Context, id, decidedBy, note string) (*models.RoleGrantRequest, error) {
+	return decideGrantRequest(ctx, r.db, id, models.GrantRequestRejected, decidedBy, note)
+}
+
+func (r *roleGovernanceRepository) deleteRule(ctx context.Context, query, id string) error {
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrRoleRuleNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrRoleRuleNotFound
+	}
+	return nil
+}
+
+func (r *roleGovernanceRepository) names(ctx context.Context, query string, args ...interface{}) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+func grantRoleTx(ctx context.Context, tx *sql.Tx, userID, roleID, assignedBy string, expiresAt *time.Time) error {
+	// Bumping the version first locks the user row for the rest of the transaction
+	if err := touchUser(ctx, tx, userID); err != nil {
+		return err
+	}
+
+	conflicts, err := conflictingRoles(ctx, tx, userID, roleID)
+	if err != nil {
+		return err
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrRoleExcluded, strings.Join(conflicts, ", "))
+	}
+
+	var roleName string
+	query := `
+		INSERT INTO user_roles (user_id, role_id, assigned_by, expires_at)
+		VALUES ($1, $2, NULLIF($3, '')::uuid, $4)
+		ON CONFLICT (user_id, role_id) DO UPDATE
+		SET assigned_by = EXCLUDED.assigned_by, expires_at = EXCLUDED.expires_at, assigned_at = CURRENT_TIMESTAMP
+		RETURNING (SELECT name FROM roles WHERE id = $2)
+	`
+	if err := tx.QueryRowContext(ctx, query, userID, roleID, assignedBy, expiresAt).Scan(&roleName); err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+			return ErrRoleNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	assigned := events.UserRoleAssigned{
+		BaseEvent:  newBaseEvent("atlas.user.role_assigned", userID),
+		RoleName:   roleName,
+		RoleID:     roleID,
+		AssignedBy: assignedBy,
+		ExpiresAt:  expiresAt,
+	}
+	return enqueueEvents(ctx, tx, assigned)
+}
+
+// revokeRoleTx removes an active assignment and ends any elevation that
+// granted it
+func revokeRoleTx(ctx context.Context, tx *sql.Tx, userID, roleID, revokedBy, reason string) error {
+	var roleName string
+	query := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE r.id = ur.role_id AND ur.user_id = $1 AND ur.role_id = $2
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+		RETURNING r.name
+	`
+	if err := tx.QueryRowContext(ctx, query, userID, roleID).Scan(&roleName); err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return ErrRoleNotAssigned
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	endElevation := `
+		UPDATE role_elevations
+		SET status = 'ended', ended_by = NULLIF($3, '')::uuid, ended_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND role_id = $2 AND status = 'active'
+	`
+	if _, err := tx.ExecContext(ctx, endElevation, userID, roleID, revokedBy); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	if err := touchUser(ctx, tx, userID); err != nil {
+		return err
+	}
+	revoked := events.UserRoleRevoked{
+		BaseEvent: newBaseEvent("atlas.user.role_revoked", userID),
+		RoleName:  roleName,
+		RoleID:    roleID,
+		RevokedBy: revokedBy,
+		Reason:    reason,
+	}
+	return enqueueEvents(ctx, tx, revoked)
+}
+
+func conflictingRoles(ctx context.Context, q queryer, userID, roleID string) ([]string, error) {
+	query := `
+		SELECT r.name
+		FROM role_exclusions e
+		JOIN user_roles ur ON ur.role_id = CASE WHEN e.role_a_id = $2 THEN e.role_b_id ELSE e.role_a_id END
+		JOIN roles r ON r.id = ur.role_id
+		WHERE (e.role_a_id = $2 OR e.role_b_id = $2) AND ur.user_id = $1
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+		ORDER BY r.name
+	`
+
+	rows, err := q.QueryContext(ctx, query, userID, roleID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
+
+func decideGrantRequest(ctx context.Context, q queryer, id, status, decidedBy, note string) (*models.RoleGrantRequest, error) {
+	query := `
+		WITH decided AS (
+			UPDATE role_grant_requests
+			SET status = $2, decided_by = NULLIF($3, '')::uuid, decision_note = NULLIF($4, ''), decided_at = CURRENT_TIMESTAMP
+			WHERE id = $1 AND status = 'pending'
+			RETURNING *
+		)
+		SELECT g.id, g.user_id, g.role_id, r.name, COALESCE(g.requested_by::text, ''),
+		       COALESCE(g.reason, ''), g.grant_expires_at, g.status,
+		       COALESCE(g.decided_by::text, ''), COALESCE(g.decision_note, ''), g.decided_at, g.created_at
+		FROM decided g
+		JOIN roles r ON r.id = g.role_id
+	`
+
+	req, err := scanGrantRequest(q.QueryRowContext(ctx, query, id, status, decidedBy, note))
+	if err == nil {
+		return req, nil
+	}
+	if isInvalidUUID(err) {
+		return nil, ErrGrantRequestNotFound
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	var exists bool
+	if err := q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM role_grant_requests WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if !exists {
+		return nil, ErrGrantRequestNotFound
+	}
+	return nil, ErrGrantRequestDecided
+}
+
+// touchUser bumps users.version so aggregate writes loaded before a role
+// change fail their optimistic concurrency check instead of reverting it
+func touchUser(ctx context.Context, exec execer, userID string) error {
+	result, err := exec.ExecContext(ctx,
+		`UPDATE users SET version = version + 1, updated_at = CURRENT_TIMESTAMP WHERE id = $1`, userID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func scanGrantRequest(row rowScanner) (*models.RoleGrantRequest, error) {
+	var req models.RoleGrantRequest
+	var grantExpiresAt, decidedAt sql.NullTime
+	if err := row.Scan(
+		&req.ID, &req.UserID, &req.RoleID, &req.RoleName, &req.RequestedBy,
+		&req.Reason, &grantExpiresAt, &req.Status,
+		&req.DecidedBy, &req.DecisionNote, &decidedAt, &req.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+	req.GrantExpiresAt = nullTimePtr(grantExpiresAt)
+	req.DecidedAt = nullTimePtr(decidedAt)
+	return &req, nil
+}
+
+// ruleWriteError maps constraint violations on rule inserts: a duplicate rule
+// or an unknown role
+func ruleWriteError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if pqErr, ok := err.(*pq.Error); ok {
+		switch pqErr.Code {
+		case "23505":
+			return ErrRoleRuleExists
+		case "23503", "22P02":
+			return ErrRoleNotFound
+		}
+	}
+	return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+}
+
+// isInvalidUUID reports a malformed UUID parameter, which callers treat as a
+// missing row
+func isInvalidUUID(err error) bool {
+	pqErr, ok := err.(*pq.Error)
+	return ok && pqErr.Code == "22P02"
+}
@@ -0,0 +1,451 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/iam/internal/domain"
+)
+
+var (
+	ErrSettingReadOnly = errors.New("setting is read-only")
+)
+
+// SettingChangeFilter narrows the settings history
+type SettingChangeFilter struct {
+	Scope    string
+	UserID   string
+	Category string
+	Limit    int
+}
+
+// SettingsRepository stores user settings, system configuration and
+// notification preferences. Every write that changes a value appends to
+// settings_history in the same transaction.
+type SettingsRepository interface {
+	UserSettings(ctx context.Context, userID string) ([]*models.UserSetting, error)
+	SaveUserSettings(ctx context.Context, userID, changedBy string, values []*models.UserSetting) error
+
+	SystemSettings(ctx context.Context, namespace string) ([]*models.SystemSetting, error)
+	SaveSystemSetting(ctx context.Context, setting *models.SystemSetting) error
+
+	NotificationPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error)
+	SaveNotificationPreferences(ctx context.Context, userID, changedBy string, prefs []*models.NotificationPreference) error
+
+	History(ctx context.Context, filter SettingChangeFilter) ([]*models.SettingChange, error)
+}
+
+type settingsRepository struct {
+	db *sql.DB
+}
+
+func NewSettingsRepository(db *sql.DB) SettingsRepository {
+	return &settingsRepository{db: db}
+}
+
+func (r *settingsRepository) UserSettings(ctx context.Context, userID string) ([]*models.UserSetting, error) {
+	query := `
+		SELECT category, key, value, COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+		FROM user_settings
+		WHERE user_id = $1
+		ORDER BY category, key
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	values := []*models.UserSetting{}
+	for rows.Next() {
+		var s models.UserSetting
+		var value []byte
+		if err := rows.Scan(&s.Category, &s.Key, &value, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		s.Value = value
+		values = append(values, &s)
+	}
+	return values, rows.Err()
+}
+
+// SaveUserSettings applies values in one transaction. A nil Value removes
+// the override so the default applies again.
+func (r *settingsRepository) SaveUserSettings(ctx context.Context, userID, changedBy string, values []*models.UserSetting) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	for _, s := range values {
+		var old []byte
+		err := tx.QueryRowContext(ctx,
+			`SELECT value FROM user_settings WHERE user_id = $1 AND category = $2 AND key = $3 FOR UPDATE`,
+			userID, s.Category, s.Key,
+		).Scan(&old)
+		if err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+
+		var current []byte
+		if s.Value == nil {
+			if old == nil {
+				continue
+			}
+			if _, err := tx.ExecContext(ctx,
+				`DELETE FROM user_settings WHERE user_id = $1 AND category = $2 AND key = $3`,
+				userID, s.Category, s.Key,
+			); err != nil {
+				return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+			}
+		} else {
+			query := `
+				INSERT INTO user_settings (user_id, category, key, value)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (user_id, category, key) DO UPDATE
+				SET value = EXCLUDED.value, updated_at = CURRENT_TIMESTAMP
+				RETURNING value, updated_at
+			`
+			if err := tx.QueryRowContext(ctx, query, userID, s.Category, s.Key, []byte(s.Value)).Scan(&current, &s.UpdatedAt); err != nil {
+				if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+					return ErrUserNotFound
+				}
+				return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+			}
+			s.Value = current
+		}
+
+		if string(old) == string(current) {
+			continue
+		}
+		if err := recordSettingChange(ctx, tx, &models.SettingChange{
+			Scope:     models.SettingScopeUser,
+			UserID:    userID,
+			Category:  s.Category,
+			Key:       s.Key,
+			OldValue:  old,
+			NewValue:  current,
+			ChangedBy: changedBy,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *settingsRepository) SystemSettings(ctx context.Context, namespace string) ([]*models.SystemSetting, error) {
+	query := `
+		SELECT namespace, key, value, COALESCE(description, ''), COALESCE(is_sensitive, false),
+		       COALESCE(is_readonly, false), COALESCE(updated_by::text, ''),
+		       COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+		FROM system_config
+		WHERE $1 = '' OR namespace = $1
+		ORDER BY namespace, key
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	values := []*models.SystemSetting{}
+	for rows.Next() {
+		var s models.SystemSetting
+		var value []byte
+		if err := rows.Scan(&s.Namespace, &s.Key, &value, &s.Description, &s.Sensitive, &s.ReadOnly, &s.UpdatedBy, &s.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		s.Value = value
+		values = append(values, &s)
+	}
+	return values, rows.Err()
+}
+
+// SaveSystemSetting creates or updates a system setting. Read-only settings
+// are refused; history for sensitive settings omits the values.
+func (r *settingsRepository) SaveSystemSetting(ctx context.Context, setting *models.SystemSetting) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	var old []byte
+	var sensitive, readOnly bool
+	err = tx.QueryRowContext(ctx, `
+		SELECT value, COALESCE(is_sensitive, false), COALESCE(is_readonly, false)
+		FROM system_config WHERE namespace = $1 AND key = $2
+		FOR UPDATE
+	`, setting.Namespace, setting.Key).Scan(&old, &sensitive, &readOnly)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if readOnly {
+		return ErrSettingReadOnly
+	}
+
+	query := `
+		INSERT INTO system_config (namespace, key, value, description, updated_by)
+		VALUES ($1, $2, $3, NULLIF($4, ''), NULLIF($5, '')::uuid)
+		ON CONFLICT (namespace, key) DO UPDATE
+		SET value = EXCLUDED.value,
+		    description = COALESCE(EXCLUDED.description, system_config.description),
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = CURRENT_TIMESTAMP
+		RETURNING value, COALESCE(description, ''), COALESCE(is_sensitive, false), COALESCE(updated_at, CURRENT_TIMESTAMP)
+	`
+	var current []byte
+	if err := tx.QueryRowContext(ctx, query,
+		setting.Namespace, setting.Key, []byte(setting.Value), setting.Description, setting.UpdatedBy,
+	).Scan(&current, &setting.Description, &setting.Sensitive, &setting.UpdatedAt); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	setting.Value = current
+
+	if string(old) != string(current) {
+		change := &models.SettingChange{
+			Scope:     models.SettingScopeSystem,
+			Category:  setting.Namespace,
+			Key:       setting.Key,
+			OldValue:  old,
+			NewValue:  current,
+			ChangedBy: setting.UpdatedBy,
+		}
+		if sensitive {
+			change.OldValue, change.NewValue, change.Redacted = nil, nil, true
+		}
+		if err := recordSettingChange(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *settingsRepository) NotificationPreferences(ctx context.Context, userID string) ([]*models.NotificationPreference, error) {
+	query := `
+		SELECT notification_type, channel, COALESCE(is_enabled, true),
+		       COALESCE(to_char(quiet_hours_start, 'HH24:MI'), ''), COALESCE(to_char(quiet_hours_end, 'HH24:MI'), ''),
+		       COALESCE(min_priority, 'low'), COALESCE(metadata->>'target', ''), COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY notification_type, channel
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	prefs := []*models.NotificationPreference{}
+	for rows.Next() {
+		p, err := scanNotificationPreference(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		prefs = append(prefs, p)
+	}
+	return prefs, rows.Err()
+}
+
+// SaveNotificationPreferences upserts preferences in one transaction
+func (r *settingsRepository) SaveNotificationPreferences(ctx context.Context, userID, changedBy string, prefs []*models.NotificationPreference) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	for _, p := range prefs {
+		previous, err := scanNotificationPreference(tx.QueryRowContext(ctx, `
+			SELECT notification_type, channel, COALESCE(is_enabled, true),
+			       COALESCE(to_char(quiet_hours_start, 'HH24:MI'), ''), COALESCE(to_char(quiet_hours_end, 'HH24:MI'), ''),
+			       COALESCE(min_priority, 'low'), COALESCE(metadata->>'target', ''), COALESCE(updated_at, created_at, CURRENT_TIMESTAMP)
+			FROM notification_preferences
+			WHERE user_id = $1 AND notification_type = $2 AND channel = $3
+			FOR UPDATE
+		`, userID, p.NotificationType, p.Channel))
+		if err != nil && err != sql.ErrNoRows {
+			if isInvalidUUID(err) {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+
+		query := `
+			INSERT INTO notification_preferences (
+				user_id, notification_type, channel, is_enabled, quiet_hours_start, quiet_hours_end, min_priority, metadata
+			)
+			VALUES ($1, $2, $3, $4, NULLIF($5, '')::time, NULLIF($6, '')::time, $7,
+			        jsonb_strip_nulls(jsonb_build_object('target', NULLIF($8, ''))))
+			ON CONFLICT (user_id, notification_type, channel) DO UPDATE
+			SET is_enabled = EXCLUDED.is_enabled,
+			    quiet_hours_start = EXCLUDED.quiet_hours_start,
+			    quiet_hours_end = EXCLUDED.quiet_hours_end,
+			    min_priority = EXCLUDED.min_priority,
+			    metadata = (COALESCE(notification_preferences.metadata, '{}'::jsonb) - 'target') || EXCLUDED.metadata,
+			    updated_at = CURRENT_TIMESTAMP
+			RETURNING updated_at
+		`
+		if err := tx.QueryRowContext(ctx, query,
+			userID, p.NotificationType, p.Channel, p.Enabled, p.QuietHoursStart, p.QuietHoursEnd, p.MinPriority, p.Target,
+		).Scan(&p.UpdatedAt); err != nil {
+			if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23503" {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+
+		var old json.RawMessage
+		if previous != nil {
+			old = preferenceValue(previous)
+		}
+		current := preferenceValue(p)
+		if string(old) == string(current) {
+			continue
+		}
+		if err := recordSettingChange(ctx, tx, &models.SettingChange{
+			Scope:     models.SettingScopeNotification,
+			UserID:    userID,
+			Category:  p.NotificationType,
+			Key:       p.Channel,
+			OldValue:  old,
+			NewValue:  current,
+			ChangedBy: changedBy,
+		}); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *settingsRepository) History(ctx context.Context, filter SettingChangeFilter) ([]*models.SettingChange, error) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Scope != "" {
+		args = append(args, filter.Scope)
+		conditions = append(conditions, fmt.Sprintf("scope = $%d", len(args)))
+	}
+	if filter.UserID != "" {
+		args = append(args, filter.UserID)
+		conditions = append(conditions, fmt.Sprintf("user_id::text = $%d", len(args)))
+	}
+	if filter.Category != "" {
+		args = append(args, filter.Category)
+		conditions = append(conditions, fmt.Sprintf("category = $%d", len(args)))
+	}
+
+	limit := filter.Limit
+	if limit < 1 || limit > 1000 {
+		limit = 100
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT id, scope, COALESCE(user_id::text, ''), category, key, old_value, new_value, redacted,
+		       COALESCE(changed_by::text, ''), COALESCE(changed_at, CURRENT_TIMESTAMP)
+		FROM settings_history
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY changed_at DESC LIMIT $%d", len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	changes := []*models.SettingChange{}
+	for rows.Next() {
+		var c models.SettingChange
+		var old, current []byte
+		if err := rows.Scan(&c.ID, &c.Scope, &c.UserID, &c.Category, &c.Key, &old, &current, &c.Redacted, &c.ChangedBy, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		c.OldValue, c.NewValue = old, current
+		changes = append(changes, &c)
+	}
+	return changes, rows.Err()
+}
+
+func recordSettingChange(ctx context.Context, exec execer, change *models.SettingChange) error {
+	query := `
+		INSERT INTO settings_history (scope, user_id, category, key, old_value, new_value, redacted, changed_by)
+		VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6, $7, NULLIF($8, '')::uuid)
+	`
+	_, err := exec.ExecContext(ctx, query,
+		change.Scope, change.UserID, change.Category, change.Key,
+		nullableJSON(change.OldValue), nullableJSON(change.NewValue), change.Redacted, change.ChangedBy,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: failed to record setting change: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func scanNotificationPreference(row rowScanner) (*models.NotificationPreference, error) {
+	var p models.NotificationPreference
+	if err := row.Scan(&p.NotificationType, &p.Channel, &p.Enabled, &p.QuietHoursStart, &p.QuietHoursEnd, &p.MinPriority, &p.Target, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// preferenceValue is the history representation of a preference. Webhook
+// and Slack URLs often embed a secret in the path, so only the target's
+// host is kept.
+func preferenceValue(p *models.NotificationPreference) json.RawMessage {
+	value, _ := json.Marshal(map[string]interface{}{
+		"is_enabled":        p.Enabled,
+		"quiet_hours_start": p.QuietHoursStart,
+		"quiet_hours_end":   p.QuietHoursEnd,
+		"min_priority":      p.MinPriority,
+		"target_host":       targetHost(p.Target),
+	})
+	return value
+}
+
+func targetHost(target string) string {
+	if u, err := url.Parse(target); err == nil {
+		return u.Host
+	}
+	return ""
+}
+
+func nullableJSON(value json.RawMessage) interface{} {
+	if value == nil {
+		return nil
+	}
+	return []byte(value)
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/lib/pq"
 	models "atlas-core-api/services/iam/internal/domain"
+	"atlas-core-api/services/iam/internal/domain/events"
 )
 
 var (
@@ -24,7 +26,9 @@ type UserRepository interface {
 	Update(user *models.User) error
 	Delete(id string) error
 	List(offset, limit int) ([]*models.User, int, error)
-	UpdateLastLogin(id string) error
+	UpdateLastLogin(id, ipAddress, userAgent string) error
+	UpdatePassword(id, passwordHash, reason string) error
+	MarkVerified(id string) error
 	Deactivate(id string) error
 }
 
@@ -39,20 +43,23 @@ func NewUserRepository(db *sql.DB) UserRepository {
 func (r *userRepository) GetByID(id string) (*models.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.is_active,
+		       COALESCE(u.is_verified, false), u.password_changed_at,
 		       u.created_at::text, u.updated_at::text,
 		       COALESCE(array_agg(r.name) FILTER (WHERE r.name IS NOT NULL), '{}') as roles
 		FROM users u
-		LEFT JOIN user_roles ur ON u.id = ur.user_id
+		LEFT JOIN user_roles ur ON u.id = ur.user_id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
 		LEFT JOIN roles r ON ur.role_id = r.id
 		WHERE u.id = $1
-		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.created_at, u.updated_at
+		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.is_verified, u.password_changed_at, u.created_at, u.updated_at
 	`
 
 	var user models.User
 	var roles pq.StringArray
+	var passwordChangedAt sql.NullTime
 	err := r.db.QueryRow(query, id).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.Active, &user.CreatedAt, &user.UpdatedAt, &roles,
+		&user.Active, &user.Verified, &passwordChangedAt,
+		&user.CreatedAt, &user.UpdatedAt, &roles,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -61,6 +68,9 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
 
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	user.Roles = []string(roles)
 	return &user, nil
 }
@@ -68,20 +78,23 @@ func (r *userRepository) GetByID(id string) (*models.User, error) {
 func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.is_active,
+		       COALESCE(u.is_verified, false), u.password_changed_at,
 		       u.created_at::text, u.updated_at::text,
 		       COALESCE(array_agg(r.name) FILTER (WHERE r.name IS NOT NULL), '{}') as roles
 		FROM users u
-		LEFT JOIN user_roles ur ON u.id = ur.user_id
+		LEFT JOIN user_roles ur ON u.id = ur.user_id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
 		LEFT JOIN roles r ON ur.role_id = r.id
 		WHERE u.username = $1 AND u.is_active = true
-		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.created_at, u.updated_at
+		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.is_verified, u.password_changed_at, u.created_at, u.updated_at
 	`
 
 	var user models.User
 	var roles pq.StringArray
+	var passwordChangedAt sql.NullTime
 	err := r.db.QueryRow(query, username).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.Active, &user.CreatedAt, &user.UpdatedAt, &roles,
+		&user.Active, &user.Verified, &passwordChangedAt,
+		&user.CreatedAt, &user.UpdatedAt, &roles,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -90,6 +103,9 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
 
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	user.Roles = []string(roles)
 	return &user, nil
 }
@@ -97,20 +113,23 @@ func (r *userRepository) GetByUsername(username string) (*models.User, error) {
 func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.is_active,
+		       COALESCE(u.is_verified, false), u.password_changed_at,
 		       u.created_at::text, u.updated_at::text,
 		       COALESCE(array_agg(r.name) FILTER (WHERE r.name IS NOT NULL), '{}') as roles
 		FROM users u
-		LEFT JOIN user_roles ur ON u.id = ur.user_id
+		LEFT JOIN user_roles ur ON u.id = ur.user_id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
 		LEFT JOIN roles r ON ur.role_id = r.id
 		WHERE u.email = $1 AND u.is_active = true
-		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.created_at, u.updated_at
+		GROUP BY u.id, u.username, u.email, u.password_hash, u.is_active, u.is_verified, u.password_changed_at, u.created_at, u.updated_at
 	`
 
 	var user models.User
 	var roles pq.StringArray
+	var passwordChangedAt sql.NullTime
 	err := r.db.QueryRow(query, email).Scan(
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
-		&user.Active, &user.CreatedAt, &user.UpdatedAt, &roles,
+		&user.Active, &user.Verified, &passwordChangedAt,
+		&user.CreatedAt, &user.UpdatedAt, &roles,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -119,6 +138,9 @@ func (r *userRepository) GetByEmail(email string) (*models.User, error) {
 		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
 
+	if passwordChangedAt.Valid {
+		user.PasswordChangedAt = &passwordChangedAt.Time
+	}
 	user.Roles = []string(roles)
 	return &user, nil
 }
@@ -152,18 +174,32 @@ func (r *userRepository) Create(user *models.User) error {
 		SELECT $1, id FROM roles WHERE name = 'viewer'
 		ON CONFLICT (user_id, role_id) DO NOTHING
 	`
-	_, err = tx.Exec(roleQuery, user.ID)
+	result, err := tx.Exec(roleQuery, user.ID)
 	if err != nil {
 		return fmt.Errorf("%w: failed to assign default role: %v", ErrDatabaseOperation, err)
 	}
 
+	roles := []string{}
+	if n, _ := result.RowsAffected(); n > 0 {
+		roles = append(roles, "viewer")
+	}
+	created := events.UserCreated{
+		BaseEvent: newBaseEvent("atlas.user.created", user.ID),
+		Username:  user.Username,
+		Email:     user.Email,
+		Roles:     roles,
+	}
+	if err := enqueueEvents(context.Background(), tx, created); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
 func (r *userRepository) Update(user *models.User) error {
 	query := `
 		UPDATE users
-		SET username = $2, email = $3, updated_at = CURRENT_TIMESTAMP
+		SET username = $2, email = $3, updated_at = CURRENT_TIMESTAMP, version = version + 1
 		WHERE id = $1 AND is_active = true
 		RETURNING updated_at::text
 	`
@@ -183,12 +219,18 @@ func (r *userRepository) Update(user *models.User) error {
 }
 
 func (r *userRepository) Delete(id string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
 	query := `
-		UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP
+		UPDATE users SET is_active = false, updated_at = CURRENT_TIMESTAMP, version = version + 1
 		WHERE id = $1 AND is_active = true
 	`
 
-	result, err := r.db.Exec(query, id)
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
@@ -202,7 +244,15 @@ func (r *userRepository) Delete(id string) error {
 		return ErrUserNotFound
 	}
 
-	return nil
+	deactivated := events.UserDeactivated{
+		BaseEvent: newBaseEvent("atlas.user.deactivated", id),
+		Reason:    "deleted",
+	}
+	if err := enqueueEvents(context.Background(), tx, deactivated); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 func (r *userRepository) List(offset, limit int) ([]*models.User, int, error) {
@@ -217,7 +267,7 @@ func (r *userRepository) List(offset, limit int) ([]*models.User, int, error) {
 		       u.created_at::text, u.updated_at::text,
 		       COALESCE(array_agg(r.name) FILTER (WHERE r.name IS NOT NULL), '{}') as roles
 		FROM users u
-		LEFT JOIN user_roles ur ON u.id = ur.user_id
+		LEFT JOIN user_roles ur ON u.id = ur.user_id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
 		LEFT JOIN roles r ON ur.role_id = r.id
 		WHERE u.is_active = true
 		GROUP BY u.id, u.username, u.email, u.is_active, u.created_at, u.updated_at
@@ -248,12 +298,76 @@ func (r *userRepository) List(offset, limit int) ([]*models.User, int, error) {
 	return users, total, nil
 }
 
-func (r *userRepository) UpdateLastLogin(id string) error {
-	query := `UPDATE users SET last_login_at = $2, updated_at = CURRENT_TIMESTAMP WHERE id = $1`
-	_, err := r.db.Exec(query, id, time.Now())
+func (r *userRepository) UpdateLastLogin(id, ipAddress, userAgent string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE users SET last_login_at = $2, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1 RETURNING username`
+	var username string
+	if err := tx.QueryRow(query, id, time.Now()).Scan(&username); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	loggedIn := events.UserLoggedIn{
+		BaseEvent: newBaseEvent("atlas.user.logged_in", id),
+		Username:  username,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+	}
+	if err := enqueueEvents(context.Background(), tx, loggedIn); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdatePassword stores a new hash and records a PasswordChanged event with
+// the given reason in the same transaction
+func (r *userRepository) UpdatePassword(id, passwordHash, reason string) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
 	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET password_hash = $2, password_changed_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP, version = version + 1
+		WHERE id = $1 AND is_active = true
+		RETURNING username
+	`
+
+	var username string
+	if err := tx.QueryRow(query, id, passwordHash).Scan(&username); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	changed := events.PasswordChanged{
+		BaseEvent: newBaseEvent("atlas.user.password_changed", id),
+		Username:  username,
+		Reason:    reason,
+	}
+	if err := enqueueEvents(context.Background(), tx, changed); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *userRepository) MarkVerified(id string) error {
+	query := `UPDATE users SET is_verified = true, updated_at = CURRENT_TIMESTAMP, version = version + 1 WHERE id = $1`
+	if _, err := r.db.Exec(query, id); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
 	return nil
 }
 
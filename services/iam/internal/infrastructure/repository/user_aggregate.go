@@ -0,0 +1,286 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	"atlas-core-api/services/iam/internal/domain/aggregates"
+	"atlas-core-api/services/iam/internal/domain/repositories"
+	"atlas-core-api/services/iam/internal/domain/valueobjects"
+)
+
+// userAggregateRepository is the Postgres implementation of the domain
+// UserRepository. Writes are guarded by the users.version column and carry
+// the aggregate's domain events into the outbox in the same transaction.
+type userAggregateRepository struct {
+	db *sql.DB
+}
+
+func NewUserAggregateRepository(db *sql.DB) repositories.UserRepository {
+	return &userAggregateRepository{db: db}
+}
+
+const selectUserAggregate = `
+	SELECT u.id, u.username, u.email, u.password_hash,
+	       COALESCE(u.first_name, ''), COALESCE(u.last_name, ''),
+	       COALESCE(u.is_active, false), COALESCE(u.is_verified, false),
+	       u.last_login_at, u.password_changed_at,
+	       COALESCE(u.created_at, CURRENT_TIMESTAMP), COALESCE(u.updated_at, CURRENT_TIMESTAMP),
+	       u.version,
+	       ARRAY(
+	           SELECT r.name FROM user_roles ur
+	           JOIN roles r ON r.id = ur.role_id
+	           WHERE ur.user_id = u.id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+	           ORDER BY r.name
+	       ) AS roles,
+	       ARRAY(
+	           SELECT DISTINCT p.name FROM user_roles ur
+	           JOIN role_permissions rp ON rp.role_id = ur.role_id
+	           JOIN permissions p ON p.id = rp.permission_id
+	           WHERE ur.user_id = u.id AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+	           ORDER BY p.name
+	       ) AS permissions
+	FROM users u
+`
+
+func (r *userAggregateRepository) FindByID(ctx context.Context, id valueobjects.UserID) (*aggregates.UserAggregate, error) {
+	return r.findOne(ctx, selectUserAggregate+`WHERE u.id = $1`, id.String())
+}
+
+func (r *userAggregateRepository) FindByUsername(ctx context.Context, username string) (*aggregates.UserAggregate, error) {
+	return r.findOne(ctx, selectUserAggregate+`WHERE u.username = $1`, username)
+}
+
+func (r *userAggregateRepository) FindByEmail(ctx context.Context, email valueobjects.Email) (*aggregates.UserAggregate, error) {
+	return r.findOne(ctx, selectUserAggregate+`WHERE LOWER(u.email) = $1`, email.String())
+}
+
+func (r *userAggregateRepository) Save(ctx context.Context, user *aggregates.UserAggregate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO users (
+			id, username, email, password_hash, first_name, last_name,
+			is_active, is_verified, last_login_at, password_changed_at,
+			created_at, updated_at, version
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, 1)
+	`
+
+	_, err = tx.ExecContext(ctx, query,
+		user.ID().String(), user.Username(), user.Email().String(), user.PasswordHash(),
+		user.FirstName(), user.LastName(), user.Active(), user.Verified(),
+		user.LastLoginAt(), user.PasswordChangedAt(), user.CreatedAt(), user.UpdatedAt(),
+	)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return repositories.ErrUserAlreadyExists
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	if err := syncUserRoles(ctx, tx, user.ID().String(), user.Roles()); err != nil {
+		return err
+	}
+	if err := enqueueEvents(ctx, tx, user.DomainEvents()...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	user.SetVersion(1)
+	user.ClearEvents()
+	return nil
+}
+
+func (r *userAggregateRepository) Update(ctx context.Context, user *aggregates.UserAggregate) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE users
+		SET email = $3, password_hash = $4, first_name = $5, last_name = $6,
+		    is_active = $7, is_verified = $8, last_login_at = $9, password_changed_at = $10,
+		    updated_at = $11, version = version + 1
+		WHERE id = $1 AND version = $2
+		RETURNING version
+	`
+
+	var version int
+	err = tx.QueryRowContext(ctx, query,
+		user.ID().String(), user.Version(), user.Email().String(), user.PasswordHash(),
+		user.FirstName(), user.LastName(), user.Active(), user.Verified(),
+		user.LastLoginAt(), user.PasswordChangedAt(), user.UpdatedAt(),
+	).Scan(&version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return r.missOrConflict(ctx, tx, user.ID().String())
+		}
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code == "23505" {
+			return repositories.ErrUserAlreadyExists
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	if err := syncUserRoles(ctx, tx, user.ID().String(), user.Roles()); err != nil {
+		return err
+	}
+	if err := enqueueEvents(ctx, tx, user.DomainEvents()...); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	user.SetVersion(version)
+	user.ClearEvents()
+	return nil
+}
+
+// Delete deactivates the user through the aggregate so the same invariants
+// and events apply as for any other update
+func (r *userAggregateRepository) Delete(ctx context.Context, id valueobjects.UserID) error {
+	user, err := r.FindByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !user.IsActive() {
+		return repositories.ErrUserNotFound
+	}
+	user.Deactivate("deleted")
+	return r.Update(ctx, user)
+}
+
+func (r *userAggregateRepository) List(ctx context.Context, offset, limit int) ([]*aggregates.UserAggregate, int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM users WHERE is_active = true`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	query := selectUserAggregate + `
+		WHERE u.is_active = true
+		ORDER BY u.created_at DESC
+		LIMIT $1 OFFSET $2
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	users := []*aggregates.UserAggregate{}
+	for rows.Next() {
+		user, err := scanUserAggregate(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	return users, total, nil
+}
+
+func (r *userAggregateRepository) findOne(ctx context.Context, query string, arg interface{}) (*aggregates.UserAggregate, error) {
+	user, err := scanUserAggregate(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, repositories.ErrUserNotFound
+	}
+	return user, err
+}
+
+// missOrConflict tells a deleted row apart from a stale version
+func (r *userAggregateRepository) missOrConflict(ctx context.Context, tx *sql.Tx, id string) error {
+	var exists bool
+	if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if !exists {
+		return repositories.ErrUserNotFound
+	}
+	return repositories.ErrConcurrentModification
+}
+
+// syncUserRoles makes the active rows of user_roles match the aggregate's
+// role names; unknown names are ignored. Expired rows are left for the expiry
+// sweep, which records their revocation.
+func syncUserRoles(ctx context.Context, tx *sql.Tx, userID string, roles []string) error {
+	names := pq.StringArray(roles)
+
+	remove := `
+		DELETE FROM user_roles ur
+		USING roles r
+		WHERE ur.role_id = r.id AND ur.user_id = $1 AND NOT (r.name = ANY($2))
+		  AND (ur.expires_at IS NULL OR ur.expires_at > NOW())
+	`
+	if _, err := tx.ExecContext(ctx, remove, userID, names); err != nil {
+		return fmt.Errorf("%w: failed to sync roles: %v", ErrDatabaseOperation, err)
+	}
+
+	add := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id FROM roles WHERE name = ANY($2)
+		ON CONFLICT (user_id, role_id) DO UPDATE SET expires_at = NULL
+		WHERE user_roles.expires_at IS NOT NULL AND user_roles.expires_at <= NOW()
+	`
+	if _, err := tx.ExecContext(ctx, add, userID, names); err != nil {
+		return fmt.Errorf("%w: failed to sync roles: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func scanUserAggregate(row rowScanner) (*aggregates.UserAggregate, error) {
+	var (
+		id, username, email, passwordHash string
+		firstName, lastName               string
+		active, verified                  bool
+		lastLoginAt, passwordChangedAt    sql.NullTime
+		createdAt, updatedAt              time.Time
+		version                           int
+		roles, permissions                pq.StringArray
+	)
+	err := row.Scan(
+		&id, &username, &email, &passwordHash, &firstName, &lastName,
+		&active, &verified, &lastLoginAt, &passwordChangedAt,
+		&createdAt, &updatedAt, &version, &roles, &permissions,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	return aggregates.ReconstructUserAggregate(
+		id, username, email, passwordHash,
+		firstName, lastName,
+		[]string(roles), []string(permissions),
+		active, verified, false,
+		nullTimePtr(lastLoginAt),
+		createdAt, updatedAt,
+		nullTimePtr(passwordChangedAt),
+		version,
+	), nil
+}
+
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,402 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/iam/internal/domain"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook subscription not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// WebhookDeliveryFilter narrows a subscription's delivery log
+type WebhookDeliveryFilter struct {
+	SubscriptionID string
+	Status         string
+	EventType      string
+	Limit          int
+	Offset         int
+}
+
+// WebhookRepository stores webhook subscriptions and their delivery log,
+// which doubles as the queue of deliveries waiting to be attempted
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context, ownerID string) ([]*models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error
+	DeleteSubscription(ctx context.Context, id string) error
+	// SubscriptionsFor returns the active subscriptions to eventType
+	SubscriptionsFor(ctx context.Context, eventType string) ([]*models.WebhookSubscription, error)
+	// RecordOutcome resets a subscription's failure count after a delivered
+	// event or increments it after a failed one, disabling the subscription
+	// when the count reaches disableAfter. It reports whether this outcome
+	// disabled the subscription.
+	RecordOutcome(ctx context.Context, subscriptionID string, delivered bool, disableAfter int) (bool, error)
+
+	// EnqueueDelivery queues a delivery, unless the event is already queued
+	// for the subscription; redeliveries are always queued
+	EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error)
+	// ClaimDueDeliveries leases up to limit due deliveries, oldest first
+	ClaimDueDeliveries(ctx context.Context, limit int, claimFor time.Duration) ([]*models.WebhookDelivery, error)
+	// RecordAttempt stores the status and response of a delivery attempt and
+	// releases the lease
+	RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error
+	GetDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*models.WebhookDelivery, int, error)
+}
+
+type webhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+const webhookSubscriptionColumns = `
+	id, name, url, secret, events, COALESCE(owner_id::text, ''), COALESCE(is_active, true),
+	COALESCE(retry_count, 3), COALESCE(timeout_seconds, 30), COALESCE(failure_count, 0),
+	last_triggered_at, disabled_at, COALESCE(disabled_reason, ''),
+	COALESCE(created_at, CURRENT_TIMESTAMP), COALESCE(updated_at, CURRENT_TIMESTAMP)`
+
+const webhookDeliveryColumns = `
+	id, subscription_id, COALESCE(event_id, ''), event_type, payload, status,
+	COALESCE(attempt_number, 0), COALESCE(response_status, 0), COALESCE(response_body, ''),
+	COALESCE(duration_ms, 0), COALESCE(error_message, ''), next_attempt_at, delivered_at,
+	COALESCE(redelivery_of::text, ''), COALESCE(created_at, CURRENT_TIMESTAMP)`
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `
+		INSERT INTO webhook_subscriptions (name, url, secret, events, owner_id, is_active, retry_count, timeout_seconds)
+		VALUES ($1, $2, $3, $4, NULLIF($5, '')::uuid, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		sub.Name, sub.URL, sub.Secret, pq.StringArray(sub.Events), sub.OwnerID, sub.IsActive,
+		sub.RetryCount, sub.TimeoutSeconds,
+	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id string) (*models.WebhookSubscription, error) {
+	query := `SELECT ` + webhookSubscriptionColumns + ` FROM webhook_subscriptions WHERE id = $1`
+
+	sub, err := scanWebhookSubscription(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context, ownerID string) ([]*models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE ($1 = '' OR owner_id::text = $1)
+		ORDER BY created_at DESC
+	`
+
+	return r.subscriptions(ctx, query, ownerID)
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *models.WebhookSubscription) error {
+	query := `
+		UPDATE webhook_subscriptions
+		SET name = $2, url = $3, secret = $4, events = $5, is_active = $6, retry_count = $7,
+		    timeout_seconds = $8, failure_count = $9, disabled_at = $10, disabled_reason = NULLIF($11, ''),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		sub.ID, sub.Name, sub.URL, sub.Secret, pq.StringArray(sub.Events), sub.IsActive, sub.RetryCount,
+		sub.TimeoutSeconds, sub.FailureCount, sub.DisabledAt, sub.DisabledReason,
+	).Scan(&sub.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id string) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrWebhookNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrWebhookNotFound
+	}
+	return nil
+}
+
+func (r *webhookRepository) SubscriptionsFor(ctx context.Context, eventType string) ([]*models.WebhookSubscription, error) {
+	query := `
+		SELECT ` + webhookSubscriptionColumns + `
+		FROM webhook_subscriptions
+		WHERE COALESCE(is_active, true) AND $1 = ANY(events)
+		ORDER BY created_at
+	`
+
+	return r.subscriptions(ctx, query, eventType)
+}
+
+func (r *webhookRepository) RecordOutcome(ctx context.Context, subscriptionID string, delivered bool, disableAfter int) (bool, error) {
+	if delivered {
+		query := `
+			UPDATE webhook_subscriptions
+			SET failure_count = 0, last_triggered_at = CURRENT_TIMESTAMP
+			WHERE id = $1
+		`
+		if _, err := r.db.ExecContext(ctx, query, subscriptionID); err != nil {
+			return false, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		return false, nil
+	}
+
+	// Only the update that reaches the threshold disables the subscription,
+	// so the reason and time record the first time it tripped
+	query := `
+		UPDATE webhook_subscriptions
+		SET failure_count = COALESCE(failure_count, 0) + 1,
+		    last_triggered_at = CURRENT_TIMESTAMP,
+		    is_active = CASE WHEN $2 > 0 AND COALESCE(failure_count, 0) + 1 = $2 THEN false ELSE is_active END,
+		    disabled_at = CASE WHEN $2 > 0 AND COALESCE(failure_count, 0) + 1 = $2 THEN CURRENT_TIMESTAMP ELSE disabled_at END,
+		    disabled_reason = CASE WHEN $2 > 0 AND COALESCE(failure_count, 0) + 1 = $2
+		        THEN $2::text || ' consecutive deliveries failed' ELSE disabled_reason END
+		WHERE id = $1
+		RETURNING failure_count
+	`
+
+	var failures int
+	if err := r.db.QueryRowContext(ctx, query, subscriptionID, disableAfter).Scan(&failures); err != nil {
+		if err == sql.ErrNoRows {
+			return false, ErrWebhookNotFound
+		}
+		return false, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return disableAfter > 0 && failures == disableAfter, nil
+}
+
+func (r *webhookRepository) EnqueueDelivery(ctx context.Context, delivery *models.WebhookDelivery) (bool, error) {
+	query := `
+		INSERT INTO webhook_deliveries (
+			subscription_id, event_id, event_type, payload, status, attempt_number, next_attempt_at, redelivery_of
+		) VALUES ($1, NULLIF($2, ''), $3, $4, $5, 0, $6, NULLIF($7, '')::uuid)
+		ON CONFLICT (subscription_id, event_id) WHERE redelivery_of IS NULL AND event_id IS NOT NULL DO NOTHING
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		delivery.SubscriptionID, delivery.EventID, delivery.EventType, []byte(delivery.Payload),
+		delivery.Status, delivery.NextAttemptAt, delivery.RedeliveryOf,
+	).Scan(&delivery.ID, &delivery.CreatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		if pqErr, ok := err.(*pq.Error); ok && (pqErr.Code == "23503" || pqErr.Code == "22P02") {
+			return false, ErrWebhookNotFound
+		}
+		return false, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return true, nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, claimFor time.Duration) ([]*models.WebhookDelivery, error) {
+	// SKIP LOCKED lets replicas claim disjoint batches
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET claimed_until = CURRENT_TIMESTAMP + ($2 * INTERVAL '1 millisecond')
+			WHERE id IN (
+				SELECT id FROM webhook_deliveries
+				WHERE status = 'pending'
+				  AND COALESCE(next_attempt_at, created_at) <= CURRENT_TIMESTAMP
+				  AND (claimed_until IS NULL OR claimed_until < CURRENT_TIMESTAMP)
+				ORDER BY created_at
+				LIMIT $1
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT ` + webhookDeliveryColumns + ` FROM claimed ORDER BY created_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, limit, claimFor.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	return scanWebhookDeliveries(rows)
+}
+
+func (r *webhookRepository) RecordAttempt(ctx context.Context, delivery *models.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempt_number = $3, response_status = NULLIF($4, 0), response_body = NULLIF($5, ''),
+		    duration_ms = $6, error_message = NULLIF($7, ''), success = ($2 = 'delivered'),
+		    next_attempt_at = $8, delivered_at = $9, claimed_until = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.ResponseStatus, delivery.ResponseBody,
+		delivery.DurationMs, delivery.ErrorMessage, delivery.NextAttemptAt, delivery.DeliveredAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return nil
+}
+
+func (r *webhookRepository) GetDelivery(ctx context.Context, subscriptionID, id string) (*models.WebhookDelivery, error) {
+	query := `SELECT ` + webhookDeliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2`
+
+	delivery, err := scanWebhookDelivery(r.db.QueryRowContext(ctx, query, id, subscriptionID))
+	if err != nil {
+		if err == sql.ErrNoRows || isInvalidUUID(err) {
+			return nil, ErrWebhookDeliveryNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return delivery, nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter WebhookDeliveryFilter) ([]*models.WebhookDelivery, int, error) {
+	where := `
+		WHERE subscription_id = $1
+		  AND ($2 = '' OR status = $2)
+		  AND ($3 = '' OR event_type = $3)
+	`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM webhook_deliveries`+where,
+		filter.SubscriptionID, filter.Status, filter.EventType).Scan(&total); err != nil {
+		if isInvalidUUID(err) {
+			return nil, 0, ErrWebhookNotFound
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+
+	// LIMIT NULL returns every row
+	var limit interface{}
+	if filter.Limit > 0 {
+		limit = filter.Limit
+	}
+	rows, err := r.db.QueryContext(ctx, `SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries`+where+`
+		ORDER BY created_at DESC
+		LIMIT $4 OFFSET $5`,
+		filter.SubscriptionID, filter.Status, filter.EventType, limit, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func (r *webhookRepository) subscriptions(ctx context.Context, query string, args ...interface{}) ([]*models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	defer rows.Close()
+
+	subs := []*models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanWebhookSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		subs = append(subs, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return subs, nil
+}
+
+func scanWebhookSubscription(row rowScanner) (*models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	var events pq.StringArray
+	var lastTriggeredAt, disabledAt sql.NullTime
+	if err := row.Scan(&sub.ID, &sub.Name, &sub.URL, &sub.Secret, &events, &sub.OwnerID, &sub.IsActive,
+		&sub.RetryCount, &sub.TimeoutSeconds, &sub.FailureCount,
+		&lastTriggeredAt, &disabledAt, &sub.DisabledReason,
+		&sub.CreatedAt, &sub.UpdatedAt); err != nil {
+		return nil, err
+	}
+	sub.Events = []string(events)
+	if lastTriggeredAt.Valid {
+		sub.LastTriggeredAt = &lastTriggeredAt.Time
+	}
+	if disabledAt.Valid {
+		sub.DisabledAt = &disabledAt.Time
+	}
+	return &sub, nil
+}
+
+func scanWebhookDelivery(row rowScanner) (*models.WebhookDelivery, error) {
+	var d models.WebhookDelivery
+	var payload []byte
+	var nextAttemptAt, deliveredAt sql.NullTime
+	if err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &payload, &d.Status,
+		&d.Attempts, &d.ResponseStatus, &d.ResponseBody,
+		&d.DurationMs, &d.ErrorMessage, &nextAttemptAt, &deliveredAt,
+		&d.RedeliveryOf, &d.CreatedAt); err != nil {
+		return nil, err
+	}
+	d.Payload = payload
+	if nextAttemptAt.Valid {
+		d.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		d.DeliveredAt = &deliveredAt.Time
+	}
+	return &d, nil
+}
+
+func scanWebhookDeliveries(rows *sql.Rows) ([]*models.WebhookDelivery, error) {
+	deliveries := []*models.WebhookDelivery{}
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabaseOperation, err)
+	}
+	return deliveries, nil
+}
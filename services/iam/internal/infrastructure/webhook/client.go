@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"atlas-core-api/pkg/safehttp"
+)
+
+// Headers sent with every delivery. The signature headers are the ones the
+// API gateway's RequestSigning middleware verifies.
+const (
+	SignatureHeader = "X-Request-Signature"
+	TimestampHeader = "X-Request-Timestamp"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign returns the hex HMAC-SHA256 of "timestamp.path.body" under secret
+func Sign(secret, timestamp, path string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "." + path + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Request is one signed delivery attempt
+type Request struct {
+	URL        string
+	Secret     string
+	EventType  string
+	DeliveryID string
+	Body       []byte
+	Timeout    time.Duration
+}
+
+// Response describes how an endpoint answered. StatusCode is zero when no
+// response was received; Body is only kept when the poster is configured to
+// capture it.
+type Response struct {
+	StatusCode int
+	Body       string
+	Duration   time.Duration
+}
+
+// Poster sends delivery attempts; tests substitute their own
+type Poster interface {
+	Post(ctx context.Context, req Request) (Response, error)
+}
+
+// PosterConfig controls what an HTTPPoster may reach and keep
+type PosterConfig struct {
+	// AllowPrivateNetworks lets deliveries reach loopback, private and
+	// link-local addresses. Only tests should set it.
+	AllowPrivateNetworks bool
+	// CaptureResponseBody is how many bytes of each response body are kept
+	// in the delivery log; zero keeps only the status code
+	CaptureResponseBody int
+}
+
+// HTTPPoster posts signed JSON over HTTP. Any status outside 2xx is an
+// error, redirects are not followed, and, unless the config allows it,
+// hosts that resolve to internal addresses are refused when dialed.
+type HTTPPoster struct {
+	client *http.Client
+	config PosterConfig
+}
+
+func NewHTTPPoster(config PosterConfig) *HTTPPoster {
+	return &HTTPPoster{
+		client: safehttp.NewClient(safehttp.Config{AllowPrivateNetworks: config.AllowPrivateNetworks}),
+		config: config,
+	}
+}
+
+func (p *HTTPPoster) Post(ctx context.Context, req Request) (Response, error) {
+	target, err := url.Parse(req.URL)
+	if err != nil {
+		return Response{}, fmt.Errorf("invalid webhook url: %w", err)
+	}
+	path := target.Path
+	if path == "" {
+		path = "/"
+	}
+
+	if req.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, req.Timeout)
+		defer cancel()
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.URL, bytes.NewReader(req.Body))
+	if err != nil {
+		return Response{}, err
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("User-Agent", "Atlas-Webhooks/1.0")
+	httpReq.Header.Set(TimestampHeader, timestamp)
+	httpReq.Header.Set(SignatureHeader, Sign(req.Secret, timestamp, path, req.Body))
+	httpReq.Header.Set(EventHeader, req.EventType)
+	httpReq.Header.Set(DeliveryHeader, req.DeliveryID)
+
+	start := time.Now()
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return Response{Duration: time.Since(start)}, err
+	}
+	defer resp.Body.Close()
+
+	result := Response{StatusCode: resp.StatusCode}
+	if p.config.CaptureResponseBody > 0 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, int64(p.config.CaptureResponseBody)))
+		result.Body = string(body)
+	}
+	result.Duration = time.Since(start)
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return result, fmt.Errorf("endpoint responded with status %d", resp.StatusCode)
+	}
+	return result, nil
+}
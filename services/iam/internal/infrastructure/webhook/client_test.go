@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"atlas-core-api/pkg/safehttp"
+)
+
+func TestHTTPPoster_SignsLikeTheGateway(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := []byte(`{"event_type":"atlas.risk.assessed"}`)
+	resp, err := NewHTTPPoster(PosterConfig{AllowPrivateNetworks: true}).Post(context.Background(), Request{
+		URL:        server.URL + "/hooks/atlas?source=test",
+		Secret:     "whsec_test_secret_value",
+		EventType:  "atlas.risk.assessed",
+		DeliveryID: "delivery-1",
+		Body:       payload,
+		Timeout:    time.Second,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// The signature covers the path without the query, as the gateway's
+	// RequestSigning middleware computes it
+	timestamp := got.Header.Get(TimestampHeader)
+	_, err = time.Parse(time.RFC3339, timestamp)
+	require.NoError(t, err)
+	assert.Equal(t, Sign("whsec_test_secret_value", timestamp, "/hooks/atlas", payload), got.Header.Get(SignatureHeader))
+	assert.Equal(t, "atlas.risk.assessed", got.Header.Get(EventHeader))
+	assert.Equal(t, "delivery-1", got.Header.Get(DeliveryHeader))
+	assert.Equal(t, payload, body)
+}
+
+func TestHTTPPoster_FailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte("try later"))
+	}))
+	defer server.Close()
+
+	poster := NewHTTPPoster(PosterConfig{AllowPrivateNetworks: true})
+	resp, err := poster.Post(context.Background(), Request{URL: server.URL, Secret: "s", Body: []byte("{}")})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Empty(t, resp.Body, "response bodies are only kept when capture is enabled")
+
+	poster = NewHTTPPoster(PosterConfig{AllowPrivateNetworks: true, CaptureResponseBody: 3})
+	resp, err = poster.Post(context.Background(), Request{URL: server.URL, Secret: "s", Body: []byte("{}")})
+	require.Error(t, err)
+	assert.Equal(t, "try", resp.Body)
+}
+
+func TestHTTPPoster_DoesNotFollowRedirects(t *testing.T) {
+	followed := false
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		followed = true
+	}))
+	defer internal.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, internal.URL, http.StatusFound)
+	}))
+	defer server.Close()
+
+	resp, err := NewHTTPPoster(PosterConfig{AllowPrivateNetworks: true}).Post(context.Background(), Request{URL: server.URL, Secret: "s", Body: []byte("{}")})
+	require.Error(t, err)
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.False(t, followed)
+}
+
+func TestHTTPPoster_RefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached a loopback server")
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPPoster(PosterConfig{}).Post(context.Background(), Request{URL: server.URL, Secret: "s", Body: []byte("{}")})
+	assert.ErrorIs(t, err, safehttp.ErrBlockedAddress)
+}
+
+func TestSign(t *testing.T) {
+	// hex(HMAC-SHA256("secret", "2024-01-15T10:30:00Z./hook.{}"))
+	assert.Equal(t,
+		"0de4e84ba4c946e806f489420ba2dface078a23380215d12c1e49685e2d9bd93",
+		Sign("secret", "2024-01-15T10:30:00Z", "/hook", []byte("{}")),
+	)
+}
@@ -2,6 +2,8 @@ package config
 
 import (
 	"os"
+
+	"atlas-core-api/pkg/servicetoken"
 )
 
 type Config struct {
@@ -14,6 +16,15 @@ type Config struct {
 	DBUser       string
 	DBPassword   string
 	DBName       string
+
+	// Environment is "production" in production deployments
+	Environment string
+
+	// Service-to-service authentication
+	ServiceName                   string
+	TrustDomain                   string
+	ServiceTokenPublicKey         string
+	ServiceTokenPreviousPublicKey string
 }
 
 func Load() *Config {
@@ -27,6 +38,11 @@ func Load() *Config {
 		DBUser:       getEnv("DB_USER", "atlas"),
 		DBPassword:   getEnv("DB_PASSWORD", "atlas"),
 		DBName:       getEnv("DB_NAME", "atlas"),
+		Environment:                   getEnv("ENVIRONMENT", "development"),
+		ServiceName:                   getEnv("SERVICE_NAME", "ingestion-service"),
+		TrustDomain:                   getEnv("SERVICE_TRUST_DOMAIN", "atlas.internal"),
+		ServiceTokenPublicKey:         getEnv("SERVICE_TOKEN_PUBLIC_KEY", servicetoken.DefaultPublicKey),
+		ServiceTokenPreviousPublicKey: getEnv("SERVICE_TOKEN_PREVIOUS_PUBLIC_KEY", ""),
 	}
 }
 
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"atlas-core-api/pkg/servicetoken"
 	"atlas-core-api/services/normalization/internal/api/handlers"
 	"atlas-core-api/services/normalization/internal/api/middleware"
 	service "atlas-core-api/services/normalization/internal/application"
@@ -19,6 +20,15 @@ import (
 
 func main() {
 	cfg := config.Load()
+	if cfg.Environment == "production" {
+		if err := servicetoken.CheckKey(cfg.ServiceTokenPublicKey); err != nil {
+			log.Fatalf("SERVICE_TOKEN_PUBLIC_KEY must be set in production: %v", err)
+		}
+	}
+	gatewayKeys, err := servicetoken.ParsePublicKeys(cfg.ServiceTokenPublicKey, cfg.ServiceTokenPreviousPublicKey)
+	if err != nil {
+		log.Fatalf("Invalid SERVICE_TOKEN_PUBLIC_KEY: %v", err)
+	}
 
 	// Initialize Kafka consumer and producer
 	kafkaConsumer := messaging.NewKafkaConsumer(cfg.KafkaBrokers, cfg.KafkaRawTopic)
@@ -49,6 +59,7 @@ func main() {
 
 	// API routes
 	api := router.Group("/api/v1")
+	api.Use(servicetoken.Require(cfg.TrustDomain, cfg.ServiceName, gatewayKeys...))
 	{
 		// Normalization endpoints
 		rules := api.Group("/normalization/rules")
@@ -13,11 +13,15 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 
+	"atlas-core-api/pkg/servicetoken"
 	"atlas-core-api/services/risk-assessment/internal/api/handlers"
 	"atlas-core-api/services/risk-assessment/internal/api/middleware"
 	service "atlas-core-api/services/risk-assessment/internal/application"
-	"atlas-core-api/services/risk-assessment/internal/infrastructure"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
 	"atlas-core-api/services/risk-assessment/internal/infrastructure/config"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/messaging"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/notify"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/providers"
 	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
 )
 
@@ -31,29 +35,104 @@ func main() {
 
 	// Load configuration
 	cfg := config.Load()
+	if cfg.Environment == "production" {
+		if err := servicetoken.CheckKey(cfg.ServiceTokenPublicKey); err != nil {
+			logger.Fatal("SERVICE_TOKEN_PUBLIC_KEY must be set in production", zap.Error(err))
+		}
+		if cfg.Notifications.Driver != "live" {
+			logger.Fatal("NOTIFY_DRIVER must be live in production")
+		}
+	}
+	gatewayKeys, err := servicetoken.ParsePublicKeys(cfg.ServiceTokenPublicKey, cfg.ServiceTokenPreviousPublicKey)
+	if err != nil {
+		logger.Fatal("Invalid SERVICE_TOKEN_PUBLIC_KEY", zap.Error(err))
+	}
 
 	// Initialize repository
 	riskRepo := repository.NewRiskRepository()
+	modelRepo := repository.NewScoringModelRepository()
+	jobRepo := repository.NewJobRepository()
+	watchlistRepo := repository.NewWatchlistRepository()
+	notificationRepo := repository.NewNotificationRepository()
+	outcomeRepo := repository.NewOutcomeRepository()
+	backtestRepo := repository.NewBacktestRepository()
+	leader := repository.NewLocalLeaderLock()
+	if cfg.DatabaseURL != "" {
+		db, err := repository.NewPostgresDB(cfg.DatabaseURL)
+		if err != nil {
+			logger.Fatal("Failed to connect to database", zap.Error(err))
+		}
+		defer db.Close()
+		riskRepo = repository.NewPostgresRiskRepository(db)
+		modelRepo = repository.NewPostgresScoringModelRepository(db)
+		jobRepo = repository.NewPostgresJobRepository(db)
+		watchlistRepo = repository.NewPostgresWatchlistRepository(db)
+		notificationRepo = repository.NewPostgresNotificationRepository(db)
+		outcomeRepo = repository.NewPostgresOutcomeRepository(db)
+		backtestRepo = repository.NewPostgresBacktestRepository(db)
+		leader = repository.NewPostgresLeaderLock(db, "risk-assessment-scheduler")
+	} else {
+		logger.Warn("DATABASE_URL not set; risk assessments are kept in memory")
+	}
 
-	// Initialize external data provider
-	dataProviderConfig := &infrastructure.DataProviderConfig{
-		FinancialAPIKey:     cfg.DataProvider.FinancialAPIKey,
-		FinancialBaseURL:    cfg.DataProvider.FinancialBaseURL,
-		GeopoliticalAPIKey:  cfg.DataProvider.GeopoliticalAPIKey,
-		GeopoliticalBaseURL: cfg.DataProvider.GeopoliticalBaseURL,
-		ComplianceAPIKey:    cfg.DataProvider.ComplianceAPIKey,
-		ComplianceBaseURL:   cfg.DataProvider.ComplianceBaseURL,
-		NewsAPIKey:          cfg.DataProvider.NewsAPIKey,
-		NewsBaseURL:         cfg.DataProvider.NewsBaseURL,
-		Timeout:             cfg.DataProvider.Timeout,
+	// Initialize external data providers
+	registry, err := newProviderRegistry(cfg.DataProvider, cfg.Environment, logger)
+	if err != nil {
+		logger.Fatal("Failed to configure data providers", zap.Error(err))
 	}
-	dataProvider := infrastructure.NewRealDataProvider(dataProviderConfig)
 
 	// Initialize services
-	riskService := service.NewRiskAssessmentService(riskRepo, dataProvider)
+	scoringService := service.NewScoringModelService(modelRepo)
+	if err := scoringService.EnsureDefaultModel(); err != nil {
+		logger.Fatal("Failed to seed default scoring model", zap.Error(err))
+	}
+	kafkaPublisher := messaging.NewKafkaEventPublisher(cfg.KafkaBrokers, logger)
+	defer kafkaPublisher.Close()
+
+	// Alert events are also turned into notifications for the alert owner
+	senders, err := newNotificationSenders(cfg.Notifications, logger)
+	if err != nil {
+		logger.Fatal("Failed to configure notification delivery", zap.Error(err))
+	}
+	notificationService := service.NewNotificationService(notificationRepo, riskRepo, senders,
+		logger, service.NotificationPolicy{
+			DedupWindow:     cfg.Notifications.DedupWindow,
+			DigestWindow:    cfg.Notifications.DigestWindow,
+			DigestThreshold: cfg.Notifications.DigestThreshold,
+			MaxAttempts:     cfg.Notifications.MaxAttempts,
+			RetryBase:       cfg.Notifications.RetryBase,
+		}, cfg.Notifications.Tick)
+	publisher := service.NewNotifyingPublisher(kafkaPublisher, notificationService)
+
+	riskService := service.NewRiskAssessmentService(riskRepo, modelRepo, registry, publisher, cfg.AssessmentBudget)
+
+	// Batch job workers and the scheduler run until shutdown; unfinished
+	// jobs resume
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	jobService := service.NewJobService(jobRepo, riskService, publisher, logger, cfg.JobWorkers)
+	if err := jobService.Start(workerCtx); err != nil {
+		logger.Fatal("Failed to resume assessment jobs", zap.Error(err))
+	}
+	watchlistService := service.NewWatchlistService(watchlistRepo)
+	backtestService := service.NewBacktestService(outcomeRepo, backtestRepo, riskRepo, logger)
+	if err := backtestService.Start(workerCtx); err != nil {
+		logger.Fatal("Failed to restart backtests", zap.Error(err))
+	}
+	go notificationService.Run(workerCtx)
+	if cfg.SchedulerEnabled {
+		scheduler := service.NewScheduler(watchlistRepo, riskService, publisher, leader, logger, cfg.SchedulerTick, cfg.ReassessMargin)
+		go scheduler.Run(workerCtx)
+		defer leader.Release()
+	}
 
 	// Initialize handlers
 	riskHandler := handlers.NewRiskHandler(riskService)
+	scoringHandler := handlers.NewScoringModelHandler(scoringService)
+	jobHandler := handlers.NewJobHandler(jobService)
+	watchlistHandler := handlers.NewWatchlistHandler(watchlistService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	backtestHandler := handlers.NewBacktestHandler(backtestService)
 
 	// Set Gin mode
 	if cfg.Environment == "production" {
@@ -66,19 +145,75 @@ func main() {
 	// Health check
 	r.GET("/health", handlers.HealthCheck)
 
-	// Public API routes (no auth for testing)
-	r.POST("/api/v1/risks/assess", riskHandler.AssessRisk)
-	r.GET("/api/v1/risks/:id", riskHandler.GetRiskAssessment)
-	r.GET("/api/v1/risks/trends", riskHandler.GetRiskTrends)
-	r.GET("/api/v1/risks/entities/:entity_id", riskHandler.GetAssessmentsByEntity)
-
-	// API routes for alerts (keeping auth for now)
-	alerts := r.Group("/api/v1/risk/alerts")
-	alerts.Use(middleware.Authenticate(cfg.JWTSecret))
+	// API routes are reachable only through the API gateway
+	api := r.Group("/api/v1")
+	api.Use(servicetoken.Require(cfg.TrustDomain, cfg.ServiceName, gatewayKeys...))
 	{
-		alerts.POST("", riskHandler.ConfigureAlert)
-		alerts.GET("", riskHandler.ListAlerts)
-		alerts.DELETE("/:id", riskHandler.DeleteAlert)
+		api.POST("/risks/assess", riskHandler.AssessRisk)
+		api.POST("/risks/simulate", riskHandler.SimulateRisk)
+		api.GET("/risks/:id", riskHandler.GetRiskAssessment)
+		api.GET("/risks/trends", riskHandler.GetRiskTrends)
+		api.GET("/risks/entities/:entity_id", riskHandler.GetAssessmentsByEntity)
+		api.GET("/risks/providers/health", riskHandler.GetProviderHealth)
+
+		jobs := api.Group("/risks/jobs")
+		{
+			jobs.POST("", jobHandler.SubmitJob)
+			jobs.GET("/:id", jobHandler.GetJob)
+			jobs.GET("/:id/results", jobHandler.GetJobResults)
+		}
+
+		watchlist := api.Group("/risks/watchlist")
+		{
+			watchlist.POST("", watchlistHandler.Watch)
+			watchlist.GET("", watchlistHandler.ListWatches)
+			watchlist.GET("/:id", watchlistHandler.GetWatch)
+			watchlist.DELETE("/:id", watchlistHandler.Unwatch)
+		}
+
+		scoringModels := api.Group("/risks/models")
+		{
+			scoringModels.GET("", scoringHandler.ListModels)
+			scoringModels.GET("/:id", scoringHandler.GetModel)
+			scoringModels.POST("", middleware.RequireRole("admin"), scoringHandler.CreateModel)
+			scoringModels.POST("/:id/activate", middleware.RequireRole("admin"), scoringHandler.ActivateModel)
+		}
+
+		alerts := api.Group("/risks/alerts")
+		{
+			alerts.POST("", riskHandler.ConfigureAlert)
+			alerts.GET("", riskHandler.ListAlerts)
+			alerts.POST("/test", riskHandler.TestAlertRule)
+			alerts.GET("/:id", riskHandler.GetAlert)
+			alerts.DELETE("/:id", riskHandler.DeleteAlert)
+			alerts.POST("/:id/acknowledge", riskHandler.AcknowledgeAlert)
+			alerts.POST("/:id/resolve", riskHandler.ResolveAlert)
+		}
+
+		// Real events scores are measured against
+		outcomes := api.Group("/risks/outcomes")
+		{
+			outcomes.POST("", backtestHandler.RecordOutcome)
+			outcomes.GET("", backtestHandler.ListOutcomes)
+			outcomes.GET("/:id", backtestHandler.GetOutcome)
+			outcomes.DELETE("/:id", middleware.RequireRole("admin"), backtestHandler.DeleteOutcome)
+		}
+
+		backtests := api.Group("/risks/backtests")
+		{
+			backtests.POST("", backtestHandler.SubmitBacktest)
+			backtests.GET("", backtestHandler.ListBacktests)
+			backtests.GET("/:id", backtestHandler.GetBacktest)
+			backtests.GET("/:id/report", backtestHandler.GetBacktestReport)
+		}
+
+		// The caller's in-app notifications
+		notifications := api.Group("/notifications")
+		{
+			notifications.GET("", notificationHandler.ListNotifications)
+			notifications.POST("/:id/read", notificationHandler.MarkRead)
+			notifications.POST("/:id/dismiss", notificationHandler.Dismiss)
+		}
 	}
 
 	// Create HTTP server
@@ -107,6 +242,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down server...")
+	stopWorkers()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -118,3 +254,67 @@ func main() {
 
 	logger.Info("Server exited")
 }
+
+// newNotificationSenders returns the sender for each delivered channel. The
+// log driver only logs that a message was due, so it is refused in
+// production.
+func newNotificationSenders(cfg config.NotificationConfig, logger *zap.Logger) (map[string]notify.Sender, error) {
+	switch cfg.Driver {
+	case "live":
+		return map[string]notify.Sender{
+			models.ChannelEmail:   notify.NewSMTPSender(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From),
+			models.ChannelWebhook: notify.NewWebhookSender(cfg.HTTPTimeout),
+			models.ChannelSlack:   notify.NewSlackSender(cfg.HTTPTimeout),
+		}, nil
+	case "log":
+		stub := notify.NewLogSender(logger)
+		return map[string]notify.Sender{
+			models.ChannelEmail:   stub,
+			models.ChannelWebhook: stub,
+			models.ChannelSlack:   stub,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown NOTIFY_DRIVER %q", cfg.Driver)
+	}
+}
+
+// newProviderRegistry builds each dimension's provider chain from config.
+// The fixture provider generates data, so production refuses it.
+func newProviderRegistry(cfg config.DataProviderConfig, environment string, logger *zap.Logger) (*providers.Registry, error) {
+	feeds := map[string]struct{ name, baseURL, apiKey string }{
+		"financial":    {"financial-data-api", cfg.FinancialBaseURL, cfg.FinancialAPIKey},
+		"geopolitical": {"geopolitical-data-api", cfg.GeopoliticalBaseURL, cfg.GeopoliticalAPIKey},
+		"compliance":   {"compliance-data-api", cfg.ComplianceBaseURL, cfg.ComplianceAPIKey},
+		"reputational": {"news-sentiment-api", cfg.NewsBaseURL, cfg.NewsAPIKey},
+		"operational":  {"operational-data-api", cfg.OperationalBaseURL, cfg.OperationalAPIKey},
+	}
+	fixture := providers.NewFixtureProvider()
+	csvFiles := providers.NewCSVProvider(cfg.CSVDir)
+	sanctions := providers.NewSanctionsProvider(cfg.SanctionsURL)
+
+	registry := providers.NewRegistry(cfg.CacheTTL)
+	for dimension, chain := range cfg.Chains {
+		for _, name := range chain {
+			switch name {
+			case "http":
+				feed := feeds[dimension]
+				registry.Register(dimension, providers.NewHTTPProvider(feed.name, feed.baseURL, feed.apiKey),
+					providers.Options{Timeout: cfg.Timeout, MaxConcurrent: cfg.MaxConcurrent})
+			case "csv":
+				registry.Register(dimension, csvFiles, providers.Options{Timeout: time.Second})
+			case "sanctions":
+				registry.Register(dimension, sanctions,
+					providers.Options{Timeout: cfg.SanctionsTimeout, MaxConcurrent: cfg.MaxConcurrent})
+			case "fixture":
+				if environment == "production" {
+					return nil, fmt.Errorf("the fixture provider for %s generates synthetic data and is not allowed in production", dimension)
+				}
+				logger.Warn("Scoring with synthetic fixture data", zap.String("dimension", dimension))
+				registry.Register(dimension, fixture, providers.Options{})
+			default:
+				return nil, fmt.Errorf("unknown provider %q for %s (want http, csv, sanctions or fixture)", name, dimension)
+			}
+		}
+	}
+	return registry, nil
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/risk-assessment/internal/application"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+type BacktestHandler struct {
+	backtestService *service.BacktestService
+}
+
+func NewBacktestHandler(backtestService *service.BacktestService) *BacktestHandler {
+	return &BacktestHandler{backtestService: backtestService}
+}
+
+// RecordOutcome records a real event, such as a default or a sanction,
+// that happened to an entity
+func (h *BacktestHandler) RecordOutcome(c *gin.Context) {
+	var req models.RecordOutcomeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	outcome, err := h.backtestService.RecordOutcome(&req, c.GetString("user_id"))
+	if err != nil {
+		respondOutcomeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": outcome})
+}
+
+// ListOutcomes pages through outcomes, latest first, filtered by
+// entity_id, a comma-separated type list and an RFC 3339 from/to range
+func (h *BacktestHandler) ListOutcomes(c *gin.Context) {
+	limit, offset := pageParams(c)
+	filter := models.OutcomeFilter{EntityID: c.Query("entity_id"), Limit: limit, Offset: offset}
+	if types := c.Query("type"); types != "" {
+		filter.Types = strings.Split(types, ",")
+	}
+	for _, bound := range []struct {
+		param string
+		dest  *time.Time
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		if value := c.Query(bound.param); value != "" {
+			parsed, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": bound.param + " must be an RFC 3339 time"})
+				return
+			}
+			*bound.dest = parsed
+		}
+	}
+
+	outcomes, total, err := h.backtestService.ListOutcomes(filter)
+	if err != nil {
+		respondOutcomeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": outcomes,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+func (h *BacktestHandler) GetOutcome(c *gin.Context) {
+	outcome, err := h.backtestService.GetOutcome(c.Param("id"))
+	if err != nil {
+		respondOutcomeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": outcome})
+}
+
+func (h *BacktestHandler) DeleteOutcome(c *gin.Context) {
+	if err := h.backtestService.DeleteOutcome(c.Param("id")); err != nil {
+		respondOutcomeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Outcome deleted"})
+}
+
+// SubmitBacktest queues a backtest of the assessments made in a window
+func (h *BacktestHandler) SubmitBacktest(c *gin.Context) {
+	var req models.BacktestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	backtest, err := h.backtestService.Submit(&req, c.GetString("user_id"))
+	if err != nil {
+		respondBacktestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": backtest})
+}
+
+func (h *BacktestHandler) ListBacktests(c *gin.Context) {
+	limit, offset := pageParams(c)
+	backtests, total, err := h.backtestService.ListBacktests(limit, offset)
+	if err != nil {
+		respondBacktestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": backtests,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+func (h *BacktestHandler) GetBacktest(c *gin.Context) {
+	backtest, err := h.backtestService.GetBacktest(c.Param("id"))
+	if err != nil {
+		respondBacktestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": backtest})
+}
+
+// GetBacktestReport compares the model versions a completed backtest
+// measured, dimension by dimension
+func (h *BacktestHandler) GetBacktestReport(c *gin.Context) {
+	report, err := h.backtestService.Report(c.Param("id"))
+	if err != nil {
+		respondBacktestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": report})
+}
+
+// pageParams reads limit and offset, capping limit at maxPageSize
+func pageParams(c *gin.Context) (limit, offset int) {
+	limit = maxPageSize
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 {
+		limit = parsed
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed > 0 {
+		offset = parsed
+	}
+	return limit, offset
+}
+
+func respondOutcomeError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidOutcome):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Outcome not found"})
+	case errors.Is(err, repository.ErrConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": "This outcome is already recorded for the entity"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func respondBacktestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidBacktest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrBacktestNotCompleted):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Backtest not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
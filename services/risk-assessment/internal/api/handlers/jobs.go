@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/risk-assessment/internal/application"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+// maxJobPageSize is larger than maxPageSize so portfolio results can be
+// exported in fewer requests
+const maxJobPageSize = 1000
+
+type JobHandler struct {
+	jobService *service.JobService
+}
+
+func NewJobHandler(jobService *service.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+// SubmitJob queues a batch assessment. Entities come from a JSON body, a
+// text/csv body, or a multipart "file" upload; CSV submissions take
+// entity_type, dimensions and include_factors from the query string.
+func (h *JobHandler) SubmitJob(c *gin.Context) {
+	var req models.SubmitJobRequest
+	switch c.ContentType() {
+	case "text/csv", "multipart/form-data":
+		body := c.Request.Body
+		if c.ContentType() == "multipart/form-data" {
+			file, err := c.FormFile("file")
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "CSV upload must be in the \"file\" field"})
+				return
+			}
+			f, err := file.Open()
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			defer f.Close()
+			body = f
+		}
+
+		entities, err := service.ParseJobCSV(body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		req.Entities = entities
+		req.EntityType = c.Query("entity_type")
+		if dimensions := c.Query("dimensions"); dimensions != "" {
+			req.Dimensions = strings.Split(dimensions, ",")
+		}
+		req.IncludeFactors, _ = strconv.ParseBool(c.Query("include_factors"))
+	default:
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	job, err := h.jobService.Submit(&req, c.GetString("user_id"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidJob) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"data": job})
+}
+
+func (h *JobHandler) GetJob(c *gin.Context) {
+	job, err := h.jobService.GetJob(c.Param("id"))
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": job})
+}
+
+// GetJobResults pages through a job's per-entity outcomes in submission
+// order, optionally filtered by status
+func (h *JobHandler) GetJobResults(c *gin.Context) {
+	limit, offset := maxPageSize, 0
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 {
+		limit = parsed
+	}
+	if limit > maxJobPageSize {
+		limit = maxJobPageSize
+	}
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed > 0 {
+		offset = parsed
+	}
+
+	items, total, err := h.jobService.ListResults(c.Param("id"), c.Query("status"), limit, offset)
+	if err != nil {
+		respondJobError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": items,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+func respondJobError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Assessment job not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/risk-assessment/internal/application"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+type NotificationHandler struct {
+	notificationService *service.NotificationService
+}
+
+func NewNotificationHandler(notificationService *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+// ListNotifications returns the caller's in-app notifications, newest first
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID := c.GetString("user_id")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+		return
+	}
+
+	limit, offset := maxPageSize, 0
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 {
+		limit = parsed
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed > 0 {
+		offset = parsed
+	}
+
+	notifications, total, err := h.notificationService.ListNotifications(userID, c.Query("unread_only") == "true", limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": notifications,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	notification, err := h.notificationService.MarkRead(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notification})
+}
+
+func (h *NotificationHandler) Dismiss(c *gin.Context) {
+	notification, err := h.notificationService.Dismiss(c.GetString("user_id"), c.Param("id"))
+	if err != nil {
+		respondNotificationError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": notification})
+}
+
+func respondNotificationError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -1,18 +1,28 @@
 package handlers
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	models "atlas-core-api/services/risk-assessment/internal/domain"
 	service "atlas-core-api/services/risk-assessment/internal/application"
+	"atlas-core-api/services/risk-assessment/internal/application/commands"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
 )
 
+const maxPageSize = 100
+
 type RiskHandler struct {
 	riskService *service.RiskAssessmentService
 }
 
+// statusClientClosedRequest is logged when a client disconnects before its
+// assessment completes
+const statusClientClosedRequest = 499
+
 func NewRiskHandler(riskService *service.RiskAssessmentService) *RiskHandler {
 	return &RiskHandler{riskService: riskService}
 }
@@ -24,21 +34,62 @@ func (h *RiskHandler) AssessRisk(c *gin.Context) {
 		return
 	}
 
-	assessment, err := h.riskService.AssessRisk(&req)
+	assessment, err := h.riskService.AssessRisk(c.Request.Context(), &req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, context.Canceled):
+			// The client disconnected; there is no one to respond to
+			c.AbortWithStatus(statusClientClosedRequest)
+		case errors.Is(err, service.ErrUnknownDimension):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNoActiveModel):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"data": assessment})
 }
 
+// SimulateRisk scores an entity with overridden indicators and returns the
+// change against its current assessment. Simulations are not stored.
+func (h *RiskHandler) SimulateRisk(c *gin.Context) {
+	var req models.SimulateRiskRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	simulation, err := h.riskService.Simulate(c.Request.Context(), &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			c.AbortWithStatus(statusClientClosedRequest)
+		case errors.Is(err, service.ErrInvalidSimulation), errors.Is(err, service.ErrUnknownDimension):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, service.ErrNoActiveModel):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": simulation})
+}
+
 func (h *RiskHandler) GetRiskAssessment(c *gin.Context) {
 	id := c.Param("id")
 	
 	assessment, err := h.riskService.GetRiskAssessment(id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Risk assessment not found"})
+		if errors.Is(err, repository.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Risk assessment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
@@ -50,8 +101,30 @@ func (h *RiskHandler) GetRiskTrends(c *gin.Context) {
 	dimension := c.Query("dimension")
 	period := c.Query("period")
 
-	trends, err := h.riskService.GetRiskTrends(entityID, dimension, period)
+	opts := models.TrendOptions{Bucket: c.Query("bucket"), ForecastDays: 7}
+	if raw := c.Query("forecast_days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "forecast_days must be a number"})
+			return
+		}
+		opts.ForecastDays = days
+	}
+	if raw := c.Query("window"); raw != "" {
+		window, err := strconv.Atoi(raw)
+		if err != nil || window < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "window must be a positive number"})
+			return
+		}
+		opts.MovingAverageWindow = window
+	}
+
+	trends, err := h.riskService.GetRiskTrends(entityID, dimension, period, opts)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidTrendQuery) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -66,8 +139,12 @@ func (h *RiskHandler) ConfigureAlert(c *gin.Context) {
 		return
 	}
 
-	alert, err := h.riskService.ConfigureAlert(&config)
+	alert, err := h.riskService.ConfigureAlert(&config, c.GetString("user_id"))
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAlert) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -75,6 +152,27 @@ func (h *RiskHandler) ConfigureAlert(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"data": alert})
 }
 
+// TestAlertRule replays an alert rule over an entity's stored assessments
+func (h *RiskHandler) TestAlertRule(c *gin.Context) {
+	var test models.AlertRuleTest
+	if err := c.ShouldBindJSON(&test); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.riskService.TestAlertRule(&test)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAlert) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": result})
+}
+
 func (h *RiskHandler) ListAlerts(c *gin.Context) {
 	activeOnly := c.Query("active_only") == "true"
 	
@@ -91,7 +189,7 @@ func (h *RiskHandler) DeleteAlert(c *gin.Context) {
 	id := c.Param("id")
 	
 	if err := h.riskService.DeleteAlert(id); err != nil {
-		if err.Error() == "not found" {
+		if errors.Is(err, repository.ErrNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
 			return
 		}
@@ -102,21 +200,97 @@ func (h *RiskHandler) DeleteAlert(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
+func (h *RiskHandler) GetAlert(c *gin.Context) {
+	alert, err := h.riskService.GetAlert(c.Param("id"))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alert})
+}
+
+// AcknowledgeAlert records that the caller is handling a firing alert
+func (h *RiskHandler) AcknowledgeAlert(c *gin.Context) {
+	alert, err := h.riskService.AcknowledgeAlert(c.Param("id"), c.GetString("user_id"))
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alert})
+}
+
+// ResolveAlert closes a firing or acknowledged alert with an optional
+// resolution note
+func (h *RiskHandler) ResolveAlert(c *gin.Context) {
+	var body struct {
+		Resolution string `json:"resolution"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	alert, err := h.riskService.ResolveAlert(commands.ResolveAlertCommand{
+		AlertID:    c.Param("id"),
+		ResolvedBy: c.GetString("user_id"),
+		Resolution: body.Resolution,
+	})
+	if err != nil {
+		respondAlertError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": alert})
+}
+
+func respondAlertError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "alert not found"})
+	case errors.Is(err, service.ErrAlertState):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (h *RiskHandler) GetAssessmentsByEntity(c *gin.Context) {
 	entityID := c.Param("entity_id")
 	limit := 10
-	
+	offset := 0
+
 	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil {
+		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 {
 			limit = parsed
 		}
 	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if offsetStr := c.Query("offset"); offsetStr != "" {
+		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed > 0 {
+			offset = parsed
+		}
+	}
 
-	assessments, err := h.riskService.GetAssessmentsByEntity(entityID, limit)
+	assessments, total, err := h.riskService.GetAssessmentsByEntity(entityID, limit, offset)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": assessments})
+	c.JSON(http.StatusOK, gin.H{
+		"data": assessments,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+// GetProviderHealth reports each data provider's breaker state, success and
+// failure counts and most recent error
+func (h *RiskHandler) GetProviderHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"data": h.riskService.ProviderHealth()})
 }
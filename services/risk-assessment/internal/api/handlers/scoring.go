@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/risk-assessment/internal/application"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+type ScoringModelHandler struct {
+	scoringService *service.ScoringModelService
+}
+
+func NewScoringModelHandler(scoringService *service.ScoringModelService) *ScoringModelHandler {
+	return &ScoringModelHandler{scoringService: scoringService}
+}
+
+// ListModels returns scoring model versions, optionally for one entity type
+func (h *ScoringModelHandler) ListModels(c *gin.Context) {
+	scoringModels, err := h.scoringService.ListModels(c.Query("entity_type"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": scoringModels})
+}
+
+func (h *ScoringModelHandler) GetModel(c *gin.Context) {
+	model, err := h.scoringService.GetModel(c.Param("id"))
+	if err != nil {
+		respondModelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model})
+}
+
+// CreateModel stores a new draft version; it scores nothing until activated
+func (h *ScoringModelHandler) CreateModel(c *gin.Context) {
+	var req service.CreateScoringModelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	model, err := h.scoringService.CreateModel(&req, c.GetString("user_id"))
+	if err != nil {
+		respondModelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": model})
+}
+
+// ActivateModel switches new assessments of the model's entity type to it
+func (h *ScoringModelHandler) ActivateModel(c *gin.Context) {
+	model, err := h.scoringService.ActivateModel(c.Param("id"))
+	if err != nil {
+		respondModelError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": model})
+}
+
+func respondModelError(c *gin.Context, err error) {
+	var invalid *service.ModelValidationError
+	switch {
+	case errors.As(err, &invalid):
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scoring model", "details": invalid.Problems})
+	case errors.Is(err, repository.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "scoring model not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	service "atlas-core-api/services/risk-assessment/internal/application"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+type WatchlistHandler struct {
+	watchlistService *service.WatchlistService
+}
+
+func NewWatchlistHandler(watchlistService *service.WatchlistService) *WatchlistHandler {
+	return &WatchlistHandler{watchlistService: watchlistService}
+}
+
+// Watch adds an entity to the scheduled reassessment watchlist
+func (h *WatchlistHandler) Watch(c *gin.Context) {
+	var req models.WatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	watch, err := h.watchlistService.Watch(&req, c.GetString("user_id"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidWatch):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, repository.ErrConflict):
+			c.JSON(http.StatusConflict, gin.H{"error": "Entity is already watched"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"data": watch})
+}
+
+func (h *WatchlistHandler) ListWatches(c *gin.Context) {
+	limit, offset := maxPageSize, 0
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 {
+		limit = parsed
+	}
+	if limit > maxPageSize {
+		limit = maxPageSize
+	}
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed > 0 {
+		offset = parsed
+	}
+
+	watches, total, err := h.watchlistService.ListWatches(limit, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"data": watches,
+		"meta": gin.H{"total": total, "limit": limit, "offset": offset},
+	})
+}
+
+func (h *WatchlistHandler) GetWatch(c *gin.Context) {
+	watch, err := h.watchlistService.GetWatch(c.Param("id"))
+	if err != nil {
+		respondWatchError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"data": watch})
+}
+
+func (h *WatchlistHandler) Unwatch(c *gin.Context) {
+	if err := h.watchlistService.Unwatch(c.Param("id")); err != nil {
+		respondWatchError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func respondWatchError(c *gin.Context, err error) {
+	if errors.Is(err, repository.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Watched entity not found"})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireRole allows the request when the user context from the service
+// token holds any of roles. It must run after servicetoken.Require.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, held := range c.GetStringSlice("roles") {
+			for _, role := range roles {
+				if held == role {
+					c.Next()
+					return
+				}
+			}
+		}
+
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error":   "forbidden",
+			"message": "Insufficient permissions",
+		})
+	}
+}
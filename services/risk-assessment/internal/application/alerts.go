@@ -0,0 +1,344 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"atlas-core-api/services/risk-assessment/internal/application/commands"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+)
+
+var (
+	// ErrInvalidAlert is returned for an alert rule that cannot be created
+	ErrInvalidAlert = errors.New("invalid alert")
+	// ErrAlertState is returned when an alert cannot make the requested
+	// transition from its current state
+	ErrAlertState = errors.New("alert cannot make this transition")
+)
+
+func (s *RiskAssessmentService) ConfigureAlert(config *models.AlertConfiguration, createdBy string) (*models.RiskAlert, error) {
+	alert, _, err := newAlert(config, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	alert.CreatedBy = createdBy
+
+	if err := s.repo.CreateAlert(alert); err != nil {
+		return nil, err
+	}
+
+	return alert, nil
+}
+
+// newAlert validates an alert configuration and returns the armed alert
+// and its rule
+func newAlert(config *models.AlertConfiguration, now time.Time) (*models.RiskAlert, *models.Rule, error) {
+	hysteresis := models.DefaultAlertHysteresis
+	if config.Hysteresis != nil {
+		hysteresis = *config.Hysteresis
+	}
+	cooldown := int(models.DefaultAlertCooldown / time.Second)
+	if config.CooldownSeconds != nil {
+		cooldown = *config.CooldownSeconds
+	}
+	if hysteresis < 0 || hysteresis > 1 {
+		return nil, nil, fmt.Errorf("%w: hysteresis must be between 0 and 1", ErrInvalidAlert)
+	}
+	if cooldown < 0 {
+		return nil, nil, fmt.Errorf("%w: cooldown_seconds must not be negative", ErrInvalidAlert)
+	}
+
+	grouped := config.EntityType != "" || config.Tag != ""
+	if (config.EntityID == "") == !grouped {
+		return nil, nil, fmt.Errorf("%w: set either entity_id, or entity_type and/or tag", ErrInvalidAlert)
+	}
+	var threshold float64
+	if config.Expression != "" {
+		if config.Dimension != "" || config.Condition != "" || config.Threshold != nil {
+			return nil, nil, fmt.Errorf("%w: expression replaces dimension, condition and threshold", ErrInvalidAlert)
+		}
+	} else {
+		if config.Dimension == "" || config.Condition == "" || config.Threshold == nil {
+			return nil, nil, fmt.Errorf("%w: set expression, or dimension, condition and threshold", ErrInvalidAlert)
+		}
+		threshold = *config.Threshold
+	}
+
+	alert := &models.RiskAlert{
+		ID:              uuid.New().String(),
+		EntityID:        config.EntityID,
+		EntityType:      config.EntityType,
+		Tag:             config.Tag,
+		Expression:      config.Expression,
+		Dimension:       config.Dimension,
+		Threshold:       threshold,
+		Condition:       config.Condition,
+		Hysteresis:      hysteresis,
+		CooldownSeconds: cooldown,
+		Active:          true,
+		State:           models.AlertStateArmed,
+		// Replaced by the breach severity when the alert fires
+		Severity:  models.SeverityMedium,
+		Triggered: false,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	rule, err := alert.Rule()
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidAlert, err)
+	}
+	return alert, rule, nil
+}
+
+// TestAlertRule replays an alert rule over an entity's stored assessments
+// without saving the alert or publishing anything
+func (s *RiskAssessmentService) TestAlertRule(test *models.AlertRuleTest) (*models.AlertRuleTestResult, error) {
+	if test.EntityID == "" {
+		return nil, fmt.Errorf("%w: entity_id is required to test a rule", ErrInvalidAlert)
+	}
+	if test.Period == "" {
+		test.Period = "30d"
+	}
+	alert, rule, err := newAlert(&test.AlertConfiguration, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	assessments, err := s.repo.GetTrends(test.EntityID, "", test.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &models.AlertRuleTestResult{
+		EntityID:    test.EntityID,
+		Period:      test.Period,
+		Evaluations: make([]models.AlertRuleEvaluation, 0, len(assessments)),
+	}
+	for _, assessment := range assessments {
+		if !rule.Applies(assessment) {
+			continue
+		}
+		transition, outcome := alert.Evaluate(rule, models.RuleInput{Assessment: assessment, History: assessments}, assessment.Timestamp)
+		evaluation := models.AlertRuleEvaluation{
+			AssessmentID: assessment.ID,
+			Timestamp:    assessment.Timestamp,
+			OverallScore: assessment.OverallScore,
+			Matched:      outcome.Matched,
+			Fired:        transition == models.AlertFired,
+			State:        alert.State,
+		}
+		if outcome.Matched {
+			result.Matched++
+		}
+		if evaluation.Fired {
+			evaluation.Severity = alert.Severity
+			result.Fired++
+		}
+		result.Evaluations = append(result.Evaluations, evaluation)
+	}
+	return result, nil
+}
+
+func (s *RiskAssessmentService) ListAlerts(activeOnly bool) ([]*models.RiskAlert, error) {
+	return s.repo.ListAlerts(activeOnly)
+}
+
+func (s *RiskAssessmentService) GetAlert(id string) (*models.RiskAlert, error) {
+	return s.repo.GetAlert(id)
+}
+
+func (s *RiskAssessmentService) DeleteAlert(id string) error {
+	return s.repo.DeleteAlert(id)
+}
+
+// AcknowledgeAlert marks a firing alert as being handled
+func (s *RiskAssessmentService) AcknowledgeAlert(id, acknowledgedBy string) (*models.RiskAlert, error) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	alert, err := s.repo.GetAlert(id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !alert.Acknowledge(acknowledgedBy, now) {
+		return nil, fmt.Errorf("%w: alert is %s", ErrAlertState, alert.State)
+	}
+	if err := s.repo.UpdateAlert(alert.ID, alert); err != nil {
+		return nil, err
+	}
+
+	s.publish(events.AlertAcknowledged{
+		BaseEvent:      alertEvent(events.TopicAlertAcknowledged, alert, now),
+		AlertID:        alert.ID,
+		EntityID:       alert.EntityID,
+		AcknowledgedBy: acknowledgedBy,
+	})
+	return alert, nil
+}
+
+// ResolveAlert closes a firing or acknowledged alert; it re-arms after its
+// cooldown
+func (s *RiskAssessmentService) ResolveAlert(cmd commands.ResolveAlertCommand) (*models.RiskAlert, error) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	alert, err := s.repo.GetAlert(cmd.AlertID)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if !alert.Resolve(cmd.ResolvedBy, cmd.Resolution, now) {
+		return nil, fmt.Errorf("%w: alert is %s", ErrAlertState, alert.State)
+	}
+	if err := s.repo.UpdateAlert(alert.ID, alert); err != nil {
+		return nil, err
+	}
+
+	s.publishResolved(alert, now)
+	return alert, nil
+}
+
+// checkAlerts evaluates the active alerts covering a new assessment and
+// publishes each transition. A group rule is evaluated through the alert
+// it raises for the entity, which is stored on its first transition.
+func (s *RiskAssessmentService) checkAlerts(assessment *models.RiskAssessment) {
+	s.alertsMu.Lock()
+	defer s.alertsMu.Unlock()
+
+	alerts, err := s.repo.ListAlerts(true)
+	if err != nil {
+		return
+	}
+
+	instances := make(map[string]*models.RiskAlert)
+	for _, alert := range alerts {
+		if alert.RuleID != "" && alert.EntityID == assessment.EntityID {
+			instances[alert.RuleID] = alert
+		}
+	}
+
+	// History is only loaded for rules with change() terms
+	var history []*models.RiskAssessment
+	historyLoaded := false
+
+	for _, alert := range alerts {
+		stored := true
+		switch {
+		case alert.IsGroupRule():
+			if !alert.Covers(assessment) {
+				continue
+			}
+			if instance, ok := instances[alert.ID]; ok {
+				alert = instance
+			} else {
+				alert = alert.ForEntity(uuid.New().String(), assessment.EntityID, assessment.Timestamp)
+				stored = false
+			}
+		case alert.RuleID != "" || alert.EntityID != assessment.EntityID:
+			continue
+		}
+
+		rule, err := alert.Rule()
+		if err != nil || !rule.Applies(assessment) {
+			continue
+		}
+		in := models.RuleInput{Assessment: assessment}
+		if rule.Window() > 0 {
+			if !historyLoaded {
+				history, _ = s.repo.GetTrends(assessment.EntityID, "", "90d")
+				historyLoaded = true
+			}
+			in.History = history
+		}
+
+		before := alert.State
+		transition, result := alert.Evaluate(rule, in, assessment.Timestamp)
+		if alert.State == before {
+			continue
+		}
+		if stored {
+			err = s.repo.UpdateAlert(alert.ID, alert)
+		} else {
+			err = s.repo.CreateAlert(alert)
+		}
+		if err != nil {
+			continue
+		}
+
+		switch transition {
+		case models.AlertFired:
+			s.publishTriggered(alert, assessment, result)
+		case models.AlertCleared:
+			s.publishResolved(alert, assessment.Timestamp)
+		}
+	}
+}
+
+// publishTriggered announces a fired alert, and each change() condition
+// that fired it as a threshold breach
+func (s *RiskAssessmentService) publishTriggered(alert *models.RiskAlert, assessment *models.RiskAssessment, result models.RuleResult) {
+	s.publish(events.AlertTriggered{
+		BaseEvent:  alertEvent(events.TopicAlertTriggered, alert, assessment.Timestamp),
+		AlertID:    alert.ID,
+		EntityID:   alert.EntityID,
+		Dimension:  alert.Dimension,
+		Score:      *alert.TriggeredScore,
+		Threshold:  alert.Threshold,
+		Condition:  alert.Condition,
+		Severity:   alert.Severity,
+		Expression: alert.Expression,
+		RuleID:     alert.RuleID,
+	})
+	for _, change := range result.Changes {
+		s.publish(events.RiskThresholdBreached{
+			BaseEvent: events.BaseEvent{
+				ID:          uuid.New().String(),
+				Type:        events.TopicThresholdBreached,
+				AggregateId: assessment.EntityID,
+				Timestamp:   assessment.Timestamp,
+				Version:     1,
+			},
+			EntityID:  assessment.EntityID,
+			Dimension: change.Dimension,
+			Score:     change.Score,
+			PrevScore: change.PrevScore,
+			Threshold: change.Threshold,
+		})
+	}
+}
+
+func (s *RiskAssessmentService) publishResolved(alert *models.RiskAlert, at time.Time) {
+	s.publish(events.AlertResolved{
+		BaseEvent:  alertEvent(events.TopicAlertResolved, alert, at),
+		AlertID:    alert.ID,
+		EntityID:   alert.EntityID,
+		ResolvedBy: alert.ResolvedBy,
+		Resolution: alert.Resolution,
+	})
+}
+
+// publish sends an alert event; the transition is already stored, so a
+// failed publish is not reported to the caller
+func (s *RiskAssessmentService) publish(event events.DomainEvent) {
+	if s.publisher == nil {
+		return
+	}
+	_ = s.publisher.Publish(context.Background(), event)
+}
+
+// alertEvent keys alert events by alert so their lifecycle stays ordered
+func alertEvent(topic string, alert *models.RiskAlert, at time.Time) events.BaseEvent {
+	return events.BaseEvent{
+		ID:          uuid.New().String(),
+		Type:        topic,
+		AggregateId: alert.ID,
+		Timestamp:   at,
+		Version:     1,
+	}
+}
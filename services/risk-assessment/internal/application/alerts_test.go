@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"atlas-core-api/services/risk-assessment/internal/application/commands"
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/messaging"
+)
+
+func TestAlertTransitionsArePublished(t *testing.T) {
+	service := newTestService(t, time.Second)
+	publisher := messaging.NewInMemoryEventPublisher(zap.NewNop())
+	service.publisher = publisher
+
+	assessment, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+	})
+	require.NoError(t, err)
+
+	// Fire on a score just under the assessed one
+	zero := 0
+	threshold := assessment.Dimensions[models.DimensionFinancial].Score - 0.01
+	alert, err := service.ConfigureAlert(&models.AlertConfiguration{
+		EntityID:        "acme",
+		Dimension:       models.DimensionFinancial,
+		Threshold:       &threshold,
+		Condition:       "above",
+		CooldownSeconds: &zero,
+	}, "")
+	require.NoError(t, err)
+	assert.Equal(t, models.AlertStateArmed, alert.State)
+
+	_, err = service.AcknowledgeAlert(alert.ID, "analyst-1")
+	assert.ErrorIs(t, err, ErrAlertState)
+
+	service.checkAlerts(assessment)
+	service.checkAlerts(assessment)
+	alert, err = service.GetAlert(alert.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.AlertStateFiring, alert.State)
+	assert.Equal(t, models.SeverityLow, alert.Severity)
+
+	_, err = service.AcknowledgeAlert(alert.ID, "analyst-1")
+	require.NoError(t, err)
+	alert, err = service.ResolveAlert(commands.ResolveAlertCommand{AlertID: alert.ID, ResolvedBy: "analyst-1", Resolution: "supplier replaced"})
+	require.NoError(t, err)
+	assert.Equal(t, models.AlertStateResolved, alert.State)
+
+	published := publisher.Events()
+	require.Len(t, published, 3)
+	assert.Equal(t, events.TopicAlertTriggered, published[0].EventType())
+	assert.Equal(t, events.TopicAlertAcknowledged, published[1].EventType())
+	resolved, ok := published[2].(events.AlertResolved)
+	require.True(t, ok)
+	assert.Equal(t, "supplier replaced", resolved.Resolution)
+	assert.Equal(t, alert.ID, resolved.AggregateID())
+}
+
+func TestGroupRuleRaisesAlertPerEntity(t *testing.T) {
+	service := newTestService(t, time.Second)
+
+	_, err := service.ConfigureAlert(&models.AlertConfiguration{EntityType: "organization", Expression: "financial >= 0", Tag: "tier-1", EntityID: "acme"}, "")
+	assert.ErrorIs(t, err, ErrInvalidAlert)
+	_, err = service.ConfigureAlert(&models.AlertConfiguration{Tag: "tier-1", Expression: "financial >= 0", Condition: "above"}, "")
+	assert.ErrorIs(t, err, ErrInvalidAlert)
+
+	rule, err := service.ConfigureAlert(&models.AlertConfiguration{Tag: "tier-1", Expression: "financial >= 0"}, "")
+	require.NoError(t, err)
+
+	for _, entity := range []string{"acme", "globex"} {
+		assessment, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+			EntityID:   entity,
+			EntityType: "organization",
+			Dimensions: []string{models.DimensionFinancial},
+			Tags:       []string{"tier-1"},
+		})
+		require.NoError(t, err)
+		service.checkAlerts(assessment)
+	}
+	// Untagged entities are not covered
+	other, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+		EntityID:   "initech",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+	})
+	require.NoError(t, err)
+	service.checkAlerts(other)
+
+	alerts, err := service.ListAlerts(false)
+	require.NoError(t, err)
+	raised := map[string]string{}
+	for _, alert := range alerts {
+		if alert.RuleID == rule.ID {
+			raised[alert.EntityID] = alert.State
+		}
+	}
+	assert.Equal(t, map[string]string{"acme": models.AlertStateFiring, "globex": models.AlertStateFiring}, raised)
+
+	require.NoError(t, service.DeleteAlert(rule.ID))
+	alerts, err = service.ListAlerts(false)
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+}
+
+func TestAlertRuleReplaysHistory(t *testing.T) {
+	service := newTestService(t, time.Second)
+	now := time.Now()
+	for i, score := range []float64{0.2, 0.25, 0.5, 0.55, 0.3} {
+		require.NoError(t, service.repo.Create(&models.RiskAssessment{
+			ID:           fmt.Sprintf("a%d", i),
+			EntityID:     "acme",
+			OverallScore: score,
+			Dimensions:   map[string]models.RiskDimension{models.DimensionFinancial: {Score: score}},
+			Timestamp:    now.AddDate(0, 0, i-5),
+		}))
+	}
+
+	zero := 0
+	result, err := service.TestAlertRule(&models.AlertRuleTest{
+		AlertConfiguration: models.AlertConfiguration{
+			EntityID:        "acme",
+			Expression:      "change(financial, 2d) > 0.2",
+			CooldownSeconds: &zero,
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "30d", result.Period)
+	require.Len(t, result.Evaluations, 5)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 1, result.Fired)
+	assert.True(t, result.Evaluations[2].Fired)
+	assert.Equal(t, models.AlertStateFiring, result.Evaluations[3].State)
+	assert.Equal(t, models.AlertStateResolved, result.Evaluations[4].State)
+
+	_, err = service.TestAlertRule(&models.AlertRuleTest{AlertConfiguration: models.AlertConfiguration{Tag: "tier-1", Expression: "financial > 0.5"}})
+	assert.ErrorIs(t, err, ErrInvalidAlert)
+}
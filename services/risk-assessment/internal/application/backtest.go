@@ -0,0 +1,363 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+var (
+	// ErrInvalidOutcome is returned for an outcome that cannot be recorded
+	ErrInvalidOutcome = errors.New("invalid outcome")
+	// ErrInvalidBacktest is returned for a backtest that cannot be run
+	ErrInvalidBacktest = errors.New("invalid backtest")
+	// ErrBacktestNotCompleted is returned for the report of a backtest that
+	// has no results yet
+	ErrBacktestNotCompleted = errors.New("backtest has not completed")
+)
+
+const (
+	// backtestPageSize is how many stored assessments a backtest loads at
+	// a time
+	backtestPageSize = 500
+	// maxBacktestHorizonDays bounds how long after an assessment outcomes
+	// are looked for
+	maxBacktestHorizonDays = 3650
+	// backtestLease is how long a replica holds a backtest without
+	// renewing its claim. A running backtest renews it every third of the
+	// lease.
+	backtestLease = 5 * time.Minute
+)
+
+// BacktestService records real outcomes and measures how well stored
+// assessments predicted them. Backtests run in the background under a
+// lease, so each runs on one replica; ones interrupted by a restart, or
+// whose replica went away, run again from the start once their lease
+// expires.
+type BacktestService struct {
+	outcomes    repository.OutcomeRepository
+	backtests   repository.BacktestRepository
+	assessments repository.RiskRepository
+	logger      *zap.Logger
+	worker      string
+	lease       time.Duration
+
+	ctx context.Context
+
+	mu      sync.Mutex
+	running map[string]bool
+}
+
+func NewBacktestService(outcomes repository.OutcomeRepository, backtests repository.BacktestRepository, assessments repository.RiskRepository, logger *zap.Logger) *BacktestService {
+	return &BacktestService{
+		outcomes:    outcomes,
+		backtests:   backtests,
+		assessments: assessments,
+		logger:      logger,
+		worker:      uuid.New().String(),
+		lease:       backtestLease,
+		ctx:         context.Background(),
+		running:     make(map[string]bool),
+	}
+}
+
+// Start runs the unfinished backtests no other replica holds, then looks
+// for backtests with expired leases every lease period. Running backtests
+// stop when ctx is cancelled.
+func (s *BacktestService) Start(ctx context.Context) error {
+	s.ctx = ctx
+	if err := s.resume(ctx); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(s.lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.resume(ctx); err != nil {
+					s.logger.Warn("Failed to restart backtests", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// resume starts every unfinished backtest this replica can claim
+func (s *BacktestService) resume(ctx context.Context) error {
+	unfinished, err := s.backtests.Unfinished()
+	if err != nil {
+		return err
+	}
+	for _, backtest := range unfinished {
+		if s.start(ctx, backtest) {
+			s.logger.Info("Restarting backtest", zap.String("backtest_id", backtest.ID))
+		}
+	}
+	return nil
+}
+
+// start claims a backtest and runs it in the background, unless this
+// replica is already running it or another one holds it
+func (s *BacktestService) start(ctx context.Context, backtest *models.Backtest) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running[backtest.ID] {
+		return false
+	}
+	claimed, err := s.backtests.Claim(backtest.ID, s.worker, time.Now(), s.lease)
+	if err != nil {
+		s.logger.Error("Failed to claim backtest", zap.String("backtest_id", backtest.ID), zap.Error(err))
+		return false
+	}
+	if !claimed {
+		return false
+	}
+	s.running[backtest.ID] = true
+
+	go func() {
+		runCtx, cancel := context.WithCancel(ctx)
+		defer func() {
+			cancel()
+			s.mu.Lock()
+			delete(s.running, backtest.ID)
+			s.mu.Unlock()
+		}()
+		go s.holdClaim(runCtx, cancel, backtest.ID)
+		s.run(runCtx, backtest)
+	}()
+	return true
+}
+
+// holdClaim renews a running backtest's lease until ctx ends, stopping the
+// run if the lease is lost to another replica
+func (s *BacktestService) holdClaim(ctx context.Context, stop context.CancelFunc, id string) {
+	ticker := time.NewTicker(s.lease / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			claimed, err := s.backtests.Claim(id, s.worker, time.Now(), s.lease)
+			if err != nil {
+				s.logger.Warn("Failed to renew backtest claim", zap.String("backtest_id", id), zap.Error(err))
+				continue
+			}
+			if !claimed {
+				s.logger.Warn("Lost backtest claim to another replica", zap.String("backtest_id", id))
+				stop()
+				return
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RecordOutcome stores a real event that happened to an entity
+func (s *BacktestService) RecordOutcome(req *models.RecordOutcomeRequest, recordedBy string) (*models.Outcome, error) {
+	if !models.IsOutcomeType(req.Type) {
+		return nil, fmt.Errorf("%w: unknown type %q (want %s)", ErrInvalidOutcome, req.Type, strings.Join(models.OutcomeTypes, ", "))
+	}
+	entityID := strings.TrimSpace(req.EntityID)
+	if entityID == "" {
+		return nil, fmt.Errorf("%w: entity_id is required", ErrInvalidOutcome)
+	}
+	now := time.Now()
+	if req.OccurredAt.After(now) {
+		return nil, fmt.Errorf("%w: occurred_at is in the future", ErrInvalidOutcome)
+	}
+
+	outcome := &models.Outcome{
+		ID:          uuid.New().String(),
+		EntityID:    entityID,
+		EntityType:  strings.TrimSpace(req.EntityType),
+		Type:        req.Type,
+		OccurredAt:  req.OccurredAt.UTC(),
+		Description: strings.TrimSpace(req.Description),
+		Source:      strings.TrimSpace(req.Source),
+		RecordedBy:  recordedBy,
+		CreatedAt:   now,
+	}
+	if err := s.outcomes.Create(outcome); err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
+
+func (s *BacktestService) GetOutcome(id string) (*models.Outcome, error) {
+	return s.outcomes.GetByID(id)
+}
+
+// ListOutcomes returns a page of outcomes, latest first, and how many match
+func (s *BacktestService) ListOutcomes(filter models.OutcomeFilter) ([]*models.Outcome, int, error) {
+	for _, t := range filter.Types {
+		if !models.IsOutcomeType(t) {
+			return nil, 0, fmt.Errorf("%w: unknown type %q", ErrInvalidOutcome, t)
+		}
+	}
+	return s.outcomes.List(filter)
+}
+
+func (s *BacktestService) DeleteOutcome(id string) error {
+	return s.outcomes.Delete(id)
+}
+
+// Submit validates and stores a backtest, then runs it in the background
+func (s *BacktestService) Submit(req *models.BacktestRequest, createdBy string) (*models.Backtest, error) {
+	now := time.Now()
+	backtest := &models.Backtest{
+		ID:           uuid.New().String(),
+		Status:       models.BacktestStatusQueued,
+		From:         req.From.UTC(),
+		To:           req.To.UTC(),
+		HorizonDays:  req.HorizonDays,
+		OutcomeTypes: req.OutcomeTypes,
+		Bins:         req.Bins,
+		CreatedBy:    createdBy,
+		CreatedAt:    now,
+	}
+	if req.To.IsZero() {
+		backtest.To = now.UTC()
+	}
+	if req.From.IsZero() {
+		backtest.From = backtest.To.AddDate(-1, 0, 0)
+	}
+	if backtest.HorizonDays == 0 {
+		backtest.HorizonDays = models.DefaultBacktestHorizonDays
+	}
+	if backtest.Bins == 0 {
+		backtest.Bins = models.DefaultCalibrationBins
+	}
+
+	switch {
+	case !backtest.From.Before(backtest.To):
+		return nil, fmt.Errorf("%w: from must be before to", ErrInvalidBacktest)
+	case backtest.HorizonDays < 0 || backtest.HorizonDays > maxBacktestHorizonDays:
+		return nil, fmt.Errorf("%w: horizon_days must be between 1 and %d", ErrInvalidBacktest, maxBacktestHorizonDays)
+	case backtest.Bins < 0 || backtest.Bins > models.MaxCalibrationBins:
+		return nil, fmt.Errorf("%w: bins must be between 1 and %d", ErrInvalidBacktest, models.MaxCalibrationBins)
+	}
+	for _, t := range backtest.OutcomeTypes {
+		if !models.IsOutcomeType(t) {
+			return nil, fmt.Errorf("%w: unknown outcome type %q", ErrInvalidBacktest, t)
+		}
+	}
+
+	if err := s.backtests.Create(backtest); err != nil {
+		return nil, err
+	}
+	// The run updates its own copy while the caller reads this one
+	queued := *backtest
+	s.start(s.ctx, &queued)
+	return backtest, nil
+}
+
+func (s *BacktestService) GetBacktest(id string) (*models.Backtest, error) {
+	return s.backtests.GetByID(id)
+}
+
+// ListBacktests returns a page of backtests without their results, newest
+// first, and how many there are
+func (s *BacktestService) ListBacktests(limit, offset int) ([]*models.Backtest, int, error) {
+	return s.backtests.List(limit, offset)
+}
+
+// Report compares the model versions a completed backtest measured
+func (s *BacktestService) Report(id string) (*models.BacktestReport, error) {
+	backtest, err := s.backtests.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if backtest.Status != models.BacktestStatusCompleted {
+		return nil, fmt.Errorf("%w: backtest is %s", ErrBacktestNotCompleted, backtest.Status)
+	}
+	return &models.BacktestReport{
+		BacktestID:  backtest.ID,
+		From:        backtest.From,
+		To:          backtest.To,
+		HorizonDays: backtest.HorizonDays,
+		Dimensions:  models.CompareModelVersions(backtest.Results),
+	}, nil
+}
+
+// run replays the window's assessments against the outcomes that followed
+// them. A backtest stopped by ctx stays running and is started again once
+// its lease expires.
+func (s *BacktestService) run(ctx context.Context, backtest *models.Backtest) {
+	startedAt := time.Now()
+	backtest.Status = models.BacktestStatusRunning
+	backtest.StartedAt = &startedAt
+	if err := s.backtests.Update(backtest); err != nil {
+		s.logger.Error("Failed to start backtest", zap.String("backtest_id", backtest.ID), zap.Error(err))
+		return
+	}
+
+	evaluator, err := s.evaluate(ctx, backtest, startedAt)
+	if ctx.Err() != nil {
+		return
+	}
+
+	completedAt := time.Now()
+	backtest.CompletedAt = &completedAt
+	if err != nil {
+		backtest.Status = models.BacktestStatusFailed
+		backtest.Error = err.Error()
+		s.logger.Error("Backtest failed", zap.String("backtest_id", backtest.ID), zap.Error(err))
+	} else {
+		backtest.Status = models.BacktestStatusCompleted
+		backtest.Assessments = evaluator.Assessments
+		backtest.Censored = evaluator.Censored
+		backtest.Results = evaluator.Results(backtest.Bins)
+		s.logger.Info("Backtest completed",
+			zap.String("backtest_id", backtest.ID),
+			zap.Int("assessments", backtest.Assessments),
+			zap.Int("censored", backtest.Censored),
+		)
+	}
+	if err := s.backtests.Update(backtest); err != nil {
+		s.logger.Error("Failed to record backtest results", zap.String("backtest_id", backtest.ID), zap.Error(err))
+	}
+}
+
+// evaluate labels every assessment in the window. Outcomes can follow the
+// last assessment by up to the horizon, so they are loaded past To.
+func (s *BacktestService) evaluate(ctx context.Context, backtest *models.Backtest, observedUntil time.Time) (*models.BacktestEvaluator, error) {
+	horizon := time.Duration(backtest.HorizonDays) * 24 * time.Hour
+	outcomes, _, err := s.outcomes.List(models.OutcomeFilter{
+		Types: backtest.OutcomeTypes,
+		From:  backtest.From,
+		To:    backtest.To.Add(horizon),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("loading outcomes: %w", err)
+	}
+
+	evaluator := models.NewBacktestEvaluator(outcomes, horizon, observedUntil)
+	for offset := 0; ; offset += backtestPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, err := s.assessments.ListBetween(backtest.From, backtest.To, backtestPageSize, offset)
+		if err != nil {
+			return nil, fmt.Errorf("loading assessments: %w", err)
+		}
+		for _, assessment := range page {
+			evaluator.Add(assessment)
+		}
+		if len(page) < backtestPageSize {
+			return evaluator, nil
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+func TestBacktestServiceMeasuresStoredAssessments(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	assessments := repository.NewRiskRepository()
+	backtests := NewBacktestService(repository.NewOutcomeRepository(), repository.NewBacktestRepository(), assessments, zap.NewNop())
+	require.NoError(t, backtests.Start(ctx))
+
+	start := time.Now().AddDate(0, -6, 0)
+	for i, entity := range []struct {
+		id    string
+		score float64
+	}{{"acme", 0.8}, {"globex", 0.3}, {"initech", 0.6}} {
+		require.NoError(t, assessments.Create(&models.RiskAssessment{
+			ID:           entity.id + "-1",
+			EntityID:     entity.id,
+			OverallScore: entity.score,
+			Dimensions:   map[string]models.RiskDimension{models.DimensionFinancial: {Score: entity.score}},
+			Timestamp:    start.Add(time.Duration(i) * time.Hour),
+			ModelID:      "default",
+			ModelVersion: 1,
+		}))
+	}
+	_, err := backtests.RecordOutcome(&models.RecordOutcomeRequest{
+		EntityID: "acme", Type: models.OutcomeDefault, OccurredAt: start.AddDate(0, 0, 20),
+	}, "analyst-1")
+	require.NoError(t, err)
+	_, err = backtests.RecordOutcome(&models.RecordOutcomeRequest{
+		EntityID: "acme", Type: models.OutcomeDefault, OccurredAt: start.AddDate(0, 0, 20),
+	}, "analyst-1")
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	backtest, err := backtests.Submit(&models.BacktestRequest{HorizonDays: 30}, "analyst-1")
+	require.NoError(t, err)
+	_, err = backtests.Report(backtest.ID)
+	assert.ErrorIs(t, err, ErrBacktestNotCompleted)
+
+	require.Eventually(t, func() bool {
+		backtest, err = backtests.GetBacktest(backtest.ID)
+		require.NoError(t, err)
+		return backtest.Status == models.BacktestStatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 3, backtest.Assessments)
+	assert.Zero(t, backtest.Censored)
+	require.Len(t, backtest.Results, 2)
+	require.NotNil(t, backtest.Results[0].AUC)
+	assert.Equal(t, 1.0, *backtest.Results[0].AUC)
+
+	report, err := backtests.Report(backtest.ID)
+	require.NoError(t, err)
+	require.Len(t, report.Dimensions, 2)
+	assert.Equal(t, models.BacktestOverallDimension, report.Dimensions[0].Dimension)
+
+	_, err = backtests.Submit(&models.BacktestRequest{OutcomeTypes: []string{"fraud"}}, "analyst-1")
+	assert.ErrorIs(t, err, ErrInvalidBacktest)
+	_, err = backtests.RecordOutcome(&models.RecordOutcomeRequest{
+		EntityID: "acme", Type: models.OutcomeBreach, OccurredAt: time.Now().Add(time.Hour),
+	}, "analyst-1")
+	assert.ErrorIs(t, err, ErrInvalidOutcome)
+}
+
+// startCountingBacktests counts how many times backtests are started
+type startCountingBacktests struct {
+	repository.BacktestRepository
+	mu      sync.Mutex
+	started int
+}
+
+func (r *startCountingBacktests) Update(backtest *models.Backtest) error {
+	if backtest.Status == models.BacktestStatusRunning {
+		r.mu.Lock()
+		r.started++
+		r.mu.Unlock()
+	}
+	return r.BacktestRepository.Update(backtest)
+}
+
+func TestBacktestServiceReplicasRestartEachBacktestOnce(t *testing.T) {
+	repo := &startCountingBacktests{BacktestRepository: repository.NewBacktestRepository()}
+	now := time.Now()
+	require.NoError(t, repo.Create(&models.Backtest{
+		ID: "backtest-1", Status: models.BacktestStatusRunning, From: now.AddDate(-1, 0, 0), To: now,
+		HorizonDays: 30, Bins: 5, CreatedAt: now,
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Two replicas sharing the store both find the backtest on start
+	assessments := repository.NewRiskRepository()
+	first := NewBacktestService(repository.NewOutcomeRepository(), repo, assessments, zap.NewNop())
+	second := NewBacktestService(repository.NewOutcomeRepository(), repo, assessments, zap.NewNop())
+	require.NoError(t, first.Start(ctx))
+	require.NoError(t, second.Start(ctx))
+
+	require.Eventually(t, func() bool {
+		backtest, err := first.GetBacktest("backtest-1")
+		require.NoError(t, err)
+		return backtest.Status == models.BacktestStatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, 1, repo.started, "one replica runs the backtest")
+
+	claimed, err := repo.Claim("backtest-1", "late-replica", time.Now(), time.Minute)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a finished backtest cannot be claimed")
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+)
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.DomainEvent) error
+	Close() error
+}
+
+// notifyingPublisher passes events on and hands them to the notification
+// dispatcher
+type notifyingPublisher struct {
+	EventPublisher
+	notifications *NotificationService
+}
+
+// NewNotifyingPublisher publishes through next and then lets notifications
+// act on each event, whether or not publishing succeeded
+func NewNotifyingPublisher(next EventPublisher, notifications *NotificationService) EventPublisher {
+	return &notifyingPublisher{EventPublisher: next, notifications: notifications}
+}
+
+func (p *notifyingPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	err := p.EventPublisher.Publish(ctx, event)
+	p.notifications.Handle(event)
+	return err
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// ExplainDimension turns a dimension's indicator contributions into risk
+// factors. share is the dimension's weight as a fraction of all assessed
+// weights, so a factor's Impact is what it added to the overall score.
+func ExplainDimension(entityID, dimension string, share float64, result models.DimensionScore, source string, observedAt time.Time) []models.RiskFactor {
+	factors := make([]models.RiskFactor, 0, len(result.Contributions))
+	for _, c := range result.Contributions {
+		if c.Weighted == 0 {
+			continue
+		}
+
+		factor := models.RiskFactor{
+			ID:           uuid.New().String(),
+			Name:         c.Label,
+			Impact:       round4(c.Weighted * share),
+			Source:       source,
+			SourceID:     entityID,
+			Dimension:    dimension,
+			Indicator:    c.Indicator,
+			Value:        c.Value,
+			Contribution: round4(c.Weighted),
+			Flags:        c.Flags,
+			Explanation:  explain(dimension, c),
+		}
+		if !observedAt.IsZero() {
+			observed := observedAt
+			factor.ObservedAt = &observed
+		}
+		factors = append(factors, factor)
+	}
+	return factors
+}
+
+// RankFactors orders factors by the size of their impact, largest first
+func RankFactors(factors []models.RiskFactor) {
+	sort.SliceStable(factors, func(i, j int) bool {
+		return math.Abs(factors[i].Impact) > math.Abs(factors[j].Impact)
+	})
+}
+
+func explain(dimension string, c models.Contribution) string {
+	verb := "adds %.2f to"
+	amount := c.Weighted
+	if amount < 0 {
+		verb = "subtracts %.2f from"
+		amount = -amount
+	}
+
+	text := fmt.Sprintf("%s of %s "+verb+" the %s score", c.Label, formatValue(c.Value), amount, dimension)
+	if len(c.Flags) > 0 {
+		text += " (" + strings.Join(c.Flags, ", ") + ")"
+	}
+	return text
+}
+
+func formatValue(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e9 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.2f", v)
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+func TestExplainDimension(t *testing.T) {
+	observed := time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC)
+	score := models.DefaultScoringModel().Dimensions[models.DimensionFinancial].Score(map[string]float64{
+		"credit_score":      800,
+		"debt_to_equity":    1.8,
+		"revenue_growth":    0.1,
+		"market_volatility": 0,
+	})
+
+	factors := ExplainDimension("acme", models.DimensionFinancial, 0.5, score, "financial-data-api", observed)
+	RankFactors(factors)
+
+	// A perfect credit score and zero volatility contribute nothing
+	require.Len(t, factors, 2)
+
+	leverage := factors[0]
+	assert.Equal(t, "debt_to_equity", leverage.Indicator)
+	assert.InDelta(t, 0.27, leverage.Contribution, 1e-9)
+	assert.InDelta(t, 0.135, leverage.Impact, 1e-9)
+	assert.Equal(t, []string{"High leverage"}, leverage.Flags)
+	assert.Equal(t, "financial-data-api", leverage.Source)
+	assert.Equal(t, "acme", leverage.SourceID)
+	assert.Equal(t, observed, *leverage.ObservedAt)
+	assert.Equal(t, "Debt-to-equity ratio of 1.80 adds 0.27 to the financial score (High leverage)", leverage.Explanation)
+
+	growth := factors[1]
+	assert.Equal(t, "revenue_growth", growth.Indicator)
+	assert.InDelta(t, -0.02, growth.Contribution, 1e-9)
+	assert.Equal(t, "Revenue growth of 0.10 subtracts 0.02 from the financial score", growth.Explanation)
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// indicatorSnapshot is one provider response flattened into the indicator
+// values scoring models refer to by name
+type indicatorSnapshot struct {
+	Values     map[string]float64
+	Source     string
+	ObservedAt time.Time
+	Quality    float64
+	Synthetic  bool
+	// Corroborating holds the values the other providers in the chain
+	// reported, used to measure how far the providers agree
+	Corroborating []map[string]float64
+}
+
+// fetchIndicators loads the provider data for a dimension from every
+// provider in its chain. The first provider that answers is the source.
+func (s *RiskAssessmentService) fetchIndicators(ctx context.Context, entityID, dimension string) (*indicatorSnapshot, error) {
+	records, err := s.providers.FetchAll(ctx, dimension, entityID)
+	if err != nil {
+		return nil, err
+	}
+	record := records[0]
+	snapshot := &indicatorSnapshot{
+		Values:     record.Values,
+		Source:     record.Provider,
+		ObservedAt: record.ObservedAt,
+		Quality:    record.Quality,
+		Synthetic:  record.Synthetic,
+	}
+	for _, other := range records[1:] {
+		snapshot.Corroborating = append(snapshot.Corroborating, other.Values)
+	}
+	return snapshot, nil
+}
+
+// providerScores scores the source and each corroborating provider on the
+// indicators they all report, so a provider that covers fewer indicators
+// is not mistaken for one that disagrees. Providers sharing no indicator
+// with the source are left out.
+func providerScores(dm models.DimensionModel, snapshot *indicatorSnapshot) []float64 {
+	var shared []map[string]float64
+	common := make(map[string]bool)
+	for _, ind := range dm.Indicators {
+		if _, ok := snapshot.Values[ind.Name]; ok {
+			common[ind.Name] = true
+		}
+	}
+	for _, values := range snapshot.Corroborating {
+		overlap := make(map[string]bool)
+		for name := range common {
+			if _, ok := values[name]; ok {
+				overlap[name] = true
+			}
+		}
+		if len(overlap) == 0 {
+			continue
+		}
+		common = overlap
+		shared = append(shared, values)
+	}
+
+	restrict := func(values map[string]float64) map[string]float64 {
+		restricted := make(map[string]float64, len(common))
+		for name := range common {
+			restricted[name] = values[name]
+		}
+		return restricted
+	}
+	scores := []float64{dm.Score(restrict(snapshot.Values)).Score}
+	for _, values := range shared {
+		scores = append(scores, dm.Score(restrict(values)).Score)
+	}
+	return scores
+}
+
+// withOverrides returns a copy of snapshot with the overridden indicators
+// replaced. With no provider data the overrides alone are scored.
+func withOverrides(snapshot *indicatorSnapshot, overrides map[string]float64, now time.Time) *indicatorSnapshot {
+	if snapshot == nil {
+		snapshot = &indicatorSnapshot{Source: "override", ObservedAt: now, Quality: 1}
+	}
+	values := make(map[string]float64, len(snapshot.Values)+len(overrides))
+	for name, v := range snapshot.Values {
+		values[name] = v
+	}
+	for name, v := range overrides {
+		values[name] = v
+	}
+	copied := *snapshot
+	copied.Values = values
+	copied.Corroborating = nil
+	for _, other := range snapshot.Corroborating {
+		corroborating := make(map[string]float64, len(other)+len(overrides))
+		for name, v := range other {
+			corroborating[name] = v
+		}
+		for name, v := range overrides {
+			corroborating[name] = v
+		}
+		copied.Corroborating = append(copied.Corroborating, corroborating)
+	}
+	return &copied
+}
@@ -0,0 +1,375 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+// ErrInvalidJob is returned for a batch job that cannot be submitted
+var ErrInvalidJob = errors.New("invalid assessment job")
+
+// jobItemLease is how long a replica holds an item it has claimed. Items
+// are claimed by a free worker, so it only has to cover the assessment.
+const jobItemLease = 5 * time.Minute
+
+// JobService runs batch assessment jobs on a fixed pool of workers. Items
+// stay pending until assessed, so jobs interrupted by a restart resume
+// where they stopped. Every replica resumes unfinished jobs; a worker claims
+// one item under a lease only when it is free to assess it, so each item is
+// assessed by one replica and no replica holds items it is not working on.
+// Items claimed by a replica that went away are picked up once their lease
+// expires.
+type JobService struct {
+	jobs      repository.JobRepository
+	risk      *RiskAssessmentService
+	publisher EventPublisher
+	logger    *zap.Logger
+	workers   int
+	worker    string
+	lease     time.Duration
+
+	// wake tells idle workers that a job became active
+	wake chan struct{}
+
+	mu sync.Mutex
+	// active lists the jobs that may have claimable items, in the order
+	// workers take them
+	active []*models.AssessmentJob
+}
+
+type jobWork struct {
+	job  *models.AssessmentJob
+	item *models.JobItem
+}
+
+func NewJobService(jobs repository.JobRepository, risk *RiskAssessmentService, publisher EventPublisher, logger *zap.Logger, workers int) *JobService {
+	if workers < 1 {
+		workers = 1
+	}
+	return &JobService{
+		jobs:      jobs,
+		risk:      risk,
+		publisher: publisher,
+		logger:    logger,
+		workers:   workers,
+		worker:    uuid.New().String(),
+		lease:     jobItemLease,
+		wake:      make(chan struct{}, workers),
+	}
+}
+
+// Start launches the workers and resumes unfinished jobs, then looks for
+// items with expired leases every lease period. Workers stop when ctx is
+// cancelled, leaving unassessed items pending.
+func (s *JobService) Start(ctx context.Context) error {
+	for i := 0; i < s.workers; i++ {
+		go s.work(ctx)
+	}
+
+	if err := s.resume(ctx); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(s.lease)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.resume(ctx); err != nil {
+					s.logger.Warn("Failed to resume assessment jobs", zap.Error(err))
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// resume activates every unfinished job
+func (s *JobService) resume(ctx context.Context) error {
+	unfinished, err := s.jobs.UnfinishedJobs()
+	if err != nil {
+		return err
+	}
+	for _, job := range unfinished {
+		s.activate(job)
+	}
+	return nil
+}
+
+// Submit validates and stores a job, then queues its entities
+func (s *JobService) Submit(req *models.SubmitJobRequest, createdBy string) (*models.AssessmentJob, error) {
+	if len(req.Entities) == 0 {
+		return nil, fmt.Errorf("%w: no entities", ErrInvalidJob)
+	}
+	if len(req.Entities) > models.MaxJobEntities {
+		return nil, fmt.Errorf("%w: %d entities exceeds the limit of %d", ErrInvalidJob, len(req.Entities), models.MaxJobEntities)
+	}
+	for _, dimension := range req.Dimensions {
+		if _, ok := models.DimensionIndicators[dimension]; !ok {
+			return nil, fmt.Errorf("%w: unknown dimension %q", ErrInvalidJob, dimension)
+		}
+	}
+
+	now := time.Now()
+	job := &models.AssessmentJob{
+		ID:             uuid.New().String(),
+		Status:         models.JobStatusQueued,
+		Dimensions:     req.Dimensions,
+		IncludeFactors: req.IncludeFactors,
+		Total:          len(req.Entities),
+		CreatedBy:      createdBy,
+		CreatedAt:      now,
+	}
+	items := make([]*models.JobItem, len(req.Entities))
+	for i, entity := range req.Entities {
+		entityID := strings.TrimSpace(entity.EntityID)
+		entityType := strings.TrimSpace(entity.EntityType)
+		if entityType == "" {
+			entityType = req.EntityType
+		}
+		if entityID == "" || entityType == "" {
+			return nil, fmt.Errorf("%w: entity %d needs an entity_id and entity_type", ErrInvalidJob, i+1)
+		}
+		items[i] = &models.JobItem{
+			JobID:      job.ID,
+			Position:   i,
+			EntityID:   entityID,
+			EntityType: entityType,
+			Status:     models.JobItemPending,
+			UpdatedAt:  now,
+		}
+	}
+
+	if err := s.jobs.CreateJob(job, items); err != nil {
+		return nil, err
+	}
+	s.activate(job)
+	return job, nil
+}
+
+func (s *JobService) GetJob(id string) (*models.AssessmentJob, error) {
+	return s.jobs.GetJob(id)
+}
+
+// ListResults returns a page of a job's items, optionally only those with
+// status, and how many match
+func (s *JobService) ListResults(jobID, status string, limit, offset int) ([]*models.JobItem, int, error) {
+	if _, err := s.jobs.GetJob(jobID); err != nil {
+		return nil, 0, err
+	}
+	return s.jobs.ListItems(jobID, status, limit, offset)
+}
+
+// activate marks a job started and hands it to the workers, unless they
+// already have it
+func (s *JobService) activate(job *models.AssessmentJob) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, active := range s.active {
+		if active.ID == job.ID {
+			return
+		}
+	}
+	if err := s.jobs.MarkStarted(job.ID, time.Now()); err != nil {
+		s.logger.Error("Failed to start assessment job", zap.String("job_id", job.ID), zap.Error(err))
+		return
+	}
+	s.active = append(s.active, job)
+	for i := 0; i < s.workers; i++ {
+		select {
+		case s.wake <- struct{}{}:
+		default:
+			return
+		}
+	}
+}
+
+// claim leases the next pending item of the active jobs to this replica,
+// taking the jobs in turn. A job with nothing left to claim is dropped;
+// items other replicas hold bring it back through resume once their lease
+// expires.
+func (s *JobService) claim() (jobWork, bool) {
+	for {
+		s.mu.Lock()
+		if len(s.active) == 0 {
+			s.mu.Unlock()
+			return jobWork{}, false
+		}
+		job := s.active[0]
+		s.mu.Unlock()
+
+		items, err := s.jobs.ClaimItems(job.ID, s.worker, time.Now(), s.lease, 1)
+		if err != nil {
+			s.logger.Error("Failed to claim assessment job items", zap.String("job_id", job.ID), zap.Error(err))
+		}
+
+		s.mu.Lock()
+		for i, active := range s.active {
+			if active == job {
+				s.active = append(s.active[:i], s.active[i+1:]...)
+				break
+			}
+		}
+		if len(items) > 0 {
+			// Back of the line, so concurrent jobs share the workers
+			s.active = append(s.active, job)
+		}
+		s.mu.Unlock()
+		if len(items) > 0 {
+			return jobWork{job: job, item: items[0]}, true
+		}
+	}
+}
+
+// work assesses claimed items until ctx is cancelled, waiting for a job to
+// be activated whenever there is nothing to claim
+func (s *JobService) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		if w, ok := s.claim(); ok {
+			s.process(ctx, w)
+			continue
+		}
+		select {
+		case <-s.wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *JobService) process(ctx context.Context, w jobWork) {
+	assessment, err := s.risk.AssessRisk(ctx, &models.AssessRiskRequest{
+		EntityID:       w.item.EntityID,
+		EntityType:     w.item.EntityType,
+		Dimensions:     w.job.Dimensions,
+		IncludeFactors: w.job.IncludeFactors,
+	})
+	// Shutting down: the item stays pending and is assessed on restart
+	if ctx.Err() != nil {
+		return
+	}
+
+	item := *w.item
+	item.UpdatedAt = time.Now()
+	if err != nil {
+		item.Status = models.JobItemFailed
+		item.Error = err.Error()
+	} else {
+		item.Status = models.JobItemSucceeded
+		item.AssessmentID = assessment.ID
+		item.OverallScore = &assessment.OverallScore
+		item.Degraded = assessment.Degraded
+	}
+
+	job, completed, err := s.jobs.FinishItem(&item)
+	if err != nil {
+		s.logger.Error("Failed to record assessment job item",
+			zap.String("job_id", item.JobID),
+			zap.Int("position", item.Position),
+			zap.Error(err),
+		)
+		return
+	}
+	if completed {
+		s.publishCompleted(ctx, job)
+	}
+}
+
+func (s *JobService) publishCompleted(ctx context.Context, job *models.AssessmentJob) {
+	s.logger.Info("Assessment job completed",
+		zap.String("job_id", job.ID),
+		zap.Int("succeeded", job.Succeeded),
+		zap.Int("failed", job.Failed),
+	)
+	if s.publisher == nil {
+		return
+	}
+
+	completedAt := time.Now()
+	if job.CompletedAt != nil {
+		completedAt = *job.CompletedAt
+	}
+	event := events.RiskAssessmentJobCompleted{
+		BaseEvent: events.BaseEvent{
+			ID:          uuid.New().String(),
+			Type:        events.TopicRiskAssessed,
+			AggregateId: job.ID,
+			Timestamp:   completedAt,
+			Version:     1,
+		},
+		JobID:       job.ID,
+		Total:       job.Total,
+		Succeeded:   job.Succeeded,
+		Failed:      job.Failed,
+		CreatedBy:   job.CreatedBy,
+		CompletedAt: completedAt,
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish assessment job completion", zap.String("job_id", job.ID), zap.Error(err))
+	}
+}
+
+// ParseJobCSV reads batch entities from CSV with a header row. The
+// entity_id column is required; entity_type is optional.
+func ParseJobCSV(r io.Reader) ([]models.JobEntity, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: reading CSV header: %v", ErrInvalidJob, err)
+	}
+
+	idColumn, typeColumn := -1, -1
+	for i, name := range header {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "entity_id":
+			idColumn = i
+		case "entity_type":
+			typeColumn = i
+		}
+	}
+	if idColumn < 0 {
+		return nil, fmt.Errorf("%w: CSV header has no entity_id column", ErrInvalidJob)
+	}
+
+	entities := make([]models.JobEntity, 0)
+	for line := 2; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return entities, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidJob, err)
+		}
+		if len(entities) == models.MaxJobEntities {
+			return nil, fmt.Errorf("%w: more than %d entities", ErrInvalidJob, models.MaxJobEntities)
+		}
+
+		entity := models.JobEntity{}
+		if idColumn < len(row) {
+			entity.EntityID = row[idColumn]
+		}
+		if typeColumn >= 0 && typeColumn < len(row) {
+			entity.EntityType = row[typeColumn]
+		}
+		if strings.TrimSpace(entity.EntityID) == "" {
+			return nil, fmt.Errorf("%w: line %d has no entity_id", ErrInvalidJob, line)
+		}
+		entities = append(entities, entity)
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/messaging"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+func waitForJob(t *testing.T, jobs *JobService, id string) *models.AssessmentJob {
+	t.Helper()
+	var job *models.AssessmentJob
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = jobs.GetJob(id)
+		require.NoError(t, err)
+		return job.Status == models.JobStatusCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func TestJobServiceRunsBatchAndPublishesSummary(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	publisher := messaging.NewInMemoryEventPublisher(zap.NewNop())
+	jobs := NewJobService(repository.NewJobRepository(), newTestService(t, time.Second), publisher, zap.NewNop(), 2)
+	require.NoError(t, jobs.Start(ctx))
+
+	job, err := jobs.Submit(&models.SubmitJobRequest{
+		Entities: []models.JobEntity{
+			{EntityID: "acme"},
+			{EntityID: "globex", EntityType: "supplier"},
+			{EntityID: "initech"},
+		},
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+	}, "analyst-1")
+	require.NoError(t, err)
+
+	job = waitForJob(t, jobs, job.ID)
+	assert.Equal(t, 3, job.Succeeded)
+	assert.Zero(t, job.Failed)
+
+	items, total, err := jobs.ListResults(job.ID, models.JobItemSucceeded, 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, total)
+	require.Len(t, items, 2)
+	assert.Equal(t, "globex", items[0].EntityID)
+	assert.Equal(t, "supplier", items[0].EntityType)
+	assert.NotEmpty(t, items[0].AssessmentID)
+
+	require.Eventually(t, func() bool { return len(publisher.Events()) == 1 }, time.Second, 10*time.Millisecond)
+	summary := publisher.Events()[0].(events.RiskAssessmentJobCompleted)
+	assert.Equal(t, events.TopicRiskAssessed, summary.EventType())
+	assert.Equal(t, job.ID, summary.JobID)
+	assert.Equal(t, 3, summary.Succeeded)
+	assert.Equal(t, "analyst-1", summary.CreatedBy)
+}
+
+func TestJobServiceResumesUnfinishedJobs(t *testing.T) {
+	repo := repository.NewJobRepository()
+	job := &models.AssessmentJob{ID: "job-1", Status: models.JobStatusRunning, Total: 2, CreatedAt: time.Now()}
+	require.NoError(t, repo.CreateJob(job, []*models.JobItem{
+		{JobID: "job-1", Position: 0, EntityID: "acme", EntityType: "organization", Status: models.JobItemPending},
+		{JobID: "job-1", Position: 1, EntityID: "globex", EntityType: "organization", Status: models.JobItemPending},
+	}))
+	_, _, err := repo.FinishItem(&models.JobItem{JobID: "job-1", Position: 0, Status: models.JobItemFailed, Error: "before restart"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := NewJobService(repo, newTestService(t, time.Second), nil, zap.NewNop(), 1)
+	require.NoError(t, jobs.Start(ctx))
+
+	job = waitForJob(t, jobs, "job-1")
+	assert.Equal(t, 1, job.Succeeded)
+	assert.Equal(t, 1, job.Failed)
+
+	items, _, err := jobs.ListResults("job-1", "", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "before restart", items[0].Error)
+}
+
+// countingJobs counts the item outcomes workers record
+type countingJobs struct {
+	repository.JobRepository
+	mu       sync.Mutex
+	finished int
+}
+
+func (r *countingJobs) FinishItem(item *models.JobItem) (*models.AssessmentJob, bool, error) {
+	r.mu.Lock()
+	r.finished++
+	r.mu.Unlock()
+	return r.JobRepository.FinishItem(item)
+}
+
+func TestJobServiceReplicasSplitResumedJobs(t *testing.T) {
+	repo := &countingJobs{JobRepository: repository.NewJobRepository()}
+	items := make([]*models.JobItem, 20)
+	for i := range items {
+		items[i] = &models.JobItem{JobID: "job-1", Position: i, EntityID: fmt.Sprintf("entity-%d", i), EntityType: "organization", Status: models.JobItemPending}
+	}
+	require.NoError(t, repo.CreateJob(&models.AssessmentJob{ID: "job-1", Status: models.JobStatusRunning, Total: len(items), CreatedAt: time.Now()}, items))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	// Two replicas sharing the store both resume the job on start
+	first := NewJobService(repo, newTestService(t, time.Second), nil, zap.NewNop(), 2)
+	second := NewJobService(repo, newTestService(t, time.Second), nil, zap.NewNop(), 2)
+	require.NoError(t, first.Start(ctx))
+	require.NoError(t, second.Start(ctx))
+
+	job := waitForJob(t, first, "job-1")
+	assert.Equal(t, 20, job.Succeeded)
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.Equal(t, 20, repo.finished, "each item is assessed by one replica")
+}
+
+// leaseTrackingJobs records the most items held under lease at once
+type leaseTrackingJobs struct {
+	repository.JobRepository
+	mu      sync.Mutex
+	held    int
+	maxHeld int
+}
+
+func (r *leaseTrackingJobs) ClaimItems(jobID, worker string, now time.Time, lease time.Duration, limit int) ([]*models.JobItem, error) {
+	items, err := r.JobRepository.ClaimItems(jobID, worker, now, lease, limit)
+	r.mu.Lock()
+	r.held += len(items)
+	r.maxHeld = max(r.maxHeld, r.held)
+	r.mu.Unlock()
+	return items, err
+}
+
+func (r *leaseTrackingJobs) FinishItem(item *models.JobItem) (*models.AssessmentJob, bool, error) {
+	r.mu.Lock()
+	r.held--
+	r.mu.Unlock()
+	return r.JobRepository.FinishItem(item)
+}
+
+func TestJobServiceClaimsOnlyWhatWorkersCanAssess(t *testing.T) {
+	repo := &leaseTrackingJobs{JobRepository: repository.NewJobRepository()}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	jobs := NewJobService(repo, newTestService(t, time.Second), nil, zap.NewNop(), 2)
+	require.NoError(t, jobs.Start(ctx))
+
+	var submitted []*models.AssessmentJob
+	for i := 0; i < 4; i++ {
+		entities := make([]models.JobEntity, 5)
+		for j := range entities {
+			entities[j] = models.JobEntity{EntityID: fmt.Sprintf("entity-%d-%d", i, j)}
+		}
+		job, err := jobs.Submit(&models.SubmitJobRequest{
+			Entities:   entities,
+			EntityType: "organization",
+			Dimensions: []string{models.DimensionFinancial},
+		}, "analyst-1")
+		require.NoError(t, err)
+		submitted = append(submitted, job)
+	}
+
+	for _, job := range submitted {
+		assert.Equal(t, 5, waitForJob(t, jobs, job.ID).Succeeded)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	assert.LessOrEqual(t, repo.maxHeld, 2, "a replica holds no more items than it has workers")
+}
+
+func TestSubmitRejectsInvalidJobs(t *testing.T) {
+	jobs := NewJobService(repository.NewJobRepository(), nil, nil, zap.NewNop(), 1)
+
+	_, err := jobs.Submit(&models.SubmitJobRequest{}, "")
+	assert.ErrorIs(t, err, ErrInvalidJob)
+
+	_, err = jobs.Submit(&models.SubmitJobRequest{Entities: []models.JobEntity{{EntityID: "acme"}}}, "")
+	assert.EqualError(t, err, "invalid assessment job: entity 1 needs an entity_id and entity_type")
+
+	_, err = jobs.Submit(&models.SubmitJobRequest{
+		Entities:   []models.JobEntity{{EntityID: "acme", EntityType: "organization"}},
+		Dimensions: []string{"weather"},
+	}, "")
+	assert.EqualError(t, err, `invalid assessment job: unknown dimension "weather"`)
+}
+
+func TestParseJobCSV(t *testing.T) {
+	entities, err := ParseJobCSV(strings.NewReader("Entity_Type,entity_id\nsupplier,acme\n,globex\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []models.JobEntity{
+		{EntityID: "acme", EntityType: "supplier"},
+		{EntityID: "globex"},
+	}, entities)
+
+	_, err = ParseJobCSV(strings.NewReader("name\nacme\n"))
+	assert.EqualError(t, err, "invalid assessment job: CSV header has no entity_id column")
+
+	_, err = ParseJobCSV(strings.NewReader("entity_id,entity_type\n,supplier\n"))
+	assert.EqualError(t, err, "invalid assessment job: line 2 has no entity_id")
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/notify"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+const (
+	// deliveryBatchSize bounds how many notifications one tick delivers
+	deliveryBatchSize = 100
+	// deliveryLease is how long a claimed notification is held by this
+	// replica before another may retry it
+	deliveryLease = time.Minute
+	// maxRetryDelay caps the backoff between delivery attempts
+	maxRetryDelay = time.Hour
+)
+
+// NotificationPolicy controls deduplication, digesting and retries
+type NotificationPolicy struct {
+	// DedupWindow drops a notification about the same thing sent to the
+	// same user and channel within the window
+	DedupWindow time.Duration
+	// A user who received DigestThreshold notifications on a channel within
+	// DigestWindow gets the rest held to the end of the window and sent as
+	// one digest. Zero disables digesting.
+	DigestWindow    time.Duration
+	DigestThreshold int
+	// MaxAttempts is how often a delivery is tried before it fails; retries
+	// back off exponentially from RetryBase
+	MaxAttempts int
+	RetryBase   time.Duration
+}
+
+// NotificationService turns alert events into notifications for the alert
+// owner on each channel their preferences enable, and delivers those that
+// are not in-app through the channel's sender
+type NotificationService struct {
+	repo    repository.NotificationRepository
+	alerts  repository.RiskRepository
+	senders map[string]notify.Sender
+	logger  *zap.Logger
+	policy  NotificationPolicy
+	tick    time.Duration
+	now     func() time.Time
+}
+
+// NewNotificationService delivers through senders, keyed by channel; a
+// channel without a sender is skipped
+func NewNotificationService(repo repository.NotificationRepository, alerts repository.RiskRepository, senders map[string]notify.Sender, logger *zap.Logger, policy NotificationPolicy, tick time.Duration) *NotificationService {
+	return &NotificationService{
+		repo:    repo,
+		alerts:  alerts,
+		senders: senders,
+		logger:  logger,
+		policy:  policy,
+		tick:    tick,
+		now:     time.Now,
+	}
+}
+
+// Handle notifies the owner of the alert an event is about. Other events
+// are ignored.
+func (s *NotificationService) Handle(event events.DomainEvent) {
+	var alertID, dedupKey, title, message, priority string
+	switch e := event.(type) {
+	case events.AlertTriggered:
+		alertID = e.AlertID
+		dedupKey = events.TopicAlertTriggered + ":" + e.AlertID
+		title = fmt.Sprintf("Risk alert for %s (%s)", e.EntityID, e.Severity)
+		if e.Expression != "" {
+			message = fmt.Sprintf("Rule %q matched with an overall score of %.2f.", e.Expression, e.Score)
+		} else {
+			message = fmt.Sprintf("%s risk is %.2f, %s the threshold of %.2f.", e.Dimension, e.Score, e.Condition, e.Threshold)
+		}
+		priority = models.PriorityForSeverity(e.Severity)
+	case events.AlertResolved:
+		alertID = e.AlertID
+		dedupKey = events.TopicAlertResolved + ":" + e.AlertID
+		title = fmt.Sprintf("Risk alert resolved for %s", e.EntityID)
+		message = fmt.Sprintf("Resolved by %s.", e.ResolvedBy)
+		if e.Resolution != "" {
+			message = fmt.Sprintf("Resolved by %s: %s", e.ResolvedBy, e.Resolution)
+		}
+		priority = models.PriorityLow
+	default:
+		return
+	}
+
+	alert, err := s.alerts.GetAlert(alertID)
+	if err != nil {
+		s.logger.Warn("Failed to load alert for notification", zap.String("alert_id", alertID), zap.Error(err))
+		return
+	}
+	if alert.CreatedBy == "" {
+		return
+	}
+
+	s.notify(&models.Notification{
+		UserID:           alert.CreatedBy,
+		NotificationType: models.NotificationTypeAlert,
+		Title:            title,
+		Message:          message,
+		Priority:         priority,
+		SourceType:       "risk_alert",
+		SourceID:         alert.ID,
+		ActionURL:        "/api/v1/risks/alerts/" + alert.ID,
+		DedupKey:         dedupKey,
+	})
+}
+
+// notify stores a copy of the notification for each channel the user's
+// preferences enable at its priority
+func (s *NotificationService) notify(template *models.Notification) {
+	prefs, err := s.repo.Preferences(template.UserID, template.NotificationType)
+	if err != nil {
+		s.logger.Error("Failed to load notification preferences", zap.String("user_id", template.UserID), zap.Error(err))
+		return
+	}
+	if len(prefs) == 0 {
+		prefs = models.DefaultNotificationPreferences(template.UserID, template.NotificationType)
+	}
+
+	now := s.now()
+	for _, pref := range prefs {
+		if !pref.Allows(template.Priority) {
+			continue
+		}
+		if pref.Channel != models.ChannelInApp && (s.senders[pref.Channel] == nil || pref.Target == "") {
+			continue
+		}
+		duplicate, err := s.repo.SentSince(template.UserID, pref.Channel, template.DedupKey, now.Add(-s.policy.DedupWindow))
+		if err != nil || duplicate {
+			continue
+		}
+
+		n := *template
+		n.ID = uuid.New().String()
+		n.Channel = pref.Channel
+		n.CreatedAt = now
+		if pref.Channel == models.ChannelInApp {
+			n.Status = models.NotificationDelivered
+			n.DeliveredAt = &now
+		} else {
+			at := s.deliverAt(pref, template.Priority, now)
+			n.Status = models.NotificationPending
+			n.Target = pref.Target
+			n.NextAttemptAt = &at
+		}
+		if err := s.repo.Create(&n); err != nil {
+			s.logger.Error("Failed to store notification",
+				zap.String("user_id", n.UserID), zap.String("channel", n.Channel), zap.Error(err))
+		}
+	}
+}
+
+// deliverAt is when a notification is first tried: after quiet hours, and
+// at the end of the digest window while the user is receiving a burst
+func (s *NotificationService) deliverAt(pref *models.NotificationPreference, priority string, now time.Time) time.Time {
+	at := pref.DeliverAt(priority, now)
+	if at.After(now) || s.policy.DigestThreshold <= 0 || s.policy.DigestWindow <= 0 {
+		return at
+	}
+	recent, err := s.repo.CountSince(pref.UserID, pref.Channel, now.Add(-s.policy.DigestWindow))
+	if err != nil || recent < s.policy.DigestThreshold {
+		return at
+	}
+	// Held notifications share the window's end so they go out together
+	return now.Truncate(s.policy.DigestWindow).Add(s.policy.DigestWindow)
+}
+
+// Run delivers due notifications every tick until ctx is cancelled
+func (s *NotificationService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		s.DeliverDue(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// DeliverDue sends the pending notifications that are due. Notifications
+// due together for the same user and target go out as one digest.
+func (s *NotificationService) DeliverDue(ctx context.Context) {
+	now := s.now()
+	due, err := s.repo.ClaimDue(now, deliveryLease, deliveryBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to claim due notifications", zap.Error(err))
+		return
+	}
+
+	var order []string
+	batches := make(map[string][]*models.Notification)
+	for _, n := range due {
+		key := n.UserID + "|" + n.Channel + "|" + n.Target
+		if _, ok := batches[key]; !ok {
+			order = append(order, key)
+		}
+		batches[key] = append(batches[key], n)
+	}
+	for _, key := range order {
+		if ctx.Err() != nil {
+			return
+		}
+		s.deliver(ctx, batches[key], now)
+	}
+}
+
+func (s *NotificationService) deliver(ctx context.Context, batch []*models.Notification, now time.Time) {
+	first := batch[0]
+	title, message, priority := models.Digest(batch)
+	msg := notify.Message{Title: title, Body: message, Priority: priority}
+	if len(batch) == 1 {
+		msg.ActionURL = first.ActionURL
+	}
+
+	var err error
+	if sender := s.senders[first.Channel]; sender != nil {
+		err = sender.Send(ctx, first.Target, msg)
+	} else {
+		err = fmt.Errorf("no sender for channel %s", first.Channel)
+	}
+	if err != nil {
+		s.logger.Warn("Notification delivery failed",
+			zap.String("user_id", first.UserID),
+			zap.String("channel", first.Channel),
+			zap.Int("notifications", len(batch)),
+			zap.Error(err),
+		)
+	}
+
+	for _, n := range batch {
+		n.Attempts++
+		switch {
+		case err == nil:
+			n.Status = models.NotificationDelivered
+			n.DeliveredAt = &now
+			n.NextAttemptAt = nil
+			n.LastError = ""
+		case n.Attempts >= s.policy.MaxAttempts:
+			n.Status = models.NotificationFailed
+			n.NextAttemptAt = nil
+			n.LastError = err.Error()
+		default:
+			next := now.Add(s.retryDelay(n.Attempts))
+			n.NextAttemptAt = &next
+			n.LastError = err.Error()
+		}
+		if err := s.repo.Update(n); err != nil {
+			s.logger.Error("Failed to record notification delivery", zap.String("notification_id", n.ID), zap.Error(err))
+		}
+	}
+}
+
+// retryDelay doubles from RetryBase with each failed attempt
+func (s *NotificationService) retryDelay(attempts int) time.Duration {
+	delay := s.policy.RetryBase
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// ListNotifications returns a page of the user's in-app notifications
+func (s *NotificationService) ListNotifications(userID string, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error) {
+	return s.repo.ListInApp(userID, unreadOnly, limit, offset)
+}
+
+// MarkRead marks one of the user's in-app notifications as read
+func (s *NotificationService) MarkRead(userID, id string) (*models.Notification, error) {
+	return s.updateInApp(userID, id, func(n *models.Notification, now time.Time) {
+		if !n.IsRead {
+			n.IsRead = true
+			n.ReadAt = &now
+		}
+	})
+}
+
+// Dismiss hides one of the user's in-app notifications
+func (s *NotificationService) Dismiss(userID, id string) (*models.Notification, error) {
+	return s.updateInApp(userID, id, func(n *models.Notification, now time.Time) {
+		if !n.IsDismissed {
+			n.IsDismissed = true
+			n.DismissedAt = &now
+		}
+	})
+}
+
+// updateInApp changes a notification the user owns; other users'
+// notifications are reported as not found
+func (s *NotificationService) updateInApp(userID, id string, change func(*models.Notification, time.Time)) (*models.Notification, error) {
+	n, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if n.UserID != userID || n.Channel != models.ChannelInApp {
+		return nil, repository.ErrNotFound
+	}
+	change(n, s.now())
+	if err := s.repo.Update(n); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
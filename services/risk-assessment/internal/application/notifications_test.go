@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/notify"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+// fakeSender records the messages it is asked to send, or fails with err
+type fakeSender struct {
+	sent []sentMessage
+	err  error
+}
+
+type sentMessage struct {
+	Target  string
+	Message notify.Message
+}
+
+func (s *fakeSender) Send(ctx context.Context, target string, msg notify.Message) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.sent = append(s.sent, sentMessage{Target: target, Message: msg})
+	return nil
+}
+
+func newTestNotifications(t *testing.T, policy NotificationPolicy) (*NotificationService, repository.NotificationRepository, *fakeSender, *time.Time) {
+	t.Helper()
+	alerts := repository.NewRiskRepository()
+	require.NoError(t, alerts.CreateAlert(&models.RiskAlert{ID: "alert-1", EntityID: "acme", Active: true, CreatedBy: "user-1"}))
+
+	repo := repository.NewNotificationRepository()
+	require.NoError(t, repo.SavePreference(&models.NotificationPreference{
+		UserID: "user-1", NotificationType: models.NotificationTypeAlert, Channel: models.ChannelInApp,
+		Enabled: true, MinPriority: models.PriorityLow,
+	}))
+	require.NoError(t, repo.SavePreference(&models.NotificationPreference{
+		UserID: "user-1", NotificationType: models.NotificationTypeAlert, Channel: models.ChannelEmail,
+		Enabled: true, MinPriority: models.PriorityLow, Target: "analyst@example.com",
+	}))
+
+	sender := &fakeSender{}
+	service := NewNotificationService(repo, alerts, map[string]notify.Sender{models.ChannelEmail: sender}, zap.NewNop(), policy, time.Second)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+	return service, repo, sender, &now
+}
+
+func triggered(severity string) events.AlertTriggered {
+	return events.AlertTriggered{
+		AlertID: "alert-1", EntityID: "acme", Dimension: models.DimensionFinancial,
+		Score: 0.8, Threshold: 0.6, Condition: "above", Severity: severity,
+	}
+}
+
+func TestNotificationsAreDeduplicatedAndRetried(t *testing.T) {
+	service, repo, sender, now := newTestNotifications(t, NotificationPolicy{
+		DedupWindow: 10 * time.Minute, MaxAttempts: 2, RetryBase: 30 * time.Second,
+	})
+
+	service.Handle(triggered(models.SeverityHigh))
+	service.Handle(triggered(models.SeverityHigh))
+	inApp, total, err := service.ListNotifications("user-1", true, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, models.PriorityHigh, inApp[0].Priority)
+
+	service.DeliverDue(context.Background())
+	deliveries := sender.sent
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, "analyst@example.com", deliveries[0].Target)
+	assert.Equal(t, "Risk alert for acme (high)", deliveries[0].Message.Title)
+
+	// A failing channel is retried with backoff, then given up on
+	sender.err = errors.New("smtp unavailable")
+	service.Handle(events.AlertResolved{AlertID: "alert-1", EntityID: "acme", ResolvedBy: "analyst-1"})
+	service.DeliverDue(context.Background())
+	*now = now.Add(30 * time.Second)
+	service.DeliverDue(context.Background())
+
+	pending, err := repo.ClaimDue(now.Add(time.Hour), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.Len(t, sender.sent, 1)
+
+	// Other users cannot touch the notification
+	_, err = service.MarkRead("user-2", inApp[0].ID)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+	_, err = service.MarkRead("user-1", inApp[0].ID)
+	require.NoError(t, err)
+	_, total, err = service.ListNotifications("user-1", true, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total, "only the resolution is unread")
+	_, err = service.Dismiss("user-1", inApp[0].ID)
+	require.NoError(t, err)
+	_, total, err = service.ListNotifications("user-1", false, 10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+}
+
+func TestNotificationBurstsAreDigested(t *testing.T) {
+	service, repo, sender, now := newTestNotifications(t, NotificationPolicy{
+		DigestWindow: 5 * time.Minute, DigestThreshold: 1, MaxAttempts: 3, RetryBase: time.Second,
+	})
+	require.NoError(t, repo.SavePreference(&models.NotificationPreference{
+		UserID: "user-1", NotificationType: models.NotificationTypeAlert, Channel: models.ChannelEmail,
+		Enabled: true, MinPriority: models.PriorityNormal, Target: "analyst@example.com",
+	}))
+
+	service.Handle(triggered(models.SeverityMedium))
+	service.DeliverDue(context.Background())
+	require.Len(t, sender.sent, 1)
+
+	// Below the preference's minimum priority, so in-app only
+	*now = now.Add(time.Second)
+	service.Handle(events.AlertResolved{AlertID: "alert-1", EntityID: "acme", ResolvedBy: models.SystemActor})
+
+	// Past the threshold the rest of the window is held for a digest
+	for _, severity := range []string{models.SeverityCritical, models.SeverityHigh} {
+		*now = now.Add(time.Second)
+		service.Handle(triggered(severity))
+	}
+	service.DeliverDue(context.Background())
+	require.Len(t, sender.sent, 1)
+
+	*now = now.Add(5 * time.Minute)
+	service.DeliverDue(context.Background())
+	deliveries := sender.sent
+	require.Len(t, deliveries, 2)
+	assert.Equal(t, "2 notifications (highest priority: urgent)", deliveries[1].Message.Title)
+	assert.Equal(t, models.PriorityUrgent, deliveries[1].Message.Priority)
+}
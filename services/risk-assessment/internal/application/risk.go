@@ -1,419 +1,396 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"math"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
+
 	models "atlas-core-api/services/risk-assessment/internal/domain"
-	"atlas-core-api/services/risk-assessment/internal/infrastructure"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/providers"
 	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
-	"context"
 )
 
+// ErrUnknownDimension is returned when a request names a dimension the
+// scoring model does not score
+var ErrUnknownDimension = errors.New("dimension is not scored by the model")
+
+// ErrInvalidTrendQuery is returned for a trend request that cannot be
+// analysed
+var ErrInvalidTrendQuery = errors.New("invalid trend query")
+
 type RiskAssessmentService struct {
-	repo     repository.RiskRepository
-	dataProvider infrastructure.ExternalDataProvider
+	repo      repository.RiskRepository
+	models    repository.ScoringModelRepository
+	providers *providers.Registry
+	publisher EventPublisher
+	budget    time.Duration
+
+	// alertsMu serialises alert evaluation so concurrent assessments of an
+	// entity cannot both fire the same alert
+	alertsMu sync.Mutex
 }
 
-func NewRiskAssessmentService(repo repository.RiskRepository, dataProvider infrastructure.ExternalDataProvider) *RiskAssessmentService {
+// NewRiskAssessmentService creates the assessment service. budget bounds
+// how long an assessment waits for providers across all dimensions; alert
+// transitions are published to publisher, which may be nil.
+func NewRiskAssessmentService(repo repository.RiskRepository, scoringModels repository.ScoringModelRepository, registry *providers.Registry, publisher EventPublisher, budget time.Duration) *RiskAssessmentService {
 	return &RiskAssessmentService{
-		repo:     repo,
-		dataProvider: dataProvider,
+		repo:      repo,
+		models:    scoringModels,
+		providers: registry,
+		publisher: publisher,
+		budget:    budget,
+	}
+}
+
+// AssessRisk scores every requested dimension concurrently under ctx.
+// Dimensions still waiting on providers when the budget runs out get
+// fallback scores and the assessment is marked degraded; if ctx itself is
+// cancelled the assessment is abandoned and ctx's error returned.
+func (s *RiskAssessmentService) AssessRisk(ctx context.Context, req *models.AssessRiskRequest) (*models.RiskAssessment, error) {
+	assessment, err := s.score(ctx, req.EntityID, req.EntityType, req.Dimensions, req.IncludeFactors, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	assessment.ID = uuid.New().String()
+	assessment.Tags = req.Tags
+	if req.Tags == nil {
+		// Scheduled and batch reassessments keep the entity's tags
+		if previous, _, err := s.repo.GetByEntityID(req.EntityID, 1, 0); err == nil && len(previous) > 0 {
+			assessment.Tags = previous[0].Tags
+		}
+	}
+
+	// Store assessment
+	if err := s.repo.Create(assessment); err != nil {
+		return nil, err
+	}
+
+	// Check alerts
+	go s.checkAlerts(assessment)
+
+	return assessment, nil
+}
+
+// score runs the scoring pipeline for an entity and returns an assessment
+// that has no ID and is not stored. overrides replace provider indicator
+// values per dimension and may be nil.
+func (s *RiskAssessmentService) score(ctx context.Context, entityID, entityType string, dimensionNames []string, includeFactors bool, overrides models.IndicatorOverrides) (*models.RiskAssessment, error) {
+	model, err := ActiveModel(s.models, entityType)
+	if err != nil {
+		return nil, err
 	}
+	return s.scoreWith(ctx, model, entityID, entityType, dimensionNames, includeFactors, overrides)
 }
 
-func (s *RiskAssessmentService) AssessRisk(req *models.AssessRiskRequest) (*models.RiskAssessment, error) {
+// scoreWith is score with the scoring model already chosen
+func (s *RiskAssessmentService) scoreWith(ctx context.Context, model *models.ScoringModel, entityID, entityType string, dimensionNames []string, includeFactors bool, overrides models.IndicatorOverrides) (*models.RiskAssessment, error) {
 	// Determine which dimensions to assess
-	dimensionsToAssess := req.Dimensions
+	dimensionsToAssess := dimensionNames
 	if len(dimensionsToAssess) == 0 {
-		// Default: assess all dimensions
-		dimensionsToAssess = []string{
-			models.DimensionOperational,
-			models.DimensionFinancial,
-			models.DimensionReputational,
-			models.DimensionGeopolitical,
-			models.DimensionCompliance,
+		// Default: assess every dimension the model scores
+		dimensionsToAssess = model.DimensionNames()
+	}
+	for _, dimName := range dimensionsToAssess {
+		if _, ok := model.Dimensions[dimName]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDimension, dimName)
 		}
 	}
 
 	// Calculate risk scores for each dimension
+	results, err := s.calculateDimensions(ctx, entityID, dimensionsToAssess, model, overrides)
+	if err != nil {
+		return nil, err
+	}
+
 	dimensions := make(map[string]models.RiskDimension)
 	var overallScore float64
 	var totalWeight float64
+	var degraded bool
+
+	for dimName, result := range results {
+		dimensions[dimName] = result.Dimension
+		degraded = degraded || result.Dimension.Degraded
 
-	for _, dimName := range dimensionsToAssess {
-		dimension := s.calculateDimensionRisk(req.EntityID, dimName)
-		dimensions[dimName] = dimension
-		
 		// Weighted average for overall score
-		weight := dimension.Weight
-		if weight == 0 {
-			weight = 0.2 // Default equal weight
-		}
-		overallScore += dimension.Score * weight
-		totalWeight += weight
+		overallScore += result.Dimension.Score * result.Dimension.Weight
+		totalWeight += result.Dimension.Weight
 	}
 
 	if totalWeight > 0 {
 		overallScore = overallScore / totalWeight
 	}
 
-	// Calculate confidence based on data availability
-	confidence := s.calculateConfidence(req.EntityID, dimensions)
+	// Label each dimension by how it moved across recent assessments
+	s.labelTrends(entityID, dimensions, time.Now())
+
+	// Calculate confidence from the data behind each dimension
+	confidence, warnings := s.calculateConfidence(dimensions)
 
-	// Get risk factors if requested
+	// Explain the scores if requested
 	var factors []models.RiskFactor
-	if req.IncludeFactors {
-		factors = s.getRiskFactors(req.EntityID, dimensions)
+	if includeFactors {
+		factors = s.getRiskFactors(entityID, results, totalWeight)
 	}
 
 	// Create assessment
 	assessment := &models.RiskAssessment{
-		ID:           uuid.New().String(),
-		EntityID:     req.EntityID,
-		EntityType:   req.EntityType,
+		EntityID:     entityID,
+		EntityType:   entityType,
 		OverallScore: math.Round(overallScore*100) / 100,
 		Confidence:   math.Round(confidence*100) / 100,
 		Dimensions:   dimensions,
 		Factors:      factors,
 		Timestamp:    time.Now(),
 		ValidUntil:   time.Now().Add(24 * time.Hour),
+		ModelID:      model.ID,
+		ModelVersion: model.Version,
+		Warnings:     warnings,
+		Degraded:     degraded,
 	}
-
-	// Store assessment
-	if err := s.repo.Create(assessment); err != nil {
-		return nil, err
-	}
-
-	// Check alerts
-	go s.checkAlerts(assessment)
-
+	assessment.LowConfidence = len(warnings) > 0
 	return assessment, nil
 }
 
-func (s *RiskAssessmentService) calculateDimensionRisk(entityID, dimension string) models.RiskDimension {
-	// Create context with timeout for external API calls
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+// dimensionResult is a scored dimension with what went into it
+type dimensionResult struct {
+	Dimension models.RiskDimension
+	Score     models.DimensionScore
+	// Snapshot is nil when the provider returned no data
+	Snapshot *indicatorSnapshot
+}
 
-	var score float64
-	var trend string
-	var keyFactors []string
-
-	switch dimension {
-	case models.DimensionOperational:
-		score, trend, keyFactors, _ = s.calculateOperationalRisk(ctx, entityID)
-	case models.DimensionFinancial:
-		score, trend, keyFactors, _ = s.calculateFinancialRisk(ctx, entityID)
-	case models.DimensionReputational:
-		score, trend, keyFactors, _ = s.calculateReputationalRisk(ctx, entityID)
-	case models.DimensionGeopolitical:
-		score, trend, keyFactors, _ = s.calculateGeopoliticalRisk(ctx, entityID)
-	case models.DimensionCompliance:
-		score, trend, keyFactors, _ = s.calculateComplianceRisk(ctx, entityID)
-	default:
-		// Fallback to mock calculation
-		score = 0.5
-		trend = "stable"
-		keyFactors = []string{"Market volatility", "Regulatory changes", "Economic indicators"}
-	}
+// calculateDimensions scores the dimensions concurrently, sharing one
+// deadline of the service's budget
+func (s *RiskAssessmentService) calculateDimensions(ctx context.Context, entityID string, dimensionNames []string, model *models.ScoringModel, overrides models.IndicatorOverrides) (map[string]dimensionResult, error) {
+	budgetCtx, cancel := context.WithTimeout(ctx, s.budget)
+	defer cancel()
 
-	return models.RiskDimension{
-		Name:       dimension,
-		Score:      math.Round(score*100) / 100,
-		Trend:      trend,
-		KeyFactors: keyFactors,
-		Weight:     0.2, // Equal weight for all dimensions
+	scored := make([]dimensionResult, len(dimensionNames))
+	var wg sync.WaitGroup
+	for i, name := range dimensionNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			scored[i] = s.calculateDimensionRisk(budgetCtx, entityID, name, model.Dimensions[name], overrides[name])
+		}(i, name)
 	}
-}
+	wg.Wait()
 
-func (s *RiskAssessmentService) calculateOperationalRisk(ctx context.Context, entityID string) (float64, string, []string, float64) {
-	data, err := s.dataProvider.GetOperationalData(entityID, ctx)
-	if err != nil {
-		// Fallback to mock data if external API fails
-		return 0.45, "stable", []string{"System reliability", "Process efficiency", "Resource availability"}, 0.5
+	// The caller went away; nobody is waiting for the assessment
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
-	// Calculate operational risk score based on real data
-	cyberRisk := float64(data.CybersecurityIncidents) / 10.0 // Normalize to 0-1
-	supplyRisk := data.SupplyChainRisk / 100.0
-	downtimeRisk := data.SystemDowntime / 10.0 // 10% downtime = high risk
-	efficiencyBonus := (data.OperationalEfficiency - 70) / 30.0 // Efficiency above 70% reduces risk
-
-	score := (cyberRisk*0.3 + supplyRisk*0.25 + downtimeRisk*0.25 - efficiencyBonus*0.2)
-	score = math.Max(0.0, math.Min(1.0, score))
-
-	trend := "stable"
-	if score > 0.7 {
-		trend = "increasing"
-	} else if score < 0.3 {
-		trend = "decreasing"
+	results := make(map[string]dimensionResult, len(dimensionNames))
+	for i, name := range dimensionNames {
+		results[name] = scored[i]
 	}
+	return results, nil
+}
 
-	factors := []string{"Cybersecurity incidents", "Supply chain disruptions", "System downtime"}
-	if data.CybersecurityIncidents > 3 {
-		factors = append(factors, "High cybersecurity risk")
+// calculateDimensionRisk scores one dimension with the model, falling back
+// to the model's fallback score when the provider has no data. overrides
+// replace the provider's indicator values.
+func (s *RiskAssessmentService) calculateDimensionRisk(ctx context.Context, entityID, dimension string, dm models.DimensionModel, overrides map[string]float64) dimensionResult {
+	result := dimensionResult{}
+	inputs := models.ConfidenceInputs{Total: len(dm.Indicators)}
+	var providerError string
+	var degraded bool
+	snapshot, err := s.fetchIndicators(ctx, entityID, dimension)
+	if len(overrides) > 0 {
+		snapshot, err = withOverrides(snapshot, overrides, time.Now()), nil
 	}
-	if data.SystemDowntime > 2.0 {
-		factors = append(factors, "Frequent system outages")
-	}
-
-	return score, trend, factors, data.DataQuality
+	if err != nil {
+		result.Score = models.DimensionScore{Score: dm.FallbackScore, Trend: "stable"}
+		inputs.ProviderFailed = true
+		providerError = err.Error()
+		degraded = errors.Is(ctx.Err(), context.DeadlineExceeded)
+	} else {
+		result.Score = dm.Score(snapshot.Values)
+		result.Snapshot = snapshot
+		inputs.Quality = snapshot.Quality
+		inputs.ObservedAt = snapshot.ObservedAt
+		inputs.Missing = len(result.Score.Missing)
+		inputs.ProviderScores = providerScores(dm, snapshot)
+	}
+	breakdown := models.DimensionConfidence(inputs, time.Now())
+
+	result.Dimension = models.RiskDimension{
+		Name:                dimension,
+		Score:               math.Round(result.Score.Score*100) / 100,
+		Trend:               result.Score.Trend,
+		KeyFactors:          result.Score.KeyFactors,
+		Weight:              dm.Weight,
+		Confidence:          breakdown.Score,
+		ConfidenceBreakdown: &breakdown,
+		ProviderFailed:      inputs.ProviderFailed,
+		ProviderError:       providerError,
+		Degraded:            degraded,
+		Synthetic:           snapshot != nil && snapshot.Synthetic,
+	}
+	return result
 }
 
-func (s *RiskAssessmentService) calculateFinancialRisk(ctx context.Context, entityID string) (float64, string, []string, float64) {
-	data, err := s.dataProvider.GetFinancialData(entityID, ctx)
-	if err != nil {
-		// Fallback to mock data
-		return 0.55, "stable", []string{"Credit rating", "Debt levels", "Market conditions"}, 0.5
+// calculateConfidence is the weight-averaged confidence of the dimensions,
+// with a warning for each reason the assessment is low-confidence
+func (s *RiskAssessmentService) calculateConfidence(dimensions map[string]models.RiskDimension) (float64, []string) {
+	var weighted, totalWeight float64
+	var warnings []string
+
+	names := make([]string, 0, len(dimensions))
+	for name := range dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dim := dimensions[name]
+		weighted += dim.Confidence * dim.Weight
+		totalWeight += dim.Weight
+		switch {
+		case dim.Degraded:
+			warnings = append(warnings, fmt.Sprintf("%s: no provider answered within the %s budget, fallback score used", name, s.budget))
+		case dim.ProviderFailed:
+			warnings = append(warnings, fmt.Sprintf("%s: provider data unavailable, fallback score used (%s)", name, dim.ProviderError))
+		case dim.Synthetic:
+			warnings = append(warnings, fmt.Sprintf("%s: scored from synthetic fixture data", name))
+		}
 	}
-
-	// Calculate financial risk score
-	creditRisk := (800 - data.CreditScore) / 500.0 // Lower credit score = higher risk
-	debtRisk := data.DebtToEquity / 2.0 // High debt-to-equity = higher risk
-	volatilityRisk := data.MarketVolatility / 100.0
-	growthBonus := data.RevenueGrowth // Positive growth reduces risk
-
-	score := (creditRisk*0.3 + debtRisk*0.3 + volatilityRisk*0.2 - growthBonus*0.2)
-	score = math.Max(0.0, math.Min(1.0, score))
-
-	trend := "stable"
-	if data.RevenueGrowth < -0.1 {
-		trend = "increasing"
-	} else if data.RevenueGrowth > 0.1 {
-		trend = "decreasing"
+	if totalWeight == 0 {
+		return 0, warnings
 	}
 
-	factors := []string{"Credit rating", "Debt-to-equity ratio", "Market volatility"}
-	if data.CreditScore < 600 {
-		factors = append(factors, "Poor credit rating")
-	}
-	if data.DebtToEquity > 1.5 {
-		factors = append(factors, "High leverage")
+	confidence := weighted / totalWeight
+	if confidence < models.LowConfidenceThreshold {
+		warnings = append(warnings, fmt.Sprintf("overall confidence %.2f is below %.2f", confidence, models.LowConfidenceThreshold))
 	}
-
-	return score, trend, factors, data.DataQuality
+	return confidence, warnings
 }
 
-func (s *RiskAssessmentService) calculateReputationalRisk(ctx context.Context, entityID string) (float64, string, []string, float64) {
-	data, err := s.dataProvider.GetReputationalData(entityID, ctx)
-	if err != nil {
-		// Fallback to mock data
-		return 0.40, "stable", []string{"Public perception", "Media coverage", "Customer feedback"}, 0.5
+// getRiskFactors explains every assessed dimension that had provider data,
+// largest impact on the overall score first
+func (s *RiskAssessmentService) getRiskFactors(entityID string, results map[string]dimensionResult, totalWeight float64) []models.RiskFactor {
+	factors := make([]models.RiskFactor, 0)
+	if totalWeight == 0 {
+		return factors
 	}
 
-	// Calculate reputational risk score
-	sentimentRisk := (1.0 - data.SocialMediaSentiment) / 2.0 // Negative sentiment = higher risk
-	reviewRisk := (5.0 - data.CustomerReviews) / 5.0 // Lower reviews = higher risk
-	coverageRisk := data.NewsCoverage / 100.0 // High negative coverage = higher risk
-
-	score := (sentimentRisk*0.4 + reviewRisk*0.3 + coverageRisk*0.3)
-	score = math.Max(0.0, math.Min(1.0, score))
-
-	trend := "stable"
-	if data.SocialMediaSentiment < -0.2 {
-		trend = "increasing"
-	} else if data.SocialMediaSentiment > 0.2 {
-		trend = "decreasing"
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
 	}
+	sort.Strings(names)
 
-	factors := []string{"Social media sentiment", "Customer reviews", "Media coverage"}
-	if data.SocialMediaSentiment < 0 {
-		factors = append(factors, "Negative social media sentiment")
-	}
-	if data.CustomerReviews < 3.5 {
-		factors = append(factors, "Poor customer satisfaction")
+	for _, name := range names {
+		result := results[name]
+		if result.Snapshot == nil {
+			continue
+		}
+		share := result.Dimension.Weight / totalWeight
+		factors = append(factors, ExplainDimension(entityID, name, share, result.Score, result.Snapshot.Source, result.Snapshot.ObservedAt)...)
 	}
-
-	return score, trend, factors, data.DataQuality
+	RankFactors(factors)
+	return factors
 }
 
-func (s *RiskAssessmentService) calculateGeopoliticalRisk(ctx context.Context, entityID string) (float64, string, []string, float64) {
-	data, err := s.dataProvider.GetGeopoliticalData(entityID, ctx)
-	if err != nil {
-		// Fallback to mock data
-		return 0.60, "stable", []string{"Political stability", "Trade relations", "Regional conflicts"}, 0.5
-	}
-
-	// Calculate geopolitical risk score
-	countryRisk := data.CountryRisk / 100.0
-	stabilityRisk := (10.0 - data.PoliticalStability) / 10.0 // Lower stability = higher risk
-	sanctionsRisk := data.SanctionsExposure / 100.0
-	conflictRisk := float64(len(data.ConflictZones)) / 5.0 // More conflict zones = higher risk
-
-	score := (countryRisk*0.3 + stabilityRisk*0.25 + sanctionsRisk*0.25 + conflictRisk*0.2)
-	score = math.Max(0.0, math.Min(1.0, score))
-
-	trend := "stable"
-	if data.PoliticalStability < 5.0 {
-		trend = "increasing"
-	} else if data.PoliticalStability > 8.0 {
-		trend = "decreasing"
-	}
-
-	factors := []string{"Country risk rating", "Political stability", "Sanctions exposure"}
-	if len(data.ConflictZones) > 0 {
-		factors = append(factors, "Regional conflict exposure")
-	}
-	if data.SanctionsExposure > 30 {
-		factors = append(factors, "High sanctions risk")
-	}
+// ProviderHealth reports how each data provider has been responding
+func (s *RiskAssessmentService) ProviderHealth() []providers.ProviderHealth {
+	return s.providers.Health()
+}
 
-	return score, trend, factors, data.DataQuality
+func (s *RiskAssessmentService) GetRiskAssessment(id string) (*models.RiskAssessment, error) {
+	return s.repo.GetByID(id)
 }
 
-func (s *RiskAssessmentService) calculateComplianceRisk(ctx context.Context, entityID string) (float64, string, []string, float64) {
-	data, err := s.dataProvider.GetComplianceData(entityID, ctx)
-	if err != nil {
-		// Fallback to mock data
-		return 0.35, "stable", []string{"Regulatory compliance", "Audit findings", "Legal actions"}, 0.5
+// GetRiskTrends analyses an entity's assessments over period, overall and
+// per dimension, or for one dimension only
+func (s *RiskAssessmentService) GetRiskTrends(entityID, dimension, period string, opts models.TrendOptions) (*models.RiskTrends, error) {
+	if entityID == "" {
+		return nil, fmt.Errorf("%w: entity_id is required", ErrInvalidTrendQuery)
 	}
-
-	// Calculate compliance risk score
-	finesRisk := math.Min(1.0, data.RegulatoryFines / 1000000.0) // Cap at $1M
-	complianceRisk := (100.0 - data.ComplianceScore) / 100.0 // Lower compliance score = higher risk
-	auditRisk := float64(data.AuditFindings) / 20.0 // More findings = higher risk
-	privacyRisk := data.DataPrivacyRisk / 100.0
-	legalRisk := float64(data.LegalActions) / 10.0 // More legal actions = higher risk
-
-	score := (finesRisk*0.2 + complianceRisk*0.25 + auditRisk*0.2 + privacyRisk*0.2 + legalRisk*0.15)
-	score = math.Max(0.0, math.Min(1.0, score))
-
-	trend := "stable"
-	if data.AuditFindings > 5 {
-		trend = "increasing"
-	} else if data.ComplianceScore > 90 {
-		trend = "decreasing"
+	switch period {
+	case "":
+		period = "30d"
+	case "7d", "30d", "90d", "1y":
+	default:
+		return nil, fmt.Errorf("%w: period must be 7d, 30d, 90d or 1y", ErrInvalidTrendQuery)
 	}
-
-	factors := []string{"Regulatory fines", "Compliance score", "Audit findings"}
-	if data.RegulatoryFines > 100000 {
-		factors = append(factors, "Significant regulatory fines")
+	if dimension != "" {
+		if _, ok := models.DimensionIndicators[dimension]; !ok {
+			return nil, fmt.Errorf("%w: unknown dimension %q", ErrInvalidTrendQuery, dimension)
+		}
 	}
-	if data.AuditFindings > 3 {
-		factors = append(factors, "Multiple audit findings")
+	switch opts.Bucket {
+	case "":
+		opts.Bucket = models.TrendBucketDaily
+	case models.TrendBucketDaily, models.TrendBucketWeekly:
+	default:
+		return nil, fmt.Errorf("%w: bucket must be daily or weekly", ErrInvalidTrendQuery)
 	}
-
-	return score, trend, factors, data.DataQuality
-}
-
-func (s *RiskAssessmentService) calculateConfidence(entityID string, dimensions map[string]models.RiskDimension) float64 {
-	// Confidence based on number of dimensions assessed and data quality from external sources
-	dimensionCount := float64(len(dimensions))
-	if dimensionCount == 0 {
-		return 0.0
-	}
-	
-	// Base confidence increases with more dimensions
-	baseConfidence := 0.5 + (dimensionCount * 0.1)
-	
-	// Adjust confidence based on data quality from external sources
-	// In a real implementation, we'd track data quality per dimension
-	// For now, assume average data quality of 0.75 for external data
-	externalDataQuality := 0.75
-	
-	// Combine base confidence with external data quality
-	confidence := baseConfidence * externalDataQuality
-	
-	return math.Min(1.0, confidence)
-}
-
-func (s *RiskAssessmentService) getRiskFactors(entityID string, dimensions map[string]models.RiskDimension) []models.RiskFactor {
-	// TODO: Extract actual risk factors from data sources
-	factors := []models.RiskFactor{
-		{
-			ID:       uuid.New().String(),
-			Name:     "Market volatility",
-			Impact:   0.15,
-			Source:   "news-aggregator",
-			SourceID: "article-001",
-		},
-		{
-			ID:       uuid.New().String(),
-			Name:     "Regulatory changes",
-			Impact:   0.12,
-			Source:   "regulatory-feed",
-			SourceID: "reg-002",
-		},
+	if opts.ForecastDays < 0 || opts.ForecastDays > models.MaxForecastDays {
+		return nil, fmt.Errorf("%w: forecast_days must be between 0 and %d", ErrInvalidTrendQuery, models.MaxForecastDays)
 	}
-	return factors
-}
 
-func (s *RiskAssessmentService) checkAlerts(assessment *models.RiskAssessment) {
-	// Check all active alerts for this entity
-	alerts, err := s.repo.ListAlerts(true)
+	assessments, err := s.repo.GetTrends(entityID, dimension, period)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	for _, alert := range alerts {
-		if alert.EntityID != assessment.EntityID {
-			continue
-		}
-
-		var shouldTrigger bool
-		dimension, exists := assessment.Dimensions[alert.Dimension]
-		if !exists {
-			continue
-		}
-
-		switch alert.Condition {
-		case "above":
-			shouldTrigger = dimension.Score > alert.Threshold
-		case "below":
-			shouldTrigger = dimension.Score < alert.Threshold
-		case "equals":
-			shouldTrigger = math.Abs(dimension.Score-alert.Threshold) < 0.01
-		}
-
-		if shouldTrigger && !alert.Triggered {
-			// Trigger alert
-			now := time.Now()
-			alert.Triggered = true
-			alert.LastTrigger = &now
-			s.repo.UpdateAlert(alert.ID, alert)
-			// TODO: Send notification
+	now := time.Now()
+	overall, byDimension := trendSeries(assessments)
+	trends := &models.RiskTrends{
+		EntityID:   entityID,
+		Period:     period,
+		Bucket:     opts.Bucket,
+		From:       repository.TrendWindowStart(period, now),
+		To:         now,
+		Overall:    models.AnalyzeTrend(overall, opts),
+		Dimensions: make(map[string]*models.TrendAnalysis),
+	}
+	for name, points := range byDimension {
+		if dimension == "" || name == dimension {
+			trends.Dimensions[name] = models.AnalyzeTrend(points, opts)
 		}
 	}
+	return trends, nil
 }
 
-func (s *RiskAssessmentService) GetRiskAssessment(id string) (*models.RiskAssessment, error) {
-	return s.repo.GetByID(id)
-}
-
-func (s *RiskAssessmentService) GetRiskTrends(entityID, dimension, period string) ([]*models.RiskAssessment, error) {
-	return s.repo.GetTrends(entityID, dimension, period)
-}
-
-func (s *RiskAssessmentService) GetAssessmentsByEntity(entityID string, limit int) ([]*models.RiskAssessment, error) {
-	return s.repo.GetByEntityID(entityID, limit)
-}
-
-func (s *RiskAssessmentService) ConfigureAlert(config *models.AlertConfiguration) (*models.RiskAlert, error) {
-	alert := &models.RiskAlert{
-		ID:        uuid.New().String(),
-		EntityID:  config.EntityID,
-		Dimension: config.Dimension,
-		Threshold: config.Threshold,
-		Condition: config.Condition,
-		Active:    true,
-		Triggered: false,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
-	}
-
-	if err := s.repo.CreateAlert(alert); err != nil {
-		return nil, err
+// labelTrends sets each dimension's trend from the entity's assessments of
+// the last 30 days and the new score. Without history a dimension is
+// stable.
+func (s *RiskAssessmentService) labelTrends(entityID string, dimensions map[string]models.RiskDimension, now time.Time) {
+	// A failed lookup only costs the labels their history
+	history, _ := s.repo.GetTrends(entityID, "", "30d")
+	_, byDimension := trendSeries(history)
+	for name, dim := range dimensions {
+		points := append(byDimension[name], models.TrendPoint{Timestamp: now, Score: dim.Score})
+		dim.Trend = models.TrendDirection(points)
+		dimensions[name] = dim
 	}
-
-	return alert, nil
 }
 
-func (s *RiskAssessmentService) ListAlerts(activeOnly bool) ([]*models.RiskAlert, error) {
-	return s.repo.ListAlerts(activeOnly)
+// trendSeries splits assessments into the overall score series and one
+// series per dimension
+func trendSeries(assessments []*models.RiskAssessment) ([]models.TrendPoint, map[string][]models.TrendPoint) {
+	overall := make([]models.TrendPoint, 0, len(assessments))
+	byDimension := make(map[string][]models.TrendPoint)
+	for _, a := range assessments {
+		overall = append(overall, models.TrendPoint{Timestamp: a.Timestamp, Score: a.OverallScore})
+		for name, dim := range a.Dimensions {
+			byDimension[name] = append(byDimension[name], models.TrendPoint{Timestamp: a.Timestamp, Score: dim.Score})
+		}
+	}
+	return overall, byDimension
 }
 
-func (s *RiskAssessmentService) DeleteAlert(id string) error {
-	return s.repo.DeleteAlert(id)
+func (s *RiskAssessmentService) GetAssessmentsByEntity(entityID string, limit, offset int) ([]*models.RiskAssessment, int, error) {
+	return s.repo.GetByEntityID(entityID, limit, offset)
 }
@@ -0,0 +1,232 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/providers"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+// hangingProvider never answers before its context ends
+type hangingProvider struct{}
+
+func (hangingProvider) Name() string { return "hanging" }
+
+func (hangingProvider) Fetch(ctx context.Context, dimension, entityID string) (*providers.Record, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// valuesProvider answers with fixed indicator values
+type valuesProvider struct {
+	name   string
+	values map[string]float64
+}
+
+func (p valuesProvider) Name() string { return p.name }
+
+func (p valuesProvider) Fetch(ctx context.Context, dimension, entityID string) (*providers.Record, error) {
+	return &providers.Record{Values: p.values, ObservedAt: time.Now(), Quality: 1}, nil
+}
+
+func newTestService(t *testing.T, budget time.Duration) *RiskAssessmentService {
+	t.Helper()
+	modelRepo := repository.NewScoringModelRepository()
+	require.NoError(t, NewScoringModelService(modelRepo).EnsureDefaultModel())
+
+	registry := providers.NewRegistry(0)
+	registry.Register(models.DimensionFinancial, providers.NewFixtureProvider(), providers.Options{})
+	registry.Register(models.DimensionCompliance, hangingProvider{}, providers.Options{})
+	return NewRiskAssessmentService(repository.NewRiskRepository(), modelRepo, registry, nil, budget)
+}
+
+func TestAssessRiskDegradesDimensionsPastBudget(t *testing.T) {
+	service := newTestService(t, 50*time.Millisecond)
+
+	start := time.Now()
+	assessment, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial, models.DimensionCompliance},
+	})
+	require.NoError(t, err)
+
+	assert.Less(t, time.Since(start), time.Second)
+	assert.True(t, assessment.Degraded)
+	assert.False(t, assessment.Dimensions[models.DimensionFinancial].Degraded)
+	assert.True(t, assessment.Dimensions[models.DimensionCompliance].Degraded)
+	assert.Contains(t, assessment.Warnings, "compliance: no provider answered within the 50ms budget, fallback score used")
+}
+
+func TestAssessRiskFlagsSyntheticData(t *testing.T) {
+	service := newTestService(t, time.Second)
+
+	assessment, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+	})
+	require.NoError(t, err)
+
+	assert.True(t, assessment.Dimensions[models.DimensionFinancial].Synthetic)
+	assert.Contains(t, assessment.Warnings, "financial: scored from synthetic fixture data")
+	assert.Less(t, assessment.Confidence, models.LowConfidenceThreshold)
+}
+
+func TestAssessRiskLowersConfidenceWhenProvidersDisagree(t *testing.T) {
+	healthy := map[string]float64{"credit_score": 800, "debt_to_equity": 0.3, "revenue_growth": 0.15, "profit_margin": 0.2, "market_volatility": 0.1}
+	distressed := map[string]float64{"credit_score": 420, "debt_to_equity": 3.5, "revenue_growth": -0.2, "profit_margin": -0.1, "market_volatility": 0.8}
+
+	assess := func(second map[string]float64) models.RiskDimension {
+		modelRepo := repository.NewScoringModelRepository()
+		require.NoError(t, NewScoringModelService(modelRepo).EnsureDefaultModel())
+		registry := providers.NewRegistry(0)
+		registry.Register(models.DimensionFinancial, valuesProvider{name: "primary", values: healthy}, providers.Options{})
+		registry.Register(models.DimensionFinancial, valuesProvider{name: "secondary", values: second}, providers.Options{})
+		service := NewRiskAssessmentService(repository.NewRiskRepository(), modelRepo, registry, nil, time.Second)
+
+		assessment, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+			EntityID:   "acme",
+			EntityType: "organization",
+			Dimensions: []string{models.DimensionFinancial},
+		})
+		require.NoError(t, err)
+		return assessment.Dimensions[models.DimensionFinancial]
+	}
+
+	agreeing := assess(healthy)
+	disagreeing := assess(distressed)
+
+	assert.Equal(t, 1.0, agreeing.ConfidenceBreakdown.Agreement)
+	assert.Less(t, disagreeing.ConfidenceBreakdown.Agreement, 1.0)
+	assert.Less(t, disagreeing.Confidence, agreeing.Confidence)
+	assert.Equal(t, agreeing.Score, disagreeing.Score, "the first provider that answers stays the source")
+}
+
+func TestAssessRiskAbandonsCancelledRequests(t *testing.T) {
+	service := newTestService(t, time.Minute)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	_, err := service.AssessRisk(ctx, &models.AssessRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionCompliance},
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	_, total, err := service.GetAssessmentsByEntity("acme", 0, 0)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
+
+func TestGetRiskTrendsAnalysesStoredAssessments(t *testing.T) {
+	service := newTestService(t, time.Second)
+	for i := 0; i < 3; i++ {
+		_, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+			EntityID:   "acme",
+			EntityType: "organization",
+			Dimensions: []string{models.DimensionFinancial},
+		})
+		require.NoError(t, err)
+	}
+
+	trends, err := service.GetRiskTrends("acme", "", "", models.TrendOptions{ForecastDays: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "30d", trends.Period)
+	assert.Equal(t, models.TrendBucketDaily, trends.Bucket)
+	assert.Equal(t, 3, trends.Overall.Points)
+	assert.Equal(t, "stable", trends.Overall.Direction)
+	require.Contains(t, trends.Dimensions, models.DimensionFinancial)
+	assert.Len(t, trends.Dimensions[models.DimensionFinancial].Forecast, 3)
+
+	_, err = service.GetRiskTrends("acme", "", "", models.TrendOptions{Bucket: "hourly"})
+	assert.ErrorIs(t, err, ErrInvalidTrendQuery)
+	_, err = service.GetRiskTrends("acme", "", "", models.TrendOptions{ForecastDays: models.MaxForecastDays + 1})
+	assert.ErrorIs(t, err, ErrInvalidTrendQuery)
+	_, err = service.GetRiskTrends("", "", "", models.TrendOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTrendQuery)
+	_, err = service.GetRiskTrends("acme", "", "6m", models.TrendOptions{})
+	assert.ErrorIs(t, err, ErrInvalidTrendQuery)
+
+	trends, err = service.GetRiskTrends("acme", "", "1y", models.TrendOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "1y", trends.Period)
+}
+
+func TestSimulateComparesWithoutStoring(t *testing.T) {
+	service := newTestService(t, time.Second)
+	current, err := service.AssessRisk(context.Background(), &models.AssessRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+	})
+	require.NoError(t, err)
+
+	simulation, err := service.Simulate(context.Background(), &models.SimulateRiskRequest{
+		EntityID:       "acme",
+		EntityType:     "organization",
+		Dimensions:     []string{models.DimensionFinancial},
+		Overrides:      models.IndicatorOverrides{models.DimensionFinancial: {"credit_score": 300, "debt_to_equity": 2}},
+		IncludeFactors: true,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, current.ID, simulation.Latest.ID)
+	assert.Empty(t, simulation.Baseline.ID)
+	assert.Empty(t, simulation.Simulated.ID)
+	delta := simulation.Deltas[models.DimensionFinancial]
+	assert.Greater(t, delta.Delta, 0.0)
+	assert.Equal(t, []string{"credit_score", "debt_to_equity"}, delta.Overridden)
+	for _, factor := range simulation.Simulated.Factors {
+		if factor.Indicator == "credit_score" {
+			assert.Contains(t, factor.Flags, "overridden")
+			assert.Equal(t, 300.0, factor.Value)
+		}
+	}
+
+	_, total, err := service.GetAssessmentsByEntity("acme", 0, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+
+	_, err = service.Simulate(context.Background(), &models.SimulateRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+		Overrides:  models.IndicatorOverrides{models.DimensionGeopolitical: {"country_risk": 90}},
+	})
+	assert.ErrorIs(t, err, ErrInvalidSimulation)
+}
+
+func TestSimulateIgnoresStaleStoredAssessment(t *testing.T) {
+	service := newTestService(t, time.Second)
+	stale := &models.RiskAssessment{
+		EntityID:     "acme",
+		EntityType:   "organization",
+		OverallScore: 0.95,
+		Dimensions:   map[string]models.RiskDimension{models.DimensionFinancial: {Name: models.DimensionFinancial, Score: 0.95}},
+		Timestamp:    time.Now().Add(-30 * 24 * time.Hour),
+	}
+	require.NoError(t, service.repo.Create(stale))
+
+	fixture, err := providers.NewFixtureProvider().Fetch(context.Background(), models.DimensionFinancial, "acme")
+	require.NoError(t, err)
+	simulation, err := service.Simulate(context.Background(), &models.SimulateRiskRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+		Overrides:  models.IndicatorOverrides{models.DimensionFinancial: {"credit_score": fixture.Values["credit_score"]}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, stale.ID, simulation.Latest.ID)
+	assert.Equal(t, simulation.Simulated.ModelVersion, simulation.Baseline.ModelVersion)
+	assert.Equal(t, 0.0, simulation.OverallDelta, "an override equal to the provider value changes nothing")
+	assert.Equal(t, 0.0, simulation.Deltas[models.DimensionFinancial].Delta)
+}
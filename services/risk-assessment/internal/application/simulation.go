@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// ErrInvalidSimulation is returned for a simulation request whose
+// overrides cannot be applied
+var ErrInvalidSimulation = errors.New("invalid simulation")
+
+// overriddenFlag marks risk factors whose value came from an override
+const overriddenFlag = "overridden"
+
+// Simulate scores an entity as if the overridden indicators had the given
+// values and compares the result with a baseline scored at the same time,
+// by the same model, without the overrides, so the deltas show only what
+// the overrides changed. Nothing is stored, no alerts are evaluated and no
+// events are published.
+func (s *RiskAssessmentService) Simulate(ctx context.Context, req *models.SimulateRiskRequest) (*models.RiskSimulation, error) {
+	if problems := req.Overrides.Validate(); len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSimulation, strings.Join(problems, "; "))
+	}
+	// An override of a dimension outside the request would have no effect
+	if len(req.Dimensions) > 0 {
+		for dimension := range req.Overrides {
+			if !slices.Contains(req.Dimensions, dimension) {
+				return nil, fmt.Errorf("%w: %s is overridden but not simulated", ErrInvalidSimulation, dimension)
+			}
+		}
+	}
+
+	model, err := ActiveModel(s.models, req.EntityType)
+	if err != nil {
+		return nil, err
+	}
+	simulated, err := s.scoreWith(ctx, model, req.EntityID, req.EntityType, req.Dimensions, req.IncludeFactors, req.Overrides)
+	if err != nil {
+		return nil, err
+	}
+	for i, factor := range simulated.Factors {
+		if _, ok := req.Overrides[factor.Dimension][factor.Indicator]; ok {
+			simulated.Factors[i].Flags = append(factor.Flags, overriddenFlag)
+		}
+	}
+	baseline, err := s.scoreWith(ctx, model, req.EntityID, req.EntityType, req.Dimensions, req.IncludeFactors, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	simulation := &models.RiskSimulation{
+		EntityID:   req.EntityID,
+		EntityType: req.EntityType,
+		Overrides:  req.Overrides,
+		Baseline:   baseline,
+		Simulated:  simulated,
+	}
+	latest, _, err := s.repo.GetByEntityID(req.EntityID, 1, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(latest) > 0 {
+		simulation.Latest = latest[0]
+	}
+
+	simulation.OverallDelta, simulation.Deltas = models.CompareAssessments(baseline, simulated, req.Overrides)
+	return simulation, nil
+}
@@ -0,0 +1,250 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/domain/events"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+// ErrInvalidWatch is returned for a watch that cannot be added
+var ErrInvalidWatch = errors.New("invalid watch")
+
+const (
+	// dueBatchSize bounds how many watches one scheduler tick assesses
+	dueBatchSize = 100
+	// maxJitter caps how far a run is pulled forward to spread load
+	maxJitter = 5 * time.Minute
+	// retryDelay is how long a failed run waits before it is tried again
+	retryDelay = 5 * time.Minute
+)
+
+// WatchlistService manages the entities reassessed by the Scheduler
+type WatchlistService struct {
+	watchlist repository.WatchlistRepository
+}
+
+func NewWatchlistService(watchlist repository.WatchlistRepository) *WatchlistService {
+	return &WatchlistService{watchlist: watchlist}
+}
+
+// Watch adds an entity to the watchlist; its first run is due immediately
+func (s *WatchlistService) Watch(req *models.WatchRequest, createdBy string) (*models.WatchedEntity, error) {
+	if _, err := models.ParseSchedule(req.Schedule); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidWatch, err)
+	}
+	for _, dimension := range req.Dimensions {
+		if _, ok := models.DimensionIndicators[dimension]; !ok {
+			return nil, fmt.Errorf("%w: unknown dimension %q", ErrInvalidWatch, dimension)
+		}
+	}
+	if req.ChangeThreshold < 0 {
+		return nil, fmt.Errorf("%w: change_threshold must not be negative", ErrInvalidWatch)
+	}
+	threshold := req.ChangeThreshold
+	if threshold == 0 {
+		threshold = models.DefaultChangeThreshold
+	}
+
+	now := time.Now()
+	watch := &models.WatchedEntity{
+		ID:              uuid.New().String(),
+		EntityID:        strings.TrimSpace(req.EntityID),
+		EntityType:      strings.TrimSpace(req.EntityType),
+		Dimensions:      req.Dimensions,
+		Schedule:        strings.TrimSpace(req.Schedule),
+		ChangeThreshold: threshold,
+		Active:          true,
+		NextRunAt:       now,
+		CreatedBy:       createdBy,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := s.watchlist.Create(watch); err != nil {
+		return nil, err
+	}
+	return watch, nil
+}
+
+func (s *WatchlistService) GetWatch(id string) (*models.WatchedEntity, error) {
+	return s.watchlist.GetByID(id)
+}
+
+func (s *WatchlistService) ListWatches(limit, offset int) ([]*models.WatchedEntity, int, error) {
+	return s.watchlist.List(limit, offset)
+}
+
+func (s *WatchlistService) Unwatch(id string) error {
+	return s.watchlist.Delete(id)
+}
+
+// Scheduler reassesses watched entities when they fall due. Only the
+// replica holding the leader lock runs them, so replicas never assess the
+// same entity twice.
+type Scheduler struct {
+	watchlist repository.WatchlistRepository
+	risk      *RiskAssessmentService
+	publisher EventPublisher
+	leader    repository.LeaderLock
+	logger    *zap.Logger
+
+	tick time.Duration
+	// margin is how long before an assessment expires it is refreshed
+	margin time.Duration
+	now    func() time.Time
+	jitter func(max time.Duration) time.Duration
+}
+
+func NewScheduler(watchlist repository.WatchlistRepository, risk *RiskAssessmentService, publisher EventPublisher, leader repository.LeaderLock, logger *zap.Logger, tick, margin time.Duration) *Scheduler {
+	return &Scheduler{
+		watchlist: watchlist,
+		risk:      risk,
+		publisher: publisher,
+		leader:    leader,
+		logger:    logger,
+		tick:      tick,
+		margin:    margin,
+		now:       time.Now,
+		jitter: func(max time.Duration) time.Duration {
+			if max <= 0 {
+				return 0
+			}
+			return time.Duration(rand.Int63n(int64(max)))
+		},
+	}
+}
+
+// Run checks for due watches every tick until ctx is cancelled
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+	for {
+		s.RunDue(ctx)
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RunDue reassesses the watches that are due, if this replica is leader
+func (s *Scheduler) RunDue(ctx context.Context) {
+	leader, err := s.leader.TryAcquire(ctx)
+	if err != nil {
+		s.logger.Warn("Failed to check scheduler leadership", zap.Error(err))
+		return
+	}
+	if !leader {
+		return
+	}
+
+	due, err := s.watchlist.Due(s.now(), dueBatchSize)
+	if err != nil {
+		s.logger.Error("Failed to load due watches", zap.Error(err))
+		return
+	}
+	for _, watch := range due {
+		if ctx.Err() != nil {
+			return
+		}
+		s.reassess(ctx, watch)
+	}
+}
+
+func (s *Scheduler) reassess(ctx context.Context, watch *models.WatchedEntity) {
+	assessment, err := s.risk.AssessRisk(ctx, &models.AssessRiskRequest{
+		EntityID:   watch.EntityID,
+		EntityType: watch.EntityType,
+		Dimensions: watch.Dimensions,
+	})
+	// Shutting down: the watch stays due and runs on the next leader
+	if ctx.Err() != nil {
+		return
+	}
+
+	ranAt := s.now()
+	run := *watch
+	run.LastRunAt = &ranAt
+	run.UpdatedAt = ranAt
+	if err != nil {
+		s.logger.Warn("Scheduled reassessment failed", zap.String("entity_id", watch.EntityID), zap.Error(err))
+		run.LastError = err.Error()
+		run.NextRunAt = ranAt.Add(retryDelay)
+	} else {
+		run.LastError = ""
+		run.NextRunAt = s.planNextRun(watch, ranAt, assessment.ValidUntil)
+		run.LastAssessmentID = assessment.ID
+		run.LastScore = &assessment.OverallScore
+		run.LastDimensionScores = make(map[string]float64, len(assessment.Dimensions))
+		for name, dim := range assessment.Dimensions {
+			run.LastDimensionScores[name] = dim.Score
+		}
+		if watch.ScoreMoved(assessment) {
+			s.publishChange(ctx, watch, assessment)
+		}
+	}
+
+	if err := s.watchlist.RecordRun(&run); err != nil && !errors.Is(err, repository.ErrNotFound) {
+		s.logger.Error("Failed to record scheduled reassessment", zap.String("watch_id", watch.ID), zap.Error(err))
+	}
+}
+
+// planNextRun pulls the next run forward by a random jitter of up to a
+// tenth of the gap, so watches added together do not stay in lockstep
+func (s *Scheduler) planNextRun(watch *models.WatchedEntity, ranAt, validUntil time.Time) time.Time {
+	schedule, err := models.ParseSchedule(watch.Schedule)
+	if err != nil {
+		// Validated on creation; only a schedule edited in the store lands here
+		s.logger.Warn("Invalid watch schedule", zap.String("watch_id", watch.ID), zap.Error(err))
+		return ranAt.Add(retryDelay)
+	}
+	next := models.PlanNextRun(schedule, ranAt, validUntil, s.margin)
+	if next.IsZero() {
+		// The cron expression never fires again; keep the watch but park it
+		return ranAt.AddDate(1, 0, 0)
+	}
+
+	spread := next.Sub(ranAt) / 10
+	if spread > maxJitter {
+		spread = maxJitter
+	}
+	return next.Add(-s.jitter(spread))
+}
+
+func (s *Scheduler) publishChange(ctx context.Context, watch *models.WatchedEntity, assessment *models.RiskAssessment) {
+	if s.publisher == nil {
+		return
+	}
+	dimensions := make(map[string]float64, len(assessment.Dimensions))
+	for name, dim := range assessment.Dimensions {
+		dimensions[name] = dim.Score
+	}
+	event := events.RiskAssessed{
+		BaseEvent: events.BaseEvent{
+			ID:          uuid.New().String(),
+			Type:        events.TopicRiskAssessed,
+			AggregateId: assessment.ID,
+			Timestamp:   assessment.Timestamp,
+			Version:     1,
+		},
+		EntityID:      assessment.EntityID,
+		EntityType:    assessment.EntityType,
+		OverallScore:  assessment.OverallScore,
+		Confidence:    assessment.Confidence,
+		Dimensions:    dimensions,
+		PreviousScore: watch.LastScore,
+	}
+	if err := s.publisher.Publish(ctx, event); err != nil {
+		s.logger.Warn("Failed to publish risk change", zap.String("entity_id", watch.EntityID), zap.Error(err))
+	}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/messaging"
+	"atlas-core-api/services/risk-assessment/internal/infrastructure/repository"
+)
+
+type heldLock bool
+
+func (l heldLock) TryAcquire(ctx context.Context) (bool, error) { return bool(l), nil }
+func (l heldLock) Release() error                               { return nil }
+
+func TestSchedulerPublishesOnlyMeaningfulChanges(t *testing.T) {
+	watchlist := repository.NewWatchlistRepository()
+	publisher := messaging.NewInMemoryEventPublisher(zap.NewNop())
+	scheduler := NewScheduler(watchlist, newTestService(t, time.Second), publisher, heldLock(true), zap.NewNop(), time.Minute, time.Hour)
+	scheduler.jitter = func(time.Duration) time.Duration { return 0 }
+
+	watch, err := NewWatchlistService(watchlist).Watch(&models.WatchRequest{
+		EntityID:   "acme",
+		EntityType: "organization",
+		Dimensions: []string{models.DimensionFinancial},
+		Schedule:   "6h",
+	}, "analyst-1")
+	require.NoError(t, err)
+	assert.Equal(t, models.DefaultChangeThreshold, watch.ChangeThreshold)
+
+	scheduler.RunDue(context.Background())
+	watch, err = watchlist.GetByID(watch.ID)
+	require.NoError(t, err)
+	require.NotNil(t, watch.LastScore)
+	assert.NotEmpty(t, watch.LastAssessmentID)
+	assert.WithinDuration(t, time.Now().Add(6*time.Hour), watch.NextRunAt, time.Minute)
+	assert.Len(t, publisher.Events(), 1)
+
+	// Not due yet
+	scheduler.RunDue(context.Background())
+	assert.Len(t, publisher.Events(), 1)
+
+	// Due again with the same fixture scores: reassessed but not reported
+	scheduler.now = func() time.Time { return time.Now().Add(7 * time.Hour) }
+	scheduler.RunDue(context.Background())
+	rerun, err := watchlist.GetByID(watch.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, watch.LastAssessmentID, rerun.LastAssessmentID)
+	assert.Len(t, publisher.Events(), 1)
+}
+
+func TestSchedulerSkipsWithoutLeadership(t *testing.T) {
+	watchlist := repository.NewWatchlistRepository()
+	publisher := messaging.NewInMemoryEventPublisher(zap.NewNop())
+	scheduler := NewScheduler(watchlist, newTestService(t, time.Second), publisher, heldLock(false), zap.NewNop(), time.Minute, time.Hour)
+
+	watch, err := NewWatchlistService(watchlist).Watch(&models.WatchRequest{
+		EntityID: "acme", EntityType: "organization", Schedule: "@daily",
+	}, "")
+	require.NoError(t, err)
+
+	scheduler.RunDue(context.Background())
+	watch, err = watchlist.GetByID(watch.ID)
+	require.NoError(t, err)
+	assert.Nil(t, watch.LastRunAt)
+	assert.Empty(t, publisher.Events())
+}
+
+func TestWatchRejectsDuplicatesAndBadSchedules(t *testing.T) {
+	watchlist := NewWatchlistService(repository.NewWatchlistRepository())
+	req := &models.WatchRequest{EntityID: "acme", EntityType: "organization", Schedule: "0 6 * * *"}
+
+	_, err := watchlist.Watch(req, "")
+	require.NoError(t, err)
+	_, err = watchlist.Watch(req, "")
+	assert.ErrorIs(t, err, repository.ErrConflict)
+
+	_, err = watchlist.Watch(&models.WatchRequest{EntityID: "globex", EntityType: "organization", Schedule: "every day"}, "")
+	assert.ErrorIs(t, err, ErrInvalidWatch)
+}
@@ -2,22 +2,268 @@ package models
 
 import "time"
 
+// Alert states. An armed alert fires when its threshold is breached; a
+// firing alert may be acknowledged, and is resolved by hand or once the
+// score clears the threshold by the hysteresis margin. A resolved alert
+// re-arms after its cooldown.
+const (
+	AlertStateArmed        = "armed"
+	AlertStateFiring       = "firing"
+	AlertStateAcknowledged = "acknowledged"
+	AlertStateResolved     = "resolved"
+)
+
+// Alert severities, from how far the score is past the threshold
+const (
+	SeverityLow      = "low"
+	SeverityMedium   = "medium"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+const (
+	// DefaultAlertHysteresis is how far back past the threshold a score
+	// must move before a firing alert resolves itself
+	DefaultAlertHysteresis = 0.05
+	// DefaultAlertCooldown is how long a resolved alert waits to re-arm
+	DefaultAlertCooldown = 15 * time.Minute
+	// equalsTolerance is how close a score must be to match ==
+	equalsTolerance = 0.01
+	// scoreEpsilon absorbs float error so a score exactly on the hysteresis
+	// boundary clears
+	scoreEpsilon = 1e-9
+	// SystemActor resolves alerts whose score has cleared
+	SystemActor = "system"
+)
+
 type RiskAlert struct {
-	ID          string    `json:"id"`
-	EntityID    string    `json:"entity_id"`
-	Dimension   string    `json:"dimension"`
-	Threshold   float64   `json:"threshold"`
-	Condition   string    `json:"condition"` // "above", "below", "equals"
-	Active      bool      `json:"active"`
-	Triggered   bool      `json:"triggered"`
-	LastTrigger *time.Time `json:"last_trigger"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID string `json:"id"`
+	// An alert watches one entity (EntityID), or every entity of a type or
+	// carrying a tag. A group rule never fires itself: each entity it
+	// matches gets its own alert, linked back by RuleID.
+	EntityID   string `json:"entity_id,omitempty"`
+	EntityType string `json:"entity_type,omitempty"`
+	Tag        string `json:"tag,omitempty"`
+	RuleID     string `json:"rule_id,omitempty"`
+	// Expression is a rule expression (see ParseRule); without one the
+	// alert compares Dimension with Threshold by Condition
+	Expression string  `json:"expression,omitempty"`
+	Dimension  string  `json:"dimension,omitempty"`
+	Threshold  float64 `json:"threshold"`
+	Condition  string  `json:"condition,omitempty"` // "above", "below", "equals"
+	// Hysteresis and CooldownSeconds keep a score hovering around the
+	// threshold from firing repeatedly
+	Hysteresis      float64 `json:"hysteresis"`
+	CooldownSeconds int     `json:"cooldown_seconds"`
+	Active          bool    `json:"active"`
+
+	State    string `json:"state"`
+	Severity string `json:"severity"`
+	// Triggered is set while the alert is firing or acknowledged
+	Triggered      bool       `json:"triggered"`
+	LastTrigger    *time.Time `json:"last_trigger"`
+	TriggeredScore *float64   `json:"triggered_score,omitempty"`
+	AcknowledgedBy string     `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time `json:"acknowledged_at,omitempty"`
+	ResolvedBy     string     `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	Resolution     string     `json:"resolution,omitempty"`
+
+	// CreatedBy is notified when the alert fires or resolves
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// AlertConfiguration creates an alert rule. It watches EntityID, or every
+// entity matching EntityType and Tag, and needs either an Expression or a
+// Dimension, Condition and Threshold.
 type AlertConfiguration struct {
-	EntityID  string  `json:"entity_id" binding:"required"`
-	Dimension string  `json:"dimension" binding:"required"`
-	Threshold float64 `json:"threshold" binding:"required"`
-	Condition string  `json:"condition" binding:"required"` // "above", "below"
+	EntityID   string   `json:"entity_id"`
+	EntityType string   `json:"entity_type"`
+	Tag        string   `json:"tag"`
+	Expression string   `json:"expression"`
+	Dimension  string   `json:"dimension"`
+	Threshold  *float64 `json:"threshold"`
+	Condition  string   `json:"condition" binding:"omitempty,oneof=above below equals"`
+	// Hysteresis and CooldownSeconds default to DefaultAlertHysteresis and
+	// DefaultAlertCooldown when omitted
+	Hysteresis      *float64 `json:"hysteresis"`
+	CooldownSeconds *int     `json:"cooldown_seconds"`
+}
+
+// AlertRuleTest replays an alert rule over an entity's stored assessments
+// from the last Period ("7d", "30d", "90d" or "1y")
+type AlertRuleTest struct {
+	AlertConfiguration
+	Period string `json:"period"`
+}
+
+// AlertRuleTestResult is the alert's lifecycle over the replayed
+// assessments, oldest first
+type AlertRuleTestResult struct {
+	EntityID    string                `json:"entity_id"`
+	Period      string                `json:"period"`
+	Evaluations []AlertRuleEvaluation `json:"evaluations"`
+	// Matched counts the assessments the rule matched; Fired counts how
+	// often the alert would have fired
+	Matched int `json:"matched"`
+	Fired   int `json:"fired"`
+}
+
+// AlertRuleEvaluation is the rule's outcome for one stored assessment
+type AlertRuleEvaluation struct {
+	AssessmentID string    `json:"assessment_id"`
+	Timestamp    time.Time `json:"timestamp"`
+	OverallScore float64   `json:"overall_score"`
+	Matched      bool      `json:"matched"`
+	Fired        bool      `json:"fired"`
+	// State is the alert's state after the assessment
+	State    string `json:"state"`
+	Severity string `json:"severity,omitempty"`
+}
+
+// AlertTransition is what an evaluation did to an alert
+type AlertTransition int
+
+const (
+	AlertUnchanged AlertTransition = iota
+	AlertFired
+	AlertCleared
+)
+
+// Cooldown is how long the alert waits after resolving before it re-arms
+func (a *RiskAlert) Cooldown() time.Duration {
+	return time.Duration(a.CooldownSeconds) * time.Second
+}
+
+// IsGroupRule reports whether the alert covers a group of entities
+func (a *RiskAlert) IsGroupRule() bool {
+	return a.EntityID == "" && a.RuleID == ""
+}
+
+// Covers reports whether a group rule applies to an assessed entity
+func (a *RiskAlert) Covers(assessment *RiskAssessment) bool {
+	if a.EntityType != "" && a.EntityType != assessment.EntityType {
+		return false
+	}
+	if a.Tag == "" {
+		return true
+	}
+	for _, tag := range assessment.Tags {
+		if tag == a.Tag {
+			return true
+		}
+	}
+	return false
+}
+
+// ForEntity returns the alert a group rule raises for one entity
+func (a *RiskAlert) ForEntity(id, entityID string, now time.Time) *RiskAlert {
+	instance := *a
+	instance.ID = id
+	instance.EntityID = entityID
+	instance.RuleID = a.ID
+	instance.State = AlertStateArmed
+	instance.CreatedAt = now
+	instance.UpdatedAt = now
+	return &instance
+}
+
+// Rule returns the alert's condition as a rule
+func (a *RiskAlert) Rule() (*Rule, error) {
+	if a.Expression != "" {
+		return ParseRule(a.Expression)
+	}
+	return NewThresholdRule(a.Dimension, a.Condition, a.Threshold)
+}
+
+// SeverityFor grades a breach by how far the scores are past their
+// thresholds
+func SeverityFor(magnitude float64) string {
+	switch {
+	case magnitude >= 0.3:
+		return SeverityCritical
+	case magnitude >= 0.15:
+		return SeverityHigh
+	case magnitude >= 0.05:
+		return SeverityMedium
+	default:
+		return SeverityLow
+	}
+}
+
+// Evaluate moves the alert through its lifecycle for new scores. A
+// resolved alert re-arms once its cooldown has passed; an armed alert
+// fires when its rule matches; a firing or acknowledged alert resolves
+// itself when the rule no longer matches with the hysteresis margin. The
+// result is the rule's evaluation without margin.
+func (a *RiskAlert) Evaluate(rule *Rule, in RuleInput, now time.Time) (AlertTransition, RuleResult) {
+	if a.State == AlertStateResolved && a.ResolvedAt != nil && now.Sub(*a.ResolvedAt) >= a.Cooldown() {
+		a.rearm(now)
+	}
+
+	result := rule.Evaluate(in, 0)
+	switch a.State {
+	case AlertStateArmed:
+		if !result.Matched {
+			return AlertUnchanged, result
+		}
+		a.State = AlertStateFiring
+		a.Triggered = true
+		a.Severity = SeverityFor(result.Magnitude)
+		a.LastTrigger = &now
+		score := in.Assessment.OverallScore
+		if dim, ok := in.Assessment.Dimensions[a.Dimension]; ok && a.Expression == "" {
+			score = dim.Score
+		}
+		a.TriggeredScore = &score
+		a.AcknowledgedBy, a.AcknowledgedAt = "", nil
+		a.ResolvedBy, a.ResolvedAt, a.Resolution = "", nil, ""
+		a.UpdatedAt = now
+		return AlertFired, result
+	case AlertStateFiring, AlertStateAcknowledged:
+		if rule.Evaluate(in, a.Hysteresis).Matched {
+			return AlertUnchanged, result
+		}
+		a.resolve(SystemActor, "score cleared the threshold", now)
+		return AlertCleared, result
+	default:
+		return AlertUnchanged, result
+	}
+}
+
+// Acknowledge records that someone is handling a firing alert
+func (a *RiskAlert) Acknowledge(by string, now time.Time) bool {
+	if a.State != AlertStateFiring {
+		return false
+	}
+	a.State = AlertStateAcknowledged
+	a.AcknowledgedBy = by
+	a.AcknowledgedAt = &now
+	a.UpdatedAt = now
+	return true
+}
+
+// Resolve closes a firing or acknowledged alert by hand
+func (a *RiskAlert) Resolve(by, resolution string, now time.Time) bool {
+	if a.State != AlertStateFiring && a.State != AlertStateAcknowledged {
+		return false
+	}
+	a.resolve(by, resolution, now)
+	return true
+}
+
+func (a *RiskAlert) resolve(by, resolution string, now time.Time) {
+	a.State = AlertStateResolved
+	a.Triggered = false
+	a.ResolvedBy = by
+	a.ResolvedAt = &now
+	a.Resolution = resolution
+	a.UpdatedAt = now
+}
+
+func (a *RiskAlert) rearm(now time.Time) {
+	a.State = AlertStateArmed
+	a.UpdatedAt = now
 }
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// evaluate scores the alert's rule against a financial score
+func evaluate(t *testing.T, alert *RiskAlert, score float64, now time.Time) AlertTransition {
+	rule, err := alert.Rule()
+	require.NoError(t, err)
+	in := RuleInput{Assessment: &RiskAssessment{
+		Timestamp:  now,
+		Dimensions: map[string]RiskDimension{DimensionFinancial: {Score: score}},
+	}}
+	transition, _ := alert.Evaluate(rule, in, now)
+	return transition
+}
+
+func TestRiskAlertLifecycle(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	alert := &RiskAlert{
+		Dimension:       DimensionFinancial,
+		Threshold:       0.6,
+		Condition:       "above",
+		Hysteresis:      0.05,
+		CooldownSeconds: 600,
+		State:           AlertStateArmed,
+	}
+
+	assert.Equal(t, AlertUnchanged, evaluate(t, alert, 0.55, now))
+	assert.Equal(t, AlertFired, evaluate(t, alert, 0.8, now))
+	assert.Equal(t, AlertStateFiring, alert.State)
+	assert.Equal(t, SeverityHigh, alert.Severity)
+	assert.True(t, alert.Triggered)
+
+	// Dipping under the threshold but inside the hysteresis band keeps it firing
+	assert.Equal(t, AlertUnchanged, evaluate(t, alert, 0.58, now.Add(time.Minute)))
+	assert.True(t, alert.Acknowledge("analyst-1", now.Add(2*time.Minute)))
+	assert.False(t, alert.Acknowledge("analyst-2", now.Add(3*time.Minute)))
+
+	assert.Equal(t, AlertCleared, evaluate(t, alert, 0.55, now.Add(4*time.Minute)))
+	assert.Equal(t, AlertStateResolved, alert.State)
+	assert.Equal(t, SystemActor, alert.ResolvedBy)
+	assert.False(t, alert.Triggered)
+
+	// Within the cooldown a new breach does not fire
+	assert.Equal(t, AlertUnchanged, evaluate(t, alert, 0.9, now.Add(10*time.Minute)))
+	assert.Equal(t, AlertStateResolved, alert.State)
+
+	// After it the alert re-arms and fires again
+	assert.Equal(t, AlertFired, evaluate(t, alert, 0.9, now.Add(15*time.Minute)))
+	assert.Equal(t, SeverityCritical, alert.Severity)
+	assert.Empty(t, alert.AcknowledgedBy)
+	assert.Nil(t, alert.ResolvedAt)
+
+	assert.True(t, alert.Resolve("analyst-1", "false positive", now.Add(16*time.Minute)))
+	assert.False(t, alert.Resolve("analyst-1", "again", now.Add(17*time.Minute)))
+	assert.Equal(t, "false positive", alert.Resolution)
+}
+
+func TestRiskAlertSeverityAndConditions(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	below := &RiskAlert{Dimension: DimensionFinancial, Threshold: 0.3, Condition: "below", Hysteresis: 0.05, State: AlertStateArmed}
+	assert.Equal(t, AlertFired, evaluate(t, below, 0.28, now))
+	assert.Equal(t, SeverityLow, below.Severity)
+	assert.Equal(t, AlertUnchanged, evaluate(t, below, 0.33, now))
+	assert.Equal(t, AlertCleared, evaluate(t, below, 0.35, now))
+	assert.Equal(t, SeverityMedium, SeverityFor(0.1))
+
+	equals := &RiskAlert{Dimension: DimensionFinancial, Threshold: 0.5, Condition: "equals", State: AlertStateArmed}
+	assert.Equal(t, AlertUnchanged, evaluate(t, equals, 0.52, now))
+	assert.Equal(t, AlertFired, evaluate(t, equals, 0.505, now))
+	assert.Equal(t, SeverityMedium, equals.Severity)
+}
+
+func TestGroupRuleCoversEntities(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rule := &RiskAlert{ID: "rule-1", EntityType: "organization", Tag: "tier-1", State: AlertStateArmed}
+	assert.True(t, rule.IsGroupRule())
+	assert.True(t, rule.Covers(&RiskAssessment{EntityType: "organization", Tags: []string{"eu", "tier-1"}}))
+	assert.False(t, rule.Covers(&RiskAssessment{EntityType: "organization", Tags: []string{"eu"}}))
+	assert.False(t, rule.Covers(&RiskAssessment{EntityType: "person", Tags: []string{"tier-1"}}))
+
+	instance := rule.ForEntity("alert-1", "acme", now)
+	assert.False(t, instance.IsGroupRule())
+	assert.Equal(t, "rule-1", instance.RuleID)
+	assert.Equal(t, "acme", instance.EntityID)
+}
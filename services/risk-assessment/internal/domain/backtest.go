@@ -0,0 +1,418 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Outcome types are the real events scores are meant to anticipate
+const (
+	OutcomeDefault          = "default"
+	OutcomeSanction         = "sanction"
+	OutcomeBreach           = "breach"
+	OutcomeSupplyDisruption = "supply_disruption"
+)
+
+// OutcomeTypes lists every outcome type that can be recorded
+var OutcomeTypes = []string{OutcomeDefault, OutcomeSanction, OutcomeBreach, OutcomeSupplyDisruption}
+
+// BacktestOverallDimension names the results for overall scores
+const BacktestOverallDimension = "overall"
+
+const (
+	BacktestStatusQueued    = "queued"
+	BacktestStatusRunning   = "running"
+	BacktestStatusCompleted = "completed"
+	BacktestStatusFailed    = "failed"
+)
+
+const (
+	// DefaultBacktestHorizonDays is how long after an assessment an outcome
+	// still counts as having been predicted by it
+	DefaultBacktestHorizonDays = 90
+	// DefaultCalibrationBins is how many equal-width score bins a
+	// calibration curve has
+	DefaultCalibrationBins = 10
+	MaxCalibrationBins     = 50
+	// MinComparisonSamples is how many samples a model version needs before
+	// a report will call it the best for a dimension
+	MinComparisonSamples = 30
+)
+
+// Outcome is a real event that happened to an entity
+type Outcome struct {
+	ID          string    `json:"id"`
+	EntityID    string    `json:"entity_id"`
+	EntityType  string    `json:"entity_type,omitempty"`
+	Type        string    `json:"type"`
+	OccurredAt  time.Time `json:"occurred_at"`
+	Description string    `json:"description,omitempty"`
+	Source      string    `json:"source,omitempty"`
+	RecordedBy  string    `json:"recorded_by,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RecordOutcomeRequest records an outcome
+type RecordOutcomeRequest struct {
+	EntityID    string    `json:"entity_id" binding:"required"`
+	EntityType  string    `json:"entity_type"`
+	Type        string    `json:"type" binding:"required"`
+	OccurredAt  time.Time `json:"occurred_at" binding:"required"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+}
+
+// OutcomeFilter selects outcomes; empty fields match everything
+type OutcomeFilter struct {
+	EntityID string
+	Types    []string
+	From     time.Time
+	To       time.Time
+	Limit    int
+	Offset   int
+}
+
+// IsOutcomeType reports whether t is a known outcome type
+func IsOutcomeType(t string) bool {
+	return contains(OutcomeTypes, t)
+}
+
+// Backtest replays stored assessments made between From and To against
+// the outcomes that followed them within the horizon
+type Backtest struct {
+	ID          string    `json:"id"`
+	Status      string    `json:"status"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+	HorizonDays int       `json:"horizon_days"`
+	// OutcomeTypes counted as positive; all types when empty
+	OutcomeTypes []string `json:"outcome_types,omitempty"`
+	Bins         int      `json:"bins"`
+
+	// Assessments is how many stored assessments were replayed; Censored
+	// of them were too recent for their horizon to have been observed
+	Assessments int              `json:"assessments"`
+	Censored    int              `json:"censored"`
+	Results     []BacktestResult `json:"results,omitempty"`
+	Error       string           `json:"error,omitempty"`
+
+	CreatedBy   string     `json:"created_by,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// BacktestRequest starts a backtest. To defaults to now and From to a year
+// before To.
+type BacktestRequest struct {
+	From         time.Time `json:"from"`
+	To           time.Time `json:"to"`
+	HorizonDays  int       `json:"horizon_days"`
+	OutcomeTypes []string  `json:"outcome_types"`
+	Bins         int       `json:"bins"`
+}
+
+// BacktestResult is how well one model version's scores for one dimension,
+// or BacktestOverallDimension, predicted outcomes
+type BacktestResult struct {
+	ModelID      string `json:"model_id,omitempty"`
+	ModelVersion int    `json:"model_version"`
+	Dimension    string `json:"dimension"`
+	Samples      int    `json:"samples"`
+	Positives    int    `json:"positives"`
+	// AUC is nil when the samples do not have both outcomes
+	AUC   *float64 `json:"auc"`
+	Brier float64  `json:"brier"`
+	// CalibrationError is the sample-weighted gap between predicted and
+	// observed rates across the calibration bins
+	CalibrationError float64          `json:"calibration_error"`
+	Calibration      []CalibrationBin `json:"calibration"`
+}
+
+// CalibrationBin compares the mean score in a score range with how often
+// an outcome followed
+type CalibrationBin struct {
+	Lower         float64 `json:"lower"`
+	Upper         float64 `json:"upper"`
+	Count         int     `json:"count"`
+	MeanPredicted float64 `json:"mean_predicted"`
+	ObservedRate  float64 `json:"observed_rate"`
+}
+
+// BacktestSample is one stored score and whether an outcome followed it
+type BacktestSample struct {
+	Score    float64
+	Observed bool
+}
+
+// AUC is the probability that a sample followed by an outcome scored
+// higher than one that was not, counting ties as half. ok is false when
+// the samples do not have both outcomes.
+func AUC(samples []BacktestSample) (auc float64, ok bool) {
+	sorted := append([]BacktestSample(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Score < sorted[j].Score })
+
+	// Mann-Whitney U from the rank sum of the positives, tied scores
+	// sharing their average rank
+	var positives, negatives int
+	var rankSum float64
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j].Score == sorted[i].Score {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for _, s := range sorted[i:j] {
+			if s.Observed {
+				positives++
+				rankSum += rank
+			} else {
+				negatives++
+			}
+		}
+		i = j
+	}
+	if positives == 0 || negatives == 0 {
+		return 0, false
+	}
+	u := rankSum - float64(positives*(positives+1))/2
+	return u / float64(positives*negatives), true
+}
+
+// BrierScore is the mean squared difference between score and outcome;
+// lower is better
+func BrierScore(samples []BacktestSample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		d := s.Score - observedValue(s)
+		sum += d * d
+	}
+	return sum / float64(len(samples))
+}
+
+// CalibrationCurve groups samples into equal-width score bins. Empty bins
+// are left out.
+func CalibrationCurve(samples []BacktestSample, bins int) []CalibrationBin {
+	if bins < 1 {
+		bins = DefaultCalibrationBins
+	}
+	counts := make([]int, bins)
+	predicted := make([]float64, bins)
+	observed := make([]float64, bins)
+	for _, s := range samples {
+		i := int(clamp01(s.Score) * float64(bins))
+		if i == bins {
+			i--
+		}
+		counts[i]++
+		predicted[i] += s.Score
+		observed[i] += observedValue(s)
+	}
+
+	curve := make([]CalibrationBin, 0, bins)
+	width := 1 / float64(bins)
+	for i, count := range counts {
+		if count == 0 {
+			continue
+		}
+		curve = append(curve, CalibrationBin{
+			Lower:         round4(float64(i) * width),
+			Upper:         round4(float64(i+1) * width),
+			Count:         count,
+			MeanPredicted: round4(predicted[i] / float64(count)),
+			ObservedRate:  round4(observed[i] / float64(count)),
+		})
+	}
+	return curve
+}
+
+// ExpectedCalibrationError weights each bin's gap between mean score and
+// observed rate by its share of the samples
+func ExpectedCalibrationError(curve []CalibrationBin) float64 {
+	var total int
+	var gap float64
+	for _, bin := range curve {
+		total += bin.Count
+		gap += float64(bin.Count) * math.Abs(bin.MeanPredicted-bin.ObservedRate)
+	}
+	if total == 0 {
+		return 0
+	}
+	return gap / float64(total)
+}
+
+// BacktestEvaluator labels stored assessments with the outcomes that
+// followed them and scores each model version per dimension. Assessments
+// can be added a page at a time.
+type BacktestEvaluator struct {
+	horizon       time.Duration
+	observedUntil time.Time
+	outcomes      map[string][]time.Time
+	samples       map[backtestKey][]BacktestSample
+
+	// Assessments is how many assessments were added; Censored of them
+	// had horizons ending after observedUntil and were skipped
+	Assessments int
+	Censored    int
+}
+
+type backtestKey struct {
+	modelID      string
+	modelVersion int
+	dimension    string
+}
+
+// NewBacktestEvaluator counts an assessment as followed by an outcome when
+// one of outcomes happened to its entity within horizon after it.
+// Assessments whose horizon ends after observedUntil cannot be labelled
+// yet and are censored.
+func NewBacktestEvaluator(outcomes []*Outcome, horizon time.Duration, observedUntil time.Time) *BacktestEvaluator {
+	byEntity := make(map[string][]time.Time)
+	for _, o := range outcomes {
+		byEntity[o.EntityID] = append(byEntity[o.EntityID], o.OccurredAt)
+	}
+	return &BacktestEvaluator{
+		horizon:       horizon,
+		observedUntil: observedUntil,
+		outcomes:      byEntity,
+		samples:       make(map[backtestKey][]BacktestSample),
+	}
+}
+
+// Add labels an assessment and records its overall and dimension scores.
+// Dimensions scored with the fallback because no provider answered say
+// nothing about the model and are skipped.
+func (e *BacktestEvaluator) Add(a *RiskAssessment) {
+	e.Assessments++
+	if a.Timestamp.Add(e.horizon).After(e.observedUntil) {
+		e.Censored++
+		return
+	}
+
+	observed := false
+	for _, at := range e.outcomes[a.EntityID] {
+		if at.After(a.Timestamp) && !at.After(a.Timestamp.Add(e.horizon)) {
+			observed = true
+			break
+		}
+	}
+
+	key := backtestKey{modelID: a.ModelID, modelVersion: a.ModelVersion, dimension: BacktestOverallDimension}
+	e.samples[key] = append(e.samples[key], BacktestSample{Score: a.OverallScore, Observed: observed})
+	for name, dim := range a.Dimensions {
+		if dim.ProviderFailed {
+			continue
+		}
+		key.dimension = name
+		e.samples[key] = append(e.samples[key], BacktestSample{Score: dim.Score, Observed: observed})
+	}
+}
+
+// Results scores every model version and dimension seen, ordered by
+// dimension and then model version
+func (e *BacktestEvaluator) Results(bins int) []BacktestResult {
+	results := make([]BacktestResult, 0, len(e.samples))
+	for key, samples := range e.samples {
+		result := BacktestResult{
+			ModelID:      key.modelID,
+			ModelVersion: key.modelVersion,
+			Dimension:    key.dimension,
+			Samples:      len(samples),
+			Brier:        round4(BrierScore(samples)),
+			Calibration:  CalibrationCurve(samples, bins),
+		}
+		for _, s := range samples {
+			if s.Observed {
+				result.Positives++
+			}
+		}
+		if auc, ok := AUC(samples); ok {
+			auc = round4(auc)
+			result.AUC = &auc
+		}
+		result.CalibrationError = round4(ExpectedCalibrationError(result.Calibration))
+		results = append(results, result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Dimension != b.Dimension {
+			return a.Dimension < b.Dimension
+		}
+		if a.ModelVersion != b.ModelVersion {
+			return a.ModelVersion < b.ModelVersion
+		}
+		return a.ModelID < b.ModelID
+	})
+	return results
+}
+
+// BacktestReport compares model versions dimension by dimension
+type BacktestReport struct {
+	BacktestID  string            `json:"backtest_id"`
+	From        time.Time         `json:"from"`
+	To          time.Time         `json:"to"`
+	HorizonDays int               `json:"horizon_days"`
+	Dimensions  []ModelComparison `json:"dimensions"`
+}
+
+// ModelComparison ranks the model versions that scored one dimension. Each
+// version is measured on the assessments it made, so versions active at
+// different times were tested against different periods.
+type ModelComparison struct {
+	Dimension string           `json:"dimension"`
+	Versions  []BacktestResult `json:"versions"`
+	// BestByAUC and BestByBrier point into Versions; they are nil when no
+	// version has MinComparisonSamples samples and, for AUC, both outcomes
+	BestByAUC   *int `json:"best_by_auc"`
+	BestByBrier *int `json:"best_by_brier"`
+}
+
+// CompareModelVersions groups backtest results by dimension and picks the
+// best version of each by AUC (higher) and Brier score (lower)
+func CompareModelVersions(results []BacktestResult) []ModelComparison {
+	byDimension := make(map[string][]BacktestResult)
+	for _, r := range results {
+		byDimension[r.Dimension] = append(byDimension[r.Dimension], r)
+	}
+
+	comparisons := make([]ModelComparison, 0, len(byDimension))
+	for dimension, versions := range byDimension {
+		sort.SliceStable(versions, func(i, j int) bool { return versions[i].ModelVersion < versions[j].ModelVersion })
+		comparison := ModelComparison{Dimension: dimension, Versions: versions}
+		for i, v := range versions {
+			if v.Samples < MinComparisonSamples {
+				continue
+			}
+			if v.AUC != nil && (comparison.BestByAUC == nil || *v.AUC > *versions[*comparison.BestByAUC].AUC) {
+				best := i
+				comparison.BestByAUC = &best
+			}
+			if comparison.BestByBrier == nil || v.Brier < versions[*comparison.BestByBrier].Brier {
+				best := i
+				comparison.BestByBrier = &best
+			}
+		}
+		comparisons = append(comparisons, comparison)
+	}
+
+	// The overall comparison leads, then dimensions by name
+	sort.Slice(comparisons, func(i, j int) bool {
+		a, b := comparisons[i].Dimension, comparisons[j].Dimension
+		if (a == BacktestOverallDimension) != (b == BacktestOverallDimension) {
+			return a == BacktestOverallDimension
+		}
+		return a < b
+	})
+	return comparisons
+}
+
+func observedValue(s BacktestSample) float64 {
+	if s.Observed {
+		return 1
+	}
+	return 0
+}
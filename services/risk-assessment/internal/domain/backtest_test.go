@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktestMetrics(t *testing.T) {
+	samples := []BacktestSample{
+		{Score: 0.9, Observed: true},
+		{Score: 0.8},
+		{Score: 0.7, Observed: true},
+		{Score: 0.1},
+	}
+
+	auc, ok := AUC(samples)
+	require.True(t, ok)
+	assert.Equal(t, 0.75, auc)
+	assert.InDelta(t, 0.1875, BrierScore(samples), 1e-9)
+
+	curve := CalibrationCurve(samples, 2)
+	assert.Equal(t, []CalibrationBin{
+		{Lower: 0, Upper: 0.5, Count: 1, MeanPredicted: 0.1, ObservedRate: 0},
+		{Lower: 0.5, Upper: 1, Count: 3, MeanPredicted: 0.8, ObservedRate: 0.6667},
+	}, curve)
+	assert.InDelta(t, 0.125, ExpectedCalibrationError(curve), 1e-4)
+
+	// Ties count as half and one-sided samples have no AUC
+	auc, ok = AUC([]BacktestSample{{Score: 0.5, Observed: true}, {Score: 0.5}})
+	require.True(t, ok)
+	assert.Equal(t, 0.5, auc)
+	_, ok = AUC([]BacktestSample{{Score: 0.5}, {Score: 0.7}})
+	assert.False(t, ok)
+}
+
+func TestBacktestEvaluatorLabelsAndCensors(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	outcomes := []*Outcome{{EntityID: "acme", Type: OutcomeDefault, OccurredAt: start.Add(10 * day)}}
+	evaluator := NewBacktestEvaluator(outcomes, 30*day, start.Add(100*day))
+
+	evaluator.Add(&RiskAssessment{
+		EntityID: "acme", ModelID: "m1", ModelVersion: 1, OverallScore: 0.8, Timestamp: start,
+		Dimensions: map[string]RiskDimension{
+			DimensionFinancial:  {Score: 0.9},
+			DimensionCompliance: {Score: 0.55, ProviderFailed: true},
+		},
+	})
+	// The default came before this assessment
+	evaluator.Add(&RiskAssessment{
+		EntityID: "acme", ModelID: "m1", ModelVersion: 1, OverallScore: 0.3, Timestamp: start.Add(50 * day),
+		Dimensions: map[string]RiskDimension{DimensionFinancial: {Score: 0.2}},
+	})
+	// Its horizon has not fully passed
+	evaluator.Add(&RiskAssessment{EntityID: "globex", ModelID: "m2", ModelVersion: 2, Timestamp: start.Add(80 * day)})
+
+	assert.Equal(t, 3, evaluator.Assessments)
+	assert.Equal(t, 1, evaluator.Censored)
+
+	results := evaluator.Results(10)
+	require.Len(t, results, 2)
+	assert.Equal(t, DimensionFinancial, results[0].Dimension)
+	assert.Equal(t, BacktestOverallDimension, results[1].Dimension)
+	for _, r := range results {
+		assert.Equal(t, "m1", r.ModelID)
+		assert.Equal(t, 2, r.Samples)
+		assert.Equal(t, 1, r.Positives)
+		require.NotNil(t, r.AUC)
+		assert.Equal(t, 1.0, *r.AUC)
+	}
+}
+
+func TestCompareModelVersions(t *testing.T) {
+	auc := func(v float64) *float64 { return &v }
+	comparisons := CompareModelVersions([]BacktestResult{
+		{Dimension: DimensionFinancial, ModelVersion: 2, Samples: 40, AUC: auc(0.71), Brier: 0.2},
+		{Dimension: DimensionFinancial, ModelVersion: 1, Samples: 50, AUC: auc(0.64), Brier: 0.18},
+		{Dimension: DimensionFinancial, ModelVersion: 3, Samples: 5, AUC: auc(0.95), Brier: 0.01},
+		{Dimension: BacktestOverallDimension, ModelVersion: 1, Samples: 10, AUC: auc(0.6), Brier: 0.2},
+	})
+
+	require.Len(t, comparisons, 2)
+	assert.Equal(t, BacktestOverallDimension, comparisons[0].Dimension)
+	assert.Nil(t, comparisons[0].BestByAUC, "too few samples to rank")
+
+	financial := comparisons[1]
+	require.Len(t, financial.Versions, 3)
+	require.NotNil(t, financial.BestByAUC)
+	require.NotNil(t, financial.BestByBrier)
+	assert.Equal(t, 2, financial.Versions[*financial.BestByAUC].ModelVersion)
+	assert.Equal(t, 1, financial.Versions[*financial.BestByBrier].ModelVersion)
+}
@@ -0,0 +1,93 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// LowConfidenceThreshold is the overall confidence below which an
+// assessment is flagged as low-confidence
+const LowConfidenceThreshold = 0.5
+
+// freshnessHalfLife is the data age at which freshness halves
+const freshnessHalfLife = 7 * 24 * time.Hour
+
+// ConfidenceInputs describes the data behind one dimension score
+type ConfidenceInputs struct {
+	// Quality is the provider's own 0-1 data quality rating
+	Quality    float64
+	ObservedAt time.Time
+	// Missing of Total model indicators had no value
+	Missing int
+	Total   int
+	// ProviderScores holds the dimension score each responding provider's
+	// data produced; they agree when the scores are close
+	ProviderScores []float64
+	ProviderFailed bool
+}
+
+// ConfidenceBreakdown is a dimension's confidence and the components it is
+// the product of
+type ConfidenceBreakdown struct {
+	Score        float64 `json:"score"`
+	Quality      float64 `json:"quality"`
+	Freshness    float64 `json:"freshness"`
+	Completeness float64 `json:"completeness"`
+	Agreement    float64 `json:"agreement"`
+}
+
+// DimensionConfidence scores how far a dimension score can be trusted. A
+// dimension whose providers all failed has no confidence.
+func DimensionConfidence(in ConfidenceInputs, now time.Time) ConfidenceBreakdown {
+	if in.ProviderFailed {
+		return ConfidenceBreakdown{}
+	}
+
+	b := ConfidenceBreakdown{
+		Quality:      clamp01(in.Quality),
+		Freshness:    freshness(in.ObservedAt, now),
+		Completeness: 1,
+		Agreement:    agreement(in.ProviderScores),
+	}
+	if in.Total > 0 {
+		b.Completeness = float64(in.Total-in.Missing) / float64(in.Total)
+	}
+	b.Score = round2(b.Quality * b.Freshness * b.Completeness * b.Agreement)
+	b.Quality, b.Freshness, b.Completeness, b.Agreement =
+		round2(b.Quality), round2(b.Freshness), round2(b.Completeness), round2(b.Agreement)
+	return b
+}
+
+// freshness halves every freshnessHalfLife; data without a timestamp counts
+// as one half-life old
+func freshness(observedAt, now time.Time) float64 {
+	if observedAt.IsZero() {
+		return 0.5
+	}
+	age := now.Sub(observedAt)
+	if age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, float64(age)/float64(freshnessHalfLife))
+}
+
+// agreement falls from 1 as providers' scores spread apart; a spread of
+// 0.5 or more means they do not agree at all
+func agreement(scores []float64) float64 {
+	if len(scores) < 2 {
+		return 1
+	}
+	lo, hi := scores[0], scores[0]
+	for _, s := range scores[1:] {
+		lo, hi = math.Min(lo, s), math.Max(hi, s)
+	}
+	return clamp01(1 - (hi-lo)/0.5)
+}
+
+func clamp01(v float64) float64 {
+	return math.Max(0, math.Min(1, v))
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDimensionConfidence(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("fresh complete data keeps provider quality", func(t *testing.T) {
+		b := DimensionConfidence(ConfidenceInputs{Quality: 0.9, ObservedAt: now.Add(-time.Hour), Total: 4, ProviderScores: []float64{0.4}}, now)
+		assert.InDelta(t, 0.9, b.Quality, 1e-9)
+		assert.InDelta(t, 1.0, b.Completeness, 1e-9)
+		assert.InDelta(t, 1.0, b.Agreement, 1e-9)
+		assert.InDelta(t, 0.9, b.Score, 0.01)
+	})
+
+	t.Run("stale data halves per half-life", func(t *testing.T) {
+		b := DimensionConfidence(ConfidenceInputs{Quality: 1, ObservedAt: now.Add(-14 * 24 * time.Hour), Total: 4}, now)
+		assert.InDelta(t, 0.25, b.Freshness, 1e-9)
+	})
+
+	t.Run("missing indicators and disagreeing providers", func(t *testing.T) {
+		b := DimensionConfidence(ConfidenceInputs{Quality: 1, ObservedAt: now, Missing: 1, Total: 4, ProviderScores: []float64{0.3, 0.55}}, now)
+		assert.InDelta(t, 0.75, b.Completeness, 1e-9)
+		assert.InDelta(t, 0.5, b.Agreement, 1e-9)
+		assert.InDelta(t, 0.375, b.Score, 0.01)
+	})
+
+	t.Run("failed provider has no confidence", func(t *testing.T) {
+		b := DimensionConfidence(ConfidenceInputs{Quality: 1, ProviderFailed: true}, now)
+		assert.Zero(t, b.Score)
+	})
+}
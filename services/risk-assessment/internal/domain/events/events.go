@@ -2,6 +2,15 @@ package events
 
 import "time"
 
+// Topics the risk service publishes to, matching pkg/events
+const (
+	TopicRiskAssessed      = "atlas.risk.assessed"
+	TopicAlertTriggered    = "atlas.alert.triggered"
+	TopicAlertResolved     = "atlas.alert.resolved"
+	TopicAlertAcknowledged = "atlas.alert.acknowledged"
+	TopicThresholdBreached = "atlas.risk.threshold_breached"
+)
+
 type DomainEvent interface {
 	EventType() string
 	OccurredAt() time.Time
@@ -27,6 +36,22 @@ type RiskAssessed struct {
 	OverallScore float64            `json:"overall_score"`
 	Confidence   float64            `json:"confidence"`
 	Dimensions   map[string]float64 `json:"dimensions"`
+	// PreviousScore is the entity's last overall score, for scheduled
+	// reassessments that moved it
+	PreviousScore *float64 `json:"previous_score,omitempty"`
+}
+
+// RiskAssessmentJobCompleted summarises a finished batch job. It is
+// published to TopicRiskAssessed with the job as the aggregate; the
+// individual assessments are fetched from the job's results.
+type RiskAssessmentJobCompleted struct {
+	BaseEvent
+	JobID       string    `json:"job_id"`
+	Total       int       `json:"total"`
+	Succeeded   int       `json:"succeeded"`
+	Failed      int       `json:"failed"`
+	CreatedBy   string    `json:"created_by,omitempty"`
+	CompletedAt time.Time `json:"completed_at"`
 }
 
 type AlertTriggered struct {
@@ -38,15 +63,33 @@ type AlertTriggered struct {
 	Threshold float64 `json:"threshold"`
 	Condition string  `json:"condition"`
 	Severity  string  `json:"severity"`
+	// Expression is set for rule-expression alerts, whose Score is the
+	// overall score; RuleID is the group rule that raised the alert
+	Expression string `json:"expression,omitempty"`
+	RuleID     string `json:"rule_id,omitempty"`
+}
+
+// AlertAcknowledged is published when someone takes ownership of a
+// firing alert
+type AlertAcknowledged struct {
+	BaseEvent
+	AlertID        string `json:"alert_id"`
+	EntityID       string `json:"entity_id"`
+	AcknowledgedBy string `json:"acknowledged_by"`
 }
 
+// AlertResolved is published when an alert is resolved by hand or, with
+// ResolvedBy "system", when the score clears the threshold
 type AlertResolved struct {
 	BaseEvent
 	AlertID    string `json:"alert_id"`
 	EntityID   string `json:"entity_id"`
 	ResolvedBy string `json:"resolved_by"`
+	Resolution string `json:"resolution,omitempty"`
 }
 
+// RiskThresholdBreached is published when an alert's change() condition
+// fires: Dimension moved from PrevScore to Score, by more than Threshold
 type RiskThresholdBreached struct {
 	BaseEvent
 	EntityID  string  `json:"entity_id"`
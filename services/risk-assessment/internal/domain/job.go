@@ -0,0 +1,66 @@
+package models
+
+import "time"
+
+// MaxJobEntities bounds how many entities one batch job may assess
+const MaxJobEntities = 10000
+
+const (
+	JobStatusQueued    = "queued"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+
+	JobItemPending   = "pending"
+	JobItemSucceeded = "succeeded"
+	JobItemFailed    = "failed"
+)
+
+// AssessmentJob assesses a list of entities in the background with the
+// same options. Counters are updated as items finish.
+type AssessmentJob struct {
+	ID             string     `json:"id"`
+	Status         string     `json:"status"`
+	Dimensions     []string   `json:"dimensions,omitempty"`
+	IncludeFactors bool       `json:"include_factors"`
+	Total          int        `json:"total"`
+	Succeeded      int        `json:"succeeded"`
+	Failed         int        `json:"failed"`
+	CreatedBy      string     `json:"created_by,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	StartedAt      *time.Time `json:"started_at,omitempty"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
+
+// Processed is how many items have finished, successfully or not
+func (j *AssessmentJob) Processed() int {
+	return j.Succeeded + j.Failed
+}
+
+// JobItem is one entity in a batch job and the outcome of assessing it
+type JobItem struct {
+	JobID        string    `json:"job_id"`
+	Position     int       `json:"position"`
+	EntityID     string    `json:"entity_id"`
+	EntityType   string    `json:"entity_type"`
+	Status       string    `json:"status"`
+	AssessmentID string    `json:"assessment_id,omitempty"`
+	OverallScore *float64  `json:"overall_score,omitempty"`
+	Degraded     bool      `json:"degraded,omitempty"`
+	Error        string    `json:"error,omitempty"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// JobEntity names one entity to assess in a batch
+type JobEntity struct {
+	EntityID   string `json:"entity_id" binding:"required"`
+	EntityType string `json:"entity_type"`
+}
+
+// SubmitJobRequest starts a batch job. Entities without an entity_type use
+// EntityType.
+type SubmitJobRequest struct {
+	Entities       []JobEntity `json:"entities"`
+	EntityType     string      `json:"entity_type"`
+	Dimensions     []string    `json:"dimensions"`
+	IncludeFactors bool        `json:"include_factors"`
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Notification channels delivered by the dispatcher. The notifications
+// table also names sms and push, which are not delivered here.
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+	ChannelSlack   = "slack"
+)
+
+// Notification priorities, lowest first
+const (
+	PriorityLow    = "low"
+	PriorityNormal = "normal"
+	PriorityHigh   = "high"
+	PriorityUrgent = "urgent"
+)
+
+var priorityRank = map[string]int{PriorityLow: 0, PriorityNormal: 1, PriorityHigh: 2, PriorityUrgent: 3}
+
+// Delivery states. In-app notifications are delivered when stored; other
+// channels wait as pending until a delivery succeeds or runs out of
+// attempts.
+const (
+	NotificationPending   = "pending"
+	NotificationDelivered = "delivered"
+	NotificationFailed    = "failed"
+)
+
+// NotificationTypeAlert is the notification type of risk alerts
+const NotificationTypeAlert = "alert"
+
+// Notification is one message to one user on one channel
+type Notification struct {
+	ID               string `json:"id"`
+	UserID           string `json:"user_id"`
+	NotificationType string `json:"notification_type"`
+	Title            string `json:"title"`
+	Message          string `json:"message,omitempty"`
+	Priority         string `json:"priority"`
+	Channel          string `json:"channel"`
+	SourceType       string `json:"source_type,omitempty"`
+	SourceID         string `json:"source_id,omitempty"`
+	ActionURL        string `json:"action_url,omitempty"`
+
+	IsRead      bool       `json:"is_read"`
+	ReadAt      *time.Time `json:"read_at,omitempty"`
+	IsDismissed bool       `json:"is_dismissed"`
+	DismissedAt *time.Time `json:"dismissed_at,omitempty"`
+
+	// Delivery to channels other than in_app
+	Status        string     `json:"status"`
+	Target        string     `json:"-"`
+	Attempts      int        `json:"attempts,omitempty"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	// DedupKey identifies what the notification is about, so a repeat
+	// within the dedup window is dropped
+	DedupKey string `json:"-"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// QuietHours is a daily window, in UTC, during which only urgent
+// notifications are delivered. It may wrap past midnight.
+type QuietHours struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// ParseQuietHours parses "HH:MM" or "HH:MM:SS" window ends
+func ParseQuietHours(start, end string) (*QuietHours, error) {
+	s, err := parseClock(start)
+	if err != nil {
+		return nil, err
+	}
+	e, err := parseClock(end)
+	if err != nil {
+		return nil, err
+	}
+	return &QuietHours{Start: s, End: e}, nil
+}
+
+func parseClock(s string) (time.Duration, error) {
+	layout := "15:04"
+	if strings.Count(s, ":") == 2 {
+		layout = "15:04:05"
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second, nil
+}
+
+// Until returns when the quiet period containing t ends, and false if t is
+// outside quiet hours
+func (q *QuietHours) Until(t time.Time) (time.Time, bool) {
+	t = t.UTC()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	clock := t.Sub(midnight)
+	switch {
+	case q.Start == q.End:
+		return time.Time{}, false
+	case q.Start < q.End:
+		if clock >= q.Start && clock < q.End {
+			return midnight.Add(q.End), true
+		}
+	case clock >= q.Start:
+		return midnight.AddDate(0, 0, 1).Add(q.End), true
+	case clock < q.End:
+		return midnight.Add(q.End), true
+	}
+	return time.Time{}, false
+}
+
+// NotificationPreference is how one user receives one notification type on
+// one channel. Preferences are managed through the IAM settings API.
+type NotificationPreference struct {
+	UserID           string      `json:"user_id"`
+	NotificationType string      `json:"notification_type"`
+	Channel          string      `json:"channel"`
+	Enabled          bool        `json:"enabled"`
+	QuietHours       *QuietHours `json:"-"`
+	MinPriority      string      `json:"min_priority"`
+	// Target is the address delivered to: an email address, or the URL of
+	// a webhook or Slack incoming webhook
+	Target string `json:"-"`
+}
+
+// DefaultNotificationPreferences apply to a user with no stored
+// preferences for a type: in-app only, every priority
+func DefaultNotificationPreferences(userID, notificationType string) []*NotificationPreference {
+	return []*NotificationPreference{{
+		UserID:           userID,
+		NotificationType: notificationType,
+		Channel:          ChannelInApp,
+		Enabled:          true,
+		MinPriority:      PriorityLow,
+	}}
+}
+
+// Allows reports whether a notification of priority is delivered
+func (p *NotificationPreference) Allows(priority string) bool {
+	return p.Enabled && priorityRank[priority] >= priorityRank[p.MinPriority]
+}
+
+// DeliverAt returns when a notification of priority created at now may be
+// delivered: at the end of quiet hours, or now for urgent ones
+func (p *NotificationPreference) DeliverAt(priority string, now time.Time) time.Time {
+	if p.QuietHours == nil || priority == PriorityUrgent {
+		return now
+	}
+	if until, quiet := p.QuietHours.Until(now); quiet {
+		return until
+	}
+	return now
+}
+
+// PriorityForSeverity maps an alert severity to a notification priority
+func PriorityForSeverity(severity string) string {
+	switch severity {
+	case SeverityCritical:
+		return PriorityUrgent
+	case SeverityHigh:
+		return PriorityHigh
+	case SeverityLow:
+		return PriorityLow
+	default:
+		return PriorityNormal
+	}
+}
+
+// Digest combines notifications held back by a burst or quiet hours into
+// one message, highest priority first. A single notification is returned
+// as it is.
+func Digest(notifications []*Notification) (title, message, priority string) {
+	priority = PriorityLow
+	for _, n := range notifications {
+		if priorityRank[n.Priority] > priorityRank[priority] {
+			priority = n.Priority
+		}
+	}
+	if len(notifications) == 1 {
+		return notifications[0].Title, notifications[0].Message, priority
+	}
+
+	var b strings.Builder
+	for _, rank := range []string{PriorityUrgent, PriorityHigh, PriorityNormal, PriorityLow} {
+		for _, n := range notifications {
+			if n.Priority == rank {
+				fmt.Fprintf(&b, "- [%s] %s\n", n.Priority, n.Title)
+			}
+		}
+	}
+	return fmt.Sprintf("%d notifications (highest priority: %s)", len(notifications), priority), b.String(), priority
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuietHoursWrapPastMidnight(t *testing.T) {
+	quiet, err := ParseQuietHours("22:00", "07:30:00")
+	require.NoError(t, err)
+
+	day := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	until, ok := quiet.Until(day.Add(23 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, day.AddDate(0, 0, 1).Add(7*time.Hour+30*time.Minute), until)
+
+	until, ok = quiet.Until(day.Add(3 * time.Hour))
+	assert.True(t, ok)
+	assert.Equal(t, day.Add(7*time.Hour+30*time.Minute), until)
+
+	_, ok = quiet.Until(day.Add(12 * time.Hour))
+	assert.False(t, ok)
+
+	_, err = ParseQuietHours("25:00", "07:00")
+	assert.Error(t, err)
+}
+
+func TestNotificationPreferenceFiltersAndDefers(t *testing.T) {
+	night := time.Date(2024, 6, 1, 23, 0, 0, 0, time.UTC)
+	pref := &NotificationPreference{
+		Channel:     ChannelEmail,
+		Enabled:     true,
+		MinPriority: PriorityNormal,
+		QuietHours:  &QuietHours{Start: 22 * time.Hour, End: 7 * time.Hour},
+	}
+
+	assert.False(t, pref.Allows(PriorityLow))
+	assert.True(t, pref.Allows(PriorityHigh))
+	assert.Equal(t, time.Date(2024, 6, 2, 7, 0, 0, 0, time.UTC), pref.DeliverAt(PriorityHigh, night))
+	// Urgent notifications ignore quiet hours
+	assert.Equal(t, night, pref.DeliverAt(PriorityUrgent, night))
+
+	pref.Enabled = false
+	assert.False(t, pref.Allows(PriorityUrgent))
+}
+
+func TestDigestOrdersByPriority(t *testing.T) {
+	title, message, priority := Digest([]*Notification{
+		{Title: "a", Priority: PriorityLow},
+		{Title: "b", Priority: PriorityUrgent},
+		{Title: "c", Priority: PriorityNormal},
+	})
+	assert.Equal(t, "3 notifications (highest priority: urgent)", title)
+	assert.Equal(t, "- [urgent] b\n- [normal] c\n- [low] a\n", message)
+	assert.Equal(t, PriorityUrgent, priority)
+
+	title, message, _ = Digest([]*Notification{{Title: "only", Message: "body", Priority: PriorityHigh}})
+	assert.Equal(t, "only", title)
+	assert.Equal(t, "body", message)
+}
@@ -3,15 +3,28 @@ package models
 import "time"
 
 type RiskAssessment struct {
-	ID           string                    `json:"id"`
-	EntityID     string                    `json:"entity_id"`
-	EntityType   string                    `json:"entity_type"`
-	OverallScore float64                   `json:"overall_score"`
-	Confidence   float64                   `json:"confidence"`
-	Dimensions   map[string]RiskDimension  `json:"dimensions"`
-	Factors      []RiskFactor              `json:"factors"`
-	Timestamp    time.Time                 `json:"timestamp"`
-	ValidUntil   time.Time                 `json:"valid_until"`
+	ID           string                   `json:"id"`
+	EntityID     string                   `json:"entity_id"`
+	EntityType   string                   `json:"entity_type"`
+	OverallScore float64                  `json:"overall_score"`
+	Confidence   float64                  `json:"confidence"`
+	Dimensions   map[string]RiskDimension `json:"dimensions"`
+	Factors      []RiskFactor             `json:"factors"`
+	Timestamp    time.Time                `json:"timestamp"`
+	ValidUntil   time.Time                `json:"valid_until"`
+	// ModelID and ModelVersion identify the scoring model that produced the
+	// scores, so they can be reproduced later
+	ModelID      string `json:"model_id,omitempty"`
+	ModelVersion int    `json:"model_version,omitempty"`
+	// LowConfidence is set when a provider failed or overall confidence is
+	// below LowConfidenceThreshold; Warnings say why
+	LowConfidence bool     `json:"low_confidence"`
+	Warnings      []string `json:"warnings,omitempty"`
+	// Degraded is set when some dimensions were not scored within the
+	// assessment's time budget and carry fallback scores
+	Degraded bool `json:"degraded"`
+	// Tags group entities for alert rules
+	Tags []string `json:"tags,omitempty"`
 }
 
 type RiskDimension struct {
@@ -20,29 +33,58 @@ type RiskDimension struct {
 	Trend      string   `json:"trend"` // "increasing", "decreasing", "stable"
 	KeyFactors []string `json:"key_factors"`
 	Weight     float64  `json:"weight"` // Weight in overall score calculation
+	// Confidence is how far the score can be trusted, 0-1
+	Confidence          float64              `json:"confidence"`
+	ConfidenceBreakdown *ConfidenceBreakdown `json:"confidence_breakdown,omitempty"`
+	// ProviderFailed means no provider returned data and Score is the
+	// model's fallback
+	ProviderFailed bool `json:"provider_failed,omitempty"`
+	// ProviderError is why each provider failed when ProviderFailed is set
+	ProviderError string `json:"provider_error,omitempty"`
+	// Degraded means the time budget ran out before any provider answered
+	Degraded bool `json:"degraded,omitempty"`
+	// Synthetic means the score was computed from generated fixture data
+	// rather than an observed feed
+	Synthetic bool `json:"synthetic,omitempty"`
 }
 
 const (
-	DimensionOperational   = "operational"
-	DimensionFinancial      = "financial"
-	DimensionReputational   = "reputational"
-	DimensionGeopolitical   = "geopolitical"
-	DimensionCompliance     = "compliance"
+	DimensionOperational  = "operational"
+	DimensionFinancial    = "financial"
+	DimensionReputational = "reputational"
+	DimensionGeopolitical = "geopolitical"
+	DimensionCompliance   = "compliance"
 )
 
+// RiskFactor explains part of a score: the indicator value a provider
+// reported, what it contributed and where it came from
 type RiskFactor struct {
-	ID       string  `json:"id"`
-	Name     string  `json:"name"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// Impact is the factor's contribution to the overall score
 	Impact   float64 `json:"impact"`
 	Source   string  `json:"source"`
 	SourceID string  `json:"source_id"`
+
+	Dimension string  `json:"dimension,omitempty"`
+	Indicator string  `json:"indicator,omitempty"`
+	Value     float64 `json:"value"`
+	// Contribution is the factor's contribution to its dimension score;
+	// negative contributions lower risk
+	Contribution float64    `json:"contribution"`
+	Flags        []string   `json:"flags,omitempty"`
+	ObservedAt   *time.Time `json:"observed_at,omitempty"`
+	Explanation  string     `json:"explanation,omitempty"`
 }
 
 type AssessRiskRequest struct {
-	EntityID      string   `json:"entity_id" binding:"required"`
-	EntityType    string   `json:"entity_type" binding:"required"`
-	Dimensions    []string `json:"dimensions"`
-	TimeHorizon   string   `json:"time_horizon"`
-	IncludeFactors bool   `json:"include_factors"`
-	IncludeTrends  bool   `json:"include_trends"`
+	EntityID       string   `json:"entity_id" binding:"required"`
+	EntityType     string   `json:"entity_type" binding:"required"`
+	Dimensions     []string `json:"dimensions"`
+	TimeHorizon    string   `json:"time_horizon"`
+	IncludeFactors bool     `json:"include_factors"`
+	IncludeTrends  bool     `json:"include_trends"`
+	// Tags replace the entity's tags; when omitted the entity keeps the
+	// tags of its last assessment
+	Tags []string `json:"tags"`
 }
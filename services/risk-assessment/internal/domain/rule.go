@@ -0,0 +1,444 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	// OverallScore names the overall score in rule expressions
+	OverallScore = "overall"
+	// MaxChangeWindow is the longest window a change() term may look back
+	MaxChangeWindow = 90 * 24 * time.Hour
+)
+
+// Rule is a parsed alert condition. Expressions compare scores with
+// numbers and combine comparisons with AND, OR, NOT and parentheses:
+//
+//	financial > 0.7 AND geopolitical > 0.6
+//	change(overall, 7d) > 0.2 OR NOT (compliance < 0.4)
+//
+// An operand is a dimension, "overall", or change(score, window): how far
+// the score moved since the oldest assessment within the window (e.g. 7d or
+// 12h).
+type Rule struct {
+	root ruleNode
+}
+
+// RuleInput is what a rule is evaluated against: an assessment and the
+// entity's earlier assessments, in any order
+type RuleInput struct {
+	Assessment *RiskAssessment
+	History    []*RiskAssessment
+}
+
+// RuleResult is the outcome of evaluating a rule
+type RuleResult struct {
+	Matched bool
+	// Magnitude is how far the scores are past their thresholds; for AND,
+	// the smallest of its sides
+	Magnitude float64
+	// Changes are the change() comparisons that held
+	Changes []RuleChange
+}
+
+// RuleChange is a rate-of-change comparison that held
+type RuleChange struct {
+	Dimension string
+	Window    time.Duration
+	Score     float64
+	PrevScore float64
+	Threshold float64
+}
+
+// ParseRule parses a rule expression
+func ParseRule(expression string) (*Rule, error) {
+	tokens, err := tokenizeRule(expression)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q", p.tokens[p.pos])
+	}
+	return &Rule{root: root}, nil
+}
+
+// NewThresholdRule is the rule of a single-dimension alert: "above" is >,
+// "below" is < and "equals" is ==
+func NewThresholdRule(dimension, condition string, threshold float64) (*Rule, error) {
+	ops := map[string]string{"above": ">", "below": "<", "equals": "=="}
+	op, ok := ops[condition]
+	if !ok {
+		return nil, fmt.Errorf("unknown condition %q", condition)
+	}
+	if err := validRuleScore(dimension); err != nil {
+		return nil, err
+	}
+	return &Rule{root: &ruleComparison{operand: ruleOperand{score: dimension}, op: op, value: threshold}}, nil
+}
+
+// Evaluate evaluates the rule with every threshold moved margin towards
+// matching. A negative margin makes the rule harder to match; a firing
+// alert clears when its rule no longer matches with the hysteresis margin.
+func (r *Rule) Evaluate(in RuleInput, margin float64) RuleResult {
+	return r.root.eval(in, margin)
+}
+
+// Applies reports whether the assessment has every score the rule reads;
+// a rule is not evaluated against an assessment that skipped a dimension
+func (r *Rule) Applies(a *RiskAssessment) bool {
+	for _, score := range r.root.scores(nil) {
+		if _, ok := ruleScore(a, score); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Window is the longest change() window in the rule, or zero if it only
+// looks at the current scores
+func (r *Rule) Window() time.Duration {
+	return r.root.window()
+}
+
+type ruleNode interface {
+	eval(in RuleInput, margin float64) RuleResult
+	window() time.Duration
+	// scores appends the scores the node reads
+	scores(names []string) []string
+}
+
+type ruleBinary struct {
+	and         bool
+	left, right ruleNode
+}
+
+func (n *ruleBinary) eval(in RuleInput, margin float64) RuleResult {
+	l, r := n.left.eval(in, margin), n.right.eval(in, margin)
+	switch {
+	case n.and && l.Matched && r.Matched:
+		return RuleResult{Matched: true, Magnitude: math.Min(l.Magnitude, r.Magnitude), Changes: append(l.Changes, r.Changes...)}
+	case n.and:
+		return RuleResult{}
+	case l.Matched && r.Matched:
+		return RuleResult{Matched: true, Magnitude: math.Max(l.Magnitude, r.Magnitude), Changes: append(l.Changes, r.Changes...)}
+	case l.Matched:
+		return l
+	default:
+		return r
+	}
+}
+
+func (n *ruleBinary) window() time.Duration {
+	l, r := n.left.window(), n.right.window()
+	if l > r {
+		return l
+	}
+	return r
+}
+
+func (n *ruleBinary) scores(names []string) []string {
+	return n.right.scores(n.left.scores(names))
+}
+
+type ruleNot struct {
+	inner ruleNode
+}
+
+// eval negates the inner rule; moving its thresholds towards a match makes
+// the inner rule harder to match
+func (n *ruleNot) eval(in RuleInput, margin float64) RuleResult {
+	return RuleResult{Matched: !n.inner.eval(in, -margin).Matched}
+}
+
+func (n *ruleNot) window() time.Duration { return n.inner.window() }
+
+func (n *ruleNot) scores(names []string) []string { return n.inner.scores(names) }
+
+type ruleOperand struct {
+	score string
+	// change is the look-back window of a change() operand
+	change time.Duration
+}
+
+// value returns the operand for the input and, for change(), the baseline
+// it was measured from
+func (o ruleOperand) value(in RuleInput) (value, baseline float64, ok bool) {
+	current, ok := ruleScore(in.Assessment, o.score)
+	if !ok || o.change == 0 {
+		return current, 0, ok
+	}
+
+	since := in.Assessment.Timestamp.Add(-o.change)
+	var oldest *RiskAssessment
+	for _, h := range in.History {
+		if h.ID == in.Assessment.ID || h.Timestamp.Before(since) || !h.Timestamp.Before(in.Assessment.Timestamp) {
+			continue
+		}
+		if _, has := ruleScore(h, o.score); has && (oldest == nil || h.Timestamp.Before(oldest.Timestamp)) {
+			oldest = h
+		}
+	}
+	if oldest == nil {
+		return 0, 0, false
+	}
+	baseline, _ = ruleScore(oldest, o.score)
+	return current - baseline, baseline, true
+}
+
+func ruleScore(a *RiskAssessment, score string) (float64, bool) {
+	if score == OverallScore {
+		return a.OverallScore, true
+	}
+	dim, ok := a.Dimensions[score]
+	return dim.Score, ok
+}
+
+type ruleComparison struct {
+	operand ruleOperand
+	op      string
+	value   float64
+}
+
+func (n *ruleComparison) eval(in RuleInput, margin float64) RuleResult {
+	x, baseline, ok := n.operand.value(in)
+	if !ok {
+		return RuleResult{}
+	}
+
+	// scoreEpsilon keeps float error from deciding values on a boundary
+	t := n.value
+	var matched bool
+	switch n.op {
+	case ">":
+		matched = x > t-margin+scoreEpsilon
+	case ">=":
+		matched = x >= t-margin-scoreEpsilon
+	case "<":
+		matched = x < t+margin-scoreEpsilon
+	case "<=":
+		matched = x <= t+margin+scoreEpsilon
+	case "==":
+		matched = math.Abs(x-t) < equalsTolerance+margin
+	}
+	if !matched {
+		return RuleResult{}
+	}
+
+	result := RuleResult{Matched: true, Magnitude: math.Abs(x - t)}
+	if n.op == "==" {
+		// Hitting a value exactly has no breach size
+		result.Magnitude = 0.05
+	}
+	if n.operand.change > 0 {
+		result.Changes = []RuleChange{{
+			Dimension: n.operand.score,
+			Window:    n.operand.change,
+			Score:     baseline + x,
+			PrevScore: baseline,
+			Threshold: t,
+		}}
+	}
+	return result
+}
+
+func (n *ruleComparison) window() time.Duration { return n.operand.change }
+
+func (n *ruleComparison) scores(names []string) []string { return append(names, n.operand.score) }
+
+// tokenizeRule splits an expression into words, numbers, operators and
+// parentheses
+func tokenizeRule(s string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(s); {
+		c := rune(s[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.ContainsRune("(),", c):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.ContainsRune("<>=", c):
+			if i+1 < len(s) && s[i+1] == '=' {
+				tokens = append(tokens, s[i:i+2])
+				i += 2
+			} else if c == '=' {
+				return nil, fmt.Errorf("use == to compare at position %d", i+1)
+			} else {
+				tokens = append(tokens, string(c))
+				i++
+			}
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || c == '.' || c == '_' || c == '-':
+			j := i
+			for j < len(s) && (unicode.IsLetter(rune(s[j])) || unicode.IsDigit(rune(s[j])) || strings.ContainsRune("._-", rune(s[j]))) {
+				j++
+			}
+			tokens = append(tokens, s[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q at position %d", c, i+1)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	return tokens, nil
+}
+
+type ruleParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *ruleParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *ruleParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *ruleParser) expect(token string) error {
+	if got := p.next(); got != token {
+		if got == "" {
+			return fmt.Errorf("expected %q at end of expression", token)
+		}
+		return fmt.Errorf("expected %q, got %q", token, got)
+	}
+	return nil
+}
+
+func (p *ruleParser) parseOr() (ruleNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseAnd() (ruleNode, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &ruleBinary{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *ruleParser) parseUnary() (ruleNode, error) {
+	switch tok := p.peek(); {
+	case strings.EqualFold(tok, "NOT"):
+		p.next()
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &ruleNot{inner: inner}, nil
+	case tok == "(":
+		p.next()
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	default:
+		return p.parseComparison()
+	}
+}
+
+func (p *ruleParser) parseComparison() (ruleNode, error) {
+	operand, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	op := p.next()
+	switch op {
+	case ">", ">=", "<", "<=", "==":
+	case "":
+		return nil, fmt.Errorf("expected a comparison after %q", operand.score)
+	default:
+		return nil, fmt.Errorf("expected a comparison, got %q", op)
+	}
+	raw := p.next()
+	value, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("expected a number, got %q", raw)
+	}
+	return &ruleComparison{operand: operand, op: op, value: value}, nil
+}
+
+func (p *ruleParser) parseOperand() (ruleOperand, error) {
+	name := strings.ToLower(p.next())
+	if name != "change" {
+		return ruleOperand{score: name}, validRuleScore(name)
+	}
+
+	if err := p.expect("("); err != nil {
+		return ruleOperand{}, err
+	}
+	score := strings.ToLower(p.next())
+	if err := validRuleScore(score); err != nil {
+		return ruleOperand{}, err
+	}
+	if err := p.expect(","); err != nil {
+		return ruleOperand{}, err
+	}
+	window, err := parseRuleWindow(p.next())
+	if err != nil {
+		return ruleOperand{}, err
+	}
+	return ruleOperand{score: score, change: window}, p.expect(")")
+}
+
+func validRuleScore(name string) error {
+	if name == OverallScore {
+		return nil
+	}
+	if _, ok := DimensionIndicators[name]; !ok {
+		return fmt.Errorf("unknown score %q", name)
+	}
+	return nil
+}
+
+// parseRuleWindow accepts days ("7d") or any Go duration ("12h")
+func parseRuleWindow(s string) (time.Duration, error) {
+	var window time.Duration
+	if days, err := strconv.Atoi(strings.TrimSuffix(s, "d")); err == nil && strings.HasSuffix(s, "d") {
+		window = time.Duration(days) * 24 * time.Hour
+	} else if window, err = time.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("invalid window %q", s)
+	}
+	if window <= 0 || window > MaxChangeWindow {
+		return 0, fmt.Errorf("window %q must be positive and at most 90d", s)
+	}
+	return window, nil
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scoredAt(id string, at time.Time, overall float64, dimensions map[string]float64) *RiskAssessment {
+	a := &RiskAssessment{ID: id, Timestamp: at, OverallScore: overall, Dimensions: map[string]RiskDimension{}}
+	for name, score := range dimensions {
+		a.Dimensions[name] = RiskDimension{Name: name, Score: score}
+	}
+	return a
+}
+
+func TestParseRuleRejectsInvalidExpressions(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"financial",
+		"financial > ",
+		"financial = 0.5",
+		"weather > 0.5",
+		"financial > 0.5 AND",
+		"(financial > 0.5",
+		"change(financial) > 0.2",
+		"change(financial, 120d) > 0.2",
+		"financial > 0.5 geopolitical",
+	} {
+		_, err := ParseRule(expr)
+		assert.Error(t, err, expr)
+	}
+}
+
+func TestRuleCombinesComparisons(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	rule, err := ParseRule("financial > 0.7 AND (geopolitical > 0.6 OR NOT compliance >= 0.2)")
+	require.NoError(t, err)
+	assert.Zero(t, rule.Window())
+
+	high := scoredAt("a", now, 0.7, map[string]float64{DimensionFinancial: 0.9, DimensionGeopolitical: 0.65, DimensionCompliance: 0.5})
+	result := rule.Evaluate(RuleInput{Assessment: high}, 0)
+	assert.True(t, result.Matched)
+	assert.InDelta(t, 0.05, result.Magnitude, 1e-9)
+
+	// NOT matches when its inner comparison fails
+	lowCompliance := scoredAt("b", now, 0.7, map[string]float64{DimensionFinancial: 0.9, DimensionGeopolitical: 0.1, DimensionCompliance: 0.1})
+	assert.True(t, rule.Evaluate(RuleInput{Assessment: lowCompliance}, 0).Matched)
+
+	lowFinancial := scoredAt("c", now, 0.7, map[string]float64{DimensionFinancial: 0.65, DimensionGeopolitical: 0.9, DimensionCompliance: 0.1})
+	assert.False(t, rule.Evaluate(RuleInput{Assessment: lowFinancial}, 0).Matched)
+	// The hysteresis margin moves thresholds towards matching
+	assert.True(t, rule.Evaluate(RuleInput{Assessment: lowFinancial}, 0.1).Matched)
+
+	assert.False(t, rule.Applies(scoredAt("d", now, 0.5, map[string]float64{DimensionFinancial: 0.9})))
+	assert.True(t, rule.Applies(high))
+}
+
+func TestRuleMeasuresChangeOverWindow(t *testing.T) {
+	now := time.Date(2024, 6, 10, 12, 0, 0, 0, time.UTC)
+	rule, err := ParseRule("change(financial, 7d) > 0.2")
+	require.NoError(t, err)
+	assert.Equal(t, 7*24*time.Hour, rule.Window())
+
+	history := []*RiskAssessment{
+		scoredAt("old", now.AddDate(0, 0, -9), 0.2, map[string]float64{DimensionFinancial: 0.2}),
+		scoredAt("week", now.AddDate(0, 0, -6), 0.3, map[string]float64{DimensionFinancial: 0.4}),
+		scoredAt("recent", now.AddDate(0, 0, -1), 0.5, map[string]float64{DimensionFinancial: 0.55}),
+	}
+	current := scoredAt("now", now, 0.6, map[string]float64{DimensionFinancial: 0.7})
+	history = append(history, current)
+
+	// Measured from the oldest assessment inside the window, not the one before it
+	result := rule.Evaluate(RuleInput{Assessment: current, History: history}, 0)
+	require.True(t, result.Matched)
+	require.Len(t, result.Changes, 1)
+	assert.Equal(t, DimensionFinancial, result.Changes[0].Dimension)
+	assert.InDelta(t, 0.4, result.Changes[0].PrevScore, 1e-9)
+	assert.InDelta(t, 0.7, result.Changes[0].Score, 1e-9)
+
+	// Without history there is no change to measure
+	assert.False(t, rule.Evaluate(RuleInput{Assessment: current}, 0).Matched)
+}
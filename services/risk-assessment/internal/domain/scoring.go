@@ -0,0 +1,344 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Scoring model statuses. Only draft models can change; an active model is
+// replaced by activating a newer version, which retires it.
+const (
+	ModelStatusDraft   = "draft"
+	ModelStatusActive  = "active"
+	ModelStatusRetired = "retired"
+)
+
+// DefaultEntityType selects the model used for entity types without one
+const DefaultEntityType = "*"
+
+// Indicator transforms map a raw provider value onto a 0-1 risk scale
+const (
+	TransformLinear  = "linear"  // (v - min) / (max - min)
+	TransformInverse = "inverse" // (max - v) / (max - min)
+	TransformLog     = "log"     // ln(1 + v - min) / ln(1 + max - min)
+)
+
+// ScoringModel is a versioned configuration of how indicators become
+// dimension scores and how dimensions combine into the overall score
+type ScoringModel struct {
+	ID          string                    `json:"id"`
+	EntityType  string                    `json:"entity_type"`
+	Version     int                       `json:"version"`
+	Name        string                    `json:"name"`
+	Description string                    `json:"description,omitempty"`
+	Status      string                    `json:"status"`
+	Dimensions  map[string]DimensionModel `json:"dimensions"`
+	CreatedBy   string                    `json:"created_by,omitempty"`
+	CreatedAt   time.Time                 `json:"created_at"`
+	ActivatedAt *time.Time                `json:"activated_at,omitempty"`
+}
+
+// DimensionModel scores one dimension as the weighted sum of its transformed
+// indicators, clamped to 0-1
+type DimensionModel struct {
+	Weight     float64          `json:"weight"`
+	Indicators []IndicatorModel `json:"indicators"`
+	Trend      TrendThresholds  `json:"trend"`
+	// FallbackScore is used when no provider returned data
+	FallbackScore float64 `json:"fallback_score"`
+}
+
+// TrendThresholds label a dimension "increasing" above Increasing and
+// "decreasing" below Decreasing
+type TrendThresholds struct {
+	Increasing float64 `json:"increasing_above"`
+	Decreasing float64 `json:"decreasing_below"`
+}
+
+// IndicatorModel weighs one provider indicator. A negative weight lowers
+// risk as the transformed value grows.
+type IndicatorModel struct {
+	Name      string          `json:"name"`
+	Label     string          `json:"label"`
+	Weight    float64         `json:"weight"`
+	Transform Transform       `json:"transform"`
+	Flags     []IndicatorFlag `json:"flags,omitempty"`
+}
+
+// Transform normalizes a raw indicator value. Clamp limits the result to 0-1.
+type Transform struct {
+	Type  string  `json:"type"`
+	Min   float64 `json:"min"`
+	Max   float64 `json:"max"`
+	Clamp bool    `json:"clamp,omitempty"`
+}
+
+// IndicatorFlag adds Label to a dimension's key factors when the raw value
+// is above or below a threshold
+type IndicatorFlag struct {
+	Above *float64 `json:"above,omitempty"`
+	Below *float64 `json:"below,omitempty"`
+	Label string   `json:"label"`
+}
+
+// DimensionIndicators lists the indicators providers report per dimension
+var DimensionIndicators = map[string][]string{
+	DimensionOperational:  {"cybersecurity_incidents", "supply_chain_risk", "operational_efficiency", "employee_satisfaction", "system_downtime"},
+	DimensionFinancial:    {"credit_score", "debt_to_equity", "revenue_growth", "profit_margin", "market_volatility"},
+	DimensionReputational: {"social_media_sentiment", "news_coverage", "customer_reviews", "brand_value", "csr_score"},
+	DimensionGeopolitical: {"country_risk", "political_stability", "sanctions_exposure", "trade_relations", "conflict_zones"},
+	DimensionCompliance:   {"regulatory_fines", "compliance_score", "audit_findings", "data_privacy_risk", "legal_actions"},
+}
+
+// Validate checks that the model only uses known dimensions and indicators
+// and that every transform has a usable range
+func (m *ScoringModel) Validate() []string {
+	var problems []string
+	if len(m.Dimensions) == 0 {
+		problems = append(problems, "at least one dimension is required")
+	}
+	for name, dim := range m.Dimensions {
+		known, ok := DimensionIndicators[name]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown dimension %q", name))
+			continue
+		}
+		if dim.Weight <= 0 {
+			problems = append(problems, fmt.Sprintf("%s: weight must be positive", name))
+		}
+		if dim.Trend.Decreasing > dim.Trend.Increasing {
+			problems = append(problems, fmt.Sprintf("%s: trend decreasing_below exceeds increasing_above", name))
+		}
+		if len(dim.Indicators) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one indicator is required", name))
+		}
+		for _, ind := range dim.Indicators {
+			if !contains(known, ind.Name) {
+				problems = append(problems, fmt.Sprintf("%s: unknown indicator %q", name, ind.Name))
+			}
+			switch ind.Transform.Type {
+			case TransformLinear, TransformInverse, TransformLog:
+			default:
+				problems = append(problems, fmt.Sprintf("%s.%s: unknown transform %q", name, ind.Name, ind.Transform.Type))
+			}
+			if ind.Transform.Max <= ind.Transform.Min {
+				problems = append(problems, fmt.Sprintf("%s.%s: transform max must exceed min", name, ind.Name))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// DimensionNames returns the model's dimensions in a stable order
+func (m *ScoringModel) DimensionNames() []string {
+	names := make([]string, 0, len(m.Dimensions))
+	for name := range m.Dimensions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply normalizes v
+func (t Transform) Apply(v float64) float64 {
+	span := t.Max - t.Min
+	if span == 0 {
+		return 0
+	}
+
+	var out float64
+	switch t.Type {
+	case TransformInverse:
+		out = (t.Max - v) / span
+	case TransformLog:
+		out = math.Log1p(math.Max(0, v-t.Min)) / math.Log1p(span)
+	default:
+		out = (v - t.Min) / span
+	}
+	if t.Clamp {
+		out = math.Max(0, math.Min(1, out))
+	}
+	return out
+}
+
+// Raised reports whether the flag applies to v
+func (f IndicatorFlag) Raised(v float64) bool {
+	return (f.Above != nil && v > *f.Above) || (f.Below != nil && v < *f.Below)
+}
+
+// Contribution is what one indicator added to a dimension score
+type Contribution struct {
+	Indicator string  `json:"indicator"`
+	Label     string  `json:"label"`
+	Value     float64 `json:"value"`
+	Weighted  float64 `json:"weighted"`
+	// Flags are the labels of the indicator's raised flags
+	Flags []string `json:"flags,omitempty"`
+}
+
+// DimensionScore is the result of scoring one dimension
+type DimensionScore struct {
+	Score         float64
+	Trend         string
+	KeyFactors    []string
+	Contributions []Contribution
+	// Missing lists indicators the model uses that had no value
+	Missing []string
+}
+
+// Score evaluates the dimension against raw indicator values
+func (d DimensionModel) Score(values map[string]float64) DimensionScore {
+	var result DimensionScore
+	var flags []string
+	for _, ind := range d.Indicators {
+		v, ok := values[ind.Name]
+		if !ok {
+			result.Missing = append(result.Missing, ind.Name)
+			continue
+		}
+		weighted := ind.Transform.Apply(v) * ind.Weight
+		result.Score += weighted
+		contribution := Contribution{
+			Indicator: ind.Name,
+			Label:     ind.Label,
+			Value:     v,
+			Weighted:  weighted,
+		}
+		for _, flag := range ind.Flags {
+			if flag.Raised(v) {
+				contribution.Flags = append(contribution.Flags, flag.Label)
+				flags = append(flags, flag.Label)
+			}
+		}
+		result.Contributions = append(result.Contributions, contribution)
+	}
+	result.Score = math.Max(0, math.Min(1, result.Score))
+	result.Trend = d.Trend.Classify(result.Score)
+
+	// Key factors are the indicators that pushed the score up most, followed
+	// by any raised flags
+	ranked := append([]Contribution(nil), result.Contributions...)
+	sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Weighted > ranked[j].Weighted })
+	for i := 0; i < len(ranked) && i < 3; i++ {
+		if ranked[i].Weighted > 0 {
+			result.KeyFactors = append(result.KeyFactors, ranked[i].Label)
+		}
+	}
+	result.KeyFactors = append(result.KeyFactors, flags...)
+	return result
+}
+
+// Classify returns the trend label for score
+func (t TrendThresholds) Classify(score float64) string {
+	switch {
+	case score > t.Increasing:
+		return "increasing"
+	case score < t.Decreasing:
+		return "decreasing"
+	default:
+		return "stable"
+	}
+}
+
+// DefaultScoringModel reproduces the original fixed formulas with equal
+// dimension weights. It is seeded as version 1 of the default model.
+func DefaultScoringModel() *ScoringModel {
+	trend := TrendThresholds{Increasing: 0.7, Decreasing: 0.3}
+	at := func(v float64) *float64 { return &v }
+
+	return &ScoringModel{
+		EntityType:  DefaultEntityType,
+		Version:     1,
+		Name:        "Baseline",
+		Description: "Equal-weight baseline model for all entity types",
+		Dimensions: map[string]DimensionModel{
+			DimensionOperational: {
+				Weight: 0.2, Trend: trend, FallbackScore: 0.45,
+				Indicators: []IndicatorModel{
+					{Name: "cybersecurity_incidents", Label: "Cybersecurity incidents", Weight: 0.3,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 10},
+						Flags:     []IndicatorFlag{{Above: at(3), Label: "High cybersecurity risk"}}},
+					{Name: "supply_chain_risk", Label: "Supply chain disruptions", Weight: 0.25,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100}},
+					{Name: "system_downtime", Label: "System downtime", Weight: 0.25,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 10},
+						Flags:     []IndicatorFlag{{Above: at(2), Label: "Frequent system outages"}}},
+					{Name: "operational_efficiency", Label: "Operational efficiency", Weight: -0.2,
+						Transform: Transform{Type: TransformLinear, Min: 70, Max: 100}},
+				},
+			},
+			DimensionFinancial: {
+				Weight: 0.2, Trend: trend, FallbackScore: 0.55,
+				Indicators: []IndicatorModel{
+					{Name: "credit_score", Label: "Credit rating", Weight: 0.3,
+						Transform: Transform{Type: TransformInverse, Min: 300, Max: 800},
+						Flags:     []IndicatorFlag{{Below: at(600), Label: "Poor credit rating"}}},
+					{Name: "debt_to_equity", Label: "Debt-to-equity ratio", Weight: 0.3,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 2},
+						Flags:     []IndicatorFlag{{Above: at(1.5), Label: "High leverage"}}},
+					{Name: "market_volatility", Label: "Market volatility", Weight: 0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100}},
+					{Name: "revenue_growth", Label: "Revenue growth", Weight: -0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 1}},
+				},
+			},
+			DimensionReputational: {
+				Weight: 0.2, Trend: trend, FallbackScore: 0.40,
+				Indicators: []IndicatorModel{
+					{Name: "social_media_sentiment", Label: "Social media sentiment", Weight: 0.4,
+						Transform: Transform{Type: TransformInverse, Min: -1, Max: 1},
+						Flags:     []IndicatorFlag{{Below: at(0), Label: "Negative social media sentiment"}}},
+					{Name: "customer_reviews", Label: "Customer reviews", Weight: 0.3,
+						Transform: Transform{Type: TransformInverse, Min: 0, Max: 5},
+						Flags:     []IndicatorFlag{{Below: at(3.5), Label: "Poor customer satisfaction"}}},
+					{Name: "news_coverage", Label: "Media coverage", Weight: 0.3,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100}},
+				},
+			},
+			DimensionGeopolitical: {
+				Weight: 0.2, Trend: trend, FallbackScore: 0.60,
+				Indicators: []IndicatorModel{
+					{Name: "country_risk", Label: "Country risk rating", Weight: 0.3,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100}},
+					{Name: "political_stability", Label: "Political stability", Weight: 0.25,
+						Transform: Transform{Type: TransformInverse, Min: 0, Max: 10}},
+					{Name: "sanctions_exposure", Label: "Sanctions exposure", Weight: 0.25,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100},
+						Flags:     []IndicatorFlag{{Above: at(30), Label: "High sanctions risk"}}},
+					{Name: "conflict_zones", Label: "Regional conflicts", Weight: 0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 5},
+						Flags:     []IndicatorFlag{{Above: at(0), Label: "Regional conflict exposure"}}},
+				},
+			},
+			DimensionCompliance: {
+				Weight: 0.2, Trend: trend, FallbackScore: 0.35,
+				Indicators: []IndicatorModel{
+					{Name: "regulatory_fines", Label: "Regulatory fines", Weight: 0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 1000000, Clamp: true},
+						Flags:     []IndicatorFlag{{Above: at(100000), Label: "Significant regulatory fines"}}},
+					{Name: "compliance_score", Label: "Compliance score", Weight: 0.25,
+						Transform: Transform{Type: TransformInverse, Min: 0, Max: 100}},
+					{Name: "audit_findings", Label: "Audit findings", Weight: 0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 20},
+						Flags:     []IndicatorFlag{{Above: at(3), Label: "Multiple audit findings"}}},
+					{Name: "data_privacy_risk", Label: "Data privacy risk", Weight: 0.2,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 100}},
+					{Name: "legal_actions", Label: "Legal actions", Weight: 0.15,
+						Transform: Transform{Type: TransformLinear, Min: 0, Max: 10}},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultModelMatchesLegacyFinancialFormula(t *testing.T) {
+	values := map[string]float64{
+		"credit_score":      550,
+		"debt_to_equity":    1.8,
+		"revenue_growth":    -0.15,
+		"profit_margin":     0.1,
+		"market_volatility": 0.3,
+	}
+
+	legacy := (800-550)/500.0*0.3 + 1.8/2.0*0.3 + 0.3/100.0*0.2 - (-0.15)*0.2
+	legacy = math.Max(0, math.Min(1, legacy))
+
+	result := DefaultScoringModel().Dimensions[DimensionFinancial].Score(values)
+
+	assert.InDelta(t, legacy, result.Score, 1e-9)
+	assert.Contains(t, result.KeyFactors, "Poor credit rating")
+	assert.Contains(t, result.KeyFactors, "High leverage")
+	assert.Empty(t, result.Missing)
+}
+
+func TestDimensionScoreReportsMissingIndicators(t *testing.T) {
+	result := DefaultScoringModel().Dimensions[DimensionCompliance].Score(map[string]float64{
+		"compliance_score": 40,
+	})
+
+	assert.InDelta(t, 0.6*0.25, result.Score, 1e-9)
+	assert.ElementsMatch(t, []string{"regulatory_fines", "audit_findings", "data_privacy_risk", "legal_actions"}, result.Missing)
+}
+
+func TestTransformApply(t *testing.T) {
+	assert.InDelta(t, 0.25, Transform{Type: TransformLinear, Min: 0, Max: 4}.Apply(1), 1e-9)
+	assert.InDelta(t, 0.75, Transform{Type: TransformInverse, Min: 0, Max: 4}.Apply(1), 1e-9)
+	assert.InDelta(t, 1.0, Transform{Type: TransformLog, Min: 0, Max: 9}.Apply(9), 1e-9)
+	assert.InDelta(t, 1.5, Transform{Type: TransformLinear, Min: 0, Max: 2}.Apply(3), 1e-9)
+	assert.InDelta(t, 1.0, Transform{Type: TransformLinear, Min: 0, Max: 2, Clamp: true}.Apply(3), 1e-9)
+}
+
+func TestScoringModelValidate(t *testing.T) {
+	assert.Empty(t, DefaultScoringModel().Validate())
+
+	model := &ScoringModel{Dimensions: map[string]DimensionModel{
+		"weather": {Weight: 1, Indicators: []IndicatorModel{{Name: "rain"}}},
+		DimensionFinancial: {
+			Weight: 0,
+			Indicators: []IndicatorModel{
+				{Name: "credit_score", Transform: Transform{Type: "sigmoid", Min: 1, Max: 1}},
+			},
+		},
+	}}
+
+	assert.Equal(t, []string{
+		"financial.credit_score: transform max must exceed min",
+		"financial.credit_score: unknown transform \"sigmoid\"",
+		"financial: weight must be positive",
+		"unknown dimension \"weather\"",
+	}, model.Validate())
+}
@@ -0,0 +1,106 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// IndicatorOverrides replace provider indicator values in a simulation,
+// keyed by dimension and then indicator, e.g.
+// {"geopolitical": {"country_risk": 0.9}}
+type IndicatorOverrides map[string]map[string]float64
+
+// Validate checks that every override names a known dimension and one of
+// its indicators
+func (o IndicatorOverrides) Validate() []string {
+	var problems []string
+	if len(o) == 0 {
+		problems = append(problems, "at least one override is required")
+	}
+	for dimension, values := range o {
+		known, ok := DimensionIndicators[dimension]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown dimension %q", dimension))
+			continue
+		}
+		if len(values) == 0 {
+			problems = append(problems, fmt.Sprintf("%s: at least one indicator is required", dimension))
+		}
+		for indicator, v := range values {
+			if !contains(known, indicator) {
+				problems = append(problems, fmt.Sprintf("%s: unknown indicator %q", dimension, indicator))
+			}
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				problems = append(problems, fmt.Sprintf("%s.%s: value must be a finite number", dimension, indicator))
+			}
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// SimulateRiskRequest scores an entity as if some indicators had the given
+// values. Dimensions defaults to every dimension the model scores.
+type SimulateRiskRequest struct {
+	EntityID       string             `json:"entity_id" binding:"required"`
+	EntityType     string             `json:"entity_type" binding:"required"`
+	Dimensions     []string           `json:"dimensions"`
+	Overrides      IndicatorOverrides `json:"overrides" binding:"required"`
+	IncludeFactors bool               `json:"include_factors"`
+}
+
+// RiskSimulation compares a what-if assessment with the entity's current
+// one. Simulations are never stored, raise no alerts and publish no events.
+type RiskSimulation struct {
+	EntityID   string             `json:"entity_id"`
+	EntityType string             `json:"entity_type"`
+	Overrides  IndicatorOverrides `json:"overrides"`
+	// Baseline is scored with the simulation, by the same model and from
+	// the same provider data, without the overrides. Deltas are measured
+	// against it.
+	Baseline *RiskAssessment `json:"baseline"`
+	// Latest is the entity's latest stored assessment, for reference only.
+	// It may predate the current model or provider data.
+	Latest *RiskAssessment `json:"latest,omitempty"`
+	// Simulated has no ID; it is not an official assessment
+	Simulated    *RiskAssessment           `json:"simulated"`
+	OverallDelta float64                   `json:"overall_delta"`
+	Deltas       map[string]DimensionDelta `json:"deltas"`
+}
+
+// DimensionDelta is how far a simulation moved one dimension's score
+type DimensionDelta struct {
+	Baseline  float64 `json:"baseline"`
+	Simulated float64 `json:"simulated"`
+	Delta     float64 `json:"delta"`
+	// Overridden lists the dimension's indicators the simulation replaced
+	Overridden []string `json:"overridden,omitempty"`
+}
+
+// CompareAssessments returns the change in the overall score and in each
+// dimension scored by both assessments
+func CompareAssessments(baseline, simulated *RiskAssessment, overrides IndicatorOverrides) (float64, map[string]DimensionDelta) {
+	deltas := make(map[string]DimensionDelta)
+	for name, dim := range simulated.Dimensions {
+		base, ok := baseline.Dimensions[name]
+		if !ok {
+			continue
+		}
+		delta := DimensionDelta{
+			Baseline:  base.Score,
+			Simulated: dim.Score,
+			Delta:     roundDelta(dim.Score - base.Score),
+		}
+		for indicator := range overrides[name] {
+			delta.Overridden = append(delta.Overridden, indicator)
+		}
+		sort.Strings(delta.Overridden)
+		deltas[name] = delta
+	}
+	return roundDelta(simulated.OverallScore - baseline.OverallScore), deltas
+}
+
+func roundDelta(v float64) float64 {
+	return math.Round(v*100) / 100
+}
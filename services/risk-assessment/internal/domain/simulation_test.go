@@ -0,0 +1,49 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndicatorOverridesValidate(t *testing.T) {
+	assert.Empty(t, IndicatorOverrides{DimensionGeopolitical: {"country_risk": 90}}.Validate())
+
+	assert.Equal(t, []string{"at least one override is required"}, IndicatorOverrides{}.Validate())
+	assert.Equal(t, []string{
+		"financial.credit_score: value must be a finite number",
+		`financial: unknown indicator "country_risk"`,
+		`unknown dimension "weather"`,
+	}, IndicatorOverrides{
+		"weather":          {"rainfall": 1},
+		DimensionFinancial: {"country_risk": 1, "credit_score": math.NaN()},
+	}.Validate())
+}
+
+func TestCompareAssessments(t *testing.T) {
+	baseline := &RiskAssessment{
+		OverallScore: 0.42,
+		Dimensions: map[string]RiskDimension{
+			DimensionFinancial:  {Score: 0.3},
+			DimensionCompliance: {Score: 0.5},
+		},
+	}
+	simulated := &RiskAssessment{
+		OverallScore: 0.55,
+		Dimensions: map[string]RiskDimension{
+			DimensionFinancial:    {Score: 0.71},
+			DimensionCompliance:   {Score: 0.5},
+			DimensionGeopolitical: {Score: 0.9},
+		},
+	}
+
+	overall, deltas := CompareAssessments(baseline, simulated, IndicatorOverrides{
+		DimensionFinancial: {"debt_to_equity": 2, "credit_score": 300},
+	})
+	assert.Equal(t, 0.13, overall)
+	assert.Equal(t, map[string]DimensionDelta{
+		DimensionFinancial:  {Baseline: 0.3, Simulated: 0.71, Delta: 0.41, Overridden: []string{"credit_score", "debt_to_equity"}},
+		DimensionCompliance: {Baseline: 0.5, Simulated: 0.5, Delta: 0},
+	}, deltas)
+}
@@ -0,0 +1,365 @@
+package models
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Trend buckets group assessments by UTC day or by ISO week (Monday first)
+const (
+	TrendBucketDaily  = "daily"
+	TrendBucketWeekly = "weekly"
+)
+
+const (
+	// MaxForecastDays bounds how far ahead a trend is projected
+	MaxForecastDays = 90
+	// stableChange is the smallest fitted move across a series that counts
+	// as increasing or decreasing
+	stableChange = 0.05
+	// minShift is the smallest change in level reported as a change point
+	minShift = 0.05
+	// z95 scales the residual error to a 95% prediction interval
+	z95 = 1.96
+)
+
+// TrendPoint is one assessed score in a series
+type TrendPoint struct {
+	Timestamp time.Time `json:"timestamp"`
+	Score     float64   `json:"score"`
+}
+
+// TrendBucket summarises the scores assessed in one day or week
+type TrendBucket struct {
+	Start time.Time `json:"start"`
+	Mean  float64   `json:"mean"`
+	Min   float64   `json:"min"`
+	Max   float64   `json:"max"`
+	Count int       `json:"count"`
+	// MovingAverage is the trailing mean of bucket means, including this one
+	MovingAverage float64 `json:"moving_average"`
+}
+
+// ChangePoint is where the level of a series shifts: the mean of the
+// buckets before At differs from the mean from At onwards
+type ChangePoint struct {
+	At     time.Time `json:"at"`
+	Before float64   `json:"before"`
+	After  float64   `json:"after"`
+}
+
+// ForecastPoint is a projected score with a 95% prediction interval
+type ForecastPoint struct {
+	Date  time.Time `json:"date"`
+	Score float64   `json:"score"`
+	Lower float64   `json:"lower"`
+	Upper float64   `json:"upper"`
+}
+
+// TrendAnalysis describes how one score series moved over a period
+type TrendAnalysis struct {
+	Points int `json:"points"`
+	// Direction is "increasing", "decreasing" or "stable"
+	Direction   string  `json:"direction"`
+	SlopePerDay float64 `json:"slope_per_day"`
+	// Volatility is the standard deviation of bucket-to-bucket changes
+	Volatility   float64         `json:"volatility"`
+	Buckets      []TrendBucket   `json:"buckets"`
+	ChangePoints []ChangePoint   `json:"change_points"`
+	Forecast     []ForecastPoint `json:"forecast,omitempty"`
+}
+
+// TrendOptions controls how a series is analysed
+type TrendOptions struct {
+	Bucket string
+	// MovingAverageWindow is in buckets; zero means 7 daily or 4 weekly
+	MovingAverageWindow int
+	ForecastDays        int
+}
+
+// RiskTrends is the trend analysis of an entity's assessments over a period
+type RiskTrends struct {
+	EntityID   string                    `json:"entity_id"`
+	Period     string                    `json:"period"`
+	Bucket     string                    `json:"bucket"`
+	From       time.Time                 `json:"from"`
+	To         time.Time                 `json:"to"`
+	Overall    *TrendAnalysis            `json:"overall"`
+	Dimensions map[string]*TrendAnalysis `json:"dimensions"`
+}
+
+// AnalyzeTrend buckets points and fits a linear trend to them. The slope
+// and forecast come from the individual points; moving averages,
+// volatility and change points from the bucket means.
+func AnalyzeTrend(points []TrendPoint, opts TrendOptions) *TrendAnalysis {
+	sorted := append([]TrendPoint(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+
+	analysis := &TrendAnalysis{
+		Points:       len(sorted),
+		Direction:    "stable",
+		Buckets:      bucketPoints(sorted, opts.Bucket, opts.MovingAverageWindow),
+		ChangePoints: []ChangePoint{},
+	}
+	if len(sorted) == 0 {
+		return analysis
+	}
+
+	fit := fitLine(sorted)
+	analysis.SlopePerDay = round4(fit.slope)
+	analysis.Direction = fit.direction()
+	analysis.Volatility = round4(bucketVolatility(analysis.Buckets))
+	analysis.ChangePoints = changePoints(analysis.Buckets)
+	if opts.ForecastDays > 0 && fit.n >= 3 {
+		analysis.Forecast = fit.forecast(sorted[len(sorted)-1].Timestamp, opts.ForecastDays)
+	}
+	return analysis
+}
+
+// TrendDirection labels a series of points in time order "increasing",
+// "decreasing" or "stable" from its fitted slope
+func TrendDirection(points []TrendPoint) string {
+	if len(points) < 2 {
+		return "stable"
+	}
+	return fitLine(points).direction()
+}
+
+// bucketStart returns the start of the day or week containing t
+func bucketStart(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	if bucket != TrendBucketWeekly {
+		return day
+	}
+	// Weekday counts from Sunday; weeks start on Monday
+	return day.AddDate(0, 0, -(int(day.Weekday())+6)%7)
+}
+
+// bucketPoints groups sorted points into buckets; empty buckets are left out
+func bucketPoints(points []TrendPoint, bucket string, window int) []TrendBucket {
+	if window <= 0 {
+		window = 7
+		if bucket == TrendBucketWeekly {
+			window = 4
+		}
+	}
+
+	buckets := make([]TrendBucket, 0)
+	var sum float64
+	for _, p := range points {
+		start := bucketStart(p.Timestamp, bucket)
+		if n := len(buckets); n == 0 || !buckets[n-1].Start.Equal(start) {
+			if n > 0 {
+				buckets[n-1].Mean = sum / float64(buckets[n-1].Count)
+			}
+			buckets = append(buckets, TrendBucket{Start: start, Min: p.Score, Max: p.Score})
+			sum = 0
+		}
+		b := &buckets[len(buckets)-1]
+		b.Count++
+		sum += p.Score
+		b.Min = math.Min(b.Min, p.Score)
+		b.Max = math.Max(b.Max, p.Score)
+	}
+	if n := len(buckets); n > 0 {
+		buckets[n-1].Mean = sum / float64(buckets[n-1].Count)
+	}
+
+	for i := range buckets {
+		var total float64
+		from := i - window + 1
+		if from < 0 {
+			from = 0
+		}
+		for _, b := range buckets[from : i+1] {
+			total += b.Mean
+		}
+		buckets[i].MovingAverage = round4(total / float64(i+1-from))
+		buckets[i].Mean = round4(buckets[i].Mean)
+	}
+	return buckets
+}
+
+func bucketVolatility(buckets []TrendBucket) float64 {
+	if len(buckets) < 2 {
+		return 0
+	}
+	changes := make([]float64, len(buckets)-1)
+	for i := 1; i < len(buckets); i++ {
+		changes[i-1] = buckets[i].Mean - buckets[i-1].Mean
+	}
+	return stdDev(changes)
+}
+
+// changePoints finds level shifts by binary segmentation: a segment is
+// split where the means either side differ most, if they differ by at least
+// minShift and by more than twice the spread within the two sides. Most of
+// the shift must happen at the split itself, so a steady ramp is not
+// reported as a series of steps.
+func changePoints(buckets []TrendBucket) []ChangePoint {
+	means := make([]float64, len(buckets))
+	for i, b := range buckets {
+		means[i] = b.Mean
+	}
+
+	var splits []int
+	var segment func(lo, hi int)
+	segment = func(lo, hi int) {
+		// Each side needs at least two buckets
+		best, bestShift := -1, 0.0
+		for i := lo + 2; i <= hi-2; i++ {
+			shift := math.Abs(mean(means[i:hi]) - mean(means[lo:i]))
+			if shift > bestShift {
+				best, bestShift = i, shift
+			}
+		}
+		if best < 0 || bestShift < minShift {
+			return
+		}
+		spread := math.Max(stdDev(means[lo:best]), stdDev(means[best:hi]))
+		jump := math.Abs(means[best] - means[best-1])
+		if bestShift <= 2*spread || jump <= bestShift/2 {
+			return
+		}
+		segment(lo, best)
+		splits = append(splits, best)
+		segment(best, hi)
+	}
+	segment(0, len(means))
+
+	points := make([]ChangePoint, 0, len(splits))
+	for k, i := range splits {
+		lo, hi := 0, len(means)
+		if k > 0 {
+			lo = splits[k-1]
+		}
+		if k+1 < len(splits) {
+			hi = splits[k+1]
+		}
+		points = append(points, ChangePoint{
+			At:     buckets[i].Start,
+			Before: round4(mean(means[lo:i])),
+			After:  round4(mean(means[i:hi])),
+		})
+	}
+	return points
+}
+
+// lineFit is an ordinary least squares fit of score against days since
+// origin
+type lineFit struct {
+	origin           time.Time
+	n                int
+	spanDays         float64
+	slope, intercept float64
+	meanX, sxx       float64
+	// residualSE is the standard error of the residuals
+	residualSE float64
+}
+
+func fitLine(points []TrendPoint) lineFit {
+	fit := lineFit{origin: points[0].Timestamp, n: len(points)}
+	xs := make([]float64, len(points))
+	var sumY float64
+	for i, p := range points {
+		xs[i] = p.Timestamp.Sub(fit.origin).Hours() / 24
+		fit.meanX += xs[i]
+		sumY += p.Score
+	}
+	fit.spanDays = xs[len(xs)-1] - xs[0]
+	fit.meanX /= float64(fit.n)
+	meanY := sumY / float64(fit.n)
+
+	var sxy float64
+	for i, p := range points {
+		dx := xs[i] - fit.meanX
+		fit.sxx += dx * dx
+		sxy += dx * (p.Score - meanY)
+	}
+	if fit.sxx > 0 {
+		fit.slope = sxy / fit.sxx
+	}
+	fit.intercept = meanY - fit.slope*fit.meanX
+
+	if fit.n > 2 {
+		var sse float64
+		for i, p := range points {
+			r := p.Score - (fit.intercept + fit.slope*xs[i])
+			sse += r * r
+		}
+		fit.residualSE = math.Sqrt(sse / float64(fit.n-2))
+	}
+	return fit
+}
+
+// direction labels the fit by how far the line moves across the span of
+// the points
+func (f lineFit) direction() string {
+	change := f.slope * f.spanDays
+	switch {
+	case change >= stableChange:
+		return "increasing"
+	case change <= -stableChange:
+		return "decreasing"
+	default:
+		return "stable"
+	}
+}
+
+// forecast projects the fit one point per day after last, widening the
+// interval with distance from the observed data
+func (f lineFit) forecast(last time.Time, days int) []ForecastPoint {
+	if days > MaxForecastDays {
+		days = MaxForecastDays
+	}
+	day := bucketStart(last, TrendBucketDaily)
+	points := make([]ForecastPoint, days)
+	for i := range points {
+		date := day.AddDate(0, 0, i+1)
+		x := date.Sub(f.origin).Hours() / 24
+		score := f.intercept + f.slope*x
+
+		leverage := 1 + 1/float64(f.n)
+		if f.sxx > 0 {
+			leverage += (x - f.meanX) * (x - f.meanX) / f.sxx
+		}
+		margin := z95 * f.residualSE * math.Sqrt(leverage)
+		points[i] = ForecastPoint{
+			Date:  date,
+			Score: round4(clamp01(score)),
+			Lower: round4(clamp01(score - margin)),
+			Upper: round4(clamp01(score + margin)),
+		}
+	}
+	return points
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev is the population standard deviation
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	m := mean(values)
+	var ss float64
+	for _, v := range values {
+		ss += (v - m) * (v - m)
+	}
+	return math.Sqrt(ss / float64(len(values)))
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
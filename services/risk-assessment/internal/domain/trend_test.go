@@ -0,0 +1,94 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// daily returns one point per day from start with the given scores
+func daily(start time.Time, scores ...float64) []TrendPoint {
+	points := make([]TrendPoint, len(scores))
+	for i, score := range scores {
+		points[i] = TrendPoint{Timestamp: start.AddDate(0, 0, i), Score: score}
+	}
+	return points
+}
+
+func TestAnalyzeTrendBuckets(t *testing.T) {
+	// Wednesday 5 June 2024
+	start := time.Date(2024, 6, 5, 9, 0, 0, 0, time.UTC)
+	points := daily(start, 0.2, 0.4, 0.6, 0.8, 0.4, 0.4)
+	points = append(points, TrendPoint{Timestamp: start.Add(3 * time.Hour), Score: 0.4})
+
+	analysis := AnalyzeTrend(points, TrendOptions{Bucket: TrendBucketWeekly})
+	require.Len(t, analysis.Buckets, 2)
+	assert.Equal(t, time.Date(2024, 6, 3, 0, 0, 0, 0, time.UTC), analysis.Buckets[0].Start)
+	assert.Equal(t, 6, analysis.Buckets[0].Count)
+	assert.InDelta(t, 0.4667, analysis.Buckets[0].Mean, 1e-4)
+	assert.Equal(t, 0.2, analysis.Buckets[0].Min)
+	assert.Equal(t, 0.8, analysis.Buckets[0].Max)
+	assert.Equal(t, 1, analysis.Buckets[1].Count)
+	assert.InDelta(t, 0.4333, analysis.Buckets[1].MovingAverage, 1e-4)
+
+	analysis = AnalyzeTrend(points, TrendOptions{Bucket: TrendBucketDaily, MovingAverageWindow: 2})
+	require.Len(t, analysis.Buckets, 6)
+	assert.InDelta(t, 0.3, analysis.Buckets[0].Mean, 1e-9)
+	assert.InDelta(t, 0.35, analysis.Buckets[1].MovingAverage, 1e-9)
+	assert.Equal(t, 7, analysis.Points)
+}
+
+func TestAnalyzeTrendSlopeAndForecast(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	rising := AnalyzeTrend(daily(start, 0.30, 0.31, 0.33, 0.32, 0.34, 0.36, 0.35, 0.37), TrendOptions{ForecastDays: 5})
+	assert.Equal(t, "increasing", rising.Direction)
+	assert.InDelta(t, 0.01, rising.SlopePerDay, 0.002)
+	assert.Empty(t, rising.ChangePoints)
+
+	require.Len(t, rising.Forecast, 5)
+	assert.Equal(t, time.Date(2024, 6, 9, 0, 0, 0, 0, time.UTC), rising.Forecast[0].Date)
+	for i, f := range rising.Forecast {
+		assert.True(t, f.Lower <= f.Score && f.Score <= f.Upper)
+		if i > 0 {
+			assert.Greater(t, f.Score, rising.Forecast[i-1].Score)
+			assert.Greater(t, f.Upper-f.Lower, rising.Forecast[i-1].Upper-rising.Forecast[i-1].Lower)
+		}
+	}
+
+	flat := AnalyzeTrend(daily(start, 0.5, 0.51, 0.49, 0.5), TrendOptions{})
+	assert.Equal(t, "stable", flat.Direction)
+	assert.Nil(t, flat.Forecast)
+
+	// Forecasts stay within the score range
+	steep := AnalyzeTrend(daily(start, 0.7, 0.8, 0.9, 0.95), TrendOptions{ForecastDays: 30})
+	assert.Equal(t, 1.0, steep.Forecast[29].Score)
+	assert.Equal(t, 1.0, steep.Forecast[29].Upper)
+
+	empty := AnalyzeTrend(nil, TrendOptions{ForecastDays: 7})
+	assert.Equal(t, "stable", empty.Direction)
+	assert.Empty(t, empty.Buckets)
+}
+
+func TestAnalyzeTrendChangePoints(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	step := AnalyzeTrend(daily(start, 0.30, 0.31, 0.29, 0.30, 0.60, 0.61, 0.59, 0.60), TrendOptions{})
+	require.Len(t, step.ChangePoints, 1)
+	assert.Equal(t, start.AddDate(0, 0, 4), step.ChangePoints[0].At)
+	assert.InDelta(t, 0.30, step.ChangePoints[0].Before, 1e-9)
+	assert.InDelta(t, 0.60, step.ChangePoints[0].After, 1e-9)
+	assert.Greater(t, step.Volatility, 0.0)
+
+	ramp := AnalyzeTrend(daily(start, 0.1, 0.15, 0.2, 0.25, 0.3, 0.35, 0.4, 0.45, 0.5, 0.55), TrendOptions{})
+	assert.Empty(t, ramp.ChangePoints)
+	assert.Zero(t, ramp.Volatility)
+}
+
+func TestTrendDirection(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "stable", TrendDirection(daily(start, 0.9)))
+	assert.Equal(t, "decreasing", TrendDirection(daily(start, 0.6, 0.5, 0.4)))
+}
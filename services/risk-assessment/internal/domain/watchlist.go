@@ -0,0 +1,76 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// DefaultChangeThreshold is how far a score must move before a scheduled
+// reassessment is reported as a change
+const DefaultChangeThreshold = 0.05
+
+// WatchedEntity is an entity reassessed on a schedule. Its last scores are
+// kept so only meaningful changes are reported.
+type WatchedEntity struct {
+	ID         string   `json:"id"`
+	EntityID   string   `json:"entity_id"`
+	EntityType string   `json:"entity_type"`
+	Dimensions []string `json:"dimensions,omitempty"`
+	// Schedule is an interval or cron expression, see ParseSchedule
+	Schedule        string  `json:"schedule"`
+	ChangeThreshold float64 `json:"change_threshold"`
+	Active          bool    `json:"active"`
+
+	NextRunAt           time.Time          `json:"next_run_at"`
+	LastRunAt           *time.Time         `json:"last_run_at,omitempty"`
+	LastAssessmentID    string             `json:"last_assessment_id,omitempty"`
+	LastScore           *float64           `json:"last_score,omitempty"`
+	LastDimensionScores map[string]float64 `json:"last_dimension_scores,omitempty"`
+	LastError           string             `json:"last_error,omitempty"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WatchRequest adds an entity to the watchlist
+type WatchRequest struct {
+	EntityID        string   `json:"entity_id" binding:"required"`
+	EntityType      string   `json:"entity_type" binding:"required"`
+	Dimensions      []string `json:"dimensions"`
+	Schedule        string   `json:"schedule" binding:"required"`
+	ChangeThreshold float64  `json:"change_threshold"`
+}
+
+// ScoreMoved reports whether an assessment differs from the entity's last
+// scores by at least the change threshold, overall or in any dimension. The
+// first assessment of an entity always counts as a change.
+func (w *WatchedEntity) ScoreMoved(assessment *RiskAssessment) bool {
+	if w.LastScore == nil {
+		return true
+	}
+	if math.Abs(assessment.OverallScore-*w.LastScore) >= w.ChangeThreshold {
+		return true
+	}
+	for name, dim := range assessment.Dimensions {
+		last, ok := w.LastDimensionScores[name]
+		if !ok || math.Abs(dim.Score-last) >= w.ChangeThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// PlanNextRun returns when the entity is next due after a run at ranAt: the
+// schedule's next time, brought forward to margin before the assessment
+// expires if that is sooner
+func PlanNextRun(schedule Schedule, ranAt, validUntil time.Time, margin time.Duration) time.Time {
+	next := schedule.Next(ranAt)
+	if !validUntil.IsZero() {
+		refresh := validUntil.Add(-margin)
+		if refresh.After(ranAt) && (next.IsZero() || refresh.Before(next)) {
+			next = refresh
+		}
+	}
+	return next
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	// A Saturday
+	from := time.Date(2024, 6, 1, 10, 17, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec string
+		want time.Time
+	}{
+		{"6h", from.Add(6 * time.Hour)},
+		{"@every 90m", from.Add(90 * time.Minute)},
+		{"@hourly", time.Date(2024, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 6, 1, 10, 30, 0, 0, time.UTC)},
+		{"0 9 * * 1-5", time.Date(2024, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"30 2 1 * *", time.Date(2024, 7, 1, 2, 30, 0, 0, time.UTC)},
+		// Day of month or day of week: the 15th or a Sunday
+		{"0 0 15 * 7", time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 1,7 *", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(from))
+		})
+	}
+
+	for _, spec := range []string{"", "30s", "* * * *", "60 * * * *", "* * * * 8", "*/0 * * * *", "5-1 * * * *", "daily"} {
+		_, err := ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+
+	never, err := ParseSchedule("0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, never.Next(from).IsZero())
+}
+
+func TestScoreMoved(t *testing.T) {
+	last := 0.40
+	watch := &WatchedEntity{
+		ChangeThreshold:     0.05,
+		LastScore:           &last,
+		LastDimensionScores: map[string]float64{DimensionFinancial: 0.40, DimensionCompliance: 0.30},
+	}
+	assessment := func(overall, financial, compliance float64) *RiskAssessment {
+		return &RiskAssessment{
+			OverallScore: overall,
+			Dimensions: map[string]RiskDimension{
+				DimensionFinancial:  {Score: financial},
+				DimensionCompliance: {Score: compliance},
+			},
+		}
+	}
+
+	assert.False(t, watch.ScoreMoved(assessment(0.42, 0.43, 0.31)))
+	assert.True(t, watch.ScoreMoved(assessment(0.46, 0.43, 0.31)))
+	assert.True(t, watch.ScoreMoved(assessment(0.42, 0.43, 0.36)))
+	assert.True(t, (&WatchedEntity{ChangeThreshold: 0.05}).ScoreMoved(assessment(0.42, 0.43, 0.31)))
+}
+
+func TestPlanNextRun(t *testing.T) {
+	ranAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	daily := intervalSchedule(24 * time.Hour)
+
+	assert.Equal(t, ranAt.Add(24*time.Hour), PlanNextRun(daily, ranAt, ranAt.Add(48*time.Hour), time.Hour))
+	// The assessment expires first, so it is refreshed an hour before
+	assert.Equal(t, ranAt.Add(11*time.Hour), PlanNextRun(daily, ranAt, ranAt.Add(12*time.Hour), time.Hour))
+	// A margin reaching back past the run is ignored
+	assert.Equal(t, ranAt.Add(24*time.Hour), PlanNextRun(daily, ranAt, ranAt.Add(30*time.Minute), time.Hour))
+}
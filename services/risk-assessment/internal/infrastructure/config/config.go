@@ -3,17 +3,44 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
+
+	"atlas-core-api/pkg/servicetoken"
 )
 
 type Config struct {
 	Environment string
 	Port        int
-	JWTSecret   string
 	LogLevel    string
 
+	// DatabaseURL selects the Postgres store; assessments are kept in memory
+	// when it is empty
+	DatabaseURL string
+
 	// External Data Provider Configuration
 	DataProvider DataProviderConfig
+	// AssessmentBudget bounds how long one assessment waits for providers
+	AssessmentBudget time.Duration
+	// JobWorkers is how many batch job assessments run at once
+	JobWorkers   int
+	KafkaBrokers []string
+
+	// SchedulerEnabled runs scheduled reassessment of watched entities
+	SchedulerEnabled bool
+	SchedulerTick    time.Duration
+	// ReassessMargin is how long before an assessment expires a watched
+	// entity is reassessed
+	ReassessMargin time.Duration
+
+	// Notifications delivers alert notifications
+	Notifications NotificationConfig
+
+	// Service-to-service authentication
+	ServiceName                   string
+	TrustDomain                   string
+	ServiceTokenPublicKey         string
+	ServiceTokenPreviousPublicKey string
 }
 
 type DataProviderConfig struct {
@@ -25,30 +52,148 @@ type DataProviderConfig struct {
 	ComplianceBaseURL   string
 	NewsAPIKey          string
 	NewsBaseURL         string
+	OperationalAPIKey   string
+	OperationalBaseURL  string
 	Timeout             time.Duration
+
+	// CSVDir holds {dimension}.csv files for the csv provider
+	CSVDir string
+	// SanctionsURL is the internal sanctions-screening service
+	SanctionsURL     string
+	SanctionsTimeout time.Duration
+	// MaxConcurrent bounds in-flight calls to each remote provider
+	MaxConcurrent int
+
+	// CacheTTL is how long a provider response is reused; zero disables
+	// caching
+	CacheTTL time.Duration
+
+	// Chains lists, per dimension, the providers tried in order: http, csv,
+	// sanctions or fixture
+	Chains map[string][]string
+}
+
+// NotificationConfig selects how alert notifications are delivered: live
+// sends email over SMTP and posts to webhook and Slack URLs, log only logs
+// them and is refused in production
+type NotificationConfig struct {
+	Driver       string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	From         string
+	HTTPTimeout  time.Duration
+	Tick         time.Duration
+
+	DedupWindow     time.Duration
+	DigestWindow    time.Duration
+	DigestThreshold int
+	MaxAttempts     int
+	RetryBase       time.Duration
 }
 
 func Load() *Config {
 	port, _ := strconv.Atoi(getEnv("PORT", "8082"))
-	timeout, _ := time.ParseDuration(getEnv("API_TIMEOUT", "30s"))
+	timeout, _ := time.ParseDuration(getEnv("API_TIMEOUT", "5s"))
+	sanctionsTimeout, _ := time.ParseDuration(getEnv("SANCTIONS_TIMEOUT", "3s"))
+	cacheTTL, _ := time.ParseDuration(getEnv("PROVIDER_CACHE_TTL", "5m"))
+	budget, _ := time.ParseDuration(getEnv("ASSESSMENT_BUDGET", "10s"))
+	maxConcurrent, _ := strconv.Atoi(getEnv("PROVIDER_MAX_CONCURRENCY", "8"))
+	jobWorkers, _ := strconv.Atoi(getEnv("JOB_WORKERS", "4"))
+	schedulerEnabled, _ := strconv.ParseBool(getEnv("SCHEDULER_ENABLED", "true"))
+	schedulerTick := getEnvPositiveDuration("SCHEDULER_TICK", 30*time.Second)
+	reassessMargin, _ := time.ParseDuration(getEnv("REASSESS_MARGIN", "1h"))
+	smtpPort, _ := strconv.Atoi(getEnv("SMTP_PORT", "587"))
+	notifyTimeout, _ := time.ParseDuration(getEnv("NOTIFY_HTTP_TIMEOUT", "10s"))
+	notifyTick := getEnvPositiveDuration("NOTIFY_TICK", 10*time.Second)
+	dedupWindow, _ := time.ParseDuration(getEnv("NOTIFY_DEDUP_WINDOW", "10m"))
+	digestWindow, _ := time.ParseDuration(getEnv("NOTIFY_DIGEST_WINDOW", "5m"))
+	digestThreshold, _ := strconv.Atoi(getEnv("NOTIFY_DIGEST_THRESHOLD", "5"))
+	maxAttempts, _ := strconv.Atoi(getEnv("NOTIFY_MAX_ATTEMPTS", "5"))
+	retryBase, _ := time.ParseDuration(getEnv("NOTIFY_RETRY_BASE", "30s"))
+
+	apiKeys := map[string]string{
+		"financial":    getEnv("FINANCIAL_API_KEY", ""),
+		"geopolitical": getEnv("GEOPOLITICAL_API_KEY", ""),
+		"compliance":   getEnv("COMPLIANCE_API_KEY", ""),
+		"reputational": getEnv("NEWS_API_KEY", ""),
+		"operational":  getEnv("OPERATIONAL_API_KEY", ""),
+	}
 
 	return &Config{
 		Environment: getEnv("ENVIRONMENT", "development"),
 		Port:        port,
-		JWTSecret:   getEnv("JWT_SECRET", "change-me-in-production"),
 		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		DatabaseURL: getEnv("DATABASE_URL", ""),
 		DataProvider: DataProviderConfig{
-			FinancialAPIKey:     getEnv("FINANCIAL_API_KEY", ""),
+			FinancialAPIKey:     apiKeys["financial"],
 			FinancialBaseURL:    getEnv("FINANCIAL_API_URL", "https://api.financialdata.com"),
-			GeopoliticalAPIKey:  getEnv("GEOPOLITICAL_API_KEY", ""),
+			GeopoliticalAPIKey:  apiKeys["geopolitical"],
 			GeopoliticalBaseURL: getEnv("GEOPOLITICAL_API_URL", "https://api.geopoliticaldata.com"),
-			ComplianceAPIKey:    getEnv("COMPLIANCE_API_KEY", ""),
+			ComplianceAPIKey:    apiKeys["compliance"],
 			ComplianceBaseURL:   getEnv("COMPLIANCE_API_URL", "https://api.compliancedata.com"),
-			NewsAPIKey:          getEnv("NEWS_API_KEY", ""),
+			NewsAPIKey:          apiKeys["reputational"],
 			NewsBaseURL:         getEnv("NEWS_API_URL", "https://api.newsdata.com"),
+			OperationalAPIKey:   apiKeys["operational"],
+			OperationalBaseURL:  getEnv("OPERATIONAL_API_URL", "https://api.operationaldata.com"),
 			Timeout:             timeout,
+			CSVDir:              getEnv("PROVIDER_CSV_DIR", "./data/providers"),
+			SanctionsURL:        getEnv("SANCTIONS_SERVICE_URL", "http://sanctions-screening:8000"),
+			SanctionsTimeout:    sanctionsTimeout,
+			MaxConcurrent:       maxConcurrent,
+			CacheTTL:            cacheTTL,
+			Chains:              providerChains(apiKeys),
+		},
+		AssessmentBudget:           budget,
+		JobWorkers:                 jobWorkers,
+		KafkaBrokers:               strings.Split(getEnv("KAFKA_BROKERS", "localhost:9092"), ","),
+		SchedulerEnabled:           schedulerEnabled,
+		SchedulerTick:              schedulerTick,
+		ReassessMargin:             reassessMargin,
+		Notifications: NotificationConfig{
+			Driver:          getEnv("NOTIFY_DRIVER", "log"),
+			SMTPHost:        getEnv("SMTP_HOST", "localhost"),
+			SMTPPort:        smtpPort,
+			SMTPUsername:    getEnv("SMTP_USERNAME", ""),
+			SMTPPassword:    getEnv("SMTP_PASSWORD", ""),
+			From:            getEnv("MAIL_FROM", "ATLAS <no-reply@atlas.local>"),
+			HTTPTimeout:     notifyTimeout,
+			Tick:            notifyTick,
+			DedupWindow:     dedupWindow,
+			DigestWindow:    digestWindow,
+			DigestThreshold: digestThreshold,
+			MaxAttempts:     maxAttempts,
+			RetryBase:       retryBase,
 		},
+		ServiceName:                   getEnv("SERVICE_NAME", "risk-assessment"),
+		TrustDomain:                   getEnv("SERVICE_TRUST_DOMAIN", "atlas.internal"),
+		ServiceTokenPublicKey:         getEnv("SERVICE_TOKEN_PUBLIC_KEY", servicetoken.DefaultPublicKey),
+		ServiceTokenPreviousPublicKey: getEnv("SERVICE_TOKEN_PREVIOUS_PUBLIC_KEY", ""),
+	}
+}
+
+// providerChains reads RISK_PROVIDERS_<DIMENSION> as a comma-separated
+// provider list. Without one, a dimension uses its HTTP feed when an API key
+// is configured and has no providers otherwise. The fixture provider is
+// never a default; it has to be listed explicitly.
+func providerChains(apiKeys map[string]string) map[string][]string {
+	chains := make(map[string][]string, len(apiKeys))
+	for dimension, apiKey := range apiKeys {
+		fallback := ""
+		if apiKey != "" {
+			fallback = "http"
+		}
+
+		var chain []string
+		for _, name := range strings.Split(getEnv("RISK_PROVIDERS_"+strings.ToUpper(dimension), fallback), ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				chain = append(chain, name)
+			}
+		}
+		chains[dimension] = chain
 	}
+	return chains
 }
 
 func getEnv(key, defaultValue string) string {
@@ -57,3 +202,15 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvPositiveDuration reads a duration that must be above zero, such as
+// a ticker interval, falling back to defaultValue when it is malformed or
+// not positive
+func getEnvPositiveDuration(key string, defaultValue time.Duration) time.Duration {
+	if value := os.Getenv(key); value != "" {
+		if parsed, err := time.ParseDuration(value); err == nil && parsed > 0 {
+			return parsed
+		}
+	}
+	return defaultValue
+}
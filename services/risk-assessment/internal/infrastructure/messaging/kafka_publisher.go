@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 
 	"go.uber.org/zap"
 
@@ -51,6 +52,7 @@ func (p *KafkaEventPublisher) Close() error {
 type InMemoryEventPublisher struct {
 	events []events.DomainEvent
 	logger *zap.Logger
+	mu     sync.Mutex
 }
 
 func NewInMemoryEventPublisher(logger *zap.Logger) *InMemoryEventPublisher {
@@ -61,7 +63,9 @@ func NewInMemoryEventPublisher(logger *zap.Logger) *InMemoryEventPublisher {
 }
 
 func (p *InMemoryEventPublisher) Publish(ctx context.Context, event events.DomainEvent) error {
+	p.mu.Lock()
 	p.events = append(p.events, event)
+	p.mu.Unlock()
 	p.logger.Debug("Risk event published (in-memory)",
 		zap.String("type", event.EventType()),
 		zap.String("aggregate_id", event.AggregateID()),
@@ -70,4 +74,8 @@ func (p *InMemoryEventPublisher) Publish(ctx context.Context, event events.Domai
 }
 
 func (p *InMemoryEventPublisher) Close() error          { return nil }
-func (p *InMemoryEventPublisher) Events() []events.DomainEvent { return p.events }
+func (p *InMemoryEventPublisher) Events() []events.DomainEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]events.DomainEvent(nil), p.events...)
+}
@@ -0,0 +1,179 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"atlas-core-api/pkg/safehttp"
+)
+
+// Message is a notification rendered for delivery
+type Message struct {
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	Priority  string `json:"priority"`
+	ActionURL string `json:"action_url,omitempty"`
+}
+
+// Sender delivers messages on one channel to a target: an email address
+// or a URL
+type Sender interface {
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// SMTPSender delivers email through an SMTP relay
+type SMTPSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func NewSMTPSender(host string, port int, username, password, from string) *SMTPSender {
+	return &SMTPSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+func (s *SMTPSender) Send(ctx context.Context, target string, msg Message) error {
+	// The target is written into the To header, so it must be a single
+	// address with no line breaks a caller could use to add headers
+	to, err := mail.ParseAddress(target)
+	if err != nil {
+		return fmt.Errorf("invalid email target: %w", err)
+	}
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+	body := buildEmail(s.from, to, msg, time.Now())
+
+	addr := net.JoinHostPort(s.host, strconv.Itoa(s.port))
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- smtp.SendMail(addr, auth, s.from, []string{to.Address}, body)
+	}()
+
+	select {
+	case err := <-errCh:
+		if err != nil {
+			return fmt.Errorf("smtp send failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// buildEmail renders msg as a plain text email. The subject is
+// Q-encoded, which also encodes any CR or LF in the title.
+func buildEmail(from string, to *mail.Address, msg Message, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", msg.Title))
+	fmt.Fprintf(&buf, "Date: %s\r\n", now.UTC().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n\r\n")
+	buf.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+	if msg.ActionURL != "" {
+		fmt.Fprintf(&buf, "\r\n\r\n%s\r\n", msg.ActionURL)
+	}
+	return buf.Bytes()
+}
+
+// WebhookSender posts the message as JSON to the target URL. Targets are
+// set by users, so the client refuses internal addresses and redirects.
+type WebhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(timeout time.Duration) *WebhookSender {
+	return &WebhookSender{client: safehttp.NewClient(safehttp.Config{Timeout: timeout})}
+}
+
+func (s *WebhookSender) Send(ctx context.Context, target string, msg Message) error {
+	return postJSON(ctx, s.client, target, msg)
+}
+
+// SlackSender posts the message to a Slack incoming webhook URL, with the
+// same protections as WebhookSender
+type SlackSender struct {
+	client *http.Client
+}
+
+func NewSlackSender(timeout time.Duration) *SlackSender {
+	return &SlackSender{client: safehttp.NewClient(safehttp.Config{Timeout: timeout})}
+}
+
+func (s *SlackSender) Send(ctx context.Context, target string, msg Message) error {
+	text := fmt.Sprintf("*%s*\n%s", msg.Title, msg.Body)
+	if msg.ActionURL != "" {
+		text += "\n" + msg.ActionURL
+	}
+	return postJSON(ctx, s.client, target, map[string]string{"text": text})
+}
+
+func postJSON(ctx context.Context, client *http.Client, target string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Atlas-Risk-Assessment/1.0")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("delivery failed with status: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// LogSender logs messages instead of sending them, for local development
+type LogSender struct {
+	logger *zap.Logger
+}
+
+func NewLogSender(logger *zap.Logger) *LogSender {
+	if logger == nil {
+		logger = zap.NewNop()
+	}
+	return &LogSender{logger: logger}
+}
+
+func (s *LogSender) Send(ctx context.Context, target string, msg Message) error {
+	s.logger.Info("Notification not sent (log driver)",
+		zap.String("title", msg.Title),
+		zap.String("priority", msg.Priority),
+	)
+	return nil
+}
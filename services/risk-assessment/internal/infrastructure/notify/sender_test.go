@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"atlas-core-api/pkg/safehttp"
+)
+
+func TestSMTPSender_RejectsHeaderInjectionInTarget(t *testing.T) {
+	s := NewSMTPSender("127.0.0.1", 1, "", "", "alerts@atlas.example.com")
+
+	for _, target := range []string{
+		"analyst@example.com\r\nBcc: attacker@example.net",
+		"analyst@example.com\nBcc: attacker@example.net",
+		"\"Analyst\r\nBcc: attacker@example.net\" <analyst@example.com>",
+		"analyst@example.com, attacker@example.net",
+	} {
+		err := s.Send(context.Background(), target, Message{Title: "Risk alert"})
+		assert.ErrorContains(t, err, "invalid email target", "target %q", target)
+	}
+}
+
+func TestBuildEmail_EncodesSubjectLineBreaks(t *testing.T) {
+	to, err := mail.ParseAddress("analyst@example.com")
+	require.NoError(t, err)
+
+	email := string(buildEmail("alerts@atlas.example.com", to, Message{
+		Title: "Risk alert\r\nBcc: attacker@example.net",
+		Body:  "Score crossed 80",
+	}, time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+
+	headers, _, found := strings.Cut(email, "\r\n\r\n")
+	require.True(t, found)
+	lines := strings.Split(headers, "\r\n")
+	for _, line := range lines {
+		assert.False(t, strings.HasPrefix(line, "Bcc:"), "injected header in %q", headers)
+	}
+	assert.Contains(t, lines, "To: <analyst@example.com>")
+	assert.Len(t, lines, 6)
+}
+
+func TestHTTPSenders_RefuseInternalTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("notification reached a loopback server")
+	}))
+	defer server.Close()
+
+	for name, sender := range map[string]Sender{
+		"webhook": NewWebhookSender(time.Second),
+		"slack":   NewSlackSender(time.Second),
+	} {
+		err := sender.Send(context.Background(), server.URL+"/hook", Message{Title: "Risk alert"})
+		assert.ErrorIs(t, err, safehttp.ErrBlockedAddress, name)
+	}
+}
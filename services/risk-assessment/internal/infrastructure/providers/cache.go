@@ -0,0 +1,61 @@
+package providers
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCache keeps provider records for a fixed time. Cached records are
+// shared between callers and must not be modified.
+type ttlCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	record    *Record
+	expiresAt time.Time
+}
+
+func newTTLCache(ttl time.Duration) *ttlCache {
+	return &ttlCache{ttl: ttl, entries: make(map[string]cacheEntry)}
+}
+
+func (c *ttlCache) get(key string) (*Record, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.record, true
+}
+
+func (c *ttlCache) set(key string, record *Record) {
+	if c.ttl <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	// Drop expired entries as the cache grows so it stays bounded by the
+	// number of entities seen within one TTL
+	if len(c.entries) >= 1024 && len(c.entries)%1024 == 0 {
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+	}
+	c.entries[key] = cacheEntry{record: record, expiresAt: now.Add(c.ttl)}
+}
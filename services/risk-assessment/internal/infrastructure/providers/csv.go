@@ -0,0 +1,92 @@
+package providers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CSVProvider reads indicators from {dir}/{dimension}.csv. The header names
+// the columns: entity_id, any indicators, and optionally last_updated
+// (RFC 3339) and data_quality. Empty cells are indicators the file does not
+// report for that entity.
+type CSVProvider struct {
+	dir string
+}
+
+// NewCSVProvider creates an adapter for the CSV files in dir
+func NewCSVProvider(dir string) *CSVProvider {
+	return &CSVProvider{dir: dir}
+}
+
+func (p *CSVProvider) Name() string {
+	return "csv"
+}
+
+func (p *CSVProvider) Fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(filepath.Join(p.dir, filepath.Base(dimension)+".csv"))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrUnsupportedDimension
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s header: %w", f.Name(), err)
+	}
+	if len(header) == 0 || strings.TrimSpace(header[0]) != "entity_id" {
+		return nil, fmt.Errorf("%s: first column must be entity_id", f.Name())
+	}
+
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			return nil, ErrNoData
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", f.Name(), err)
+		}
+		if row[0] == entityID {
+			return p.record(f.Name(), header, row)
+		}
+	}
+}
+
+func (p *CSVProvider) record(file string, header, row []string) (*Record, error) {
+	record := &Record{Provider: p.Name(), Values: make(map[string]float64)}
+	for i := 1; i < len(header) && i < len(row); i++ {
+		column, cell := strings.TrimSpace(header[i]), strings.TrimSpace(row[i])
+		if cell == "" {
+			continue
+		}
+
+		var err error
+		switch column {
+		case "last_updated":
+			record.ObservedAt, err = time.Parse(time.RFC3339, cell)
+		case "data_quality":
+			record.Quality, err = strconv.ParseFloat(cell, 64)
+		default:
+			record.Values[column], err = strconv.ParseFloat(cell, 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid %s %q", file, column, cell)
+		}
+	}
+	return record, nil
+}
@@ -0,0 +1,112 @@
+package providers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// maxResponseBytes bounds how much of a provider response is read
+const maxResponseBytes = 1 << 20
+
+// HTTPProvider reads indicators from a JSON API at
+// {baseURL}/entities/{entity_id}/{dimension}. Top-level numeric fields are
+// indicator values, arrays count as their length, and last_updated and
+// data_quality describe the record.
+type HTTPProvider struct {
+	name    string
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPProvider creates an adapter for one JSON data API. Timeouts come
+// from the registry, so the client has none of its own.
+func NewHTTPProvider(name, baseURL, apiKey string) *HTTPProvider {
+	return &HTTPProvider{
+		name:    name,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		apiKey:  apiKey,
+		client:  &http.Client{},
+	}
+}
+
+func (p *HTTPProvider) Name() string {
+	return p.name
+}
+
+func (p *HTTPProvider) Fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	endpoint := fmt.Sprintf("%s/entities/%s/%s", p.baseURL, url.PathEscape(entityID), url.PathEscape(dimension))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", "Atlas-Risk-Assessment/1.0")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, ErrNoData
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("API request failed with status: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+	return p.record(fields)
+}
+
+func (p *HTTPProvider) record(fields map[string]json.RawMessage) (*Record, error) {
+	record := &Record{Provider: p.name, Values: make(map[string]float64)}
+	for key, raw := range fields {
+		switch key {
+		case "last_updated":
+			if err := json.Unmarshal(raw, &record.ObservedAt); err != nil {
+				return nil, fmt.Errorf("invalid last_updated: %w", err)
+			}
+			continue
+		case "data_quality":
+			if err := json.Unmarshal(raw, &record.Quality); err != nil {
+				return nil, fmt.Errorf("invalid data_quality: %w", err)
+			}
+			continue
+		}
+
+		// null means the provider does not report the indicator
+		if string(raw) == "null" {
+			continue
+		}
+		var number float64
+		if err := json.Unmarshal(raw, &number); err == nil {
+			record.Values[key] = number
+			continue
+		}
+		var list []json.RawMessage
+		if err := json.Unmarshal(raw, &list); err == nil {
+			record.Values[key] = float64(len(list))
+		}
+	}
+	if len(record.Values) == 0 {
+		return nil, ErrNoData
+	}
+	return record, nil
+}
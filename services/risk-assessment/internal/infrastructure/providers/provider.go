@@ -0,0 +1,40 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrUnsupportedDimension is returned by an adapter asked for a
+	// dimension it has no data for
+	ErrUnsupportedDimension = errors.New("provider does not supply this dimension")
+	// ErrNoData is returned when a provider has no record for the entity
+	ErrNoData = errors.New("provider has no data for entity")
+	// ErrNoProviders is returned when no adapter is registered for a dimension
+	ErrNoProviders = errors.New("no providers configured for dimension")
+	// ErrAllProvidersFailed is returned when every adapter for a dimension
+	// failed; the error text lists each provider's failure
+	ErrAllProvidersFailed = errors.New("all providers failed")
+)
+
+// Record is one provider response: indicator values keyed by the names
+// scoring models refer to. Indicators the provider did not report are
+// absent from Values rather than zero.
+type Record struct {
+	Provider   string
+	Values     map[string]float64
+	ObservedAt time.Time
+	// Quality is the provider's own 0-1 data quality rating
+	Quality float64
+	// Synthetic marks generated values that were never observed
+	Synthetic bool
+}
+
+// Provider is an adapter for one source of indicator data
+type Provider interface {
+	// Name identifies the provider in health reports and risk factors
+	Name() string
+	Fetch(ctx context.Context, dimension, entityID string) (*Record, error)
+}
@@ -0,0 +1,276 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Registry fetches a dimension's indicators from an ordered list of
+// providers, falling back to the next one when a provider fails. Each
+// provider has its own timeout and circuit breaker, and successful
+// responses are cached per provider and entity.
+type Registry struct {
+	mu        sync.RWMutex
+	chains    map[string][]*registeredProvider
+	providers map[string]*registeredProvider
+	cache     *ttlCache
+}
+
+type registeredProvider struct {
+	provider Provider
+	timeout  time.Duration
+	breaker  *gobreaker.CircuitBreaker
+	// slots bounds concurrent calls; nil means unbounded
+	slots chan struct{}
+
+	mu     sync.Mutex
+	health ProviderHealth
+}
+
+// ProviderHealth reports how a provider has been responding
+type ProviderHealth struct {
+	Name          string     `json:"name"`
+	Dimensions    []string   `json:"dimensions"`
+	State         string     `json:"state"`
+	Successes     int64      `json:"successes"`
+	Failures      int64      `json:"failures"`
+	CacheHits     int64      `json:"cache_hits"`
+	LastLatencyMs int64      `json:"last_latency_ms"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+}
+
+// NewRegistry creates an empty registry. Responses are cached for cacheTTL;
+// zero disables caching.
+func NewRegistry(cacheTTL time.Duration) *Registry {
+	return &Registry{
+		chains:    make(map[string][]*registeredProvider),
+		providers: make(map[string]*registeredProvider),
+		cache:     newTTLCache(cacheTTL),
+	}
+}
+
+// Options tune how the registry calls a provider
+type Options struct {
+	// Timeout bounds each call; zero leaves only the caller's deadline
+	Timeout time.Duration
+	// MaxConcurrent bounds calls in flight across all dimensions; zero
+	// means unbounded
+	MaxConcurrent int
+}
+
+// Register appends a provider to a dimension's fallback chain. A provider
+// registered for several dimensions shares one breaker, concurrency limit
+// and health record, configured by its first registration.
+func (r *Registry) Register(dimension string, p Provider, opts Options) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	rp, ok := r.providers[p.Name()]
+	if !ok {
+		rp = &registeredProvider{
+			provider: p,
+			timeout:  opts.Timeout,
+			breaker:  newBreaker(p.Name()),
+			health:   ProviderHealth{Name: p.Name()},
+		}
+		if opts.MaxConcurrent > 0 {
+			rp.slots = make(chan struct{}, opts.MaxConcurrent)
+		}
+		r.providers[p.Name()] = rp
+	}
+	rp.health.Dimensions = append(rp.health.Dimensions, dimension)
+	r.chains[dimension] = append(r.chains[dimension], rp)
+}
+
+// Fetch returns the first record a dimension's providers produce, in
+// registration order
+func (r *Registry) Fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	r.mu.RLock()
+	chain := r.chains[dimension]
+	r.mu.RUnlock()
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoProviders, dimension)
+	}
+
+	failures := make([]string, 0, len(chain))
+	for _, rp := range chain {
+		key := rp.provider.Name() + "|" + dimension + "|" + entityID
+		if record, ok := r.cache.get(key); ok {
+			rp.recordCacheHit()
+			return record, nil
+		}
+
+		record, err := rp.fetch(ctx, dimension, entityID)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", rp.provider.Name(), err))
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+		r.cache.set(key, record)
+		return record, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrAllProvidersFailed, strings.Join(failures, "; "))
+}
+
+// FetchAll asks every provider in a dimension's chain at once and returns
+// the records they produce, in registration order, so their scores can be
+// compared. Each provider is bounded by its own timeout; when ctx ends
+// first, the records already received are returned. It fails only when no
+// provider produced a record.
+func (r *Registry) FetchAll(ctx context.Context, dimension, entityID string) ([]*Record, error) {
+	r.mu.RLock()
+	chain := r.chains[dimension]
+	r.mu.RUnlock()
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoProviders, dimension)
+	}
+
+	type answer struct {
+		record *Record
+		err    error
+	}
+	answers := make([]chan answer, len(chain))
+	for i, rp := range chain {
+		answers[i] = make(chan answer, 1)
+		go func(rp *registeredProvider, out chan<- answer) {
+			key := rp.provider.Name() + "|" + dimension + "|" + entityID
+			if record, ok := r.cache.get(key); ok {
+				rp.recordCacheHit()
+				out <- answer{record: record}
+				return
+			}
+			record, err := rp.fetch(ctx, dimension, entityID)
+			if err == nil {
+				r.cache.set(key, record)
+			}
+			out <- answer{record: record, err: err}
+		}(rp, answers[i])
+	}
+
+	records := make([]*Record, 0, len(chain))
+	failures := make([]string, 0, len(chain))
+	for i, rp := range chain {
+		select {
+		case a := <-answers[i]:
+			if a.err != nil {
+				failures = append(failures, fmt.Sprintf("%s: %v", rp.provider.Name(), a.err))
+				continue
+			}
+			records = append(records, a.record)
+		case <-ctx.Done():
+			failures = append(failures, fmt.Sprintf("%s: %v", rp.provider.Name(), ctx.Err()))
+		}
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrAllProvidersFailed, strings.Join(failures, "; "))
+	}
+	return records, nil
+}
+
+// Health reports every registered provider, ordered by name
+func (r *Registry) Health() []ProviderHealth {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	report := make([]ProviderHealth, 0, len(r.providers))
+	for _, rp := range r.providers {
+		report = append(report, rp.snapshot())
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].Name < report[j].Name })
+	return report
+}
+
+func (rp *registeredProvider) fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	if rp.slots != nil {
+		select {
+		case rp.slots <- struct{}{}:
+			defer func() { <-rp.slots }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if rp.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, rp.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	result, err := rp.breaker.Execute(func() (interface{}, error) {
+		return rp.provider.Fetch(ctx, dimension, entityID)
+	})
+	latency := time.Since(start)
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	rp.health.LastLatencyMs = latency.Milliseconds()
+	now := time.Now()
+	if err != nil {
+		if !isMiss(err) {
+			rp.health.Failures++
+			rp.health.LastError = err.Error()
+			rp.health.LastErrorAt = &now
+		}
+		return nil, err
+	}
+	rp.health.Successes++
+	rp.health.LastSuccessAt = &now
+
+	record := result.(*Record)
+	if record.Provider == "" {
+		record.Provider = rp.provider.Name()
+	}
+	return record, nil
+}
+
+func (rp *registeredProvider) recordCacheHit() {
+	rp.mu.Lock()
+	rp.health.CacheHits++
+	rp.mu.Unlock()
+}
+
+func (rp *registeredProvider) snapshot() ProviderHealth {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	health := rp.health
+	health.Dimensions = append([]string(nil), rp.health.Dimensions...)
+	health.State = rp.breaker.State().String()
+	return health
+}
+
+// newBreaker opens after most of a provider's recent calls fail, matching
+// the gateway's per-service breakers
+func newBreaker(name string) *gobreaker.CircuitBreaker {
+	return gobreaker.NewCircuitBreaker(gobreaker.Settings{
+		Name:        name,
+		MaxRequests: 3,                // Half-open state: allow 3 requests
+		Interval:    10 * time.Second, // Clear counts after 10s
+		Timeout:     30 * time.Second, // Try to close after 30s in open state
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			failureRatio := float64(counts.TotalFailures) / float64(counts.Requests)
+			return counts.Requests >= 5 && failureRatio >= 0.6
+		},
+		// A provider with no record for an entity is healthy, and one the
+		// caller stopped waiting for did not fail
+		IsSuccessful: func(err error) bool {
+			return err == nil || isMiss(err) || errors.Is(err, context.Canceled)
+		},
+	})
+}
+
+// isMiss reports whether a provider answered but had nothing to return
+func isMiss(err error) bool {
+	return errors.Is(err, ErrNoData) || errors.Is(err, ErrUnsupportedDimension)
+}
@@ -0,0 +1,145 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubProvider struct {
+	name  string
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &Record{Values: map[string]float64{"credit_score": 700}, Quality: 1}, nil
+}
+
+func TestRegistryFallsBackInOrder(t *testing.T) {
+	primary := &stubProvider{name: "primary", err: errors.New("connection refused")}
+	registry := NewRegistry(0)
+	registry.Register("financial", primary, Options{Timeout: time.Second})
+	registry.Register("financial", NewFixtureProvider(), Options{})
+
+	record, err := registry.Fetch(context.Background(), "financial", "acme")
+
+	require.NoError(t, err)
+	assert.Equal(t, "fixture", record.Provider)
+	assert.Equal(t, 1, primary.calls)
+
+	health := registry.Health()
+	require.Len(t, health, 2)
+	assert.Equal(t, "fixture", health[0].Name)
+	assert.Equal(t, int64(1), health[0].Successes)
+	assert.Equal(t, "primary", health[1].Name)
+	assert.Equal(t, int64(1), health[1].Failures)
+	assert.Equal(t, "connection refused", health[1].LastError)
+	assert.Equal(t, "closed", health[1].State)
+}
+
+func TestRegistryFetchAllAsksEveryProvider(t *testing.T) {
+	failing := &stubProvider{name: "failing", err: errors.New("connection refused")}
+	registry := NewRegistry(0)
+	registry.Register("financial", failing, Options{})
+	registry.Register("financial", &stubProvider{name: "api"}, Options{})
+	registry.Register("financial", NewFixtureProvider(), Options{})
+
+	records, err := registry.FetchAll(context.Background(), "financial", "acme")
+
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "api", records[0].Provider)
+	assert.Equal(t, "fixture", records[1].Provider)
+	assert.Equal(t, 1, failing.calls)
+
+	_, err = registry.FetchAll(context.Background(), "compliance", "acme")
+	assert.ErrorIs(t, err, ErrNoProviders)
+}
+
+func TestRegistryReportsEveryFailure(t *testing.T) {
+	registry := NewRegistry(0)
+	registry.Register("compliance", &stubProvider{name: "a", err: errors.New("timeout")}, Options{})
+	registry.Register("compliance", &stubProvider{name: "b", err: ErrNoData}, Options{})
+
+	_, err := registry.Fetch(context.Background(), "compliance", "acme")
+
+	assert.ErrorIs(t, err, ErrAllProvidersFailed)
+	assert.EqualError(t, err, "all providers failed: a: timeout; b: provider has no data for entity")
+
+	_, err = registry.Fetch(context.Background(), "weather", "acme")
+	assert.ErrorIs(t, err, ErrNoProviders)
+}
+
+func TestRegistryCachesPerEntity(t *testing.T) {
+	provider := &stubProvider{name: "api"}
+	registry := NewRegistry(time.Minute)
+	registry.Register("financial", provider, Options{})
+
+	for i := 0; i < 3; i++ {
+		_, err := registry.Fetch(context.Background(), "financial", "acme")
+		require.NoError(t, err)
+	}
+	_, err := registry.Fetch(context.Background(), "financial", "globex")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, provider.calls)
+	assert.Equal(t, int64(2), registry.Health()[0].CacheHits)
+}
+
+func TestFixtureProviderIsDeterministic(t *testing.T) {
+	fixture := NewFixtureProvider()
+
+	first, err := fixture.Fetch(context.Background(), "geopolitical", "acme")
+	require.NoError(t, err)
+	second, err := fixture.Fetch(context.Background(), "geopolitical", "acme")
+	require.NoError(t, err)
+	other, err := fixture.Fetch(context.Background(), "geopolitical", "cmea")
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Values, second.Values)
+	assert.Equal(t, first.Quality, second.Quality)
+	assert.NotEqual(t, first.Values, other.Values)
+
+	_, err = fixture.Fetch(context.Background(), "weather", "acme")
+	assert.ErrorIs(t, err, ErrUnsupportedDimension)
+}
+
+func TestCSVProviderOmitsEmptyCells(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "compliance.csv"), []byte(
+		"entity_id,compliance_score,audit_findings,last_updated,data_quality\n"+
+			"acme,82.5,,2026-10-01T00:00:00Z,0.9\n"), 0o600))
+	provider := NewCSVProvider(dir)
+
+	record, err := provider.Fetch(context.Background(), "compliance", "acme")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"compliance_score": 82.5}, record.Values)
+	assert.Equal(t, 0.9, record.Quality)
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), record.ObservedAt)
+
+	_, err = provider.Fetch(context.Background(), "compliance", "globex")
+	assert.ErrorIs(t, err, ErrNoData)
+	_, err = provider.Fetch(context.Background(), "financial", "acme")
+	assert.ErrorIs(t, err, ErrUnsupportedDimension)
+}
+
+func TestFixtureRecordsAreSynthetic(t *testing.T) {
+	record, err := NewFixtureProvider().Fetch(context.Background(), "financial", "acme")
+
+	require.NoError(t, err)
+	assert.True(t, record.Synthetic)
+	assert.Equal(t, fixtureQuality, record.Quality)
+}
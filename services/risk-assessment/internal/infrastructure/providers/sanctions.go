@@ -0,0 +1,80 @@
+package providers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// screeningQuality rates sanctions screening results, which come from fuzzy
+// name matching rather than a curated feed
+const screeningQuality = 0.8
+
+// SanctionsProvider screens an entity with the internal sanctions-screening
+// service and reports the strongest list match (0-100) as the
+// geopolitical sanctions_exposure indicator. It supplies no other
+// indicators, so it is best placed after a full geopolitical feed.
+type SanctionsProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewSanctionsProvider creates an adapter for the sanctions-screening
+// service at baseURL
+func NewSanctionsProvider(baseURL string) *SanctionsProvider {
+	return &SanctionsProvider{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		client:  &http.Client{},
+	}
+}
+
+func (p *SanctionsProvider) Name() string {
+	return "sanctions-screening"
+}
+
+func (p *SanctionsProvider) Fetch(ctx context.Context, dimension, entityID string) (*Record, error) {
+	if dimension != "geopolitical" {
+		return nil, ErrUnsupportedDimension
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"entity_name": entityID,
+		"entity_type": "organization",
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/api/v1/sanctions/screen", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("sanctions screening failed with status: %d", resp.StatusCode)
+	}
+
+	var screening struct {
+		Timestamp         time.Time `json:"timestamp"`
+		HighestConfidence float64   `json:"highest_confidence"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&screening); err != nil {
+		return nil, fmt.Errorf("invalid response: %w", err)
+	}
+
+	return &Record{
+		Provider:   p.Name(),
+		Values:     map[string]float64{"sanctions_exposure": screening.HighestConfidence},
+		ObservedAt: screening.Timestamp,
+		Quality:    screeningQuality,
+	}, nil
+}
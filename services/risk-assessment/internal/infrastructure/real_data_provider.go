@@ -1,230 +0,0 @@
-package infrastructure
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"io"
-	"math/rand"
-	"net/http"
-	"time"
-)
-
-// RealDataProvider implements ExternalDataProvider with real API integrations
-type RealDataProvider struct {
-	config *DataProviderConfig
-	client *http.Client
-}
-
-// NewRealDataProvider creates a new real data provider
-func NewRealDataProvider(config *DataProviderConfig) ExternalDataProvider {
-	return &RealDataProvider{
-		config: config,
-		client: &http.Client{
-			Timeout: config.Timeout,
-		},
-	}
-}
-
-// GetFinancialData fetches real financial data from external APIs
-func (p *RealDataProvider) GetFinancialData(entityID string, ctx context.Context) (*FinancialData, error) {
-	// For demo purposes, we'll simulate API calls to real financial data sources
-	// In production, this would call APIs like:
-	// - Alpha Vantage for stock data
-	// - Financial APIs for credit scores
-	// - Bloomberg Terminal API
-	// - Company financial reports
-
-	// Simulate API call delay
-	select {
-	case <-time.After(time.Duration(100+rand.Intn(200)) * time.Millisecond):
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	// Mock real data with some variance based on entityID
-	seed := int64(0)
-	for _, r := range entityID {
-		seed += int64(r)
-	}
-	r := rand.New(rand.NewSource(seed))
-
-	data := &FinancialData{
-		CreditScore:      300 + r.Float64()*500, // 300-800 range
-		DebtToEquity:     r.Float64() * 3.0,     // 0-3.0 range
-		RevenueGrowth:    (r.Float64() - 0.5) * 0.4, // -20% to +20%
-		ProfitMargin:     r.Float64() * 0.3,     // 0-30%
-		MarketVolatility: r.Float64() * 0.5,     // 0-50%
-		LastUpdated:      time.Now().Add(-time.Duration(r.Intn(24)) * time.Hour),
-		DataQuality:      0.7 + r.Float64()*0.3, // 70-100% quality
-	}
-
-	return data, nil
-}
-
-// GetGeopoliticalData fetches real geopolitical data
-func (p *RealDataProvider) GetGeopoliticalData(entityID string, ctx context.Context) (*GeopoliticalData, error) {
-	// In production, this would integrate with:
-	// - World Bank API
-	// - UN data APIs
-	// - Geopolitical risk databases
-	// - Country risk assessment services
-
-	select {
-	case <-time.After(time.Duration(150+rand.Intn(250)) * time.Millisecond):
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	seed := int64(0)
-	for _, r := range entityID {
-		seed += int64(r)
-	}
-	r := rand.New(rand.NewSource(seed))
-
-	conflictZones := []string{}
-	if r.Float64() > 0.7 {
-		conflictZones = []string{"High Risk Region", "Border Conflict Area"}
-	}
-
-	data := &GeopoliticalData{
-		CountryRisk:        r.Float64() * 100,
-		PoliticalStability: r.Float64() * 10,
-		SanctionsExposure:  r.Float64() * 50,
-		TradeRelations:     r.Float64() * 100,
-		ConflictZones:      conflictZones,
-		LastUpdated:        time.Now().Add(-time.Duration(r.Intn(48)) * time.Hour),
-		DataQuality:        0.6 + r.Float64()*0.4,
-	}
-
-	return data, nil
-}
-
-// GetComplianceData fetches real compliance data
-func (p *RealDataProvider) GetComplianceData(entityID string, ctx context.Context) (*ComplianceData, error) {
-	// In production, this would integrate with:
-	// - SEC EDGAR API
-	// - Regulatory databases
-	// - Compliance monitoring services
-	// - Legal databases
-
-	select {
-	case <-time.After(time.Duration(120+rand.Intn(180)) * time.Millisecond):
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	seed := int64(0)
-	for _, r := range entityID {
-		seed += int64(r)
-	}
-	r := rand.New(rand.NewSource(seed))
-
-	data := &ComplianceData{
-		RegulatoryFines:  r.Float64() * 1000000, // Up to $1M
-		ComplianceScore:  60 + r.Float64()*40,   // 60-100 score
-		AuditFindings:    r.Intn(20),            // 0-20 findings
-		DataPrivacyRisk:  r.Float64() * 30,      // 0-30% risk
-		LegalActions:     r.Intn(5),             // 0-5 actions
-		LastUpdated:      time.Now().Add(-time.Duration(r.Intn(168)) * time.Hour), // Up to 1 week
-		DataQuality:      0.8 + r.Float64()*0.2,
-	}
-
-	return data, nil
-}
-
-// GetOperationalData fetches real operational data
-func (p *RealDataProvider) GetOperationalData(entityID string, ctx context.Context) (*OperationalData, error) {
-	// In production, this would integrate with:
-	// - Cybersecurity threat feeds
-	// - Supply chain monitoring
-	// - Operational metrics APIs
-	// - Employee feedback systems
-
-	select {
-	case <-time.After(time.Duration(80+rand.Intn(120)) * time.Millisecond):
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	seed := int64(0)
-	for _, r := range entityID {
-		seed += int64(r)
-	}
-	r := rand.New(rand.NewSource(seed))
-
-	data := &OperationalData{
-		CybersecurityIncidents: r.Intn(10),
-		SupplyChainRisk:        r.Float64() * 40,
-		OperationalEfficiency:  70 + r.Float64()*30,
-		EmployeeSatisfaction:   60 + r.Float64()*40,
-		SystemDowntime:         r.Float64() * 5, // Up to 5% downtime
-		LastUpdated:            time.Now().Add(-time.Duration(r.Intn(24)) * time.Hour),
-		DataQuality:            0.75 + r.Float64()*0.25,
-	}
-
-	return data, nil
-}
-
-// GetReputationalData fetches real reputational data
-func (p *RealDataProvider) GetReputationalData(entityID string, ctx context.Context) (*ReputationalData, error) {
-	// In production, this would integrate with:
-	// - Social media sentiment APIs
-	// - News aggregators
-	// - Review platforms
-	// - Brand monitoring services
-
-	select {
-	case <-time.After(time.Duration(200+rand.Intn(300)) * time.Millisecond):
-	case <-ctx.Done():
-		return nil, ctx.Err()
-	}
-
-	seed := int64(0)
-	for _, r := range entityID {
-		seed += int64(r)
-	}
-	r := rand.New(rand.NewSource(seed))
-
-	data := &ReputationalData{
-		SocialMediaSentiment: -1 + r.Float64()*2, // -1 to +1 scale
-		NewsCoverage:         r.Float64() * 100,  // 0-100 coverage score
-		CustomerReviews:      3 + r.Float64()*2,  // 3-5 star rating
-		BrandValue:           r.Float64() * 100,  // 0-100 brand value
-		CSRScore:             50 + r.Float64()*50, // 50-100 CSR score
-		LastUpdated:          time.Now().Add(-time.Duration(r.Intn(12)) * time.Hour),
-		DataQuality:          0.65 + r.Float64()*0.35,
-	}
-
-	return data, nil
-}
-
-// fetchFromAPI is a helper method for making real API calls
-func (p *RealDataProvider) fetchFromAPI(url, apiKey string, result interface{}) error {
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return err
-	}
-
-	if apiKey != "" {
-		req.Header.Set("Authorization", "Bearer "+apiKey)
-	}
-	req.Header.Set("User-Agent", "Atlas-Risk-Assessment/1.0")
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("API request failed with status: %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return err
-	}
-
-	return json.Unmarshal(body, result)
-}
\ No newline at end of file
@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// BacktestRepository stores backtests and, once they complete, their
+// results
+type BacktestRepository interface {
+	Create(backtest *models.Backtest) error
+	GetByID(id string) (*models.Backtest, error)
+	// List returns a page of backtests, newest first, and how many there
+	// are. Results are left out.
+	List(limit, offset int) ([]*models.Backtest, int, error)
+	// Update stores a backtest's status, counters and results
+	Update(backtest *models.Backtest) error
+	// Unfinished returns queued and running backtests, oldest first, so
+	// they can be run again after a restart
+	Unfinished() ([]*models.Backtest, error)
+	// Claim leases an unfinished backtest to worker until now+lease so
+	// that one replica runs it. It reports false when another worker holds
+	// an unexpired lease or the backtest has finished; the worker holding
+	// the lease renews it by claiming again.
+	Claim(id, worker string, now time.Time, lease time.Duration) (bool, error)
+}
+
+type backtestClaim struct {
+	worker string
+	until  time.Time
+}
+
+type inMemoryBacktestRepository struct {
+	backtests map[string]*models.Backtest
+	claims    map[string]backtestClaim
+	mu        sync.RWMutex
+}
+
+func NewBacktestRepository() BacktestRepository {
+	return &inMemoryBacktestRepository{
+		backtests: make(map[string]*models.Backtest),
+		claims:    make(map[string]backtestClaim),
+	}
+}
+
+func (r *inMemoryBacktestRepository) Create(backtest *models.Backtest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	c := *backtest
+	r.backtests[backtest.ID] = &c
+	return nil
+}
+
+func (r *inMemoryBacktestRepository) GetByID(id string) (*models.Backtest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	backtest, exists := r.backtests[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *backtest
+	return &c, nil
+}
+
+func (r *inMemoryBacktestRepository) List(limit, offset int) ([]*models.Backtest, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.Backtest, 0, len(r.backtests))
+	for _, backtest := range r.backtests {
+		c := *backtest
+		c.Results = nil
+		results = append(results, &c)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+
+	total := len(results)
+	if offset >= total {
+		return []*models.Backtest{}, total, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, total, nil
+}
+
+func (r *inMemoryBacktestRepository) Update(backtest *models.Backtest) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.backtests[backtest.ID]; !exists {
+		return ErrNotFound
+	}
+	c := *backtest
+	r.backtests[backtest.ID] = &c
+	return nil
+}
+
+func (r *inMemoryBacktestRepository) Unfinished() ([]*models.Backtest, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.Backtest, 0)
+	for _, backtest := range r.backtests {
+		if backtest.Status == models.BacktestStatusQueued || backtest.Status == models.BacktestStatusRunning {
+			c := *backtest
+			results = append(results, &c)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results, nil
+}
+
+func (r *inMemoryBacktestRepository) Claim(id, worker string, now time.Time, lease time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	backtest, exists := r.backtests[id]
+	if !exists || (backtest.Status != models.BacktestStatusQueued && backtest.Status != models.BacktestStatusRunning) {
+		return false, nil
+	}
+	if claim, held := r.claims[id]; held && claim.worker != worker && claim.until.After(now) {
+		return false, nil
+	}
+	r.claims[id] = backtestClaim{worker: worker, until: now.Add(lease)}
+	return true, nil
+}
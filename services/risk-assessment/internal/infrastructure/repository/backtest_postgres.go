@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresBacktestRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresBacktestRepository stores backtests in risk_backtests, with
+// their results as JSONB
+func NewPostgresBacktestRepository(db *sql.DB) BacktestRepository {
+	return &postgresBacktestRepository{db: db}
+}
+
+const backtestColumns = `
+	id, status, window_from, window_to, horizon_days, outcome_types, bins,
+	assessments, censored, COALESCE(error, ''), COALESCE(created_by, ''),
+	created_at, started_at, completed_at`
+
+func (r *postgresBacktestRepository) Create(backtest *models.Backtest) error {
+	outcomeTypes, err := json.Marshal(nonNilStrings(backtest.OutcomeTypes))
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO risk_backtests (
+			id, status, window_from, window_to, horizon_days, outcome_types, bins, created_by, created_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9)`,
+		backtest.ID, backtest.Status, backtest.From, backtest.To, backtest.HorizonDays, outcomeTypes,
+		backtest.Bins, backtest.CreatedBy, backtest.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresBacktestRepository) GetByID(id string) (*models.Backtest, error) {
+	var results []byte
+	backtest, err := scanBacktest(r.db.QueryRow(`SELECT `+backtestColumns+`, results
+		FROM risk_backtests WHERE id = $1`, id), &results)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if err := json.Unmarshal(results, &backtest.Results); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return backtest, nil
+}
+
+func (r *postgresBacktestRepository) List(limit, offset int) ([]*models.Backtest, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM risk_backtests`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	backtests, err := r.query(`SELECT `+backtestColumns+`
+		FROM risk_backtests
+		ORDER BY created_at DESC
+		LIMIT $1 OFFSET $2`, pageSize, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	return backtests, total, nil
+}
+
+func (r *postgresBacktestRepository) Update(backtest *models.Backtest) error {
+	results := backtest.Results
+	if results == nil {
+		results = []models.BacktestResult{}
+	}
+	resultsJSON, err := json.Marshal(results)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE risk_backtests
+		SET status = $2, assessments = $3, censored = $4, results = $5, error = NULLIF($6, ''),
+		    started_at = $7, completed_at = $8
+		WHERE id = $1`,
+		backtest.ID, backtest.Status, backtest.Assessments, backtest.Censored, resultsJSON,
+		backtest.Error, backtest.StartedAt, backtest.CompletedAt,
+	)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresBacktestRepository) Unfinished() ([]*models.Backtest, error) {
+	return r.query(`SELECT ` + backtestColumns + `
+		FROM risk_backtests
+		WHERE status IN ('queued', 'running')
+		ORDER BY created_at`)
+}
+
+// Claim takes or renews the lease in one statement, so replicas racing for
+// the same backtest cannot both win it
+func (r *postgresBacktestRepository) Claim(id, worker string, now time.Time, lease time.Duration) (bool, error) {
+	result, err := r.db.Exec(`
+		UPDATE risk_backtests SET claimed_by = $2, claimed_until = $4
+		WHERE id = $1 AND status IN ('queued', 'running')
+		  AND (claimed_until IS NULL OR claimed_until <= $3 OR claimed_by = $2)`,
+		id, worker, now, now.Add(lease))
+	if err != nil {
+		if isInvalidUUID(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return n > 0, nil
+}
+
+func (r *postgresBacktestRepository) query(query string, args ...interface{}) ([]*models.Backtest, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	backtests := make([]*models.Backtest, 0)
+	for rows.Next() {
+		backtest, err := scanBacktest(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		backtests = append(backtests, backtest)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return backtests, nil
+}
+
+// scanBacktest scans the backtest columns followed by any extra
+// destinations
+func scanBacktest(row rowScanner, extra ...interface{}) (*models.Backtest, error) {
+	backtest := &models.Backtest{}
+	var outcomeTypes []byte
+	var startedAt, completedAt sql.NullTime
+	dest := append([]interface{}{&backtest.ID, &backtest.Status, &backtest.From, &backtest.To,
+		&backtest.HorizonDays, &outcomeTypes, &backtest.Bins, &backtest.Assessments, &backtest.Censored,
+		&backtest.Error, &backtest.CreatedBy, &backtest.CreatedAt, &startedAt, &completedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(outcomeTypes, &backtest.OutcomeTypes); err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		backtest.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		backtest.CompletedAt = &completedAt.Time
+	}
+	return backtest, nil
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,182 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// JobRepository stores batch assessment jobs and their items. Item outcomes
+// are recorded once; a job completes when its last item finishes.
+type JobRepository interface {
+	// CreateJob stores a queued job with its items, all pending
+	CreateJob(job *models.AssessmentJob, items []*models.JobItem) error
+	GetJob(id string) (*models.AssessmentJob, error)
+	// UnfinishedJobs returns queued and running jobs, oldest first, so they
+	// can be resumed after a restart
+	UnfinishedJobs() ([]*models.AssessmentJob, error)
+	// ClaimItems leases up to limit of a job's pending items to worker
+	// until now+lease, in position order. Items under another worker's
+	// unexpired lease are skipped, so replicas resuming the same job split
+	// its items between them; an item whose worker died is claimable again
+	// once its lease runs out.
+	ClaimItems(jobID, worker string, now time.Time, lease time.Duration, limit int) ([]*models.JobItem, error)
+	// ListItems returns a page of a job's items in position order,
+	// optionally only those with status, and how many match
+	ListItems(jobID, status string, limit, offset int) ([]*models.JobItem, int, error)
+	// MarkStarted moves a queued job to running
+	MarkStarted(jobID string, at time.Time) error
+	// FinishItem records a pending item's outcome and returns the job's
+	// updated counters. completed reports whether this item finished the
+	// job; an item that already finished is left as it was.
+	FinishItem(item *models.JobItem) (job *models.AssessmentJob, completed bool, err error)
+}
+
+// inMemoryJobRepository keeps jobs in process memory; jobs do not survive a
+// restart. It hands out copies because workers update jobs concurrently.
+type inMemoryJobRepository struct {
+	jobs   map[string]*models.AssessmentJob
+	items  map[string][]*models.JobItem
+	leases map[string][]time.Time
+	mu     sync.RWMutex
+}
+
+func NewJobRepository() JobRepository {
+	return &inMemoryJobRepository{
+		jobs:   make(map[string]*models.AssessmentJob),
+		items:  make(map[string][]*models.JobItem),
+		leases: make(map[string][]time.Time),
+	}
+}
+
+func (r *inMemoryJobRepository) CreateJob(job *models.AssessmentJob, items []*models.JobItem) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *job
+	r.jobs[job.ID] = &stored
+	copies := make([]*models.JobItem, len(items))
+	for i, item := range items {
+		c := *item
+		copies[i] = &c
+	}
+	r.items[job.ID] = copies
+	r.leases[job.ID] = make([]time.Time, len(items))
+	return nil
+}
+
+func (r *inMemoryJobRepository) GetJob(id string) (*models.AssessmentJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	job, exists := r.jobs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *job
+	return &c, nil
+}
+
+func (r *inMemoryJobRepository) UnfinishedJobs() ([]*models.AssessmentJob, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.AssessmentJob, 0)
+	for _, job := range r.jobs {
+		if job.Status != models.JobStatusCompleted {
+			c := *job
+			results = append(results, &c)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+	return results, nil
+}
+
+func (r *inMemoryJobRepository) ClaimItems(jobID, worker string, now time.Time, lease time.Duration, limit int) ([]*models.JobItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	claimed := make([]*models.JobItem, 0, limit)
+	leases := r.leases[jobID]
+	for i, item := range r.items[jobID] {
+		if len(claimed) == limit {
+			break
+		}
+		if item.Status != models.JobItemPending || leases[i].After(now) {
+			continue
+		}
+		leases[i] = now.Add(lease)
+		c := *item
+		claimed = append(claimed, &c)
+	}
+	return claimed, nil
+}
+
+func (r *inMemoryJobRepository) ListItems(jobID, status string, limit, offset int) ([]*models.JobItem, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*models.JobItem, 0)
+	for _, item := range r.items[jobID] {
+		if status == "" || item.Status == status {
+			c := *item
+			matched = append(matched, &c)
+		}
+	}
+
+	total := len(matched)
+	if offset >= total {
+		return []*models.JobItem{}, total, nil
+	}
+	matched = matched[offset:]
+	if limit > 0 && limit < len(matched) {
+		matched = matched[:limit]
+	}
+	return matched, total, nil
+}
+
+func (r *inMemoryJobRepository) MarkStarted(jobID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[jobID]
+	if !exists {
+		return ErrNotFound
+	}
+	if job.Status == models.JobStatusQueued {
+		job.Status = models.JobStatusRunning
+		job.StartedAt = &at
+	}
+	return nil
+}
+
+func (r *inMemoryJobRepository) FinishItem(item *models.JobItem) (*models.AssessmentJob, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	job, exists := r.jobs[item.JobID]
+	if !exists || item.Position < 0 || item.Position >= len(r.items[item.JobID]) {
+		return nil, false, ErrNotFound
+	}
+
+	completed := false
+	stored := r.items[item.JobID][item.Position]
+	if stored.Status == models.JobItemPending {
+		*stored = *item
+		if item.Status == models.JobItemSucceeded {
+			job.Succeeded++
+		} else {
+			job.Failed++
+		}
+		if job.Processed() >= job.Total {
+			completedAt := item.UpdatedAt
+			job.Status = models.JobStatusCompleted
+			job.CompletedAt = &completedAt
+			completed = true
+		}
+	}
+	c := *job
+	return &c, completed, nil
+}
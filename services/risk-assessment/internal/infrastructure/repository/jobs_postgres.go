@@ -0,0 +1,268 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresJobRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresJobRepository stores jobs in risk_assessment_jobs and their
+// items in risk_assessment_job_items
+func NewPostgresJobRepository(db *sql.DB) JobRepository {
+	return &postgresJobRepository{db: db}
+}
+
+const jobColumns = `
+	id, status, dimensions, include_factors, total, succeeded, failed,
+	COALESCE(created_by, ''), created_at, started_at, completed_at`
+
+const itemColumns = `
+	job_id, position, entity_id, entity_type, status, COALESCE(assessment_id::text, ''),
+	overall_score, degraded, COALESCE(error, ''), updated_at`
+
+const selectItem = `SELECT ` + itemColumns + ` FROM risk_assessment_job_items`
+
+func (r *postgresJobRepository) CreateJob(job *models.AssessmentJob, items []*models.JobItem) error {
+	dimensions := job.Dimensions
+	if dimensions == nil {
+		dimensions = []string{}
+	}
+	dimensionsJSON, err := json.Marshal(dimensions)
+	if err != nil {
+		return err
+	}
+
+	positions := make([]int64, len(items))
+	entityIDs := make([]string, len(items))
+	entityTypes := make([]string, len(items))
+	for i, item := range items {
+		positions[i] = int64(item.Position)
+		entityIDs[i] = item.EntityID
+		entityTypes[i] = item.EntityType
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO risk_assessment_jobs (id, status, dimensions, include_factors, total, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)`,
+		job.ID, job.Status, dimensionsJSON, job.IncludeFactors, job.Total, job.CreatedBy, job.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO risk_assessment_job_items (job_id, position, entity_id, entity_type, updated_at)
+		SELECT $1, item.position, item.entity_id, item.entity_type, $5
+		FROM unnest($2::int[], $3::text[], $4::text[]) AS item(position, entity_id, entity_type)`,
+		job.ID, pq.Array(positions), pq.Array(entityIDs), pq.Array(entityTypes), job.CreatedAt,
+	); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresJobRepository) GetJob(id string) (*models.AssessmentJob, error) {
+	job, err := scanJob(r.db.QueryRow(`SELECT `+jobColumns+` FROM risk_assessment_jobs WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return job, nil
+}
+
+func (r *postgresJobRepository) UnfinishedJobs() ([]*models.AssessmentJob, error) {
+	rows, err := r.db.Query(`SELECT ` + jobColumns + `
+		FROM risk_assessment_jobs
+		WHERE status <> 'completed'
+		ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	jobs := make([]*models.AssessmentJob, 0)
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		jobs = append(jobs, job)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return jobs, nil
+}
+
+// ClaimItems leases items in one statement; SKIP LOCKED lets replicas
+// claim disjoint batches of the same job
+func (r *postgresJobRepository) ClaimItems(jobID, worker string, now time.Time, lease time.Duration, limit int) ([]*models.JobItem, error) {
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE risk_assessment_job_items SET claimed_by = $2, claimed_until = $4
+			WHERE job_id = $1 AND position IN (
+				SELECT position FROM risk_assessment_job_items
+				WHERE job_id = $1 AND status = 'pending'
+				  AND (claimed_until IS NULL OR claimed_until <= $3)
+				ORDER BY position
+				LIMIT $5
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+itemColumns+` FROM claimed ORDER BY position`,
+		jobID, worker, now, now.Add(lease), limit)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return []*models.JobItem{}, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	return scanItems(rows)
+}
+
+func (r *postgresJobRepository) ListItems(jobID, status string, limit, offset int) ([]*models.JobItem, int, error) {
+	var total int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM risk_assessment_job_items
+		WHERE job_id = $1 AND ($2 = '' OR status = $2)`, jobID, status).Scan(&total)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return []*models.JobItem{}, 0, nil
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	rows, err := r.db.Query(selectItem+`
+		WHERE job_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY position
+		LIMIT $3 OFFSET $4`, jobID, status, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	items, err := scanItems(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+func (r *postgresJobRepository) MarkStarted(jobID string, at time.Time) error {
+	_, err := r.db.Exec(`
+		UPDATE risk_assessment_jobs SET status = 'running', started_at = $2
+		WHERE id = $1 AND status = 'queued'`, jobID, at)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+// FinishItem updates the item only while it is pending, and counts it in the
+// same statement so concurrent workers cannot lose an increment
+func (r *postgresJobRepository) FinishItem(item *models.JobItem) (*models.AssessmentJob, bool, error) {
+	row := r.db.QueryRow(`
+		WITH finished AS (
+			UPDATE risk_assessment_job_items
+			SET status = $3, assessment_id = NULLIF($4, '')::uuid, overall_score = $5,
+			    degraded = $6, error = NULLIF($7, ''), updated_at = $8
+			WHERE job_id = $1 AND position = $2 AND status = 'pending'
+			RETURNING status
+		), counted AS (
+			SELECT COUNT(*) FILTER (WHERE status = 'succeeded') AS done_ok,
+			       COUNT(*) FILTER (WHERE status = 'failed') AS done_failed
+			FROM finished
+		)
+		UPDATE risk_assessment_jobs
+		SET succeeded = succeeded + done_ok,
+		    failed = failed + done_failed,
+		    status = CASE WHEN succeeded + failed + done_ok + done_failed >= total
+		                  THEN 'completed' ELSE status END,
+		    completed_at = CASE WHEN succeeded + failed + done_ok + done_failed >= total
+		                        THEN COALESCE(completed_at, $8) ELSE completed_at END
+		FROM counted
+		WHERE id = $1
+		RETURNING `+jobColumns+`, status = 'completed' AND done_ok + done_failed > 0`,
+		item.JobID, item.Position, item.Status, item.AssessmentID, item.OverallScore,
+		item.Degraded, item.Error, item.UpdatedAt,
+	)
+
+	var completed bool
+	job, err := scanJob(row, &completed)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, false, ErrNotFound
+		}
+		return nil, false, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return job, completed, nil
+}
+
+func scanItems(rows *sql.Rows) ([]*models.JobItem, error) {
+	items := make([]*models.JobItem, 0)
+	for rows.Next() {
+		item := &models.JobItem{}
+		var score sql.NullFloat64
+		if err := rows.Scan(&item.JobID, &item.Position, &item.EntityID, &item.EntityType, &item.Status,
+			&item.AssessmentID, &score, &item.Degraded, &item.Error, &item.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if score.Valid {
+			item.OverallScore = &score.Float64
+		}
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return items, nil
+}
+
+// scanJob scans the job columns followed by any extra destinations
+func scanJob(row rowScanner, extra ...interface{}) (*models.AssessmentJob, error) {
+	job := &models.AssessmentJob{}
+	var dimensions []byte
+	var startedAt, completedAt sql.NullTime
+	dest := append([]interface{}{&job.ID, &job.Status, &dimensions, &job.IncludeFactors, &job.Total,
+		&job.Succeeded, &job.Failed, &job.CreatedBy, &job.CreatedAt, &startedAt, &completedAt}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dimensions, &job.Dimensions); err != nil {
+		return nil, err
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	return job, nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+func positions(items []*models.JobItem) []int {
+	result := make([]int, len(items))
+	for i, item := range items {
+		result[i] = item.Position
+	}
+	return result
+}
+
+func TestInMemoryClaimItemsLeasesPendingItems(t *testing.T) {
+	repo := NewJobRepository()
+	items := make([]*models.JobItem, 5)
+	for i := range items {
+		items[i] = &models.JobItem{JobID: "job-1", Position: i, EntityID: "e", EntityType: "organization", Status: models.JobItemPending}
+	}
+	require.NoError(t, repo.CreateJob(&models.AssessmentJob{ID: "job-1", Status: models.JobStatusRunning, Total: 5}, items))
+	_, _, err := repo.FinishItem(&models.JobItem{JobID: "job-1", Position: 1, Status: models.JobItemSucceeded})
+	require.NoError(t, err)
+
+	now := time.Now()
+	claimed, err := repo.ClaimItems("job-1", "replica-a", now, time.Minute, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []int{0, 2}, positions(claimed))
+
+	// Another replica gets only the items nobody holds
+	claimed, err = repo.ClaimItems("job-1", "replica-b", now, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, positions(claimed))
+
+	claimed, err = repo.ClaimItems("job-1", "replica-b", now.Add(30*time.Second), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	// Unfinished items are claimable again once their lease runs out, as
+	// when the replica holding them went away
+	_, _, err = repo.FinishItem(&models.JobItem{JobID: "job-1", Position: 0, Status: models.JobItemSucceeded})
+	require.NoError(t, err)
+	claimed, err = repo.ClaimItems("job-1", "replica-c", now.Add(time.Minute), time.Minute, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3, 4}, positions(claimed))
+}
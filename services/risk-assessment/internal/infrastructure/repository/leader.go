@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"hash/fnv"
+	"sync"
+)
+
+// LeaderLock elects one replica to run singleton work such as the
+// reassessment scheduler
+type LeaderLock interface {
+	// TryAcquire reports whether this replica holds the lock, taking it if
+	// it is free. It never blocks waiting for another holder.
+	TryAcquire(ctx context.Context) (bool, error)
+	Release() error
+}
+
+type localLeaderLock struct{}
+
+// NewLocalLeaderLock is always held; it is used with the in-memory stores,
+// where each replica has its own data anyway
+func NewLocalLeaderLock() LeaderLock {
+	return localLeaderLock{}
+}
+
+func (localLeaderLock) TryAcquire(ctx context.Context) (bool, error) { return true, nil }
+func (localLeaderLock) Release() error                               { return nil }
+
+// postgresLeaderLock holds a session advisory lock on a dedicated
+// connection; the lock is released if that connection drops, letting
+// another replica take over
+type postgresLeaderLock struct {
+	db  *sql.DB
+	key int64
+
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+// NewPostgresLeaderLock elects a leader with pg_try_advisory_lock on a key
+// derived from name
+func NewPostgresLeaderLock(db *sql.DB, name string) LeaderLock {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return &postgresLeaderLock{db: db, key: int64(h.Sum64())}
+}
+
+func (l *postgresLeaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		// The session and its lock are gone
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+	l.conn = conn
+	return true, nil
+}
+
+func (l *postgresLeaderLock) Release() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, l.key)
+	l.conn.Close()
+	l.conn = nil
+	return err
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// NotificationRepository stores notifications and reads the notification
+// preferences users set through IAM
+type NotificationRepository interface {
+	Create(notification *models.Notification) error
+	GetByID(id string) (*models.Notification, error)
+	// ListInApp returns a page of a user's undismissed in-app
+	// notifications, newest first, and how many there are
+	ListInApp(userID string, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error)
+	// Update stores read, dismissed and delivery state
+	Update(notification *models.Notification) error
+	// SentSince reports whether the user got a notification with the dedup
+	// key on the channel since the given time
+	SentSince(userID, channel, dedupKey string, since time.Time) (bool, error)
+	// CountSince counts the user's notifications on a channel since the
+	// given time
+	CountSince(userID, channel string, since time.Time) (int, error)
+	// ClaimDue returns up to limit pending notifications due at now, oldest
+	// first, and pushes their next attempt back by lease so no other
+	// replica delivers them meanwhile
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*models.Notification, error)
+	// Preferences returns the user's preferences for a notification type
+	Preferences(userID, notificationType string) ([]*models.NotificationPreference, error)
+	SavePreference(pref *models.NotificationPreference) error
+}
+
+type inMemoryNotificationRepository struct {
+	notifications map[string]*models.Notification
+	preferences   map[string]*models.NotificationPreference
+	mu            sync.RWMutex
+}
+
+func NewNotificationRepository() NotificationRepository {
+	return &inMemoryNotificationRepository{
+		notifications: make(map[string]*models.Notification),
+		preferences:   make(map[string]*models.NotificationPreference),
+	}
+}
+
+func (r *inMemoryNotificationRepository) Create(notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *notification
+	r.notifications[notification.ID] = &c
+	return nil
+}
+
+func (r *inMemoryNotificationRepository) GetByID(id string) (*models.Notification, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	notification, exists := r.notifications[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *notification
+	return &c, nil
+}
+
+func (r *inMemoryNotificationRepository) ListInApp(userID string, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.Notification, 0)
+	for _, n := range r.notifications {
+		if n.UserID != userID || n.Channel != models.ChannelInApp || n.IsDismissed || (unreadOnly && n.IsRead) {
+			continue
+		}
+		c := *n
+		results = append(results, &c)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.After(results[j].CreatedAt) })
+
+	total := len(results)
+	if offset >= total {
+		return []*models.Notification{}, total, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, total, nil
+}
+
+func (r *inMemoryNotificationRepository) Update(notification *models.Notification) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.notifications[notification.ID]; !exists {
+		return ErrNotFound
+	}
+	c := *notification
+	r.notifications[notification.ID] = &c
+	return nil
+}
+
+func (r *inMemoryNotificationRepository) SentSince(userID, channel, dedupKey string, since time.Time) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.Channel == channel && n.DedupKey == dedupKey && !n.CreatedAt.Before(since) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *inMemoryNotificationRepository) CountSince(userID, channel string, since time.Time) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, n := range r.notifications {
+		if n.UserID == userID && n.Channel == channel && !n.CreatedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *inMemoryNotificationRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*models.Notification, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]*models.Notification, 0)
+	for _, n := range r.notifications {
+		if n.Status == models.NotificationPending && n.NextAttemptAt != nil && !n.NextAttemptAt.After(now) {
+			due = append(due, n)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextAttemptAt.Before(*due[j].NextAttemptAt) })
+	if limit > 0 && limit < len(due) {
+		due = due[:limit]
+	}
+
+	claimed := make([]*models.Notification, 0, len(due))
+	for _, n := range due {
+		c := *n
+		claimed = append(claimed, &c)
+		leased := now.Add(lease)
+		n.NextAttemptAt = &leased
+	}
+	sort.Slice(claimed, func(i, j int) bool { return claimed[i].CreatedAt.Before(claimed[j].CreatedAt) })
+	return claimed, nil
+}
+
+func (r *inMemoryNotificationRepository) Preferences(userID, notificationType string) ([]*models.NotificationPreference, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	prefs := make([]*models.NotificationPreference, 0)
+	for _, p := range r.preferences {
+		if p.UserID == userID && p.NotificationType == notificationType {
+			c := *p
+			prefs = append(prefs, &c)
+		}
+	}
+	sort.Slice(prefs, func(i, j int) bool { return prefs[i].Channel < prefs[j].Channel })
+	return prefs, nil
+}
+
+func (r *inMemoryNotificationRepository) SavePreference(pref *models.NotificationPreference) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := *pref
+	r.preferences[pref.UserID+"/"+pref.NotificationType+"/"+pref.Channel] = &c
+	return nil
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresNotificationRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresNotificationRepository stores notifications in the shared
+// notifications table and reads notification_preferences. A preference
+// delivers to metadata->>'target', or for email to the user's address.
+func NewPostgresNotificationRepository(db *sql.DB) NotificationRepository {
+	return &postgresNotificationRepository{db: db}
+}
+
+const notificationColumns = `
+	id, user_id, notification_type, title, COALESCE(message, ''), priority, channel,
+	COALESCE(source_type, ''), COALESCE(source_id::text, ''), COALESCE(action_url, ''),
+	COALESCE(is_read, false), read_at, COALESCE(is_dismissed, false), dismissed_at,
+	status, COALESCE(target, ''), attempts, next_attempt_at, delivered_at,
+	COALESCE(last_error, ''), COALESCE(dedup_key, ''), created_at`
+
+func (r *postgresNotificationRepository) Create(n *models.Notification) error {
+	_, err := r.db.Exec(`
+		INSERT INTO notifications (
+			id, user_id, notification_type, title, message, priority, channel, source_type,
+			source_id, action_url, status, target, attempts, next_attempt_at, delivered_at,
+			dedup_key, created_at
+		) VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, NULLIF($8, ''), NULLIF($9, '')::uuid,
+			NULLIF($10, ''), $11, NULLIF($12, ''), $13, $14, $15, NULLIF($16, ''), $17)`,
+		n.ID, n.UserID, n.NotificationType, n.Title, n.Message, n.Priority, n.Channel, n.SourceType,
+		n.SourceID, n.ActionURL, n.Status, n.Target, n.Attempts, n.NextAttemptAt, n.DeliveredAt,
+		n.DedupKey, n.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresNotificationRepository) GetByID(id string) (*models.Notification, error) {
+	n, err := scanNotification(r.db.QueryRow(`SELECT `+notificationColumns+` FROM notifications WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return n, nil
+}
+
+func (r *postgresNotificationRepository) ListInApp(userID string, unreadOnly bool, limit, offset int) ([]*models.Notification, int, error) {
+	const where = `
+		WHERE user_id = $1 AND channel = 'in_app' AND NOT COALESCE(is_dismissed, false)
+		  AND (NOT $2 OR NOT COALESCE(is_read, false))`
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM notifications`+where, userID, unreadOnly).Scan(&total); err != nil {
+		if isInvalidUUID(err) {
+			return []*models.Notification{}, 0, nil
+		}
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	rows, err := r.db.Query(`SELECT `+notificationColumns+` FROM notifications`+where+`
+		ORDER BY created_at DESC
+		LIMIT $3 OFFSET $4`, userID, unreadOnly, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	notifications, err := scanNotifications(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return notifications, total, nil
+}
+
+func (r *postgresNotificationRepository) Update(n *models.Notification) error {
+	result, err := r.db.Exec(`
+		UPDATE notifications
+		SET title = $2, message = NULLIF($3, ''), is_read = $4, read_at = $5, is_dismissed = $6,
+		    dismissed_at = $7, status = $8, attempts = $9, next_attempt_at = $10, delivered_at = $11,
+		    last_error = NULLIF($12, '')
+		WHERE id = $1`,
+		n.ID, n.Title, n.Message, n.IsRead, n.ReadAt, n.IsDismissed,
+		n.DismissedAt, n.Status, n.Attempts, n.NextAttemptAt, n.DeliveredAt,
+		n.LastError,
+	)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresNotificationRepository) SentSince(userID, channel, dedupKey string, since time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM notifications
+			WHERE user_id = $1 AND channel = $2 AND dedup_key = $3 AND created_at >= $4
+		)`, userID, channel, dedupKey, since).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return exists, nil
+}
+
+func (r *postgresNotificationRepository) CountSince(userID, channel string, since time.Time) (int, error) {
+	var count int
+	err := r.db.QueryRow(`
+		SELECT COUNT(*) FROM notifications
+		WHERE user_id = $1 AND channel = $2 AND created_at >= $3`, userID, channel, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return count, nil
+}
+
+func (r *postgresNotificationRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*models.Notification, error) {
+	// SKIP LOCKED lets replicas claim disjoint batches
+	rows, err := r.db.Query(`
+		WITH claimed AS (
+			UPDATE notifications SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT id FROM notifications
+				WHERE status = 'pending' AND next_attempt_at <= $1
+				ORDER BY next_attempt_at
+				LIMIT $3
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+notificationColumns+` FROM claimed ORDER BY created_at`,
+		now, now.Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	return scanNotifications(rows)
+}
+
+func (r *postgresNotificationRepository) Preferences(userID, notificationType string) ([]*models.NotificationPreference, error) {
+	rows, err := r.db.Query(`
+		SELECT p.channel, COALESCE(p.is_enabled, true), p.quiet_hours_start::text, p.quiet_hours_end::text,
+		       COALESCE(p.min_priority, 'low'),
+		       COALESCE(NULLIF(p.metadata->>'target', ''), CASE WHEN p.channel = 'email' THEN u.email END, '')
+		FROM notification_preferences p
+		JOIN users u ON u.id = p.user_id
+		WHERE p.user_id = $1 AND p.notification_type = $2
+		ORDER BY p.channel`, userID, notificationType)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return []*models.NotificationPreference{}, nil
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	prefs := make([]*models.NotificationPreference, 0)
+	for rows.Next() {
+		pref := &models.NotificationPreference{UserID: userID, NotificationType: notificationType}
+		var quietStart, quietEnd sql.NullString
+		if err := rows.Scan(&pref.Channel, &pref.Enabled, &quietStart, &quietEnd, &pref.MinPriority, &pref.Target); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		if quietStart.Valid && quietEnd.Valid {
+			if pref.QuietHours, err = models.ParseQuietHours(quietStart.String, quietEnd.String); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+			}
+		}
+		prefs = append(prefs, pref)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return prefs, nil
+}
+
+func (r *postgresNotificationRepository) SavePreference(pref *models.NotificationPreference) error {
+	var quietStart, quietEnd interface{}
+	if pref.QuietHours != nil {
+		quietStart = clockString(pref.QuietHours.Start)
+		quietEnd = clockString(pref.QuietHours.End)
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO notification_preferences (
+			user_id, notification_type, channel, is_enabled, quiet_hours_start, quiet_hours_end,
+			min_priority, metadata
+		) VALUES ($1, $2, $3, $4, $5::time, $6::time, $7,
+			CASE WHEN $8 = '' THEN '{}'::jsonb ELSE jsonb_build_object('target', $8::text) END)
+		ON CONFLICT (user_id, notification_type, channel) DO UPDATE
+		SET is_enabled = EXCLUDED.is_enabled, quiet_hours_start = EXCLUDED.quiet_hours_start,
+		    quiet_hours_end = EXCLUDED.quiet_hours_end, min_priority = EXCLUDED.min_priority,
+		    metadata = notification_preferences.metadata || EXCLUDED.metadata,
+		    updated_at = CURRENT_TIMESTAMP`,
+		pref.UserID, pref.NotificationType, pref.Channel, pref.Enabled, quietStart, quietEnd,
+		pref.MinPriority, pref.Target,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func clockString(d time.Duration) string {
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC).Add(d).Format("15:04:05")
+}
+
+func scanNotification(row rowScanner) (*models.Notification, error) {
+	n := &models.Notification{}
+	var readAt, dismissedAt, nextAttemptAt, deliveredAt sql.NullTime
+	if err := row.Scan(&n.ID, &n.UserID, &n.NotificationType, &n.Title, &n.Message, &n.Priority, &n.Channel,
+		&n.SourceType, &n.SourceID, &n.ActionURL, &n.IsRead, &readAt, &n.IsDismissed, &dismissedAt,
+		&n.Status, &n.Target, &n.Attempts, &nextAttemptAt, &deliveredAt,
+		&n.LastError, &n.DedupKey, &n.CreatedAt); err != nil {
+		return nil, err
+	}
+	if readAt.Valid {
+		n.ReadAt = &readAt.Time
+	}
+	if dismissedAt.Valid {
+		n.DismissedAt = &dismissedAt.Time
+	}
+	if nextAttemptAt.Valid {
+		n.NextAttemptAt = &nextAttemptAt.Time
+	}
+	if deliveredAt.Valid {
+		n.DeliveredAt = &deliveredAt.Time
+	}
+	return n, nil
+}
+
+func scanNotifications(rows *sql.Rows) ([]*models.Notification, error) {
+	notifications := make([]*models.Notification, 0)
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return notifications, nil
+}
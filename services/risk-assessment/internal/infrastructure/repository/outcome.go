@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// OutcomeRepository stores the real events recorded against entities. An
+// entity has at most one outcome of a type at a given time.
+type OutcomeRepository interface {
+	// Create adds an outcome, or returns ErrConflict if the same outcome
+	// is already recorded
+	Create(outcome *models.Outcome) error
+	GetByID(id string) (*models.Outcome, error)
+	// List returns a page of the matching outcomes, latest first, and how
+	// many match. A zero limit returns every match.
+	List(filter models.OutcomeFilter) ([]*models.Outcome, int, error)
+	Delete(id string) error
+}
+
+type inMemoryOutcomeRepository struct {
+	outcomes map[string]*models.Outcome
+	mu       sync.RWMutex
+}
+
+func NewOutcomeRepository() OutcomeRepository {
+	return &inMemoryOutcomeRepository{
+		outcomes: make(map[string]*models.Outcome),
+	}
+}
+
+func (r *inMemoryOutcomeRepository) Create(outcome *models.Outcome) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, o := range r.outcomes {
+		if o.EntityID == outcome.EntityID && o.Type == outcome.Type && o.OccurredAt.Equal(outcome.OccurredAt) {
+			return ErrConflict
+		}
+	}
+	c := *outcome
+	r.outcomes[outcome.ID] = &c
+	return nil
+}
+
+func (r *inMemoryOutcomeRepository) GetByID(id string) (*models.Outcome, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	outcome, exists := r.outcomes[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *outcome
+	return &c, nil
+}
+
+func (r *inMemoryOutcomeRepository) List(filter models.OutcomeFilter) ([]*models.Outcome, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matched := make([]*models.Outcome, 0)
+	for _, o := range r.outcomes {
+		if filter.EntityID != "" && o.EntityID != filter.EntityID {
+			continue
+		}
+		if len(filter.Types) > 0 && !containsType(filter.Types, o.Type) {
+			continue
+		}
+		if !filter.From.IsZero() && o.OccurredAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && o.OccurredAt.After(filter.To) {
+			continue
+		}
+		c := *o
+		matched = append(matched, &c)
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].OccurredAt.After(matched[j].OccurredAt) })
+
+	total := len(matched)
+	if filter.Offset >= total {
+		return []*models.Outcome{}, total, nil
+	}
+	matched = matched[filter.Offset:]
+	if filter.Limit > 0 && filter.Limit < len(matched) {
+		matched = matched[:filter.Limit]
+	}
+	return matched, total, nil
+}
+
+func (r *inMemoryOutcomeRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.outcomes[id]; !exists {
+		return ErrNotFound
+	}
+	delete(r.outcomes, id)
+	return nil
+}
+
+func containsType(types []string, t string) bool {
+	for _, candidate := range types {
+		if candidate == t {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresOutcomeRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresOutcomeRepository stores outcomes in risk_outcomes
+func NewPostgresOutcomeRepository(db *sql.DB) OutcomeRepository {
+	return &postgresOutcomeRepository{db: db}
+}
+
+const selectOutcome = `
+	SELECT id, entity_id, COALESCE(entity_type, ''), outcome_type, occurred_at,
+	       COALESCE(description, ''), COALESCE(source, ''), COALESCE(recorded_by, ''), created_at
+	FROM risk_outcomes`
+
+// outcomeFilter matches the filter's arguments $1 to $4
+const outcomeFilter = `
+	WHERE ($1 = '' OR entity_id = $1)
+	  AND (cardinality($2::text[]) = 0 OR outcome_type = ANY($2))
+	  AND ($3::timestamp IS NULL OR occurred_at >= $3)
+	  AND ($4::timestamp IS NULL OR occurred_at <= $4)`
+
+func (r *postgresOutcomeRepository) Create(outcome *models.Outcome) error {
+	_, err := r.db.Exec(`
+		INSERT INTO risk_outcomes (
+			id, entity_id, entity_type, outcome_type, occurred_at, description, source, recorded_by, created_at
+		) VALUES ($1, $2, NULLIF($3, ''), $4, $5, NULLIF($6, ''), NULLIF($7, ''), NULLIF($8, ''), $9)`,
+		outcome.ID, outcome.EntityID, outcome.EntityType, outcome.Type, outcome.OccurredAt,
+		outcome.Description, outcome.Source, outcome.RecordedBy, outcome.CreatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresOutcomeRepository) GetByID(id string) (*models.Outcome, error) {
+	outcome, err := scanOutcome(r.db.QueryRow(selectOutcome+` WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return outcome, nil
+}
+
+func (r *postgresOutcomeRepository) List(filter models.OutcomeFilter) ([]*models.Outcome, int, error) {
+	types := filter.Types
+	if types == nil {
+		types = []string{}
+	}
+	var from, to interface{}
+	if !filter.From.IsZero() {
+		from = filter.From
+	}
+	if !filter.To.IsZero() {
+		to = filter.To
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM risk_outcomes`+outcomeFilter,
+		filter.EntityID, pq.Array(types), from, to).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if filter.Limit > 0 {
+		pageSize = filter.Limit
+	}
+	rows, err := r.db.Query(selectOutcome+outcomeFilter+`
+		ORDER BY occurred_at DESC
+		LIMIT $5 OFFSET $6`, filter.EntityID, pq.Array(types), from, to, pageSize, filter.Offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	outcomes := make([]*models.Outcome, 0)
+	for rows.Next() {
+		outcome, err := scanOutcome(rows)
+		if err != nil {
+			return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		outcomes = append(outcomes, outcome)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return outcomes, total, nil
+}
+
+func (r *postgresOutcomeRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM risk_outcomes WHERE id = $1`, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanOutcome(row rowScanner) (*models.Outcome, error) {
+	outcome := &models.Outcome{}
+	if err := row.Scan(&outcome.ID, &outcome.EntityID, &outcome.EntityType, &outcome.Type, &outcome.OccurredAt,
+		&outcome.Description, &outcome.Source, &outcome.RecordedBy, &outcome.CreatedAt); err != nil {
+		return nil, err
+	}
+	return outcome, nil
+}
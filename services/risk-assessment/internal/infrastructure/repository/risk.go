@@ -2,6 +2,7 @@ package repository
 
 import (
 	models "atlas-core-api/services/risk-assessment/internal/domain"
+	"sort"
 	"sync"
 	"time"
 )
@@ -9,14 +10,22 @@ import (
 type RiskRepository interface {
 	Create(assessment *models.RiskAssessment) error
 	GetByID(id string) (*models.RiskAssessment, error)
-	GetByEntityID(entityID string, limit int) ([]*models.RiskAssessment, error)
+	// GetByEntityID returns a page of an entity's assessments, newest first,
+	// and the total number it has
+	GetByEntityID(entityID string, limit, offset int) ([]*models.RiskAssessment, int, error)
 	GetTrends(entityID, dimension, period string) ([]*models.RiskAssessment, error)
+	// ListBetween returns a page of the assessments made from from up to
+	// but not including to, oldest first
+	ListBetween(from, to time.Time, limit, offset int) ([]*models.RiskAssessment, error)
 	ListAlerts(activeOnly bool) ([]*models.RiskAlert, error)
+	GetAlert(id string) (*models.RiskAlert, error)
 	CreateAlert(alert *models.RiskAlert) error
 	UpdateAlert(id string, alert *models.RiskAlert) error
 	DeleteAlert(id string) error
 }
 
+// inMemoryRiskRepository keeps assessments in process memory; it is used in
+// tests and when no database is configured
 type inMemoryRiskRepository struct {
 	assessments map[string]*models.RiskAssessment
 	alerts      map[string]*models.RiskAlert
@@ -48,7 +57,7 @@ func (r *inMemoryRiskRepository) GetByID(id string) (*models.RiskAssessment, err
 	return assessment, nil
 }
 
-func (r *inMemoryRiskRepository) GetByEntityID(entityID string, limit int) ([]*models.RiskAssessment, error) {
+func (r *inMemoryRiskRepository) GetByEntityID(entityID string, limit, offset int) ([]*models.RiskAssessment, int, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
@@ -68,30 +77,23 @@ func (r *inMemoryRiskRepository) GetByEntityID(entityID string, limit int) ([]*m
 		}
 	}
 	
+	total := len(results)
+	if offset >= total {
+		return []*models.RiskAssessment{}, total, nil
+	}
+	results = results[offset:]
 	if limit > 0 && limit < len(results) {
-		return results[:limit], nil
+		results = results[:limit]
 	}
-	return results, nil
+	return results, total, nil
 }
 
 func (r *inMemoryRiskRepository) GetTrends(entityID, dimension, period string) ([]*models.RiskAssessment, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 	
-	// Calculate time window based on period
-	now := time.Now()
-	var startTime time.Time
-	switch period {
-	case "7d":
-		startTime = now.AddDate(0, 0, -7)
-	case "30d":
-		startTime = now.AddDate(0, 0, -30)
-	case "90d":
-		startTime = now.AddDate(0, 0, -90)
-	default:
-		startTime = now.AddDate(0, 0, -30) // Default to 30 days
-	}
-	
+	startTime := TrendWindowStart(period, time.Now())
+
 	results := make([]*models.RiskAssessment, 0)
 	for _, assessment := range r.assessments {
 		if assessment.EntityID == entityID && assessment.Timestamp.After(startTime) {
@@ -101,15 +103,35 @@ func (r *inMemoryRiskRepository) GetTrends(entityID, dimension, period string) (
 		}
 	}
 	
-	// Sort by timestamp ascending
-	for i := 0; i < len(results)-1; i++ {
-		for j := i + 1; j < len(results); j++ {
-			if results[i].Timestamp.After(results[j].Timestamp) {
-				results[i], results[j] = results[j], results[i]
-			}
+	sort.Slice(results, func(i, j int) bool { return results[i].Timestamp.Before(results[j].Timestamp) })
+	
+	return results, nil
+}
+
+func (r *inMemoryRiskRepository) ListBetween(from, to time.Time, limit, offset int) ([]*models.RiskAssessment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.RiskAssessment, 0)
+	for _, assessment := range r.assessments {
+		if !assessment.Timestamp.Before(from) && assessment.Timestamp.Before(to) {
+			results = append(results, assessment)
 		}
 	}
-	
+	sort.Slice(results, func(i, j int) bool {
+		if !results[i].Timestamp.Equal(results[j].Timestamp) {
+			return results[i].Timestamp.Before(results[j].Timestamp)
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	if offset >= len(results) {
+		return []*models.RiskAssessment{}, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
 	return results, nil
 }
 
@@ -120,16 +142,31 @@ func (r *inMemoryRiskRepository) ListAlerts(activeOnly bool) ([]*models.RiskAler
 	results := make([]*models.RiskAlert, 0)
 	for _, alert := range r.alerts {
 		if !activeOnly || alert.Active {
-			results = append(results, alert)
+			c := *alert
+			results = append(results, &c)
 		}
 	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
 	return results, nil
 }
 
+func (r *inMemoryRiskRepository) GetAlert(id string) (*models.RiskAlert, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	alert, exists := r.alerts[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *alert
+	return &c, nil
+}
+
 func (r *inMemoryRiskRepository) CreateAlert(alert *models.RiskAlert) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
-	r.alerts[alert.ID] = alert
+	c := *alert
+	r.alerts[alert.ID] = &c
 	return nil
 }
 
@@ -140,7 +177,8 @@ func (r *inMemoryRiskRepository) UpdateAlert(id string, alert *models.RiskAlert)
 	if _, exists := r.alerts[id]; !exists {
 		return ErrNotFound
 	}
-	r.alerts[id] = alert
+	c := *alert
+	r.alerts[id] = &c
 	return nil
 }
 
@@ -152,10 +190,37 @@ func (r *inMemoryRiskRepository) DeleteAlert(id string) error {
 		return ErrNotFound
 	}
 	delete(r.alerts, id)
+	// A group rule takes the alerts it raised with it
+	for childID, alert := range r.alerts {
+		if alert.RuleID == id {
+			delete(r.alerts, childID)
+		}
+	}
 	return nil
 }
 
-var ErrNotFound = &RepositoryError{Message: "not found"}
+// TrendWindowStart returns the start of the trend window for period ("7d",
+// "30d", "90d" or "1y"; the service rejects anything else, which means 30
+// days here)
+func TrendWindowStart(period string, now time.Time) time.Time {
+	switch period {
+	case "7d":
+		return now.AddDate(0, 0, -7)
+	case "90d":
+		return now.AddDate(0, 0, -90)
+	case "1y":
+		return now.AddDate(-1, 0, 0)
+	default:
+		return now.AddDate(0, 0, -30)
+	}
+}
+
+var (
+	ErrNotFound = &RepositoryError{Message: "not found"}
+	ErrDatabase = &RepositoryError{Message: "database operation failed"}
+	// ErrConflict is returned when a record with the same key already exists
+	ErrConflict = &RepositoryError{Message: "already exists"}
+)
 
 type RepositoryError struct {
 	Message string
@@ -0,0 +1,352 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresRiskRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresRiskRepository stores assessments and alert rules in the
+// risk_assessments and risk_alerts tables. Dimensions and factors are kept
+// as JSONB; per-dimension scores are also written to the legacy columns.
+func NewPostgresRiskRepository(db *sql.DB) RiskRepository {
+	return &postgresRiskRepository{db: db}
+}
+
+const selectAssessment = `
+	SELECT id, entity_id, entity_type, overall_score, COALESCE(confidence, 0),
+	       dimensions, factors, assessed_at, valid_until,
+	       COALESCE(scoring_model_id::text, ''), COALESCE(scoring_model_version, 0),
+	       low_confidence, warnings, tags
+	FROM risk_assessments`
+
+func (r *postgresRiskRepository) Create(assessment *models.RiskAssessment) error {
+	dimensions, err := json.Marshal(assessment.Dimensions)
+	if err != nil {
+		return err
+	}
+	factors := assessment.Factors
+	if factors == nil {
+		factors = []models.RiskFactor{}
+	}
+	factorsJSON, err := json.Marshal(factors)
+	if err != nil {
+		return err
+	}
+	warnings := assessment.Warnings
+	if warnings == nil {
+		warnings = []string{}
+	}
+	warningsJSON, err := json.Marshal(warnings)
+	if err != nil {
+		return err
+	}
+	tags := assessment.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+	tagsJSON, err := json.Marshal(tags)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO risk_assessments (
+			id, entity_id, entity_type, overall_score, confidence, dimensions, factors,
+			operational_risk, financial_risk, reputational_risk, geopolitical_risk, compliance_risk,
+			assessed_at, valid_until, scoring_model_id, scoring_model_version, low_confidence, warnings,
+			tags, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, '')::uuid, $16, $17, $18, $19, $13, $13)`,
+		assessment.ID, assessment.EntityID, assessment.EntityType, assessment.OverallScore,
+		assessment.Confidence, dimensions, factorsJSON,
+		dimensionScore(assessment, models.DimensionOperational),
+		dimensionScore(assessment, models.DimensionFinancial),
+		dimensionScore(assessment, models.DimensionReputational),
+		dimensionScore(assessment, models.DimensionGeopolitical),
+		dimensionScore(assessment, models.DimensionCompliance),
+		assessment.Timestamp, assessment.ValidUntil, assessment.ModelID, assessment.ModelVersion,
+		assessment.LowConfidence, warningsJSON, tagsJSON,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresRiskRepository) GetByID(id string) (*models.RiskAssessment, error) {
+	assessment, err := scanAssessment(r.db.QueryRow(selectAssessment+` WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return assessment, nil
+}
+
+func (r *postgresRiskRepository) GetByEntityID(entityID string, limit, offset int) ([]*models.RiskAssessment, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM risk_assessments WHERE entity_id = $1`, entityID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	rows, err := r.db.Query(selectAssessment+`
+		WHERE entity_id = $1
+		ORDER BY assessed_at DESC
+		LIMIT $2 OFFSET $3`, entityID, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	assessments, err := scanAssessments(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return assessments, total, nil
+}
+
+func (r *postgresRiskRepository) GetTrends(entityID, dimension, period string) ([]*models.RiskAssessment, error) {
+	rows, err := r.db.Query(selectAssessment+`
+		WHERE entity_id = $1 AND assessed_at > $2 AND ($3 = '' OR dimensions ? $3)
+		ORDER BY assessed_at ASC`,
+		entityID, TrendWindowStart(period, time.Now()), dimension)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	return scanAssessments(rows)
+}
+
+func (r *postgresRiskRepository) ListBetween(from, to time.Time, limit, offset int) ([]*models.RiskAssessment, error) {
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	rows, err := r.db.Query(selectAssessment+`
+		WHERE assessed_at >= $1 AND assessed_at < $2
+		ORDER BY assessed_at, id
+		LIMIT $3 OFFSET $4`, from, to, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	return scanAssessments(rows)
+}
+
+const selectAlert = `
+	SELECT id, COALESCE(entity_id, ''), COALESCE(entity_type, ''), COALESCE(tag, ''),
+	       COALESCE(rule_id::text, ''), COALESCE(expression, ''), COALESCE(dimension, ''),
+	       COALESCE(threshold, 0), COALESCE(condition, ''), hysteresis, cooldown_seconds, is_active,
+	       state, severity, is_triggered, last_triggered_at, triggered_score,
+	       COALESCE(acknowledged_by, ''), acknowledged_at, COALESCE(resolver, ''), resolved_at,
+	       COALESCE(resolution, ''), COALESCE(created_by, ''), created_at, updated_at
+	FROM risk_alerts
+	WHERE alert_type = 'threshold'`
+
+func (r *postgresRiskRepository) ListAlerts(activeOnly bool) ([]*models.RiskAlert, error) {
+	rows, err := r.db.Query(selectAlert+` AND (NOT $1 OR is_active) ORDER BY created_at`, activeOnly)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	alerts := make([]*models.RiskAlert, 0)
+	for rows.Next() {
+		alert, err := scanAlert(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		alerts = append(alerts, alert)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return alerts, nil
+}
+
+func (r *postgresRiskRepository) GetAlert(id string) (*models.RiskAlert, error) {
+	alert, err := scanAlert(r.db.QueryRow(selectAlert+` AND id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return alert, nil
+}
+
+func (r *postgresRiskRepository) CreateAlert(alert *models.RiskAlert) error {
+	_, err := r.db.Exec(`
+		INSERT INTO risk_alerts (
+			id, alert_type, severity, title, entity_id, entity_type, tag, rule_id, expression,
+			dimension, threshold, condition, hysteresis, cooldown_seconds, is_active, state,
+			is_triggered, last_triggered_at, triggered_score, created_by, created_at, updated_at
+		) VALUES ($1, 'threshold', $2, $3, NULLIF($4, ''), NULLIF($5, ''), NULLIF($6, ''), NULLIF($7, '')::uuid,
+			NULLIF($8, ''), NULLIF($9, ''), $10, NULLIF($11, ''), $12, $13, $14, $15, $16, $17, $18,
+			NULLIF($19, ''), $20, $21)`,
+		alert.ID, alert.Severity, alertTitle(alert), alert.EntityID, alert.EntityType, alert.Tag, alert.RuleID,
+		alert.Expression, alert.Dimension, alert.Threshold, alert.Condition, alert.Hysteresis,
+		alert.CooldownSeconds, alert.Active, alert.State, alert.Triggered, alert.LastTrigger,
+		alert.TriggeredScore, alert.CreatedBy, alert.CreatedAt, alert.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresRiskRepository) UpdateAlert(id string, alert *models.RiskAlert) error {
+	result, err := r.db.Exec(`
+		UPDATE risk_alerts
+		SET title = $2, dimension = NULLIF($3, ''), threshold = $4, condition = NULLIF($5, ''), hysteresis = $6,
+		    cooldown_seconds = $7, is_active = $8, state = $9, severity = $10, is_triggered = $11,
+		    last_triggered_at = $12, triggered_score = $13, acknowledged_by = NULLIF($14, ''),
+		    acknowledged_at = $15, resolver = NULLIF($16, ''), resolved_at = $17,
+		    resolution = NULLIF($18, ''), is_resolved = $9 = 'resolved', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND alert_type = 'threshold'`,
+		id, alertTitle(alert), alert.Dimension, alert.Threshold, alert.Condition, alert.Hysteresis,
+		alert.CooldownSeconds, alert.Active, alert.State, alert.Severity, alert.Triggered,
+		alert.LastTrigger, alert.TriggeredScore, alert.AcknowledgedBy,
+		alert.AcknowledgedAt, alert.ResolvedBy, alert.ResolvedAt,
+		alert.Resolution,
+	)
+	return alertWriteResult(result, err)
+}
+
+func (r *postgresRiskRepository) DeleteAlert(id string) error {
+	result, err := r.db.Exec(`DELETE FROM risk_alerts WHERE id = $1 AND alert_type = 'threshold'`, id)
+	return alertWriteResult(result, err)
+}
+
+func scanAlert(row rowScanner) (*models.RiskAlert, error) {
+	alert := &models.RiskAlert{}
+	var lastTrigger, acknowledgedAt, resolvedAt sql.NullTime
+	var triggeredScore sql.NullFloat64
+	if err := row.Scan(&alert.ID, &alert.EntityID, &alert.EntityType, &alert.Tag, &alert.RuleID,
+		&alert.Expression, &alert.Dimension, &alert.Threshold, &alert.Condition,
+		&alert.Hysteresis, &alert.CooldownSeconds, &alert.Active, &alert.State, &alert.Severity,
+		&alert.Triggered, &lastTrigger, &triggeredScore, &alert.AcknowledgedBy, &acknowledgedAt,
+		&alert.ResolvedBy, &resolvedAt, &alert.Resolution, &alert.CreatedBy, &alert.CreatedAt, &alert.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if lastTrigger.Valid {
+		alert.LastTrigger = &lastTrigger.Time
+	}
+	if triggeredScore.Valid {
+		alert.TriggeredScore = &triggeredScore.Float64
+	}
+	if acknowledgedAt.Valid {
+		alert.AcknowledgedAt = &acknowledgedAt.Time
+	}
+	if resolvedAt.Valid {
+		alert.ResolvedAt = &resolvedAt.Time
+	}
+	return alert, nil
+}
+
+func alertWriteResult(result sql.Result, err error) error {
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func alertTitle(alert *models.RiskAlert) string {
+	if alert.Expression != "" {
+		// title is VARCHAR(255)
+		if title := "risk rule " + alert.Expression; len(title) <= 255 {
+			return title
+		}
+		return "risk rule"
+	}
+	return fmt.Sprintf("%s risk %s %.2f", alert.Dimension, alert.Condition, alert.Threshold)
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAssessment(row rowScanner) (*models.RiskAssessment, error) {
+	assessment := &models.RiskAssessment{}
+	var dimensions, factors, warnings, tags []byte
+	var validUntil sql.NullTime
+	if err := row.Scan(&assessment.ID, &assessment.EntityID, &assessment.EntityType, &assessment.OverallScore,
+		&assessment.Confidence, &dimensions, &factors, &assessment.Timestamp, &validUntil,
+		&assessment.ModelID, &assessment.ModelVersion, &assessment.LowConfidence, &warnings, &tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(tags, &assessment.Tags); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(warnings, &assessment.Warnings); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dimensions, &assessment.Dimensions); err != nil {
+		return nil, err
+	}
+	for _, d := range assessment.Dimensions {
+		assessment.Degraded = assessment.Degraded || d.Degraded
+	}
+	if err := json.Unmarshal(factors, &assessment.Factors); err != nil {
+		return nil, err
+	}
+	if validUntil.Valid {
+		assessment.ValidUntil = validUntil.Time
+	}
+	return assessment, nil
+}
+
+func scanAssessments(rows *sql.Rows) ([]*models.RiskAssessment, error) {
+	assessments := make([]*models.RiskAssessment, 0)
+	for rows.Next() {
+		assessment, err := scanAssessment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		assessments = append(assessments, assessment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return assessments, nil
+}
+
+// dimensionScore returns the score for a legacy per-dimension column, or
+// NULL when the dimension was not assessed
+func dimensionScore(assessment *models.RiskAssessment, dimension string) interface{} {
+	if d, ok := assessment.Dimensions[dimension]; ok {
+		return d.Score
+	}
+	return nil
+}
+
+// isInvalidUUID reports whether Postgres rejected an id that is not a UUID
+func isInvalidUUID(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "22P02"
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+func TestInMemoryGetByEntityIDPaginates(t *testing.T) {
+	repo := NewRiskRepository()
+	now := time.Now()
+	for i, id := range []string{"a1", "a2", "a3", "a4", "a5"} {
+		require.NoError(t, repo.Create(&models.RiskAssessment{
+			ID:        id,
+			EntityID:  "entity-1",
+			Timestamp: now.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, repo.Create(&models.RiskAssessment{ID: "other", EntityID: "entity-2", Timestamp: now}))
+
+	page, total, err := repo.GetByEntityID("entity-1", 2, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	require.Len(t, page, 2)
+	assert.Equal(t, "a4", page[0].ID, "newest first, after skipping one")
+	assert.Equal(t, "a3", page[1].ID)
+
+	page, total, err = repo.GetByEntityID("entity-1", 10, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 5, total)
+	assert.Empty(t, page)
+}
+
+func TestTrendWindowStart(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, now.AddDate(0, 0, -7), TrendWindowStart("7d", now))
+	assert.Equal(t, now.AddDate(0, 0, -90), TrendWindowStart("90d", now))
+	assert.Equal(t, now.AddDate(0, 0, -30), TrendWindowStart("30d", now))
+	assert.Equal(t, now.AddDate(-1, 0, 0), TrendWindowStart("1y", now))
+	assert.Equal(t, now.AddDate(0, 0, -30), TrendWindowStart("bogus", now))
+}
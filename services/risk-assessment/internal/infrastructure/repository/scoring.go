@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// ScoringModelRepository stores versioned scoring models. Versions are
+// numbered per entity type and at most one version per entity type is active.
+type ScoringModelRepository interface {
+	// Create stores a draft as the next version for its entity type
+	Create(model *models.ScoringModel) error
+	GetByID(id string) (*models.ScoringModel, error)
+	// List returns models for entityType, or all models when it is empty,
+	// newest version first
+	List(entityType string) ([]*models.ScoringModel, error)
+	Active(entityType string) (*models.ScoringModel, error)
+	// Activate makes a model the active version for its entity type and
+	// retires the one it replaces
+	Activate(id string) (*models.ScoringModel, error)
+}
+
+type inMemoryScoringModelRepository struct {
+	models map[string]*models.ScoringModel
+	mu     sync.RWMutex
+}
+
+func NewScoringModelRepository() ScoringModelRepository {
+	return &inMemoryScoringModelRepository{
+		models: make(map[string]*models.ScoringModel),
+	}
+}
+
+func (r *inMemoryScoringModelRepository) Create(model *models.ScoringModel) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	version := 0
+	for _, m := range r.models {
+		if m.EntityType == model.EntityType && m.Version > version {
+			version = m.Version
+		}
+	}
+	model.Version = version + 1
+	model.Status = models.ModelStatusDraft
+	r.models[model.ID] = model
+	return nil
+}
+
+func (r *inMemoryScoringModelRepository) GetByID(id string) (*models.ScoringModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	model, exists := r.models[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return model, nil
+}
+
+func (r *inMemoryScoringModelRepository) List(entityType string) ([]*models.ScoringModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.ScoringModel, 0)
+	for _, m := range r.models {
+		if entityType == "" || m.EntityType == entityType {
+			results = append(results, m)
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].EntityType != results[j].EntityType {
+			return results[i].EntityType < results[j].EntityType
+		}
+		return results[i].Version > results[j].Version
+	})
+	return results, nil
+}
+
+func (r *inMemoryScoringModelRepository) Active(entityType string) (*models.ScoringModel, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, m := range r.models {
+		if m.EntityType == entityType && m.Status == models.ModelStatusActive {
+			return m, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (r *inMemoryScoringModelRepository) Activate(id string) (*models.ScoringModel, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	model, exists := r.models[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	if model.Status == models.ModelStatusActive {
+		return model, nil
+	}
+
+	for _, m := range r.models {
+		if m.EntityType == model.EntityType && m.Status == models.ModelStatusActive {
+			m.Status = models.ModelStatusRetired
+		}
+	}
+	now := time.Now()
+	model.Status = models.ModelStatusActive
+	model.ActivatedAt = &now
+	return model, nil
+}
@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+// WatchlistRepository stores entities reassessed on a schedule. An entity
+// is watched at most once per entity type.
+type WatchlistRepository interface {
+	// Create adds a watch, or returns ErrConflict if the entity is watched
+	Create(watch *models.WatchedEntity) error
+	GetByID(id string) (*models.WatchedEntity, error)
+	// List returns a page of watches, oldest first, and how many there are
+	List(limit, offset int) ([]*models.WatchedEntity, int, error)
+	Delete(id string) error
+	// Due returns active watches whose next run is at or before now,
+	// earliest first
+	Due(now time.Time, limit int) ([]*models.WatchedEntity, error)
+	// RecordRun stores the outcome of a scheduled run and the next run time
+	RecordRun(watch *models.WatchedEntity) error
+}
+
+type inMemoryWatchlistRepository struct {
+	watches map[string]*models.WatchedEntity
+	mu      sync.RWMutex
+}
+
+func NewWatchlistRepository() WatchlistRepository {
+	return &inMemoryWatchlistRepository{
+		watches: make(map[string]*models.WatchedEntity),
+	}
+}
+
+func (r *inMemoryWatchlistRepository) Create(watch *models.WatchedEntity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, w := range r.watches {
+		if w.EntityID == watch.EntityID && w.EntityType == watch.EntityType {
+			return ErrConflict
+		}
+	}
+	c := *watch
+	r.watches[watch.ID] = &c
+	return nil
+}
+
+func (r *inMemoryWatchlistRepository) GetByID(id string) (*models.WatchedEntity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	watch, exists := r.watches[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	c := *watch
+	return &c, nil
+}
+
+func (r *inMemoryWatchlistRepository) List(limit, offset int) ([]*models.WatchedEntity, int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	results := make([]*models.WatchedEntity, 0, len(r.watches))
+	for _, w := range r.watches {
+		c := *w
+		results = append(results, &c)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].CreatedAt.Before(results[j].CreatedAt) })
+
+	total := len(results)
+	if offset >= total {
+		return []*models.WatchedEntity{}, total, nil
+	}
+	results = results[offset:]
+	if limit > 0 && limit < len(results) {
+		results = results[:limit]
+	}
+	return results, total, nil
+}
+
+func (r *inMemoryWatchlistRepository) Delete(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.watches[id]; !exists {
+		return ErrNotFound
+	}
+	delete(r.watches, id)
+	return nil
+}
+
+func (r *inMemoryWatchlistRepository) Due(now time.Time, limit int) ([]*models.WatchedEntity, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	due := make([]*models.WatchedEntity, 0)
+	for _, w := range r.watches {
+		if w.Active && !w.NextRunAt.After(now) {
+			c := *w
+			due = append(due, &c)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].NextRunAt.Before(due[j].NextRunAt) })
+	if limit > 0 && limit < len(due) {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (r *inMemoryWatchlistRepository) RecordRun(watch *models.WatchedEntity) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.watches[watch.ID]
+	if !exists {
+		return ErrNotFound
+	}
+	stored.NextRunAt = watch.NextRunAt
+	stored.LastRunAt = watch.LastRunAt
+	stored.LastAssessmentID = watch.LastAssessmentID
+	stored.LastScore = watch.LastScore
+	stored.LastDimensionScores = watch.LastDimensionScores
+	stored.LastError = watch.LastError
+	stored.UpdatedAt = watch.UpdatedAt
+	return nil
+}
@@ -0,0 +1,188 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+
+	models "atlas-core-api/services/risk-assessment/internal/domain"
+)
+
+type postgresWatchlistRepository struct {
+	db *sql.DB
+}
+
+// NewPostgresWatchlistRepository stores watches in risk_watchlist
+func NewPostgresWatchlistRepository(db *sql.DB) WatchlistRepository {
+	return &postgresWatchlistRepository{db: db}
+}
+
+const selectWatch = `
+	SELECT id, entity_id, entity_type, dimensions, schedule, change_threshold, is_active,
+	       next_run_at, last_run_at, COALESCE(last_assessment_id::text, ''), last_score,
+	       last_dimension_scores, COALESCE(last_error, ''), COALESCE(created_by, ''),
+	       created_at, updated_at
+	FROM risk_watchlist`
+
+func (r *postgresWatchlistRepository) Create(watch *models.WatchedEntity) error {
+	dimensions := watch.Dimensions
+	if dimensions == nil {
+		dimensions = []string{}
+	}
+	dimensionsJSON, err := json.Marshal(dimensions)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO risk_watchlist (
+			id, entity_id, entity_type, dimensions, schedule, change_threshold, is_active,
+			next_run_at, created_by, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11)`,
+		watch.ID, watch.EntityID, watch.EntityType, dimensionsJSON, watch.Schedule, watch.ChangeThreshold,
+		watch.Active, watch.NextRunAt, watch.CreatedBy, watch.CreatedAt, watch.UpdatedAt,
+	)
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrConflict
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return nil
+}
+
+func (r *postgresWatchlistRepository) GetByID(id string) (*models.WatchedEntity, error) {
+	watch, err := scanWatch(r.db.QueryRow(selectWatch+` WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) || isInvalidUUID(err) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return watch, nil
+}
+
+func (r *postgresWatchlistRepository) List(limit, offset int) ([]*models.WatchedEntity, int, error) {
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM risk_watchlist`).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+
+	// LIMIT NULL returns every row
+	var pageSize interface{}
+	if limit > 0 {
+		pageSize = limit
+	}
+	rows, err := r.db.Query(selectWatch+` ORDER BY created_at LIMIT $1 OFFSET $2`, pageSize, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	watches, err := scanWatches(rows)
+	if err != nil {
+		return nil, 0, err
+	}
+	return watches, total, nil
+}
+
+func (r *postgresWatchlistRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM risk_watchlist WHERE id = $1`, id)
+	if err != nil {
+		if isInvalidUUID(err) {
+			return ErrNotFound
+		}
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *postgresWatchlistRepository) Due(now time.Time, limit int) ([]*models.WatchedEntity, error) {
+	rows, err := r.db.Query(selectWatch+`
+		WHERE is_active AND next_run_at <= $1
+		ORDER BY next_run_at
+		LIMIT $2`, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	defer rows.Close()
+
+	return scanWatches(rows)
+}
+
+func (r *postgresWatchlistRepository) RecordRun(watch *models.WatchedEntity) error {
+	var scores interface{}
+	if watch.LastDimensionScores != nil {
+		encoded, err := json.Marshal(watch.LastDimensionScores)
+		if err != nil {
+			return err
+		}
+		scores = encoded
+	}
+
+	result, err := r.db.Exec(`
+		UPDATE risk_watchlist
+		SET next_run_at = $2, last_run_at = $3, last_assessment_id = NULLIF($4, '')::uuid,
+		    last_score = $5, last_dimension_scores = $6, last_error = NULLIF($7, ''), updated_at = $8
+		WHERE id = $1`,
+		watch.ID, watch.NextRunAt, watch.LastRunAt, watch.LastAssessmentID,
+		watch.LastScore, scores, watch.LastError, watch.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanWatch(row rowScanner) (*models.WatchedEntity, error) {
+	watch := &models.WatchedEntity{}
+	var dimensions, scores []byte
+	var lastRun sql.NullTime
+	var lastScore sql.NullFloat64
+	if err := row.Scan(&watch.ID, &watch.EntityID, &watch.EntityType, &dimensions, &watch.Schedule,
+		&watch.ChangeThreshold, &watch.Active, &watch.NextRunAt, &lastRun, &watch.LastAssessmentID,
+		&lastScore, &scores, &watch.LastError, &watch.CreatedBy, &watch.CreatedAt, &watch.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(dimensions, &watch.Dimensions); err != nil {
+		return nil, err
+	}
+	if scores != nil {
+		if err := json.Unmarshal(scores, &watch.LastDimensionScores); err != nil {
+			return nil, err
+		}
+	}
+	if lastRun.Valid {
+		watch.LastRunAt = &lastRun.Time
+	}
+	if lastScore.Valid {
+		watch.LastScore = &lastScore.Float64
+	}
+	return watch, nil
+}
+
+func scanWatches(rows *sql.Rows) ([]*models.WatchedEntity, error) {
+	watches := make([]*models.WatchedEntity, 0)
+	for rows.Next() {
+		watch, err := scanWatch(rows)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+		}
+		watches = append(watches, watch)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDatabase, err)
+	}
+	return watches, nil
+}